@@ -30,16 +30,24 @@ func generateFeatureMatrix() error {
 			{"DNS Provider", "Can manage and serve DNS zones"},
 			{"Registrar", "The provider has registrar capabilities to set nameservers for zones"},
 			{"ALIAS", "Provider supports some kind of ALIAS, ANAME or flattened CNAME record type"},
+			{"APL", "Provider can manage APL records"},
 			{"AUTODNSSEC", "Provider can automatically manage DNSSEC"},
 			{"CAA", "Provider can manage CAA records"},
+			{"CSYNC", "Provider can manage CSYNC records"},
+			{"DNAME", "Provider can manage DNAME records"},
+			{"HINFO", "Provider can manage HINFO records"},
+			{"HTTPS", "Provider can manage HTTPS records"},
+			{"LOC", "Provider can manage LOC records"},
 			{"PTR", "Provider supports adding PTR records for reverse lookup zones"},
 			{"NAPTR", "Provider can manage NAPTR records"},
 			{"SOA", "Provider can manage SOA records"},
 			{"SRV", "Driver has explicitly implemented SRV record management"},
 			{"SSHFP", "Provider can manage SSHFP records"},
+			{"SVCB", "Provider can manage SVCB records"},
 			{"TLSA", "Provider can manage TLSA records"},
 			{"TXTMulti", "Provider can manage TXT records with multiple strings"},
 			{"R53_ALIAS", "Provider supports Route 53 limited ALIAS"},
+			{"ROUTING", "Provider can manage routing policies (weighted, latency, geo) of records"},
 			{"AZURE_ALIAS", "Provider supports Azure DNS limited ALIAS"},
 			{"DS", "Provider supports adding DS records"},
 
@@ -78,15 +86,23 @@ func generateFeatureMatrix() error {
 		fm.SetSimple("DNS Provider", false, func() bool { return providers.DNSProviderTypes[p].Initializer != nil })
 		fm.SetSimple("Registrar", false, func() bool { return providers.RegistrarTypes[p] != nil })
 		setCap("ALIAS", providers.CanUseAlias)
+		setCap("APL", providers.CanUseAPL)
 		setCap("AUTODNSSEC", providers.CanAutoDNSSEC)
 		setCap("CAA", providers.CanUseCAA)
+		setCap("CSYNC", providers.CanUseCSYNC)
+		setCap("DNAME", providers.CanUseDNAME)
+		setCap("HINFO", providers.CanUseHINFO)
+		setCap("HTTPS", providers.CanUseHTTPS)
+		setCap("LOC", providers.CanUseLOC)
 		setCap("NAPTR", providers.CanUseNAPTR)
 		setCap("PTR", providers.CanUsePTR)
 		setCap("R53_ALIAS", providers.CanUseRoute53Alias)
+		setCap("ROUTING", providers.CanUseRouting)
 		setCap("AZURE_ALIAS", providers.CanUseAzureAlias)
 		setCap("SOA", providers.CanUseSOA)
 		setCap("SRV", providers.CanUseSRV)
 		setCap("SSHFP", providers.CanUseSSHFP)
+		setCap("SVCB", providers.CanUseSVCB)
 		setCap("TLSA", providers.CanUseTLSA)
 		setCap("get-zones", providers.CanGetZones)
 		setCap("DS", providers.CanUseDS)
@@ -1,11 +1,14 @@
 package commands
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"os"
+	"os/signal"
 	"sort"
 	"strings"
+	"syscall"
 
 	"github.com/urfave/cli/v2"
 
@@ -62,12 +65,28 @@ func Run(v string) int {
 	sort.Sort(cli.CommandsByName(commands))
 	app.Commands = commands
 	app.EnableBashCompletion = true
-	if err := app.Run(os.Args); err != nil {
+	if err := app.RunContext(interruptContext(), os.Args); err != nil {
 		return 1
 	}
 	return 0
 }
 
+// interruptContext returns a context that is cancelled by the first
+// SIGINT or SIGTERM, so that the commands can stop cleanly. Another signal
+// terminates the process as usual.
+func interruptContext() context.Context {
+	ctx, cancel := context.WithCancel(context.Background())
+	sig := make(chan os.Signal, 1)
+	signal.Notify(sig, os.Interrupt, syscall.SIGTERM)
+	go func() {
+		<-sig
+		signal.Stop(sig)
+		fmt.Fprintln(os.Stderr, "Interrupted, stopping. Interrupt again to exit immediately.")
+		cancel()
+	}()
+	return ctx
+}
+
 // Shared config types
 
 // GetDNSConfigArgs contains what we need to get a valid dns config.
@@ -258,6 +277,22 @@ func (args *FilterArgs) flags() []cli.Flag {
 	}
 }
 
+// defaultConcurrency is the number of zones (or certs) that are worked on at
+// a time, unless set by --concurrency or $DNSCONTROL_CONCURRENCY.
+const defaultConcurrency = 10
+
+// concurrencyFlag returns the --concurrency flag. Providers without the
+// CanRunConcurrently capability always run one zone at a time, regardless.
+func concurrencyFlag(dest *int, usage string) cli.Flag {
+	return &cli.IntFlag{
+		Name:        "concurrency",
+		Destination: dest,
+		Value:       defaultConcurrency,
+		EnvVars:     []string{"DNSCONTROL_CONCURRENCY"},
+		Usage:       usage,
+	}
+}
+
 func (args *FilterArgs) shouldRunProvider(name string, dc *models.DomainConfig) bool {
 	if args.Providers == "all" {
 		return true
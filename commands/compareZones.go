@@ -0,0 +1,200 @@
+package commands
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/StackExchange/dnscontrol/v3/models"
+	"github.com/StackExchange/dnscontrol/v3/pkg/diff"
+	"github.com/StackExchange/dnscontrol/v3/pkg/printer"
+	"github.com/StackExchange/dnscontrol/v3/providers"
+	"github.com/StackExchange/dnscontrol/v3/providers/config"
+	"github.com/urfave/cli/v2"
+)
+
+var _ = cmd(catUtils, func() *cli.Command {
+	var args CompareZonesArgs
+	return &cli.Command{
+		Name:  "compare-zones",
+		Usage: "compares the records of zones at two providers (stand-alone)",
+		Action: func(ctx *cli.Context) error {
+			if ctx.NArg() < 5 {
+				return cli.NewExitError("Arguments should be: credskey1 providername1 credskey2 providername2 zone(s) (Ex: old BIND new HETZNER example.com)", 1)
+			}
+			args.CredNameA = ctx.Args().Get(0)
+			args.ProviderNameA = ctx.Args().Get(1)
+			args.CredNameB = ctx.Args().Get(2)
+			args.ProviderNameB = ctx.Args().Get(3)
+			args.ZoneNames = ctx.Args().Slice()[4:]
+			return exit(CompareZonesContext(ctx.Context, args))
+		},
+		Flags:     args.flags(),
+		UsageText: "dnscontrol compare-zones [command options] credkey1 provider1 credkey2 provider2 zone [...]",
+		Description: `Compare the records of zones hosted at two providers, e.g. before moving
+a zone to a new provider. This is a stand-alone utility, it never changes
+any records.
+
+ARGUMENTS:
+   credkey1:  The name used in creds.json for the first (reference) provider
+   provider1: The name of the first provider (e.g. BIND, HETZNER)
+   credkey2:  The name used in creds.json for the second provider
+   provider2: The name of the second provider
+   zone:      One or more zones (domains) to compare
+
+The differences are reported as the changes that would make the zone at
+the second provider equal to the one at the first provider. The NS records
+at the apex and the SOA record are ignored, as each provider has its own,
+unless --include-ns-soa is given.
+
+EXAMPLES:
+   dnscontrol compare-zones old BIND new HETZNER example.com
+   dnscontrol compare-zones --format=json r53 ROUTE53 hetzner HETZNER example.com other.com`,
+	}
+}())
+
+// CompareZonesArgs contains all data/flags needed to run compare-zones, independently of CLI.
+type CompareZonesArgs struct {
+	GetCredentialsArgs
+	CredNameA     string   // key in creds.json of the reference provider
+	ProviderNameA string   // provider name: BIND, GANDI_V5, etc
+	CredNameB     string   // key in creds.json of the compared provider
+	ProviderNameB string   // provider name: BIND, GANDI_V5, etc
+	ZoneNames     []string // The zones to compare
+	Format        string
+	IncludeNSSOA  bool
+	WarnChanges   bool
+}
+
+func (args *CompareZonesArgs) flags() []cli.Flag {
+	flags := args.GetCredentialsArgs.flags()
+	flags = append(flags, &cli.StringFlag{
+		Name:        "format",
+		Destination: &args.Format,
+		Value:       "text",
+		Usage:       `Output format: text or json (the report of preview, on stdout)`,
+	})
+	flags = append(flags, &cli.BoolFlag{
+		Name:        "include-ns-soa",
+		Destination: &args.IncludeNSSOA,
+		Usage:       `Also compare the NS records at the apex and the SOA record`,
+	})
+	flags = append(flags, &cli.BoolFlag{
+		Name:        "expect-no-changes",
+		Destination: &args.WarnChanges,
+		Usage:       `set to true for non-zero return code if the zones differ`,
+	})
+	return flags
+}
+
+// CompareZones implements the compare-zones subcommand.
+func CompareZones(args CompareZonesArgs) error {
+	return CompareZonesContext(context.Background(), args)
+}
+
+// CompareZonesContext is like CompareZones. Cancelling ctx aborts the
+// requests of the providers that support it.
+func CompareZonesContext(ctx context.Context, args CompareZonesArgs) error {
+	providerConfigs, err := config.LoadProviderConfigs(args.CredsFile)
+	if err != nil {
+		return fmt.Errorf("failed CompareZones lpc(%q): %w", args.CredsFile, err)
+	}
+	a, err := providers.CreateDNSProvider(args.ProviderNameA, providerConfigs[args.CredNameA], nil)
+	if err != nil {
+		return fmt.Errorf("failed CompareZones cdp(%q): %w", args.CredNameA, err)
+	}
+	b, err := providers.CreateDNSProvider(args.ProviderNameB, providerConfigs[args.CredNameB], nil)
+	if err != nil {
+		return fmt.Errorf("failed CompareZones cdp(%q): %w", args.CredNameB, err)
+	}
+	return compareZones(ctx, args, a, b, printer.DefaultPrinter)
+}
+
+func compareZones(ctx context.Context, args CompareZonesArgs, a, b models.DNSProvider, out printer.CLI) (err error) {
+	var report *jsonReport
+	switch args.Format {
+	case "", "text":
+	case "json":
+		report = newJSONReport()
+		stdout, restore := redirectStdout()
+		defer restore()
+		defer func() {
+			if writeErr := report.write(stdout); writeErr != nil && err == nil {
+				err = writeErr
+			}
+		}()
+	default:
+		return fmt.Errorf("unknown format %q, expected text or json", args.Format)
+	}
+
+	anyErrors := false
+	totalChanges := 0
+	for _, zone := range args.ZoneNames {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		out.StartDomain(zone)
+		report.startDomain(zone)
+		out.StartDNSProvider(fmt.Sprintf("%s compared to %s", args.CredNameB, args.CredNameA), false)
+		create, del, modify, err := compareZone(ctx, zone, a, b, args.IncludeNSSOA)
+		var changes diff.Changeset
+		for _, set := range []diff.Changeset{create, del, modify} {
+			changes = append(changes, set...)
+		}
+		out.EndProvider(len(changes), err)
+		var records []jsonChange
+		if err == nil {
+			records = newJSONChanges(create, del, modify)
+		}
+		report.addProvider(args.CredNameB, args.ProviderNameB, false, records, nil, err)
+		if err != nil {
+			anyErrors = true
+			continue
+		}
+		for i, c := range changes {
+			out.PrintCorrection(i, &models.Correction{Msg: c.String()})
+		}
+		totalChanges += len(changes)
+	}
+	out.Printf("Done. %d differences.\n", totalChanges)
+	if anyErrors {
+		return fmt.Errorf("completed with errors")
+	}
+	if totalChanges != 0 && args.WarnChanges {
+		return fmt.Errorf("the zones differ")
+	}
+	return nil
+}
+
+// compareZone returns the changes that would make the records of zone at
+// b equal to those at a.
+func compareZone(ctx context.Context, zone string, a, b models.DNSProvider, includeNSSOA bool) (create, del, modify diff.Changeset, err error) {
+	want, err := providers.GetZoneRecordsContext(ctx, a, zone)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	have, err := providers.GetZoneRecordsContext(ctx, b, zone)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	if !includeNSSOA {
+		want, have = withoutApexNSSOA(want), withoutApexNSSOA(have)
+	}
+	models.PostProcessRecords(want)
+	models.PostProcessRecords(have)
+	dc := &models.DomainConfig{Name: zone, Records: want}
+	_, create, del, modify, err = diff.New(dc).IncrementalDiff(have)
+	return create, del, modify, err
+}
+
+// withoutApexNSSOA removes the records that providers generate for each
+// zone: the NS records at the apex and the SOA record.
+func withoutApexNSSOA(recs models.Records) models.Records {
+	var out models.Records
+	for _, rec := range recs {
+		if (rec.Type == "NS" || rec.Type == "SOA") && rec.GetLabel() == "@" {
+			continue
+		}
+		out = append(out, rec)
+	}
+	return out
+}
@@ -0,0 +1,92 @@
+package commands
+
+import (
+	"bytes"
+	"context"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/StackExchange/dnscontrol/v3/pkg/printer"
+	"github.com/StackExchange/dnscontrol/v3/providers"
+)
+
+func TestCompareZones(t *testing.T) {
+	old := bindTestDir(t, "$TTL 300\n"+
+		"@ IN SOA ns1.old.example. hostmaster.example.com. 1 3600 600 604800 1440\n"+
+		"@ IN NS ns1.old.example.\n"+
+		"www IN A 192.0.2.1\n"+
+		"api IN A 192.0.2.2\n"+
+		"mail IN A 192.0.2.3\n")
+	defer os.RemoveAll(old)
+	newZone := "$TTL 300\n" +
+		"@ IN SOA ns1.new.example. hostmaster.example.com. 7 7200 600 604800 1440\n" +
+		"@ IN NS ns1.new.example.\n" +
+		"www IN A 192.0.2.1\n" +
+		"mail IN A 192.0.2.4\n" +
+		"stale IN A 192.0.2.9\n"
+	newDir := bindTestDir(t, newZone)
+	defer os.RemoveAll(newDir)
+	args := CompareZonesArgs{
+		CredNameA:     "old",
+		ProviderNameA: "BIND",
+		CredNameB:     "new",
+		ProviderNameB: "BIND",
+		ZoneNames:     []string{"example.com"},
+		WarnChanges:   true,
+	}
+	a, err := providers.CreateDNSProvider("BIND", map[string]string{"directory": old}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	b, err := providers.CreateDNSProvider("BIND", map[string]string{"directory": newDir}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	buf := &bytes.Buffer{}
+	err = compareZones(context.Background(), args, a, b, &printer.ConsolePrinter{Writer: buf})
+	if err == nil {
+		t.Errorf("expected an error with --expect-no-changes, the zones differ")
+	}
+	var got []string
+	for _, line := range strings.Split(buf.String(), "\n") {
+		if strings.HasPrefix(line, "#") {
+			got = append(got, line)
+		}
+	}
+	want := []string{
+		"#1: CREATE A api.example.com 192.0.2.2 ttl=300",
+		"#2: DELETE A stale.example.com 192.0.2.9 ttl=300",
+		"#3: MODIFY A mail.example.com: (192.0.2.4 ttl=300) -> (192.0.2.3 ttl=300)",
+	}
+	if strings.Join(got, "\n") != strings.Join(want, "\n") {
+		t.Errorf("expected differences\n%s\ngot\n%s", strings.Join(want, "\n"), buf)
+	}
+
+	// The NS and SOA records are only compared on request.
+	args.IncludeNSSOA = true
+	buf.Reset()
+	compareZones(context.Background(), args, a, b, &printer.ConsolePrinter{Writer: buf})
+	if !strings.Contains(buf.String(), "NS example.com") || !strings.Contains(buf.String(), "SOA example.com") {
+		t.Errorf("expected the NS and SOA records to differ, got\n%s", buf)
+	}
+
+	// Nothing is changed.
+	data, err := ioutil.ReadFile(filepath.Join(newDir, "example.com.zone"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(data) != newZone {
+		t.Errorf("expected the zone to be unchanged, got\n%s", data)
+	}
+
+	// A zone compared to itself has no differences.
+	args.IncludeNSSOA = false
+	args.CredNameB = "old"
+	if err := compareZones(context.Background(), args, a, a, &printer.ConsolePrinter{Writer: ioutil.Discard}); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+}
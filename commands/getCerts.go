@@ -1,15 +1,19 @@
 package commands
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
+	"io/ioutil"
 	"os"
 	"regexp"
+	"strconv"
 	"strings"
 
 	"github.com/StackExchange/dnscontrol/v3/models"
 	"github.com/StackExchange/dnscontrol/v3/pkg/acme"
 	"github.com/StackExchange/dnscontrol/v3/pkg/normalize"
+	"github.com/StackExchange/dnscontrol/v3/pkg/notifications"
 	"github.com/StackExchange/dnscontrol/v3/pkg/printer"
 	"github.com/urfave/cli/v2"
 )
@@ -20,7 +24,7 @@ var _ = cmd(catUtils, func() *cli.Command {
 		Name:  "get-certs",
 		Usage: "Issue certificates via Let's Encrypt",
 		Action: func(c *cli.Context) error {
-			return exit(GetCerts(args))
+			return exit(GetCertsContext(c.Context, args))
 		},
 		Flags: args.flags(),
 	}
@@ -31,20 +35,45 @@ type GetCertsArgs struct {
 	GetDNSConfigArgs
 	GetCredentialsArgs
 
-	ACMEServer     string
-	CertsFile      string
-	RenewUnderDays int
-	CertDirectory  string
-	Email          string
-	AgreeTOS       bool
-	Verbose        bool
-	Vault          bool
-	VaultPath      string
-	Only           string
+	ACMEServer    string
+	CertsFile     string
+	RenewUnder    string
+	RenewJitter   int
+	ExpiryWarning int
+	CertDirectory string
+	Email         string
+	AgreeTOS      bool
+	Verbose       bool
+	Vault         bool
+	VaultAndDir   bool
+	VaultPath     string
+	VaultKV       int
+	VaultVersions string
+	K8s           bool
+	K8sNamespace  string
+	K8sSecret     string
+	Only          string
+	Concurrency   int
+	VerifyOnly    bool
 
 	Notify bool
 
-	IgnoredProviders string
+	IgnoredProviders   string
+	ChallengeOnly      string
+	ForcePending       bool
+	ForcePendingExcept string
+	Resolvers          string
+	Nameservers        string
+	NoPreCheck         bool
+	MaxFailedChecks    int
+	CABundle           string
+	ForcePurge         string
+	CheckSCT           bool
+	CAACheck           string
+	EABKID             string
+	EABHMAC            string
+	LogFormat          string
+	LogLevel           string
 }
 
 func (args *GetCertsArgs) flags() []cli.Flag {
@@ -57,11 +86,23 @@ func (args *GetCertsArgs) flags() []cli.Flag {
 		Value:       "live",
 		Usage:       `ACME server to issue against. Give full directory endpoint. Can also use 'staging' or 'live' for standard Let's Encrypt endpoints.`,
 	})
-	flags = append(flags, &cli.IntFlag{
+	flags = append(flags, &cli.StringFlag{
 		Name:        "renew",
-		Destination: &args.RenewUnderDays,
-		Value:       15,
-		Usage:       `Renew certs with less than this many days remaining`,
+		Destination: &args.RenewUnder,
+		Value:       "15",
+		Usage:       `Renew certs with less than this many days remaining, or this percentage of their validity (e.g. 33%)`,
+	})
+	flags = append(flags, &cli.IntFlag{
+		Name:        "renewJitter",
+		Destination: &args.RenewJitter,
+		Value:       0,
+		Usage:       `Move the renewal threshold of each cert by up to this many days, to spread renewals`,
+	})
+	flags = append(flags, &cli.IntFlag{
+		Name:        "expiryWarning",
+		Destination: &args.ExpiryWarning,
+		Value:       0,
+		Usage:       `Notify if a cert expires in less than this many days and is not renewed (0 disables the warning)`,
 	})
 	flags = append(flags, &cli.StringFlag{
 		Name:        "dir",
@@ -91,17 +132,141 @@ func (args *GetCertsArgs) flags() []cli.Flag {
 		Destination: &args.Vault,
 		Usage:       `Store certificates as secrets in hashicorp vault instead of on disk.`,
 	})
+	flags = append(flags, &cli.BoolFlag{
+		Name:        "vaultAndDir",
+		Destination: &args.VaultAndDir,
+		Usage:       `Store certificates both in hashicorp vault and on disk.`,
+	})
+	flags = append(flags, &cli.StringFlag{
+		Name:        "eabKID",
+		Destination: &args.EABKID,
+		EnvVars:     []string{"ACME_EAB_KID"},
+		Usage:       `Key ID for the external account binding required by some ACME servers to register (e.g. ZeroSSL)`,
+	})
+	flags = append(flags, &cli.StringFlag{
+		Name:        "eabHMAC",
+		Destination: &args.EABHMAC,
+		EnvVars:     []string{"ACME_EAB_HMAC"},
+		Usage:       `HMAC key for the external account binding. Prefer setting ACME_EAB_HMAC over this flag`,
+	})
 	flags = append(flags, &cli.StringFlag{
 		Name:        "vaultPath",
 		Destination: &args.VaultPath,
 		Value:       "/secret/certs",
 		Usage:       `Path in vault to store certificates`,
 	})
+	flags = append(flags, &cli.IntFlag{
+		Name:        "vaultKVVersion",
+		Destination: &args.VaultKV,
+		Usage:       `Version (1 or 2) of the KV secrets engine in vault. Detected if not set`,
+	})
+	flags = append(flags, &cli.StringFlag{
+		Name:        "vaultSecretVersions",
+		Destination: &args.VaultVersions,
+		Usage:       `Versions of certificates to read from vault, comma separated, e.g. 'mainCert=3'. Needs KV version 2`,
+	})
+	flags = append(flags, &cli.BoolFlag{
+		Name:        "k8s",
+		Destination: &args.K8s,
+		Usage:       `Store certificates as Kubernetes TLS secrets instead of on disk.`,
+	})
+	flags = append(flags, &cli.StringFlag{
+		Name:        "k8sNamespace",
+		Destination: &args.K8sNamespace,
+		Value:       "default",
+		Usage:       `Kubernetes namespace to store certificates in`,
+	})
+	flags = append(flags, &cli.StringFlag{
+		Name:        "k8sSecret",
+		Destination: &args.K8sSecret,
+		Value:       "dnscontrol",
+		Usage:       `Prefix of the names of the Kubernetes secrets`,
+	})
 	flags = append(flags, &cli.StringFlag{
 		Name:        "skip",
 		Destination: &args.IgnoredProviders,
 		Value:       "",
-		Usage:       `Provider names to not use for challenges (comma separated)`,
+		Usage:       `Provider names to not use for challenges (comma separated), unless a certificate names its own`,
+	})
+	flags = append(flags, &cli.StringFlag{
+		Name:        "logFormat",
+		Destination: &args.LogFormat,
+		Value:       "text",
+		Usage:       `Format of the log messages: text or json (one object per line, on stderr)`,
+	})
+	flags = append(flags, &cli.StringFlag{
+		Name:        "logLevel",
+		Destination: &args.LogLevel,
+		Value:       "info",
+		Usage:       `Lowest level of the log messages: debug, info, warn or error (--verbose enables debug)`,
+	})
+	flags = append(flags, &cli.StringFlag{
+		Name:        "challengeOnly",
+		Destination: &args.ChallengeOnly,
+		Value:       "",
+		Usage:       `Domain names (comma separated) for which only the challenge records are changed, ignoring any other pending corrections`,
+	})
+	flags = append(flags, &cli.BoolFlag{
+		Name:        "forcePending",
+		Destination: &args.ForcePending,
+		Usage:       `Issue certificates even if there are pending corrections, printing them as warnings. Only the challenge records are changed`,
+	})
+	flags = append(flags, &cli.StringFlag{
+		Name:        "forcePendingExcept",
+		Destination: &args.ForcePendingExcept,
+		Value:       "",
+		Usage:       `Record types (comma separated, e.g. TXT) whose pending changes still abort issuing; implies --forcePending`,
+	})
+	flags = append(flags, &cli.StringFlag{
+		Name:        "resolvers",
+		Destination: &args.Resolvers,
+		Value:       "",
+		Usage:       `DNS resolvers (comma separated, host[:port]) used to check that challenge records are visible`,
+	})
+	flags = append(flags, &cli.StringFlag{
+		Name:        "nameservers",
+		Destination: &args.Nameservers,
+		Value:       "",
+		Usage:       `Nameservers (comma separated, host[:port]) queried directly to check that challenge records are visible (default: the nameservers of the domain)`,
+	})
+	flags = append(flags, &cli.BoolFlag{
+		Name:        "noPreCheck",
+		Destination: &args.NoPreCheck,
+		Usage:       `Do not check that challenge records are visible before asking the ACME server to validate them`,
+	})
+	flags = append(flags, &cli.StringFlag{
+		Name:        "forcePurge",
+		Destination: &args.ForcePurge,
+		Value:       "",
+		Usage:       `Label globs (comma separated) whose records are always removed when cleaning up, even for NO_PURGE domains`,
+	})
+	flags = append(flags, &cli.StringFlag{
+		Name:        "caBundle",
+		Destination: &args.CABundle,
+		Value:       "",
+		Usage:       `PEM file with CA certificates to trust for the ACME server, e.g. for an internal CA`,
+	})
+	flags = append(flags, &cli.IntFlag{
+		Name:        "maxFailedChecks",
+		Destination: &args.MaxFailedChecks,
+		Value:       0,
+		Usage:       `Give up after this many failed DNS checks of a challenge record (default: poll for 5 minutes)`,
+	})
+	flags = append(flags, &cli.StringFlag{
+		Name:        "caaCheck",
+		Destination: &args.CAACheck,
+		Value:       "",
+		Usage:       `Before ordering a cert, check that CAA records permit the CA with this identifier (e.g. letsencrypt.org) to issue`,
+	})
+	flags = append(flags, &cli.BoolFlag{
+		Name:        "checkSCT",
+		Destination: &args.CheckSCT,
+		Usage:       `Warn if a newly issued cert has no embedded certificate transparency SCTs`,
+	})
+	flags = append(flags, &cli.BoolFlag{
+		Name:        "verifyOnly",
+		Destination: &args.VerifyOnly,
+		Usage:       `Only create and remove the challenge records of every domain, without ordering certs`,
 	})
 	flags = append(flags, &cli.BoolFlag{
 		Name:        "verbose",
@@ -113,6 +278,8 @@ func (args *GetCertsArgs) flags() []cli.Flag {
 		Destination: &args.Notify,
 		Usage:       `set to true to send notifications to configured destinations`,
 	})
+	flags = append(flags, concurrencyFlag(&args.Concurrency,
+		`Issue up to this many certs at a time (certs sharing a zone, or using providers that do not support it, are still issued one after another)`))
 	flags = append(flags, &cli.StringFlag{
 		Name:        "only",
 		Destination: &args.Only,
@@ -123,6 +290,13 @@ func (args *GetCertsArgs) flags() []cli.Flag {
 
 // GetCerts implements the get-certs command.
 func GetCerts(args GetCertsArgs) error {
+	return GetCertsContext(context.Background(), args)
+}
+
+// GetCertsContext is like GetCerts. Cancelling ctx aborts the requests of
+// the DNS providers that support it, the challenge records are cleaned up
+// regardless.
+func GetCertsContext(ctx context.Context, args GetCertsArgs) error {
 	fmt.Println(args.JSFile)
 	// check agree flag
 	if !args.AgreeTOS {
@@ -131,6 +305,10 @@ func GetCerts(args GetCertsArgs) error {
 	if args.Email == "" {
 		return fmt.Errorf("must provide email to use for Let's Encrypt registration")
 	}
+	renewUnder, err := acme.ParseRenewUnder(args.RenewUnder)
+	if err != nil {
+		return fmt.Errorf("--renew: %w", err)
+	}
 
 	// load dns config
 	cfg, err := GetDNSConfig(args.GetDNSConfigArgs)
@@ -149,6 +327,11 @@ func GetCerts(args GetCertsArgs) error {
 	for _, skip := range strings.Split(args.IgnoredProviders, ",") {
 		acme.IgnoredProviders[skip] = true
 	}
+	for _, domain := range strings.Split(args.ChallengeOnly, ",") {
+		if domain != "" {
+			acme.ChallengeOnlyDomains[domain] = true
+		}
+	}
 
 	// load cert list
 	certList := []*acme.CertConfig{}
@@ -169,30 +352,25 @@ func GetCerts(args GetCertsArgs) error {
 		return err
 	}
 
-	acmeServer := args.ACMEServer
-	if acmeServer == "live" {
-		acmeServer = acme.LetsEncryptLive
-	} else if acmeServer == "staging" {
-		acmeServer = acme.LetsEncryptStage
-	}
-
-	var client acme.Client
-
-	if args.Vault {
-		client, err = acme.NewVault(cfg, args.VaultPath, args.Email, acmeServer, notifier)
-	} else {
-		client, err = acme.New(cfg, args.CertDirectory, args.Email, acmeServer, notifier)
-	}
+	client, err := args.newClient(ctx, cfg, notifier)
 	if err != nil {
 		return err
 	}
-	var manyerr error
+	var certs []*acme.CertConfig
 	for _, cert := range certList {
 		if args.Only != "" && cert.CertName != args.Only {
 			continue
 		}
-		v := args.Verbose || printer.DefaultPrinter.Verbose
-		issued, err := client.IssueOrRenewCert(cert, args.RenewUnderDays, v)
+		certs = append(certs, cert)
+	}
+	if args.VerifyOnly {
+		return verifyChallenges(client, certs, notifier)
+	}
+	v := args.Verbose || printer.DefaultPrinter.Verbose
+	results := client.IssueOrRenewCerts(certs, renewUnder, v, args.Concurrency)
+	var manyerr error
+	for i, cert := range certs {
+		issued, err := results[i].Issued, results[i].Err
 		if issued || err != nil {
 			notifier.Notify(cert.CertName, "certificate", "Issued new certificate", err, false)
 		}
@@ -208,6 +386,130 @@ func GetCerts(args GetCertsArgs) error {
 	return manyerr
 }
 
+// verifyChallenges checks for all certs that the challenge records can be
+// created and removed, see acme.Client.VerifyChallengeCapability.
+func verifyChallenges(client acme.Client, certs []*acme.CertConfig, notifier notifications.Notifier) error {
+	var manyerr error
+	for _, cert := range certs {
+		if err := client.VerifyChallengeCapability(cert); err != nil {
+			if manyerr == nil {
+				manyerr = err
+			} else {
+				manyerr = fmt.Errorf("%w; %v", manyerr, err)
+			}
+		}
+	}
+	notifier.Done()
+	return manyerr
+}
+
+// newClient returns the ACME client configured by the flags.
+func (args *GetCertsArgs) newClient(ctx context.Context, cfg *models.DNSConfig, notifier notifications.Notifier) (acme.Client, error) {
+	acmeServer := args.ACMEServer
+	if acmeServer == "live" {
+		acmeServer = acme.LetsEncryptLive
+	} else if acmeServer == "staging" {
+		acmeServer = acme.LetsEncryptStage
+	}
+
+	opts := []acme.Option{acme.WithContext(ctx)}
+	switch args.LogFormat {
+	case "", "text":
+	case "json":
+		opts = append(opts, acme.WithLogger(acme.NewJSONLogger(os.Stderr)))
+	default:
+		return nil, fmt.Errorf("unknown log format %q, expected text or json", args.LogFormat)
+	}
+	if args.LogLevel != "" {
+		level, err := acme.ParseLevel(args.LogLevel)
+		if err != nil {
+			return nil, err
+		}
+		opts = append(opts, acme.WithLogLevel(level))
+	}
+	if args.Resolvers != "" {
+		opts = append(opts, acme.WithPreCheckResolvers(strings.Split(args.Resolvers, ",")))
+	}
+	if args.Nameservers != "" {
+		opts = append(opts, acme.WithAuthoritativeNameservers(strings.Split(args.Nameservers, ",")))
+	}
+	if args.ForcePending || args.ForcePendingExcept != "" {
+		var except []string
+		if args.ForcePendingExcept != "" {
+			except = strings.Split(args.ForcePendingExcept, ",")
+		}
+		opts = append(opts, acme.WithForcePendingCorrections(except))
+	}
+	if args.NoPreCheck {
+		opts = append(opts, acme.WithoutPreCheck())
+	}
+	if args.RenewJitter > 0 {
+		opts = append(opts, acme.WithRenewalJitter(args.RenewJitter))
+	}
+	if args.ExpiryWarning > 0 {
+		opts = append(opts, acme.WithExpiryWarning(args.ExpiryWarning))
+	}
+	if args.ForcePurge != "" {
+		opts = append(opts, acme.WithForcePurgeLabels(strings.Split(args.ForcePurge, ",")))
+	}
+	if args.CABundle != "" {
+		pemData, err := ioutil.ReadFile(args.CABundle)
+		if err != nil {
+			return nil, err
+		}
+		opts = append(opts, acme.WithCABundle(pemData))
+	}
+	if args.EABKID != "" || args.EABHMAC != "" {
+		opts = append(opts, acme.WithExternalAccountBinding(args.EABKID, args.EABHMAC))
+	}
+	if args.CAACheck != "" {
+		opts = append(opts, acme.WithCAACheck(args.CAACheck))
+	}
+	if args.CheckSCT {
+		opts = append(opts, acme.WithSCTCheck())
+	}
+	if args.MaxFailedChecks > 0 {
+		opts = append(opts, acme.WithMaxFailedChecks(args.MaxFailedChecks))
+	}
+	if args.VaultKV != 0 {
+		opts = append(opts, acme.WithVaultKVVersion(args.VaultKV))
+	}
+	if args.VaultVersions != "" {
+		versions, err := parseSecretVersions(args.VaultVersions)
+		if err != nil {
+			return nil, err
+		}
+		opts = append(opts, acme.WithVaultSecretVersions(versions))
+	}
+
+	switch {
+	case args.VaultAndDir:
+		return acme.NewVaultAndDirectory(cfg, args.CertDirectory, args.VaultPath, args.Email, acmeServer, notifier, opts...)
+	case args.K8s:
+		return acme.NewK8s(cfg, args.K8sNamespace, args.K8sSecret, args.Email, acmeServer, notifier, opts...)
+	case args.Vault:
+		return acme.NewVault(cfg, args.VaultPath, args.Email, acmeServer, notifier, opts...)
+	}
+	return acme.New(cfg, args.CertDirectory, args.Email, acmeServer, notifier, opts...)
+}
+
+// parseSecretVersions parses a list like "mainCert=3,otherCert=1".
+func parseSecretVersions(s string) (map[string]int, error) {
+	versions := map[string]int{}
+	for _, item := range strings.Split(s, ",") {
+		parts := strings.SplitN(item, "=", 2)
+		if len(parts) != 2 {
+			return nil, fmt.Errorf("invalid secret version '%s', expected name=version", item)
+		}
+		version, err := strconv.Atoi(parts[1])
+		if err != nil {
+			return nil, fmt.Errorf("invalid secret version '%s': %w", item, err)
+		}
+		versions[strings.TrimSpace(parts[0])] = version
+	}
+	return versions, nil
+}
+
 var validCertNamesRegex = regexp.MustCompile(`^[a-zA-Z][a-zA-Z0-9_\-]*$`)
 
 func validateCertificateList(certs []*acme.CertConfig, cfg *models.DNSConfig) error {
@@ -223,12 +525,47 @@ func validateCertificateList(certs []*acme.CertConfig, cfg *models.DNSConfig) er
 		if len(sans) == 0 {
 			return fmt.Errorf("certificate '%s' needs at least one SAN", name)
 		}
+		if _, err := cert.ParseKeyType(); err != nil {
+			return err
+		}
+		if cert.KeyType != "" && cert.UseECC {
+			printer.Warnf("certificate '%s' sets both key_type and the deprecated use_ecc, using key_type '%s'\n", name, cert.KeyType)
+		}
+		if cert.RenewFraction < 0 || cert.RenewFraction >= 1 {
+			return fmt.Errorf("certificate '%s' has renew_fraction %v, it must be between 0 and 1", name, cert.RenewFraction)
+		}
+		aliased := map[string]bool{}
+		for alias, target := range cert.ChallengeAliases {
+			found := false
+			for _, san := range sans {
+				found = found || strings.EqualFold(strings.TrimPrefix(san, "*."), alias)
+			}
+			if !found {
+				return fmt.Errorf("certificate '%s' has a challenge alias for '%s', which is not one of its SANs", name, alias)
+			}
+			if cfg.DomainContainingFQDN(target) == nil {
+				return fmt.Errorf("DNS config has no domain that matches the challenge alias '%s' of '%s'", target, alias)
+			}
+			aliased[strings.ToLower(alias)] = true
+		}
 		for _, san := range sans {
+			if aliased[strings.ToLower(strings.TrimPrefix(san, "*."))] {
+				// The zone of the SAN may be at a provider dnscontrol can not write to.
+				continue
+			}
 			d := cfg.DomainContainingFQDN(san)
 			if d == nil {
 				return fmt.Errorf("DNS config has no domain that matches SAN '%s'", san)
 			}
 		}
+		for _, t := range cert.TLSA {
+			if err := t.Validate(); err != nil {
+				return fmt.Errorf("certificate '%s': %w", name, err)
+			}
+			if cfg.DomainContainingFQDN(strings.TrimSuffix(t.Name, ".")) == nil {
+				return fmt.Errorf("DNS config has no domain that matches TLSA record '%s'", t.Name)
+			}
+		}
 	}
 	return nil
 }
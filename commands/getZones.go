@@ -1,6 +1,7 @@
 package commands
 
 import (
+	"context"
 	"fmt"
 	"os"
 	"strings"
@@ -26,7 +27,7 @@ var _ = cmd(catUtils, func() *cli.Command {
 			args.CredName = ctx.Args().Get(0)
 			args.ProviderName = ctx.Args().Get(1)
 			args.ZoneNames = ctx.Args().Slice()[2:]
-			return exit(GetZone(args))
+			return exit(GetZoneContext(ctx.Context, args))
 		},
 		Flags:     args.flags(),
 		UsageText: "dnscontrol get-zones [command options] credkey provider zone [...]",
@@ -80,7 +81,7 @@ var _ = cmd(catUtils, func() *cli.Command {
 			args.ProviderName = ctx.Args().Get(1)
 			args.ZoneNames = []string{"all"}
 			args.OutputFormat = "nameonly"
-			return exit(GetZone(args))
+			return exit(GetZoneContext(ctx.Context, args))
 		},
 		Flags:     args.flags(),
 		UsageText: "dnscontrol check-creds [command options] credkey provider",
@@ -133,6 +134,12 @@ func (args *GetZoneArgs) flags() []cli.Flag {
 
 // GetZone contains all data/flags needed to run get-zones, independently of CLI.
 func GetZone(args GetZoneArgs) error {
+	return GetZoneContext(context.Background(), args)
+}
+
+// GetZoneContext is like GetZone. Cancelling ctx aborts the requests of
+// the providers that support it.
+func GetZoneContext(ctx context.Context, args GetZoneArgs) error {
 	var providerConfigs map[string]map[string]string
 	var err error
 
@@ -153,7 +160,7 @@ func GetZone(args GetZoneArgs) error {
 		if !ok {
 			return fmt.Errorf("provider type %s cannot list zones to use the 'all' feature", args.ProviderName)
 		}
-		zones, err = lister.ListZones()
+		zones, err = providers.ListZonesContext(ctx, lister)
 		if err != nil {
 			return fmt.Errorf("failed GetZone LZ: %w", err)
 		}
@@ -179,7 +186,7 @@ func GetZone(args GetZoneArgs) error {
 	// fetch all of the records
 	zoneRecs := make([]models.Records, len(zones))
 	for i, zone := range zones {
-		recs, err := provider.GetZoneRecords(zone)
+		recs, err := providers.GetZoneRecordsContext(ctx, provider, zone)
 		if err != nil {
 			return fmt.Errorf("failed GetZone gzr: %w", err)
 		}
@@ -267,25 +274,48 @@ func formatDsl(zonename string, rec *models.RecordConfig, defaultTTL uint32) str
 		}
 	}
 
+	q := jsQuote
 	switch rec.Type { // #rtype_variations
 	case "CAA":
 		return makeCaa(rec, ttlop)
+	case "CSYNC":
+		types := make([]string, len(rec.CsyncTypes))
+		for i, t := range rec.CsyncTypes {
+			types[i] = q(t)
+		}
+		target = fmt.Sprintf("%d, %d, [%s]", rec.CsyncSerial, rec.CsyncFlags, strings.Join(types, ", "))
+	case "DS":
+		target = fmt.Sprintf("%d, %d, %d, %s", rec.DsKeyTag, rec.DsAlgorithm, rec.DsDigestType, q(rec.DsDigest))
+		if rec.Name == "@" {
+			// The DS records of the zone itself belong into the parent zone.
+			rec.Type = "//DS"
+		}
+	case "HINFO":
+		target = fmt.Sprintf("%s, %s", q(rec.HinfoCpu), q(rec.HinfoOs))
 	case "MX":
-		target = fmt.Sprintf("%d, '%s'", rec.MxPreference, rec.GetTargetField())
+		target = fmt.Sprintf("%d, %s", rec.MxPreference, q(rec.GetTargetField()))
+	case "NAPTR":
+		target = fmt.Sprintf("%d, %d, %s, %s, %s, %s", rec.NaptrOrder, rec.NaptrPreference, q(rec.NaptrFlags), q(rec.NaptrService), q(rec.NaptrRegexp), q(rec.GetTargetField()))
 	case "SSHFP":
-		target = fmt.Sprintf("%d, %d, '%s'", rec.SshfpAlgorithm, rec.SshfpFingerprint, rec.GetTargetField())
+		target = fmt.Sprintf("%d, %d, %s", rec.SshfpAlgorithm, rec.SshfpFingerprint, q(rec.GetTargetField()))
 	case "SOA":
 		rec.Type = "//SOA"
-		target = fmt.Sprintf("'%s', '%s', %d, %d, %d, %d, %d", rec.GetTargetField(), rec.SoaMbox, rec.SoaSerial, rec.SoaRefresh, rec.SoaRetry, rec.SoaExpire, rec.SoaMinttl)
+		target = fmt.Sprintf("%s, %s, %d, %d, %d, %d, %d", q(rec.GetTargetField()), q(rec.SoaMbox), rec.SoaSerial, rec.SoaRefresh, rec.SoaRetry, rec.SoaExpire, rec.SoaMinttl)
 	case "SRV":
-		target = fmt.Sprintf("%d, %d, %d, '%s'", rec.SrvPriority, rec.SrvWeight, rec.SrvPort, rec.GetTargetField())
+		target = fmt.Sprintf("%d, %d, %d, %s", rec.SrvPriority, rec.SrvWeight, rec.SrvPort, q(rec.GetTargetField()))
+	case "SVCB", "HTTPS":
+		target = fmt.Sprintf("%d, %s, %s", rec.SvcPriority, q(rec.GetTargetField()), q(rec.SvcParams))
 	case "TLSA":
-		target = fmt.Sprintf("%d, %d, %d, '%s'", rec.TlsaUsage, rec.TlsaSelector, rec.TlsaMatchingType, rec.GetTargetField())
+		target = fmt.Sprintf("%d, %d, %d, %s", rec.TlsaUsage, rec.TlsaSelector, rec.TlsaMatchingType, q(rec.GetTargetField()))
 	case "TXT":
 		if len(rec.TxtStrings) == 1 {
-			target = `'` + rec.TxtStrings[0] + `'`
+			target = q(rec.TxtStrings[0])
 		} else {
-			target = `['` + strings.Join(rec.TxtStrings, `', '`) + `']`
+			txts := make([]string, len(rec.TxtStrings))
+			for i, t := range rec.TxtStrings {
+				txts[i] = q(t)
+			}
+			target = "[" + strings.Join(txts, ", ") + "]"
 		}
 		// TODO(tlim): If this is an SPF record, generate a SPF_BUILDER().
 	case "NS":
@@ -293,38 +323,45 @@ func formatDsl(zonename string, rec *models.RecordConfig, defaultTTL uint32) str
 		// DnsControl uses the API to get this info. NAMESERVER() is just
 		// to override that when needed.
 		if rec.Name == "@" {
-			return fmt.Sprintf("//NAMESERVER('%s')", target)
+			return fmt.Sprintf("//NAMESERVER(%s)", q(target))
 		}
-		target = "'" + target + "'"
+		target = q(target)
 	case "R53_ALIAS":
 		return makeR53alias(rec, ttl)
 	default:
-		target = "'" + target + "'"
+		target = q(target)
 	}
 
-	return fmt.Sprintf("%s('%s', %s%s%s)", rec.Type, rec.Name, target, cfproxy, ttlop)
+	return fmt.Sprintf("%s(%s, %s%s%s)", rec.Type, q(rec.Name), target, cfproxy, ttlop)
+}
+
+// jsQuote returns s as a single-quoted JavaScript string.
+func jsQuote(s string) string {
+	return "'" + jsEscaper.Replace(s) + "'"
 }
 
+var jsEscaper = strings.NewReplacer(`\`, `\\`, `'`, `\'`, "\n", `\n`, "\r", `\r`, "\u2028", `\u2028`, "\u2029", `\u2029`)
+
 func makeCaa(rec *models.RecordConfig, ttlop string) string {
 	var target string
 	if rec.CaaFlag == 128 {
-		target = fmt.Sprintf("'%s', '%s', CAA_CRITICAL", rec.CaaTag, rec.GetTargetField())
+		target = fmt.Sprintf("%s, %s, CAA_CRITICAL", jsQuote(rec.CaaTag), jsQuote(rec.GetTargetField()))
 	} else {
-		target = fmt.Sprintf("'%s', '%s'", rec.CaaTag, rec.GetTargetField())
+		target = fmt.Sprintf("%s, %s", jsQuote(rec.CaaTag), jsQuote(rec.GetTargetField()))
 	}
-	return fmt.Sprintf("%s('%s', %s%s)", rec.Type, rec.Name, target, ttlop)
+	return fmt.Sprintf("%s(%s, %s%s)", rec.Type, jsQuote(rec.Name), target, ttlop)
 
 	// TODO(tlim): Generate a CAA_BUILDER() instead?
 }
 
 func makeR53alias(rec *models.RecordConfig, ttl uint32) string {
 	items := []string{
-		"'" + rec.Name + "'",
-		"'" + rec.R53Alias["type"] + "'",
-		"'" + rec.GetTargetField() + "'",
+		jsQuote(rec.Name),
+		jsQuote(rec.R53Alias["type"]),
+		jsQuote(rec.GetTargetField()),
 	}
 	if z, ok := rec.R53Alias["zone_id"]; ok {
-		items = append(items, "R53_ZONE('"+z+"')")
+		items = append(items, "R53_ZONE("+jsQuote(z)+")")
 	}
 	if ttl != 0 {
 		items = append(items, fmt.Sprintf("TTL(%d)", ttl))
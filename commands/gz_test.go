@@ -1,12 +1,17 @@
 package commands
 
 import (
+	"context"
 	"fmt"
 	"io/ioutil"
 	"log"
 	"os"
+	"path/filepath"
+	"strings"
 	"testing"
 
+	"github.com/StackExchange/dnscontrol/v3/models"
+	"github.com/StackExchange/dnscontrol/v3/pkg/printer"
 	"github.com/andreyvit/diff"
 
 	_ "github.com/StackExchange/dnscontrol/v3/providers/_all"
@@ -77,3 +82,72 @@ func testFormat(t *testing.T, domain, format string) {
 		t.Errorf("testFormat mismatch (-got +want):\n%s", diff.LineDiff(g, w))
 	}
 }
+
+func TestFormatDsl(t *testing.T) {
+	rec := func(label, rtype, target string) *models.RecordConfig {
+		rc := &models.RecordConfig{Type: rtype, TTL: 300}
+		rc.SetLabel(label, "example.com")
+		if err := rc.PopulateFromString(rtype, target, "example.com"); err != nil {
+			t.Fatal(err)
+		}
+		return rc
+	}
+	for _, tst := range []struct {
+		rec  *models.RecordConfig
+		want string
+	}{
+		{rec("@", "TXT", `it's \ here`), `TXT('@', 'it\'s \\ here')`},
+		{rec("loc", "LOC", "52 22 23.000 N 4 53 32.000 E -2.00m 1m 10000m 10m"), `LOC('loc', '52 22 23.000 N 04 53 32.000 E -2m 1m 10000m 10m')`},
+		{rec("host", "HINFO", `"INTEL" "Linux 5"`), `HINFO('host', 'INTEL', 'Linux 5')`},
+		{rec("child", "DS", "2371 13 2 abcdef"), `DS('child', 2371, 13, 2, 'abcdef')`},
+		{rec("@", "DS", "2371 13 2 abcdef"), `//DS('@', 2371, 13, 2, 'abcdef')`},
+		{rec("@", "CSYNC", "1 3 A NS AAAA"), `CSYNC('@', 1, 3, ['A', 'NS', 'AAAA'])`},
+	} {
+		if got := formatDsl("example.com", tst.rec, 300); got != tst.want {
+			t.Errorf("expected %s, got %s", tst.want, got)
+		}
+	}
+}
+
+func TestGetZoneRoundTrip(t *testing.T) {
+	dir := bindTestDir(t, "$TTL 300\n"+
+		"@ IN SOA ns1.example.com. hostmaster.example.com. 1 3600 600 604800 1440\n"+
+		"@ IN MX 10 mx.example.com.\n"+
+		"@ IN TXT \"v=spf1 -all\"\n"+
+		"@ IN CAA 0 issue \"letsencrypt.org\\; accounturi=https://example.com/acct/1\"\n"+
+		"quote IN TXT \"it's \\\"quoted\\\" \\\\ here\"\n"+
+		"long IN TXT \"first\" \"second\"\n"+
+		"host IN HINFO \"INTEL\" \"Linux 5\"\n"+
+		"_sip._tcp IN SRV 10 20 5060 sip.example.com.\n"+
+		"www 600 IN CNAME example.com.\n"+
+		"1 IN PTR host.example.com.\n"+
+		"child IN NS ns.example.net.\n"+
+		"child IN DS 2371 13 2 1F987CC6583E92DF0890718C42\n")
+	defer os.RemoveAll(dir)
+	creds := `{"bind": {"directory": "` + filepath.ToSlash(dir) + `"}, "ThirdParty": {}}`
+	if err := ioutil.WriteFile(filepath.Join(dir, "creds.json"), []byte(creds), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	gzargs := GetZoneArgs{
+		ZoneNames:    []string{"example.com"},
+		OutputFormat: "js",
+		OutputFile:   filepath.Join(dir, "dnsconfig.js"),
+		CredName:     "bind",
+		ProviderName: "BIND",
+	}
+	gzargs.CredsFile = filepath.Join(dir, "creds.json")
+	if err := GetZone(gzargs); err != nil {
+		t.Fatal(err)
+	}
+
+	var args PreviewArgs
+	args.JSFile = filepath.Join(dir, "dnsconfig.js")
+	args.CredsFile = filepath.Join(dir, "creds.json")
+	args.DetailedExitCode = true
+	var out strings.Builder
+	if err := exit(run(context.Background(), args, false, false, DeletionLimitArgs{}, 1, &printer.ConsolePrinter{Writer: &out})); err != nil {
+		js, _ := ioutil.ReadFile(args.JSFile)
+		t.Errorf("expected no corrections, got %v\n%s\n%s", err, out.String(), js)
+	}
+}
@@ -0,0 +1,199 @@
+package commands
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+
+	"github.com/StackExchange/dnscontrol/v3/models"
+	"github.com/StackExchange/dnscontrol/v3/pkg/diff"
+	"github.com/StackExchange/dnscontrol/v3/pkg/printer"
+)
+
+// jsonReport is the document that preview and push print with --format json.
+// The order of everything in it is deterministic, so that the documents of
+// two runs can be compared.
+type jsonReport struct {
+	Domains     []*jsonDomain `json:"domains"`
+	Corrections int           `json:"corrections"`
+
+	results map[*models.Correction]*jsonCorrection
+}
+
+type jsonDomain struct {
+	Name      string          `json:"name"`
+	Providers []*jsonProvider `json:"providers"`
+}
+
+// jsonProvider are the changes of a domain at one DNS provider or registrar.
+// Changes is null if the provider can not list the records of a zone.
+type jsonProvider struct {
+	Name        string            `json:"name"`
+	Type        string            `json:"type"`
+	Registrar   bool              `json:"registrar,omitempty"`
+	Counts      jsonCounts        `json:"counts"`
+	Changes     []jsonChange      `json:"changes"`
+	Corrections []*jsonCorrection `json:"corrections"`
+	Error       string            `json:"error,omitempty"`
+}
+
+type jsonCounts struct {
+	Create      int `json:"create"`
+	Delete      int `json:"delete"`
+	Modify      int `json:"modify"`
+	Corrections int `json:"corrections"`
+}
+
+// jsonChange is a record that is created, deleted or modified. TTLOnly is
+// set for modifications that only change the TTL, Forced for those of
+// records that only are rewritten because of FORCE_REAPPLY.
+type jsonChange struct {
+	Action  string               `json:"action"`
+	Name    string               `json:"name"`
+	Type    string               `json:"type"`
+	TTLOnly bool                 `json:"ttl_only,omitempty"`
+	Forced  bool                 `json:"forced,omitempty"`
+	Before  *models.RecordConfig `json:"before,omitempty"`
+	After   *models.RecordConfig `json:"after,omitempty"`
+}
+
+// jsonCorrection is a correction as reported by the provider. Applied is
+// true once it ran successfully.
+type jsonCorrection struct {
+	Message       string `json:"message"`
+	Informational bool   `json:"informational,omitempty"`
+	Applied       bool   `json:"applied"`
+	Error         string `json:"error,omitempty"`
+}
+
+func newJSONReport() *jsonReport {
+	return &jsonReport{results: map[*models.Correction]*jsonCorrection{}}
+}
+
+// startDomain adds a domain to the report.
+func (r *jsonReport) startDomain(name string) {
+	if r == nil {
+		return
+	}
+	r.Domains = append(r.Domains, &jsonDomain{Name: name, Providers: []*jsonProvider{}})
+}
+
+// addProvider adds the corrections of a provider to the last domain.
+func (r *jsonReport) addProvider(name, ptype string, registrar bool, changes []jsonChange, corrections []*models.Correction, err error) {
+	if r == nil {
+		return
+	}
+	p := &jsonProvider{
+		Name:        name,
+		Type:        ptype,
+		Registrar:   registrar,
+		Changes:     changes,
+		Corrections: []*jsonCorrection{},
+	}
+	for _, c := range changes {
+		switch c.Action {
+		case "create":
+			p.Counts.Create++
+		case "delete":
+			p.Counts.Delete++
+		case "modify":
+			p.Counts.Modify++
+		}
+	}
+	for _, c := range corrections {
+		jc := &jsonCorrection{Message: c.Msg, Informational: c.Informational}
+		p.Corrections = append(p.Corrections, jc)
+		r.results[c] = jc
+	}
+	p.Counts.Corrections = models.CountChanges(corrections)
+	r.Corrections += p.Counts.Corrections
+	if err != nil {
+		p.Error = err.Error()
+	}
+	d := r.Domains[len(r.Domains)-1]
+	d.Providers = append(d.Providers, p)
+}
+
+// result records the outcome of running a correction.
+func (r *jsonReport) result(c *models.Correction, err error) {
+	if r == nil {
+		return
+	}
+	jc := r.results[c]
+	if jc == nil {
+		return
+	}
+	jc.Applied = err == nil
+	if err != nil {
+		jc.Error = err.Error()
+	}
+}
+
+func (r *jsonReport) write(w io.Writer) error {
+	data, err := json.MarshalIndent(r, "", "  ")
+	if err != nil {
+		return err
+	}
+	_, err = fmt.Fprintf(w, "%s\n", data)
+	return err
+}
+
+// newJSONChanges converts the result of a diff, sorted by name, type,
+// action and record data.
+func newJSONChanges(create, del, modify diff.Changeset) []jsonChange {
+	changes := []jsonChange{}
+	for _, group := range []struct {
+		action string
+		set    diff.Changeset
+	}{{"create", create}, {"delete", del}, {"modify", modify}} {
+		for _, m := range group.set {
+			c := jsonChange{Action: group.action, TTLOnly: m.TTLOnly(), Forced: m.Forced(), Before: m.Existing, After: m.Desired}
+			rec := m.Desired
+			if rec == nil {
+				rec = m.Existing
+			}
+			c.Name, c.Type = rec.GetLabelFQDN(), rec.Type
+			changes = append(changes, c)
+		}
+	}
+	sort.SliceStable(changes, func(i, j int) bool {
+		a, b := changes[i], changes[j]
+		if a.Name != b.Name {
+			return a.Name < b.Name
+		}
+		if a.Type != b.Type {
+			return a.Type < b.Type
+		}
+		if a.Action != b.Action {
+			return a.Action < b.Action
+		}
+		return recordSortKey(a) < recordSortKey(b)
+	})
+	return changes
+}
+
+func recordSortKey(c jsonChange) string {
+	key := ""
+	for _, rec := range []*models.RecordConfig{c.Before, c.After} {
+		if rec != nil {
+			key += rec.ToDiffable() + "\x00"
+		}
+	}
+	return key
+}
+
+// redirectStdout sends everything that would be printed to stdout to
+// stderr instead, so that stdout only carries the JSON document. It
+// returns the original stdout and a function that restores it.
+func redirectStdout() (stdout *os.File, restore func()) {
+	stdout = os.Stdout
+	writer := printer.DefaultPrinter.Writer
+	os.Stdout = os.Stderr
+	printer.DefaultPrinter.Writer = os.Stderr
+	return stdout, func() {
+		os.Stdout = stdout
+		printer.DefaultPrinter.Writer = writer
+	}
+}
@@ -0,0 +1,160 @@
+package commands
+
+import (
+	"context"
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/StackExchange/dnscontrol/v3/pkg/printer"
+)
+
+func TestPreviewJSON(t *testing.T) {
+	dir, err := ioutil.TempDir("", "dnscontrol-json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	zone := "$TTL 300\n" +
+		"@ IN SOA ns1.example.com. hostmaster.example.com. 1 3600 600 604800 1440\n" +
+		"old IN A 192.0.2.9\n" +
+		"www IN A 192.0.2.1\n"
+	js := `var REG_NONE = NewRegistrar("none", "NONE");
+var DNS_BIND = NewDnsProvider("bind", "BIND");
+D("example.com", REG_NONE, DnsProvider(DNS_BIND),
+    A("www", "192.0.2.2"),
+    A("api", "192.0.2.3"),
+    A("mail", "192.0.2.4")
+);`
+	creds := `{"bind": {"directory": "` + filepath.ToSlash(dir) + `"}, "none": {}}`
+	for name, content := range map[string]string{
+		"example.com.zone": zone,
+		"dnsconfig.js":     js,
+		"creds.json":       creds,
+	} {
+		if err := ioutil.WriteFile(filepath.Join(dir, name), []byte(content), 0600); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	var args PreviewArgs
+	args.JSFile = filepath.Join(dir, "dnsconfig.js")
+	args.CredsFile = filepath.Join(dir, "creds.json")
+	args.Format = "json"
+
+	// The report goes to the real stdout, capture it with a pipe.
+	r, w, err := os.Pipe()
+	if err != nil {
+		t.Fatal(err)
+	}
+	stdout := os.Stdout
+	os.Stdout = w
+	out := &printer.ConsolePrinter{Writer: ioutil.Discard}
+	runErr := run(context.Background(), args, false, false, DeletionLimitArgs{}, 1, out)
+	os.Stdout = stdout
+	w.Close()
+	data, err := ioutil.ReadAll(r)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if runErr != nil {
+		t.Fatalf("unexpected error: %v", runErr)
+	}
+
+	report := &jsonReport{}
+	if err := json.Unmarshal(data, report); err != nil {
+		t.Fatalf("stdout is not a JSON report: %v\n%s", err, data)
+	}
+	// The registrar is skipped, the zone has no nameservers.
+	if len(report.Domains) != 1 || len(report.Domains[0].Providers) != 1 {
+		t.Fatalf("expected one domain with one provider, got\n%s", data)
+	}
+	p := report.Domains[0].Providers[0]
+	if p.Name != "bind" || p.Type != "BIND" || p.Registrar {
+		t.Errorf("expected the BIND provider, got %+v", p)
+	}
+	if p.Counts.Create != 2 || p.Counts.Delete != 1 || p.Counts.Modify != 1 {
+		t.Errorf("expected 2 creations, 1 deletion and 1 modification, got %+v", p.Counts)
+	}
+	var got []string
+	for _, c := range p.Changes {
+		got = append(got, c.Action+" "+c.Name+" "+c.Type)
+	}
+	want := []string{
+		"create api.example.com A",
+		"create mail.example.com A",
+		"delete old.example.com A",
+		"modify www.example.com A",
+	}
+	if strings.Join(got, "\n") != strings.Join(want, "\n") {
+		t.Errorf("expected changes\n%v\ngot\n%v", want, got)
+	}
+	www := p.Changes[3]
+	if www.Before == nil || www.After == nil || www.Before.GetTargetField() != "192.0.2.1" || www.After.GetTargetField() != "192.0.2.2" {
+		t.Errorf("expected the modification to have the old and the new record, got %+v", www)
+	}
+	if len(p.Corrections) == 0 || p.Corrections[0].Applied {
+		t.Errorf("expected corrections that were not applied, got %+v", p.Corrections)
+	}
+
+	// A second run produces the same document.
+	r, w, _ = os.Pipe()
+	os.Stdout = w
+	run(context.Background(), args, false, false, DeletionLimitArgs{}, 1, out)
+	os.Stdout = stdout
+	w.Close()
+	again, _ := ioutil.ReadAll(r)
+	if string(again) != string(data) {
+		t.Errorf("expected the same report for the same changes, got\n%s\nand\n%s", data, again)
+	}
+}
+
+func TestPreviewJSONProviderChanges(t *testing.T) {
+	// The SOA record that GetZoneRecords returns is not changed.
+	dir, _ := fakeZone(t, "FAKE_LISTER", []string{"old 192.0.2.9", "www 192.0.2.1"}, `A("www", "192.0.2.2")`)
+	defer os.RemoveAll(dir)
+	var args PreviewArgs
+	args.JSFile = filepath.Join(dir, "dnsconfig.js")
+	args.CredsFile = filepath.Join(dir, "creds.json")
+	args.Format = "json"
+
+	r, w, err := os.Pipe()
+	if err != nil {
+		t.Fatal(err)
+	}
+	stdout := os.Stdout
+	os.Stdout = w
+	runErr := run(context.Background(), args, false, false, DeletionLimitArgs{}, 1, &printer.ConsolePrinter{Writer: ioutil.Discard})
+	os.Stdout = stdout
+	w.Close()
+	data, err := ioutil.ReadAll(r)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if runErr != nil {
+		t.Fatalf("unexpected error: %v", runErr)
+	}
+
+	report := &jsonReport{}
+	if err := json.Unmarshal(data, report); err != nil {
+		t.Fatalf("stdout is not a JSON report: %v\n%s", err, data)
+	}
+	if len(report.Domains) != 1 || len(report.Domains[0].Providers) != 1 {
+		t.Fatalf("expected one domain with one provider, got\n%s", data)
+	}
+	var got []string
+	for _, c := range report.Domains[0].Providers[0].Changes {
+		got = append(got, c.Action+" "+c.Name+" "+c.Type)
+	}
+	want := []string{
+		"delete old.example.com A",
+		"modify www.example.com A",
+	}
+	if strings.Join(got, "\n") != strings.Join(want, "\n") {
+		t.Errorf("expected changes\n%v\ngot\n%v", want, got)
+	}
+}
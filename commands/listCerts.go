@@ -0,0 +1,73 @@
+package commands
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/StackExchange/dnscontrol/v3/models"
+	"github.com/StackExchange/dnscontrol/v3/pkg/acme"
+	"github.com/StackExchange/dnscontrol/v3/pkg/notifications"
+	"github.com/urfave/cli/v2"
+)
+
+var _ = cmd(catUtils, func() *cli.Command {
+	var args GetCertsArgs
+	return &cli.Command{
+		Name:  "list-certs",
+		Usage: "List the certificates stored by get-certs, the soonest to expire first",
+		Action: func(c *cli.Context) error {
+			return exit(ListCerts(args))
+		},
+		Flags: args.flags(),
+	}
+}())
+
+// ListCerts implements the list-certs command. The storage flags and the
+// renewal threshold are those of get-certs.
+func ListCerts(args GetCertsArgs) error {
+	renewUnder, err := acme.ParseRenewUnder(args.RenewUnder)
+	if err != nil {
+		return fmt.Errorf("--renew: %w", err)
+	}
+	notifier, err := notifications.Init(nil)
+	if err != nil {
+		return err
+	}
+	client, err := args.newClient(context.Background(), &models.DNSConfig{}, notifier)
+	if err != nil {
+		return err
+	}
+	list, err := client.ListCerts(renewUnder)
+	if err != nil {
+		return err
+	}
+	return printCertList(os.Stdout, list, renewUnder)
+}
+
+// printCertList prints the certificates as a table. Certificates past the
+// renewal threshold are marked as due, expired ones as expired.
+func printCertList(w io.Writer, list []acme.CertStatus, renewUnder acme.RenewUnder) error {
+	tw := tabwriter.NewWriter(w, 0, 8, 2, ' ', 0)
+	fmt.Fprintln(tw, "CERT\tEXPIRES\tDAYS LEFT\tSTATUS\tNAMES")
+	for _, s := range list {
+		if s.Err != nil {
+			fmt.Fprintf(tw, "%s\t\t\terror: %v\t\n", s.Name, s.Err)
+			continue
+		}
+		daysLeft := time.Until(s.NotAfter).Hours() / 24
+		status := "ok"
+		switch {
+		case daysLeft < 0:
+			status = "expired"
+		case s.Due:
+			status = fmt.Sprintf("due (renew under %s)", renewUnder)
+		}
+		fmt.Fprintf(tw, "%s\t%s\t%0.1f\t%s\t%s\n", s.Name, s.NotAfter.UTC().Format("2006-01-02"), daysLeft, status, strings.Join(s.Names, ","))
+	}
+	return tw.Flush()
+}
@@ -1,17 +1,22 @@
 package commands
 
 import (
+	"context"
 	"fmt"
 	"log"
 	"os"
+	"time"
 
 	"github.com/urfave/cli/v2"
 
 	"github.com/StackExchange/dnscontrol/v3/models"
+	"github.com/StackExchange/dnscontrol/v3/pkg/apimetrics"
+	"github.com/StackExchange/dnscontrol/v3/pkg/diff"
 	"github.com/StackExchange/dnscontrol/v3/pkg/nameservers"
 	"github.com/StackExchange/dnscontrol/v3/pkg/normalize"
 	"github.com/StackExchange/dnscontrol/v3/pkg/notifications"
 	"github.com/StackExchange/dnscontrol/v3/pkg/printer"
+	"github.com/StackExchange/dnscontrol/v3/pkg/recordaudit"
 	"github.com/StackExchange/dnscontrol/v3/providers"
 	"github.com/StackExchange/dnscontrol/v3/providers/config"
 )
@@ -22,7 +27,7 @@ var _ = cmd(catMain, func() *cli.Command {
 		Name:  "preview",
 		Usage: "read live configuration and identify changes to be made, without applying them",
 		Action: func(ctx *cli.Context) error {
-			return exit(Preview(args))
+			return exit(PreviewContext(ctx.Context, args))
 		},
 		Flags: args.flags(),
 	}
@@ -33,8 +38,15 @@ type PreviewArgs struct {
 	GetDNSConfigArgs
 	GetCredentialsArgs
 	FilterArgs
-	Notify      bool
-	WarnChanges bool
+	Notify           bool
+	WarnChanges      bool
+	DetailedExitCode bool
+	Format           string
+	StateCache       string
+	Refresh          bool
+	Policies         string
+	Metrics          bool
+	MetricsFile      string
 }
 
 func (args *PreviewArgs) flags() []cli.Flag {
@@ -51,6 +63,47 @@ func (args *PreviewArgs) flags() []cli.Flag {
 		Destination: &args.WarnChanges,
 		Usage:       `set to true for non-zero return code if there are changes`,
 	})
+	flags = append(flags, &cli.BoolFlag{
+		Name:        "detailed-exitcode",
+		Destination: &args.DetailedExitCode,
+		Usage:       `Exit with 2 if there are changes that do not delete records, 3 if some changes delete records (1 stays for errors)`,
+	})
+	flags = append(flags, &cli.StringFlag{
+		Name:        "format",
+		Destination: &args.Format,
+		Value:       "text",
+		Usage:       `Output format: text or json (a machine-readable report on stdout, everything else goes to stderr)`,
+	})
+	flags = append(flags, &cli.StringFlag{
+		Name:        "policy",
+		Destination: &args.Policies,
+		Usage:       `Check the records of each domain against these policies first, e.g. no-wildcard-cname,txt-max-length=512,warn:require-caa (warn: only warns)`,
+	})
+	flags = append(flags, &cli.IntFlag{
+		Name:        "replace-zone-threshold",
+		Destination: &providers.ReplaceZoneThreshold,
+		Usage:       `Replace all records of a zone from this number of changes on, with providers that can (not atomic, 0 disables it)`,
+	})
+	flags = append(flags, &cli.StringFlag{
+		Name:        "state-cache",
+		Destination: &args.StateCache,
+		Usage:       `Remember the zones that are in sync in this file, and skip fetching their records while their configuration does not change`,
+	})
+	flags = append(flags, &cli.BoolFlag{
+		Name:        "refresh",
+		Destination: &args.Refresh,
+		Usage:       `Fetch the records of all zones, even if the state cache says they are unchanged`,
+	})
+	flags = append(flags, &cli.BoolFlag{
+		Name:        "metrics",
+		Destination: &args.Metrics,
+		Usage:       `Print the number and duration of the API requests of each provider at the end (only some providers report them)`,
+	})
+	flags = append(flags, &cli.StringFlag{
+		Name:        "metrics-file",
+		Destination: &args.MetricsFile,
+		Usage:       `Write the metrics of the API requests to this file in the Prometheus text format`,
+	})
 	return flags
 }
 
@@ -60,7 +113,7 @@ var _ = cmd(catMain, func() *cli.Command {
 		Name:  "push",
 		Usage: "identify changes to be made, and perform them",
 		Action: func(ctx *cli.Context) error {
-			return exit(Push(args))
+			return exit(PushContext(ctx.Context, args))
 		},
 		Flags: args.flags(),
 	}
@@ -69,7 +122,9 @@ var _ = cmd(catMain, func() *cli.Command {
 // PushArgs contains all data/flags needed to run push, independently of CLI
 type PushArgs struct {
 	PreviewArgs
+	DeletionLimitArgs
 	Interactive bool
+	Concurrency int
 }
 
 func (args *PushArgs) flags() []cli.Flag {
@@ -79,21 +134,184 @@ func (args *PushArgs) flags() []cli.Flag {
 		Destination: &args.Interactive,
 		Usage:       "Interactive. Confirm or Exclude each correction before they run",
 	})
+	flags = append(flags, concurrencyFlag(&args.Concurrency,
+		`Apply the corrections of up to this many zones at a time (zones with providers that do not support it are still applied one after another)`))
+	flags = append(flags, args.DeletionLimitArgs.flags()...)
+	return flags
+}
+
+// DeletionLimitArgs configures the guardrail against deleting large parts of a zone.
+type DeletionLimitArgs struct {
+	MaxDeletions        int
+	MaxDeletionsPercent int
+	AllowDeletions      bool
+}
+
+func (args *DeletionLimitArgs) flags() []cli.Flag {
+	var flags []cli.Flag
+	flags = append(flags, &cli.IntFlag{
+		Name:        "max-deletions",
+		Destination: &args.MaxDeletions,
+		Usage:       `Refuse to push a domain if it would delete more than this many records (0 = no limit)`,
+	})
+	flags = append(flags, &cli.IntFlag{
+		Name:        "max-deletions-percent",
+		Destination: &args.MaxDeletionsPercent,
+		Usage:       `Refuse to push a domain if it would delete more than this percentage of its records (0 = no limit)`,
+	})
+	flags = append(flags, &cli.BoolFlag{
+		Name:        "allow-deletions",
+		Aliases:     []string{"force"},
+		Destination: &args.AllowDeletions,
+		Usage:       `Push even if the limits of --max-deletions and --max-deletions-percent are exceeded`,
+	})
 	return flags
 }
 
+func (args *DeletionLimitArgs) enabled() bool {
+	return !args.AllowDeletions && (args.MaxDeletions > 0 || args.MaxDeletionsPercent > 0)
+}
+
+// zoneChanges returns the records that applying dc to the provider would
+// create, delete and modify, and the number of existing records. The
+// changes are those of the provider if it is a providers.ChangeLister,
+// otherwise the records of the zone are diffed with dc.
+func zoneChanges(ctx context.Context, dc *models.DomainConfig, provider models.DNSProvider) (create, del, modify diff.Changeset, existingCount int, err error) {
+	if lister, ok := provider.(providers.ChangeLister); ok {
+		return lister.ListChangesContext(ctx, dc)
+	}
+	dc, err = dc.Copy()
+	if err != nil {
+		return nil, nil, nil, 0, err
+	}
+	if err := dc.Punycode(); err != nil {
+		return nil, nil, nil, 0, err
+	}
+	existing, err := providers.GetZoneRecordsContext(ctx, provider, dc.Name)
+	if err != nil {
+		return nil, nil, nil, 0, err
+	}
+	models.PostProcessRecords(existing)
+	_, create, del, modify, err = diff.New(dc).IncrementalDiff(existing)
+	return create, del, modify, len(existing), err
+}
+
+// canListChanges returns true if zoneChanges works for the provider.
+func canListChanges(provider *models.DNSProviderInstance) bool {
+	_, ok := provider.Driver.(providers.ChangeLister)
+	return ok || providers.ProviderHasCapability(provider.ProviderType, providers.CanGetZones)
+}
+
+// checkDeletionLimit returns an error if deleting deletions of the
+// existing records of domain exceeds the configured limits.
+func (args *DeletionLimitArgs) checkDeletionLimit(domain string, deletions, existing int) error {
+	if args.MaxDeletions > 0 && deletions > args.MaxDeletions {
+		return fmt.Errorf("refusing to apply %s, %d deletions exceeds limit of %d (use --force to override)", domain, deletions, args.MaxDeletions)
+	}
+	if args.MaxDeletionsPercent > 0 && existing > 0 && deletions*100 > args.MaxDeletionsPercent*existing {
+		return fmt.Errorf("refusing to apply %s, %d deletions of %d records exceeds limit of %d%% (use --force to override)", domain, deletions, existing, args.MaxDeletionsPercent)
+	}
+	return nil
+}
+
+// The exit codes of preview and push with --detailed-exitcode.
+const (
+	exitCodeChanges   = 2 // There are changes, none of them deletes a record.
+	exitCodeDeletions = 3 // Some changes delete records.
+)
+
+// changeSummary classifies the changes of a run for --detailed-exitcode.
+type changeSummary struct {
+	changes, deletions bool
+}
+
+// add records the changes of a provider: the number of its corrections
+// that are not informational, and the records they delete. If the changes
+// could not be classified, because the provider can not list the records
+// of a zone, they are assumed to delete records. Without corrections there
+// are no changes, whatever a diff of the records says.
+func (s *changeSummary) add(changes int, classified bool, del diff.Changeset) {
+	if changes == 0 {
+		return
+	}
+	s.changes = true
+	if !classified || len(del) > 0 {
+		s.deletions = true
+	}
+}
+
+// err returns the error to exit with for the changes, or nil if there
+// are none.
+func (s changeSummary) err() error {
+	switch {
+	case s.deletions:
+		return cli.Exit("there are changes that delete records", exitCodeDeletions)
+	case s.changes:
+		return cli.Exit("there are changes, none of them deletes records", exitCodeChanges)
+	}
+	return nil
+}
+
 // Preview implements the preview subcommand.
 func Preview(args PreviewArgs) error {
-	return run(args, false, false, printer.DefaultPrinter)
+	return PreviewContext(context.Background(), args)
+}
+
+// PreviewContext is like Preview. Cancelling ctx aborts the requests of
+// the providers that support it, and skips the remaining domains.
+func PreviewContext(ctx context.Context, args PreviewArgs) error {
+	return run(ctx, args, false, false, DeletionLimitArgs{}, 1, printer.DefaultPrinter)
 }
 
 // Push implements the push subcommand.
 func Push(args PushArgs) error {
-	return run(args.PreviewArgs, true, args.Interactive, printer.DefaultPrinter)
+	return PushContext(context.Background(), args)
+}
+
+// PushContext is like Push. Cancelling ctx aborts the requests of the
+// providers that support it, including those of corrections that did
+// not finish yet, and skips the remaining domains.
+func PushContext(ctx context.Context, args PushArgs) error {
+	return run(ctx, args.PreviewArgs, true, args.Interactive, args.DeletionLimitArgs, args.Concurrency, printer.DefaultPrinter)
 }
 
 // run is the main routine common to preview/push
-func run(args PreviewArgs, push bool, interactive bool, out printer.CLI) error {
+func run(ctx context.Context, args PreviewArgs, push bool, interactive bool, limits DeletionLimitArgs, concurrency int, out printer.CLI) (err error) {
+	var report *jsonReport
+	switch args.Format {
+	case "", "text":
+	case "json":
+		if interactive {
+			return fmt.Errorf("--format json can not be combined with -i")
+		}
+		report = newJSONReport()
+		stdout, restore := redirectStdout()
+		defer restore()
+		defer func() {
+			if writeErr := report.write(stdout); writeErr != nil && err == nil {
+				err = writeErr
+			}
+		}()
+	default:
+		return fmt.Errorf("unknown format %q, expected text or json", args.Format)
+	}
+
+	if args.Metrics || args.MetricsFile != "" {
+		metrics := &apimetrics.Collector{}
+		apimetrics.SetHook(metrics)
+		defer func() {
+			apimetrics.SetHook(nil)
+			if writeErr := writeMetrics(metrics, args, out); writeErr != nil && err == nil {
+				err = writeErr
+			}
+		}()
+	}
+
+	policies, err := recordaudit.ParsePolicies(args.Policies)
+	if err != nil {
+		return err
+	}
+
 	// TODO: make truly CLI independent. Perhaps return results on a channel as they occur
 	cfg, err := GetDNSConfig(args.GetDNSConfigArgs)
 	if err != nil {
@@ -103,29 +321,91 @@ func run(args PreviewArgs, push bool, interactive bool, out printer.CLI) error {
 	if PrintValidationErrors(errs) {
 		return fmt.Errorf("exiting due to validation errors")
 	}
+	// Resolving the targets of ALIAS_FLATTENING needs the network, hence
+	// it is not part of the validation that check and print-ir do.
+	if PrintValidationErrors(normalize.FlattenAliases(cfg)) {
+		return fmt.Errorf("exiting due to validation errors")
+	}
 	// TODO:
 	notifier, err := InitializeProviders(args.CredsFile, cfg, args.Notify)
 	if err != nil {
 		return err
 	}
+	var state *stateCache
+	if args.StateCache != "" {
+		if state, err = loadStateCache(args.StateCache); err != nil {
+			return err
+		}
+		defer func() {
+			if saveErr := state.save(); saveErr != nil && err == nil {
+				err = saveErr
+			}
+		}()
+	}
 	anyErrors := false
 	totalCorrections := 0
+	var summary changeSummary
+
+	// With a concurrency above 1, corrections are collected and applied by
+	// the scheduler once all of them are known. The corrections of domains
+	// with providers that can not run concurrently are applied right away.
+	var scheduler *zoneScheduler
+	var scheduled []*scheduledCorrections
+	if push && !interactive && concurrency > 1 {
+		scheduler = newZoneScheduler(concurrency)
+	}
+	printOrSchedule := func(domain *models.DomainConfig, provider string, corrections []*models.Correction) {
+		key := schedulingKey(domain)
+		if scheduler == nil || key == "" {
+			anyErrors = printOrRunCorrections(domain.Name, provider, corrections, out, push, interactive, notifier, report) || anyErrors
+			return
+		}
+		if len(corrections) == 0 {
+			return
+		}
+		sc := &scheduledCorrections{domain: domain.Name, provider: provider, corrections: corrections}
+		scheduled = append(scheduled, sc)
+		scheduler.add(key, sc.run)
+	}
+
+	// An error that stops the loop is returned once the corrections that
+	// were scheduled already are applied and printed.
+	var stopErr error
 DomainLoop:
 	for _, domain := range cfg.Domains {
 		if !args.shouldRunDomain(domain.UniqueName) {
 			continue
 		}
+		if err := ctx.Err(); err != nil {
+			stopErr = err
+			break DomainLoop
+		}
 		out.StartDomain(domain.UniqueName)
+		report.startDomain(domain.UniqueName)
 		nsList, err := nameservers.DetermineNameservers(domain)
 		if err != nil {
-			return err
+			stopErr = err
+			break DomainLoop
 		}
 		domain.Nameservers = nsList
 		nameservers.AddNSRecords(domain)
+		warnings, violations := recordaudit.CheckPolicies(policies, domain.Records)
+		for _, w := range warnings {
+			out.Warnf("%s\n", w)
+		}
+		if len(violations) != 0 {
+			for _, v := range violations {
+				out.Warnf("%s\n", v)
+			}
+			out.Warnf("skipping %s, it violates the policies\n", domain.UniqueName)
+			anyErrors = true
+			continue
+		}
 		for _, provider := range domain.DNSProviderInstances {
 			dc, err := domain.Copy()
 			if err != nil {
-				return err
+				stopErr = err
+				break DomainLoop
 			}
 			shouldrun := args.shouldRunProvider(provider.Name, dc)
 			out.StartDNSProvider(provider.Name, !shouldrun)
@@ -135,14 +415,62 @@ DomainLoop:
 
 			/// This is where we should audit?
 
-			corrections, err := provider.Driver.GetDomainCorrections(dc)
-			out.EndProvider(len(corrections), err)
+			var configHash string
+			if state != nil {
+				if configHash, err = hashConfig(dc); err != nil {
+					stopErr = err
+					break DomainLoop
+				}
+				if !args.Refresh && state.unchanged(domain.UniqueName, provider.Name, configHash) {
+					out.Printf("unchanged since the last run, skipped (use --refresh to check)\n")
+					report.addProvider(provider.Name, provider.ProviderType, false, []jsonChange{}, nil, nil)
+					continue
+				}
+			}
+
+			corrections, err := providers.GetDomainCorrectionsContext(providers.WithPlan(ctx), provider.Driver, dc)
+			changes := models.CountChanges(corrections)
+			out.EndProvider(changes, err)
+			if state != nil && err == nil {
+				if changes == 0 {
+					state.set(domain.UniqueName, provider.Name, configHash)
+				} else {
+					// Whatever happens to the corrections, fetch the records next time.
+					state.invalidate(domain.UniqueName, provider.Name)
+				}
+			}
+			// The changes of records are only listed if they are needed.
+			checkLimits := push && changes > 0 && limits.enabled()
+			var create, del, modify diff.Changeset
+			var existingCount int
+			classified := false
+			if err == nil && (report != nil || args.DetailedExitCode || checkLimits) && canListChanges(provider) {
+				create, del, modify, existingCount, err = zoneChanges(ctx, dc, provider.Driver)
+				classified = true
+			}
+			if report != nil {
+				var records []jsonChange
+				if classified {
+					records = newJSONChanges(create, del, modify)
+				}
+				report.addProvider(provider.Name, provider.ProviderType, false, records, corrections, err)
+			}
 			if err != nil {
 				anyErrors = true
 				continue DomainLoop
 			}
-			totalCorrections += len(corrections)
-			anyErrors = printOrRunCorrections(domain.Name, provider.Name, corrections, out, push, interactive, notifier) || anyErrors
+			totalCorrections += changes
+			summary.add(changes, classified, del)
+			if checkLimits {
+				if !classified {
+					out.Warnf("can not count the deletions of %s at %s, it can not list the records of a zone; the deletion limits are not checked\n", domain.UniqueName, provider.Name)
+				} else if err := limits.checkDeletionLimit(dc.Name, len(del), existingCount); err != nil {
+					out.Warnf("%s\n", err)
+					anyErrors = true
+					continue DomainLoop
+				}
+			}
+			printOrSchedule(domain, provider.Name, corrections)
 		}
 		run := args.shouldRunProvider(domain.RegistrarName, domain)
 		out.StartRegistrar(domain.RegistrarName, !run)
@@ -158,13 +486,27 @@ DomainLoop:
 			log.Fatal(err)
 		}
 		corrections, err := domain.RegistrarInstance.Driver.GetRegistrarCorrections(dc)
-		out.EndProvider(len(corrections), err)
+		changes := models.CountChanges(corrections)
+		out.EndProvider(changes, err)
+		report.addProvider(domain.RegistrarName, domain.RegistrarInstance.ProviderType, true, nil, corrections, err)
 		if err != nil {
 			anyErrors = true
 			continue
 		}
-		totalCorrections += len(corrections)
-		anyErrors = printOrRunCorrections(domain.Name, domain.RegistrarName, corrections, out, push, interactive, notifier) || anyErrors
+		totalCorrections += changes
+		// The registrar changes the delegation, not the records of the zone.
+		summary.add(changes, true, nil)
+		printOrSchedule(domain, domain.RegistrarName, corrections)
+	}
+	if scheduler != nil {
+		scheduler.run()
+		for _, sc := range scheduled {
+			anyErrors = sc.print(out, notifier, report) || anyErrors
+		}
+	}
+	if stopErr != nil {
+		notifier.Done()
+		return stopErr
 	}
 	if os.Getenv("TEAMCITY_VERSION") != "" {
 		fmt.Fprintf(os.Stderr, "##teamcity[buildStatus status='SUCCESS' text='%d corrections']", totalCorrections)
@@ -174,19 +516,47 @@ DomainLoop:
 	if anyErrors {
 		return fmt.Errorf("completed with errors")
 	}
+	if args.DetailedExitCode {
+		return summary.err()
+	}
 	if totalCorrections != 0 && args.WarnChanges {
 		return fmt.Errorf("there are pending changes")
 	}
 	return nil
 }
 
+// writeMetrics prints the summary of the API requests and writes them to
+// the metrics file, as requested by args.
+func writeMetrics(metrics *apimetrics.Collector, args PreviewArgs, out printer.CLI) error {
+	if args.Metrics {
+		for _, line := range metrics.Summary() {
+			out.Printf("%s\n", line)
+		}
+	}
+	if args.MetricsFile == "" {
+		return nil
+	}
+	f, err := os.Create(args.MetricsFile)
+	if err != nil {
+		return err
+	}
+	if err := metrics.WritePrometheus(f); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
 // InitializeProviders takes a creds file path and a DNSConfig object. Creates all providers with the proper types, and returns them.
 // nonDefaultProviders is a list of providers that should not be run unless explicitly asked for by flags.
 func InitializeProviders(credsFile string, cfg *models.DNSConfig, notifyFlag bool) (notify notifications.Notifier, err error) {
 	var providerConfigs map[string]map[string]string
 	var notificationCfg map[string]string
 	defer func() {
-		notify = notifications.Init(notificationCfg)
+		var initErr error
+		if notify, initErr = notifications.Init(notificationCfg); initErr != nil && err == nil {
+			err = initErr
+		}
 	}()
 	providerConfigs, err = config.LoadProviderConfigs(credsFile)
 	if err != nil {
@@ -232,25 +602,94 @@ func InitializeProviders(credsFile string, cfg *models.DNSConfig, notifyFlag boo
 	return
 }
 
-func printOrRunCorrections(domain string, provider string, corrections []*models.Correction, out printer.CLI, push bool, interactive bool, notifier notifications.Notifier) (anyErrors bool) {
+// failFunc, if set, makes corrections fail instead of running them. Tests
+// use it to exercise the error handling of push.
+var failFunc models.FailFunc
+
+// scheduledCorrections are the corrections of a domain and provider that
+// are applied by a zoneScheduler. The results are printed afterwards, in
+// the order of the domains.
+type scheduledCorrections struct {
+	domain      string
+	provider    string
+	corrections []*models.Correction
+	errs        []error
+	starts      []time.Time
+	durations   []time.Duration
+}
+
+func (sc *scheduledCorrections) run() {
+	sc.errs = make([]error, len(sc.corrections))
+	sc.starts = make([]time.Time, len(sc.corrections))
+	sc.durations = make([]time.Duration, len(sc.corrections))
+	for i, correction := range sc.corrections {
+		if !correction.Informational {
+			sc.starts[i] = time.Now()
+			sc.errs[i] = correction.Run(failFunc)
+			sc.durations[i] = time.Since(sc.starts[i])
+		}
+	}
+}
+
+func (sc *scheduledCorrections) print(out printer.CLI, notifier notifications.Notifier, report *jsonReport) (anyErrors bool) {
+	out.Printf("Applied corrections of %s (%s):\n", sc.domain, sc.provider)
+	for i, correction := range sc.corrections {
+		out.PrintCorrection(i, correction)
+		if correction.Informational {
+			continue
+		}
+		out.EndCorrection(sc.errs[i])
+		report.result(correction, sc.errs[i])
+		if sc.errs[i] != nil {
+			anyErrors = true
+		}
+		e := notifications.NewEvent(sc.domain, sc.provider, correction.Msg, sc.errs[i], false, sc.starts[i])
+		e.Duration = sc.durations[i]
+		notifications.NotifyEvent(notifier, e)
+	}
+	return anyErrors
+}
+
+// schedulingKey returns the key of a domain for the zoneScheduler. Domains
+// that only use providers safe for concurrent use get their own key, all
+// others get "" and are not scheduled.
+func schedulingKey(domain *models.DomainConfig) string {
+	if !providers.ProviderHasCapability(domain.RegistrarInstance.ProviderType, providers.CanRunConcurrently) {
+		return ""
+	}
+	for _, p := range domain.DNSProviderInstances {
+		if !providers.ProviderHasCapability(p.ProviderType, providers.CanRunConcurrently) {
+			return ""
+		}
+	}
+	return domain.UniqueName
+}
+
+func printOrRunCorrections(domain string, provider string, corrections []*models.Correction, out printer.CLI, push bool, interactive bool, notifier notifications.Notifier, report *jsonReport) (anyErrors bool) {
 	anyErrors = false
 	if len(corrections) == 0 {
 		return false
 	}
 	for i, correction := range corrections {
 		out.PrintCorrection(i, correction)
+		if correction.Informational {
+			continue
+		}
 		var err error
+		start := time.Now()
 		if push {
 			if interactive && !out.PromptToRun() {
 				continue
 			}
-			err = correction.F()
+			start = time.Now() // not counting the time at the prompt
+			err = correction.Run(failFunc)
 			out.EndCorrection(err)
+			report.result(correction, err)
 			if err != nil {
 				anyErrors = true
 			}
 		}
-		notifier.Notify(domain, provider, correction.Msg, err, !push)
+		notifications.NotifyEvent(notifier, notifications.NewEvent(domain, provider, correction.Msg, err, !push, start))
 	}
 	return anyErrors
 }
@@ -0,0 +1,472 @@
+package commands
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/StackExchange/dnscontrol/v3/models"
+	"github.com/StackExchange/dnscontrol/v3/pkg/diff"
+	"github.com/StackExchange/dnscontrol/v3/pkg/printer"
+	"github.com/StackExchange/dnscontrol/v3/providers"
+	"github.com/urfave/cli/v2"
+)
+
+// bindTestDir returns a directory with creds.json for a BIND provider
+// that keeps its zones in the directory, and the zone example.com.
+func bindTestDir(t *testing.T, zone string) string {
+	dir, err := ioutil.TempDir("", "dnscontrol-push")
+	if err != nil {
+		t.Fatal(err)
+	}
+	creds := `{"bind": {"directory": "` + filepath.ToSlash(dir) + `"}, "none": {}}`
+	for name, content := range map[string]string{
+		"example.com.zone": zone,
+		"creds.json":       creds,
+	} {
+		if err := ioutil.WriteFile(filepath.Join(dir, name), []byte(content), 0600); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return dir
+}
+
+func TestDetailedExitCode(t *testing.T) {
+	dir := bindTestDir(t, "$TTL 300\n"+
+		"@ IN SOA ns1.example.com. hostmaster.example.com. 1 3600 600 604800 1440\n"+
+		"www IN A 192.0.2.1\n")
+	defer os.RemoveAll(dir)
+
+	for _, tst := range []struct {
+		name    string
+		records string
+		code    int
+	}{
+		{"no changes", `A("www", "192.0.2.1")`, 0},
+		{"create", `A("www", "192.0.2.1"), A("api", "192.0.2.2")`, exitCodeChanges},
+		{"modify", `A("www", "192.0.2.2")`, exitCodeChanges},
+		{"delete", `A("api", "192.0.2.2")`, exitCodeDeletions},
+	} {
+		js := `D("example.com", NewRegistrar("none", "NONE"), DnsProvider(NewDnsProvider("bind", "BIND")), ` + tst.records + `);`
+		if err := ioutil.WriteFile(filepath.Join(dir, "dnsconfig.js"), []byte(js), 0600); err != nil {
+			t.Fatal(err)
+		}
+		var args PreviewArgs
+		args.JSFile = filepath.Join(dir, "dnsconfig.js")
+		args.CredsFile = filepath.Join(dir, "creds.json")
+		args.DetailedExitCode = true
+
+		err := exit(run(context.Background(), args, false, false, DeletionLimitArgs{}, 1, &printer.ConsolePrinter{Writer: ioutil.Discard}))
+		code := 0
+		if err != nil {
+			exitErr, ok := err.(cli.ExitCoder)
+			if !ok {
+				t.Fatalf("%s: expected an exit code, got %v", tst.name, err)
+			}
+			code = exitErr.ExitCode()
+		}
+		if code != tst.code {
+			t.Errorf("%s: expected exit code %d, got %d (%v)", tst.name, tst.code, code, err)
+		}
+	}
+}
+
+func TestDetailedExitCodeOfProviderChanges(t *testing.T) {
+	existing := []string{"www 192.0.2.1"}
+	for _, tst := range []struct {
+		pType   string
+		records string
+		code    int
+	}{
+		// The SOA record of GetZoneRecords is not a change.
+		{"FAKE_LISTER", `A("www", "192.0.2.1")`, 0},
+		{"FAKE_LISTER", `A("www", "192.0.2.2")`, exitCodeChanges},
+		{"FAKE_LISTER", `A("api", "192.0.2.2")`, exitCodeDeletions},
+		// Changes that can not be classified may delete records.
+		{"FAKE_NOLIST", `A("www", "192.0.2.1")`, 0},
+		{"FAKE_NOLIST", `A("www", "192.0.2.2")`, exitCodeDeletions},
+	} {
+		dir, _ := fakeZone(t, tst.pType, existing, tst.records)
+		defer os.RemoveAll(dir)
+		var args PreviewArgs
+		args.JSFile = filepath.Join(dir, "dnsconfig.js")
+		args.CredsFile = filepath.Join(dir, "creds.json")
+		args.DetailedExitCode = true
+
+		err := exit(run(context.Background(), args, false, false, DeletionLimitArgs{}, 1, &printer.ConsolePrinter{Writer: ioutil.Discard}))
+		code := 0
+		if exitErr, ok := err.(cli.ExitCoder); ok {
+			code = exitErr.ExitCode()
+		} else if err != nil {
+			t.Fatalf("%s %s: expected an exit code, got %v", tst.pType, tst.records, err)
+		}
+		if code != tst.code {
+			t.Errorf("%s %s: expected exit code %d, got %d (%v)", tst.pType, tst.records, tst.code, code, err)
+		}
+	}
+}
+
+func TestDeletionLimit(t *testing.T) {
+	zone := "$TTL 300\n" +
+		"@ IN SOA ns1.example.com. hostmaster.example.com. 1 3600 600 604800 1440\n" +
+		"a IN A 192.0.2.1\n" +
+		"b IN A 192.0.2.2\n" +
+		"c IN A 192.0.2.3\n" +
+		"www IN A 192.0.2.4\n"
+	dir := bindTestDir(t, zone)
+	defer os.RemoveAll(dir)
+	// A typo that leaves only one record.
+	js := `D("example.com", NewRegistrar("none", "NONE"), DnsProvider(NewDnsProvider("bind", "BIND")), A("www", "192.0.2.4"));`
+	if err := ioutil.WriteFile(filepath.Join(dir, "dnsconfig.js"), []byte(js), 0600); err != nil {
+		t.Fatal(err)
+	}
+	var args PreviewArgs
+	args.JSFile = filepath.Join(dir, "dnsconfig.js")
+	args.CredsFile = filepath.Join(dir, "creds.json")
+
+	for _, tst := range []struct {
+		limits  DeletionLimitArgs
+		refused string
+	}{
+		{DeletionLimitArgs{MaxDeletions: 2}, "refusing to apply example.com, 3 deletions exceeds limit of 2"},
+		{DeletionLimitArgs{MaxDeletionsPercent: 50}, "refusing to apply example.com, 3 deletions of 5 records exceeds limit of 50%"},
+		{DeletionLimitArgs{MaxDeletions: 3, MaxDeletionsPercent: 75}, ""},
+		{DeletionLimitArgs{MaxDeletions: 1, AllowDeletions: true}, ""},
+	} {
+		if err := ioutil.WriteFile(filepath.Join(dir, "example.com.zone"), []byte(zone), 0600); err != nil {
+			t.Fatal(err)
+		}
+		var out strings.Builder
+		err := run(context.Background(), args, true, false, tst.limits, 1, &printer.ConsolePrinter{Writer: &out})
+		data, _ := ioutil.ReadFile(filepath.Join(dir, "example.com.zone"))
+		pushed := !strings.Contains(string(data), "192.0.2.1")
+		if tst.refused != "" {
+			if err == nil || pushed || !strings.Contains(out.String(), tst.refused) {
+				t.Errorf("%+v: expected the push to be refused with %q, got %v\n%s", tst.limits, tst.refused, err, out.String())
+			}
+			continue
+		}
+		if err != nil || !pushed {
+			t.Errorf("%+v: expected the push to be applied, got %v\n%s", tst.limits, err, out.String())
+		}
+	}
+}
+
+func TestDeletionLimitIgnoresModifications(t *testing.T) {
+	zone := "$TTL 300\n" +
+		"@ IN SOA ns1.example.com. hostmaster.example.com. 1 3600 600 604800 1440\n" +
+		"a IN A 192.0.2.1\n" +
+		"b IN A 192.0.2.2\n" +
+		"c IN A 192.0.2.3\n"
+	dir := bindTestDir(t, zone)
+	defer os.RemoveAll(dir)
+	js := `D("example.com", NewRegistrar("none", "NONE"), DnsProvider(NewDnsProvider("bind", "BIND")), A("a", "192.0.2.11"), A("b", "192.0.2.12"), A("c", "192.0.2.13"));`
+	if err := ioutil.WriteFile(filepath.Join(dir, "dnsconfig.js"), []byte(js), 0600); err != nil {
+		t.Fatal(err)
+	}
+	var args PreviewArgs
+	args.JSFile = filepath.Join(dir, "dnsconfig.js")
+	args.CredsFile = filepath.Join(dir, "creds.json")
+
+	var out strings.Builder
+	err := run(context.Background(), args, true, false, DeletionLimitArgs{MaxDeletions: 1}, 1, &printer.ConsolePrinter{Writer: &out})
+	data, _ := ioutil.ReadFile(filepath.Join(dir, "example.com.zone"))
+	if err != nil || !strings.Contains(string(data), "192.0.2.11") {
+		t.Errorf("expected the modifications to be applied, got %v\n%s", err, out.String())
+	}
+}
+
+func TestStateCache(t *testing.T) {
+	zone := "$TTL 300\n" +
+		"@ IN SOA ns1.example.com. hostmaster.example.com. 1 3600 600 604800 1440\n" +
+		"www IN A 192.0.2.1\n"
+	dir := bindTestDir(t, zone)
+	defer os.RemoveAll(dir)
+	writeJS := func(records string) {
+		js := `D("example.com", NewRegistrar("none", "NONE"), DnsProvider(NewDnsProvider("bind", "BIND")), ` + records + `);`
+		if err := ioutil.WriteFile(filepath.Join(dir, "dnsconfig.js"), []byte(js), 0600); err != nil {
+			t.Fatal(err)
+		}
+	}
+	var args PreviewArgs
+	args.JSFile = filepath.Join(dir, "dnsconfig.js")
+	args.CredsFile = filepath.Join(dir, "creds.json")
+	args.StateCache = filepath.Join(dir, "state.json")
+	preview := func() string {
+		var out strings.Builder
+		if err := run(context.Background(), args, false, false, DeletionLimitArgs{}, 1, &printer.ConsolePrinter{Writer: &out}); err != nil {
+			t.Fatal(err)
+		}
+		return out.String()
+	}
+
+	writeJS(`A("www", "192.0.2.1")`)
+	if out := preview(); strings.Contains(out, "skipped") {
+		t.Fatalf("expected the records to be fetched without a state, got\n%s", out)
+	}
+	state, err := loadStateCache(args.StateCache)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if z := state.Zones["example.com/bind"]; z == nil || z.Config == "" {
+		t.Fatalf("expected the zone to be remembered, got %+v", state.Zones)
+	}
+
+	// A change outside of dnscontrol is only noticed with --refresh.
+	if err := ioutil.WriteFile(filepath.Join(dir, "example.com.zone"), []byte(zone+"old IN A 192.0.2.9\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	if out := preview(); !strings.Contains(out, "skipped") {
+		t.Errorf("expected the unchanged zone to be skipped, got\n%s", out)
+	}
+	args.Refresh = true
+	if out := preview(); !strings.Contains(out, "1 correction") {
+		t.Errorf("expected the records to be fetched with --refresh, got\n%s", out)
+	}
+	args.Refresh = false
+	if out := preview(); strings.Contains(out, "skipped") {
+		t.Errorf("expected the zone with changes to be forgotten, got\n%s", out)
+	}
+
+	// A change of the configuration is noticed.
+	writeJS(`A("www", "192.0.2.1"), A("old", "192.0.2.9")`)
+	if out := preview(); strings.Contains(out, "skipped") || !strings.Contains(out, "0 corrections") {
+		t.Errorf("expected the records to be fetched for a new configuration, got\n%s", out)
+	}
+	if out := preview(); !strings.Contains(out, "skipped") {
+		t.Errorf("expected the zone in sync to be skipped, got\n%s", out)
+	}
+}
+
+func TestPolicies(t *testing.T) {
+	dir := bindTestDir(t, "$TTL 300\n"+
+		"@ IN SOA ns1.example.com. hostmaster.example.com. 1 3600 600 604800 1440\n")
+	defer os.RemoveAll(dir)
+	js := `D("example.com", NewRegistrar("none", "NONE"), DnsProvider(NewDnsProvider("bind", "BIND")), CNAME("*.dev", "dev.example.com."));`
+	if err := ioutil.WriteFile(filepath.Join(dir, "dnsconfig.js"), []byte(js), 0600); err != nil {
+		t.Fatal(err)
+	}
+	var args PreviewArgs
+	args.JSFile = filepath.Join(dir, "dnsconfig.js")
+	args.CredsFile = filepath.Join(dir, "creds.json")
+
+	for _, tst := range []struct {
+		policies string
+		wantErr  bool
+		want     string
+	}{
+		{"no-wildcard-cname", true, "WARNING: policy no-wildcard-cname: CNAME *.dev.example.com is a wildcard"},
+		{"warn:no-wildcard-cname", false, "WARNING: policy no-wildcard-cname"},
+		{"require-caa,no-wildcard-cname", true, "policy require-caa: no CAA record at the apex"},
+	} {
+		args.Policies = tst.policies
+		var out strings.Builder
+		err := run(context.Background(), args, false, false, DeletionLimitArgs{}, 1, &printer.ConsolePrinter{Writer: &out})
+		if (err != nil) != tst.wantErr || !strings.Contains(out.String(), tst.want) {
+			t.Errorf("%s: expected %q and an error %v, got %v\n%s", tst.policies, tst.want, tst.wantErr, err, out.String())
+		}
+		if skipped := !strings.Contains(out.String(), "DNS Provider: bind"); skipped != tst.wantErr {
+			t.Errorf("%s: expected the domain to be skipped %v, got\n%s", tst.policies, tst.wantErr, out.String())
+		}
+	}
+
+	args.Policies = "no-such-policy"
+	if err := run(context.Background(), args, false, false, DeletionLimitArgs{}, 1, &printer.ConsolePrinter{Writer: ioutil.Discard}); err == nil || !strings.Contains(err.Error(), "unknown policy") {
+		t.Errorf("expected an error for an unknown policy, got %v", err)
+	}
+}
+
+// fakeProvider keeps a zone in memory. Like HETZNER, GetZoneRecords
+// returns a SOA record that the corrections leave alone. The zones are
+// shared by the instances with the same "zone" setting.
+type fakeProvider struct {
+	records models.Records
+	nsErr   error // returned by GetNameservers
+}
+
+var fakeZones = map[string]*fakeProvider{}
+
+// fakeListerProvider is a fakeProvider that lists its changes, w/o the SOA.
+type fakeListerProvider struct {
+	*fakeProvider
+}
+
+func init() {
+	newFake := func(lister bool) providers.DspInitializer {
+		return func(settings map[string]string, _ json.RawMessage) (providers.DNSServiceProvider, error) {
+			p := fakeZones[settings["zone"]]
+			if p == nil {
+				return nil, fmt.Errorf("no fake zone %q", settings["zone"])
+			}
+			if lister {
+				return fakeListerProvider{p}, nil
+			}
+			return p, nil
+		}
+	}
+	audit := func([]*models.RecordConfig) error { return nil }
+	providers.RegisterDomainServiceProviderType("FAKE_LISTER", providers.DspFuncs{Initializer: newFake(true), RecordAuditor: audit}, providers.CanGetZones)
+	providers.RegisterDomainServiceProviderType("FAKE_NOLIST", providers.DspFuncs{Initializer: newFake(false), RecordAuditor: audit})
+	providers.RegisterDomainServiceProviderType("FAKE_CONCURRENT", providers.DspFuncs{Initializer: newFake(false), RecordAuditor: audit}, providers.CanRunConcurrently)
+}
+
+// fakeZone returns a directory with creds.json for a fake provider of
+// type pType with the records, and dnsconfig.js for example.com with the
+// desired records.
+func fakeZone(t *testing.T, pType string, existing []string, desired string) (dir string, zone *fakeProvider) {
+	dir, err := ioutil.TempDir("", "dnscontrol-push")
+	if err != nil {
+		t.Fatal(err)
+	}
+	zone = &fakeProvider{}
+	for _, r := range existing {
+		parts := strings.Fields(r)
+		rc := &models.RecordConfig{Type: "A", TTL: 300, Metadata: map[string]string{}}
+		rc.SetLabel(parts[0], "example.com")
+		rc.SetTarget(parts[1])
+		zone.records = append(zone.records, rc)
+	}
+	fakeZones[dir] = zone
+	creds := `{"fake": {"zone": "` + filepath.ToSlash(dir) + `"}, "none": {}}`
+	js := `D("example.com", NewRegistrar("none", "NONE"), DnsProvider(NewDnsProvider("fake", "` + pType + `")), ` + desired + `);`
+	for name, content := range map[string]string{"creds.json": creds, "dnsconfig.js": js} {
+		if err := ioutil.WriteFile(filepath.Join(dir, name), []byte(content), 0600); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return dir, zone
+}
+
+func (p *fakeProvider) GetNameservers(string) ([]*models.Nameserver, error) {
+	return nil, p.nsErr
+}
+
+func (p *fakeProvider) GetZoneRecords(domain string) (models.Records, error) {
+	soa := &models.RecordConfig{Type: "SOA", TTL: 3600}
+	soa.SetLabel("@", domain)
+	soa.SetTargetSOA("ns1.example.com.", "hostmaster.example.com.", 1, 3600, 600, 604800, 1440)
+	return append(append(models.Records{}, p.records...), soa), nil
+}
+
+func (p *fakeProvider) diff(dc *models.DomainConfig) (create, del, modify diff.Changeset, err error) {
+	_, create, del, modify, err = diff.New(dc).IncrementalDiff(p.records)
+	return create, del, modify, err
+}
+
+func (p *fakeProvider) GetDomainCorrections(dc *models.DomainConfig) ([]*models.Correction, error) {
+	create, del, modify, err := p.diff(dc)
+	if err != nil {
+		return nil, err
+	}
+	var corrections []*models.Correction
+	desired := dc.Records
+	for _, group := range []diff.Changeset{del, create, modify} {
+		for _, m := range group {
+			corrections = append(corrections, &models.Correction{
+				Msg: m.String(),
+				F: func() error {
+					p.records = desired
+					return nil
+				},
+			})
+		}
+	}
+	return corrections, nil
+}
+
+func (p fakeListerProvider) ListChangesContext(_ context.Context, dc *models.DomainConfig) (create, del, modify diff.Changeset, existingCount int, err error) {
+	create, del, modify, err = p.diff(dc)
+	return create, del, modify, len(p.records), err
+}
+
+func TestFailFunc(t *testing.T) {
+	defer func() { failFunc = nil }()
+	failFunc = func(c *models.Correction) error {
+		return fmt.Errorf("injected failure of %q", c.Msg)
+	}
+
+	dir, zone := fakeZone(t, "FAKE_LISTER", []string{"www 192.0.2.1", "b 192.0.2.3"}, `A("www", "192.0.2.1")`)
+	defer os.RemoveAll(dir)
+	var args PreviewArgs
+	args.JSFile = filepath.Join(dir, "dnsconfig.js")
+	args.CredsFile = filepath.Join(dir, "creds.json")
+
+	var out strings.Builder
+	err := run(context.Background(), args, true, false, DeletionLimitArgs{}, 1, &printer.ConsolePrinter{Writer: &out})
+	if err == nil || !strings.Contains(out.String(), "injected failure") {
+		t.Errorf("expected the push to fail, got %v\n%s", err, out.String())
+	}
+	if len(zone.records) != 2 {
+		t.Errorf("expected the failing correction not to run, got %d records", len(zone.records))
+	}
+}
+
+func TestScheduledCorrectionsBeforeError(t *testing.T) {
+	dir, zone := fakeZone(t, "FAKE_CONCURRENT", nil, `A("www", "192.0.2.1")`)
+	defer os.RemoveAll(dir)
+	broken := filepath.Join(dir, "broken")
+	fakeZones[broken] = &fakeProvider{nsErr: fmt.Errorf("injected nameserver failure")}
+	defer delete(fakeZones, broken)
+	creds := `{"fake": {"zone": "` + filepath.ToSlash(dir) + `"}, "broken": {"zone": "` + filepath.ToSlash(broken) + `"}, "none": {}}`
+	js := `D("example.com", NewRegistrar("none", "NONE"), DnsProvider(NewDnsProvider("fake", "FAKE_CONCURRENT")), A("www", "192.0.2.1"));
+D("example.net", NewRegistrar("none", "NONE"), DnsProvider(NewDnsProvider("broken", "FAKE_CONCURRENT")));`
+	for name, content := range map[string]string{"creds.json": creds, "dnsconfig.js": js} {
+		if err := ioutil.WriteFile(filepath.Join(dir, name), []byte(content), 0600); err != nil {
+			t.Fatal(err)
+		}
+	}
+	var args PreviewArgs
+	args.JSFile = filepath.Join(dir, "dnsconfig.js")
+	args.CredsFile = filepath.Join(dir, "creds.json")
+
+	// The corrections of example.com are scheduled, then example.net fails.
+	var out strings.Builder
+	err := run(context.Background(), args, true, false, DeletionLimitArgs{}, 10, &printer.ConsolePrinter{Writer: &out})
+	if err == nil || !strings.Contains(err.Error(), "injected nameserver failure") {
+		t.Errorf("expected the nameserver failure, got %v", err)
+	}
+	if len(zone.records) != 1 || !strings.Contains(out.String(), "SUCCESS!") {
+		t.Errorf("expected the scheduled corrections to be applied and printed, got %d records\n%s", len(zone.records), out.String())
+	}
+}
+
+func TestDeletionLimitOfProviderChanges(t *testing.T) {
+	existing := []string{"www 192.0.2.1", "a 192.0.2.2", "b 192.0.2.3"}
+	for _, tst := range []struct {
+		pType   string
+		limits  DeletionLimitArgs
+		refused string
+		warning string
+	}{
+		// The SOA record is not deleted, 1 of 3 records is.
+		{"FAKE_LISTER", DeletionLimitArgs{MaxDeletions: 1}, "", ""},
+		{"FAKE_LISTER", DeletionLimitArgs{MaxDeletionsPercent: 40}, "", ""},
+		{"FAKE_LISTER", DeletionLimitArgs{MaxDeletionsPercent: 30}, "1 deletions of 3 records exceeds limit of 30%", ""},
+		// The deletions of a provider that can not list records are unknown.
+		{"FAKE_NOLIST", DeletionLimitArgs{MaxDeletions: 1}, "", "the deletion limits are not checked"},
+	} {
+		dir, zone := fakeZone(t, tst.pType, existing, `A("www", "192.0.2.1"), A("a", "192.0.2.2")`)
+		defer os.RemoveAll(dir)
+		var args PreviewArgs
+		args.JSFile = filepath.Join(dir, "dnsconfig.js")
+		args.CredsFile = filepath.Join(dir, "creds.json")
+
+		var out strings.Builder
+		err := run(context.Background(), args, true, false, tst.limits, 1, &printer.ConsolePrinter{Writer: &out})
+		pushed := len(zone.records) == 2
+		if tst.refused != "" {
+			if err == nil || pushed || !strings.Contains(out.String(), tst.refused) {
+				t.Errorf("%s %+v: expected the push to be refused with %q, got %v\n%s", tst.pType, tst.limits, tst.refused, err, out.String())
+			}
+			continue
+		}
+		if err != nil || !pushed || !strings.Contains(out.String(), tst.warning) {
+			t.Errorf("%s %+v: expected the push to be applied with %q, got %v\n%s", tst.pType, tst.limits, tst.warning, err, out.String())
+		}
+	}
+}
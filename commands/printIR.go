@@ -138,6 +138,10 @@ func exit(err error) error {
 	if err == nil {
 		return nil
 	}
+	if _, ok := err.(cli.ExitCoder); ok {
+		// The error has its own exit code.
+		return err
+	}
 	return cli.NewExitError(err, 1)
 }
 
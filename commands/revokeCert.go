@@ -0,0 +1,71 @@
+package commands
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+
+	"github.com/StackExchange/dnscontrol/v3/models"
+	"github.com/StackExchange/dnscontrol/v3/pkg/acme"
+	"github.com/StackExchange/dnscontrol/v3/pkg/notifications"
+	"github.com/urfave/cli/v2"
+)
+
+var _ = cmd(catUtils, func() *cli.Command {
+	var args RevokeCertArgs
+	return &cli.Command{
+		Name:  "revoke-cert",
+		Usage: "Revoke a certificate issued by get-certs and delete it from storage",
+		Action: func(c *cli.Context) error {
+			return exit(RevokeCert(args))
+		},
+		Flags: args.flags(),
+	}
+}())
+
+// RevokeCertArgs stores the flags and arguments of the revoke-cert command.
+// The storage and ACME server flags are those of get-certs.
+type RevokeCertArgs struct {
+	GetCertsArgs
+	CertName string
+	Reason   string
+}
+
+func (args *RevokeCertArgs) flags() []cli.Flag {
+	flags := args.GetCertsArgs.flags()
+	flags = append(flags, &cli.StringFlag{
+		Name:        "cert",
+		Destination: &args.CertName,
+		Usage:       `Name of the cert to revoke`,
+	})
+	flags = append(flags, &cli.StringFlag{
+		Name:        "reason",
+		Destination: &args.Reason,
+		Value:       "unspecified",
+		Usage:       `RFC 5280 revocation reason, by name (e.g. keyCompromise) or code`,
+	})
+	return flags
+}
+
+// RevokeCert implements the revoke-cert command.
+func RevokeCert(args RevokeCertArgs) error {
+	if args.CertName == "" {
+		return fmt.Errorf("must provide the name of the cert to revoke with --cert")
+	}
+	reason, ok := acme.RevocationReasons[args.Reason]
+	if !ok {
+		var err error
+		if reason, err = strconv.Atoi(args.Reason); err != nil {
+			return fmt.Errorf("unknown revocation reason %q", args.Reason)
+		}
+	}
+	notifier, err := notifications.Init(nil)
+	if err != nil {
+		return err
+	}
+	client, err := args.newClient(context.Background(), &models.DNSConfig{}, notifier)
+	if err != nil {
+		return err
+	}
+	return client.RevokeCert(args.CertName, reason)
+}
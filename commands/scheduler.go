@@ -0,0 +1,48 @@
+package commands
+
+import "sync"
+
+// zoneScheduler runs the work queued for different zones concurrently,
+// with at most limit zones at a time. The work queued for one zone runs
+// in the order it was added.
+type zoneScheduler struct {
+	limit  int
+	zones  []string
+	queues map[string][]func()
+}
+
+func newZoneScheduler(limit int) *zoneScheduler {
+	if limit < 1 {
+		limit = 1
+	}
+	return &zoneScheduler{limit: limit, queues: map[string][]func(){}}
+}
+
+// add queues f for zone. Zones that are not safe to run concurrently with
+// others should share a key, they then run one after another.
+func (s *zoneScheduler) add(zone string, f func()) {
+	if _, ok := s.queues[zone]; !ok {
+		s.zones = append(s.zones, zone)
+	}
+	s.queues[zone] = append(s.queues[zone], f)
+}
+
+// run runs all queued work and waits for it to finish.
+func (s *zoneScheduler) run() {
+	sem := make(chan struct{}, s.limit)
+	var wg sync.WaitGroup
+	for _, zone := range s.zones {
+		queue := s.queues[zone]
+		wg.Add(1)
+		sem <- struct{}{}
+		go func() {
+			defer func() { <-sem; wg.Done() }()
+			for _, f := range queue {
+				f()
+			}
+		}()
+	}
+	wg.Wait()
+	s.zones = nil
+	s.queues = map[string][]func(){}
+}
@@ -0,0 +1,138 @@
+package commands
+
+import (
+	"fmt"
+	"os"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/StackExchange/dnscontrol/v3/models"
+	"github.com/urfave/cli/v2"
+)
+
+func TestZoneSchedulerOrder(t *testing.T) {
+	var mu sync.Mutex
+	got := map[string][]int{}
+	s := newZoneScheduler(4)
+	for i := 0; i < 20; i++ {
+		i := i
+		zone := fmt.Sprintf("zone%d.example.com", i%5)
+		s.add(zone, func() {
+			time.Sleep(time.Millisecond)
+			mu.Lock()
+			got[zone] = append(got[zone], i)
+			mu.Unlock()
+		})
+	}
+	s.run()
+
+	if len(got) != 5 {
+		t.Fatalf("expected work for 5 zones, got %d", len(got))
+	}
+	for zone, order := range got {
+		for j := 1; j < len(order); j++ {
+			if order[j-1] > order[j] {
+				t.Errorf("%s: work ran out of order: %v", zone, order)
+			}
+		}
+	}
+}
+
+func TestZoneSchedulerLimit(t *testing.T) {
+	var mu sync.Mutex
+	running, maxRunning := 0, 0
+	s := newZoneScheduler(3)
+	for i := 0; i < 10; i++ {
+		s.add(fmt.Sprintf("zone%d", i), func() {
+			mu.Lock()
+			running++
+			if running > maxRunning {
+				maxRunning = running
+			}
+			mu.Unlock()
+			time.Sleep(2 * time.Millisecond)
+			mu.Lock()
+			running--
+			mu.Unlock()
+		})
+	}
+	s.run()
+	if maxRunning > 3 {
+		t.Errorf("expected at most 3 zones at a time, got %d", maxRunning)
+	}
+}
+
+// BenchmarkZoneScheduler applies 2 corrections to each of 50 small zones,
+// each correction waits for a simulated API round trip.
+func BenchmarkZoneScheduler(b *testing.B) {
+	for _, limit := range []int{1, 4, 16} {
+		b.Run(fmt.Sprintf("concurrency=%d", limit), func(b *testing.B) {
+			for n := 0; n < b.N; n++ {
+				s := newZoneScheduler(limit)
+				for i := 0; i < 50; i++ {
+					zone := fmt.Sprintf("zone%d.example.com", i)
+					for c := 0; c < 2; c++ {
+						s.add(zone, func() { time.Sleep(time.Millisecond) })
+					}
+				}
+				s.run()
+			}
+		})
+	}
+}
+
+func TestConcurrencyFlag(t *testing.T) {
+	parse := func(args ...string) int {
+		var concurrency int
+		app := &cli.App{
+			Flags:  []cli.Flag{concurrencyFlag(&concurrency, "")},
+			Action: func(*cli.Context) error { return nil },
+		}
+		if err := app.Run(append([]string{"dnscontrol"}, args...)); err != nil {
+			t.Fatal(err)
+		}
+		return concurrency
+	}
+
+	os.Unsetenv("DNSCONTROL_CONCURRENCY")
+	if got := parse(); got != defaultConcurrency {
+		t.Errorf("default: expected %d, got %d", defaultConcurrency, got)
+	}
+	os.Setenv("DNSCONTROL_CONCURRENCY", "3")
+	defer os.Unsetenv("DNSCONTROL_CONCURRENCY")
+	if got := parse(); got != 3 {
+		t.Errorf("env: expected 3, got %d", got)
+	}
+	if got := parse("--concurrency", "5"); got != 5 {
+		t.Errorf("flag: expected 5, got %d", got)
+	}
+}
+
+func TestSchedulingKey(t *testing.T) {
+	domain := func(registrar string, pTypes ...string) *models.DomainConfig {
+		dc := &models.DomainConfig{
+			Name:              "example.com",
+			UniqueName:        "example.com!internal",
+			RegistrarInstance: &models.RegistrarInstance{ProviderBase: models.ProviderBase{ProviderType: registrar}},
+		}
+		for _, pType := range pTypes {
+			dc.DNSProviderInstances = append(dc.DNSProviderInstances, &models.DNSProviderInstance{ProviderBase: models.ProviderBase{ProviderType: pType}})
+		}
+		return dc
+	}
+
+	for _, tst := range []struct {
+		desc     string
+		dc       *models.DomainConfig
+		expected string
+	}{
+		{"concurrent", domain("FAKE_CONCURRENT", "FAKE_CONCURRENT"), "example.com!internal"},
+		{"registrar not concurrent", domain("FAKE_NOLIST", "FAKE_CONCURRENT"), ""},
+		{"provider not concurrent", domain("FAKE_CONCURRENT", "FAKE_CONCURRENT", "FAKE_LISTER"), ""},
+	} {
+		if got := schedulingKey(tst.dc); got != tst.expected {
+			t.Errorf("%s: expected %q, got %q", tst.desc, tst.expected, got)
+		}
+	}
+}
@@ -0,0 +1,99 @@
+package commands
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+
+	"github.com/StackExchange/dnscontrol/v3/models"
+)
+
+// stateCache remembers the zones that were in sync at the end of a run,
+// so that the next run can skip fetching their records if their desired
+// configuration did not change. It is stored as JSON at path.
+type stateCache struct {
+	path  string
+	Zones map[string]*zoneState `json:"zones"`
+}
+
+// zoneState is the state of a zone at one provider when it was last found
+// to be in sync. Config is the hash of the desired configuration.
+type zoneState struct {
+	Config string `json:"config"`
+}
+
+// loadStateCache reads the state cache at path. A missing file is an
+// empty cache.
+func loadStateCache(path string) (*stateCache, error) {
+	s := &stateCache{path: path, Zones: map[string]*zoneState{}}
+	data, err := ioutil.ReadFile(path)
+	if os.IsNotExist(err) {
+		return s, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(data, s); err != nil {
+		return nil, fmt.Errorf("invalid state cache %s: %w", path, err)
+	}
+	if s.Zones == nil {
+		s.Zones = map[string]*zoneState{}
+	}
+	return s, nil
+}
+
+// save writes the state cache back to its file.
+func (s *stateCache) save() error {
+	if s == nil {
+		return nil
+	}
+	data, err := json.MarshalIndent(s, "", "  ")
+	if err != nil {
+		return err
+	}
+	return ioutil.WriteFile(s.path, data, 0600)
+}
+
+func stateKey(domain, provider string) string {
+	return domain + "/" + provider
+}
+
+// unchanged returns true if the zone was in sync with the configuration
+// of the hash config at the end of the last run.
+func (s *stateCache) unchanged(domain, provider, config string) bool {
+	if s == nil {
+		return false
+	}
+	z := s.Zones[stateKey(domain, provider)]
+	return z != nil && z.Config == config
+}
+
+// set records that the zone is in sync with the configuration of the hash
+// config.
+func (s *stateCache) set(domain, provider, config string) {
+	if s == nil {
+		return
+	}
+	s.Zones[stateKey(domain, provider)] = &zoneState{Config: config}
+}
+
+// invalidate forgets the zone, its records are fetched on the next run.
+func (s *stateCache) invalidate(domain, provider string) {
+	if s == nil {
+		return
+	}
+	delete(s.Zones, stateKey(domain, provider))
+}
+
+// hashConfig returns the hash of the desired configuration of dc.
+func hashConfig(dc *models.DomainConfig) (string, error) {
+	data, err := json.Marshal(dc)
+	if err != nil {
+		return "", err
+	}
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:]), nil
+}
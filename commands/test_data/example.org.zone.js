@@ -171,9 +171,9 @@ D("example.org", REG_CHANGEME,
 	CNAME('www.ipv4', 'services.ipv4.example.org.'),
 	CNAME('www.ipv6', 'services.ipv6.example.org.'),
 	CAA('@', 'issue', 'example.net'),
-	CAA('@', 'issue', 'letsencrypt.org\; accounturi=https://acme-v01.api.letsencrypt.org/acme/reg/1234567'),
-	CAA('@', 'issue', 'letsencrypt.org\; accounturi=https://acme-staging-v02.api.letsencrypt.org/acme/acct/23456789'),
-	CAA('@', 'issue', 'letsencrypt.org\; accounturi=https://acme-v02.api.letsencrypt.org/acme/acct/76543210'),
+	CAA('@', 'issue', 'letsencrypt.org\\; accounturi=https://acme-v01.api.letsencrypt.org/acme/reg/1234567'),
+	CAA('@', 'issue', 'letsencrypt.org\\; accounturi=https://acme-staging-v02.api.letsencrypt.org/acme/acct/23456789'),
+	CAA('@', 'issue', 'letsencrypt.org\\; accounturi=https://acme-v02.api.letsencrypt.org/acme/acct/76543210'),
 	CAA('@', 'issuewild', ';'),
 	CAA('@', 'iodef', 'mailto:security@example.org'),
 	TLSA('_ourcaca4-tlsa', 2, 0, 1, 'ea99063a0a3bda9727032cf82da238698b90ba729300703d3956943635f96488'),
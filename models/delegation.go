@@ -0,0 +1,95 @@
+package models
+
+import (
+	"fmt"
+	"net"
+	"strings"
+)
+
+// Delegation describes the delegation of a subdomain to other nameservers.
+type Delegation struct {
+	Label       string // The delegated subdomain, relative to the zone.
+	Nameservers []DelegationNameserver
+}
+
+// DelegationNameserver is a nameserver of a Delegation. IPs are required
+// for glue if the nameserver is within the delegated subdomain.
+type DelegationNameserver struct {
+	Name string // FQDN, with or without trailing dot.
+	IPs  []net.IP
+}
+
+// Records returns the NS records of the delegation and the A/AAAA glue
+// records of the nameservers that are within the zone origin.
+func (d *Delegation) Records(origin string, ttl uint32) (Records, error) {
+	if d.Label == "" || d.Label == "@" {
+		return nil, fmt.Errorf("delegation of %s: can not delegate the apex", origin)
+	}
+	if len(d.Nameservers) == 0 {
+		return nil, fmt.Errorf("delegation of %s: no nameservers", d.Label)
+	}
+	subdomain := strings.ToLower(d.Label + "." + origin)
+
+	var nsRecords, glueRecords Records
+	for _, ns := range d.Nameservers {
+		name := strings.ToLower(strings.TrimSuffix(ns.Name, "."))
+		inZone := inBailiwick(name, origin)
+		if inBailiwick(name, subdomain) && len(ns.IPs) == 0 {
+			return nil, fmt.Errorf("delegation of %s: nameserver %s needs glue, but has no IPs", d.Label, name)
+		}
+		if !inZone && len(ns.IPs) > 0 {
+			return nil, fmt.Errorf("delegation of %s: can not add glue for %s, it is outside of %s", d.Label, name, origin)
+		}
+
+		rc := &RecordConfig{Type: "NS", TTL: ttl, Metadata: map[string]string{}}
+		rc.SetLabel(d.Label, origin)
+		rc.SetTarget(name + ".")
+		nsRecords = append(nsRecords, rc)
+
+		for _, ip := range ns.IPs {
+			rc := &RecordConfig{Type: "A", TTL: ttl, Metadata: map[string]string{}}
+			if ip.To4() == nil {
+				rc.Type = "AAAA"
+			}
+			rc.SetLabelFromFQDN(name, origin)
+			rc.SetTargetIP(ip)
+			glueRecords = append(glueRecords, rc)
+		}
+	}
+	return append(nsRecords, glueRecords...), nil
+}
+
+// inBailiwick returns true if name is domain or a subdomain of it.
+func inBailiwick(name, domain string) bool {
+	return name == domain || strings.HasSuffix(name, "."+domain)
+}
+
+// AddDelegation adds the records of a delegation to the domain. NS records
+// of the delegated subdomain and A/AAAA records of its in-zone nameservers
+// are replaced, so that the NS records and the glue stay consistent.
+func (dc *DomainConfig) AddDelegation(d *Delegation, ttl uint32) error {
+	records, err := d.Records(dc.Name, ttl)
+	if err != nil {
+		return err
+	}
+	replaced := map[string]bool{}
+	for _, rc := range records {
+		replaced[rc.NameFQDN+"/"+rc.Type] = true
+	}
+	for _, ns := range d.Nameservers {
+		name := strings.ToLower(strings.TrimSuffix(ns.Name, "."))
+		if inBailiwick(name, dc.Name) {
+			replaced[name+"/A"] = true
+			replaced[name+"/AAAA"] = true
+		}
+	}
+
+	kept := dc.Records[:0]
+	for _, rc := range dc.Records {
+		if !replaced[rc.NameFQDN+"/"+rc.Type] {
+			kept = append(kept, rc)
+		}
+	}
+	dc.Records = append(kept, records...)
+	return nil
+}
@@ -0,0 +1,65 @@
+package models
+
+import (
+	"net"
+	"testing"
+)
+
+func TestAddDelegation(t *testing.T) {
+	stale := &RecordConfig{Type: "A", Metadata: map[string]string{}}
+	stale.SetLabel("ns1.sub", "example.com")
+	stale.SetTarget("192.0.2.99")
+	www := &RecordConfig{Type: "A", Metadata: map[string]string{}}
+	www.SetLabel("www", "example.com")
+	www.SetTarget("192.0.2.80")
+	dc := &DomainConfig{Name: "example.com", Records: Records{stale, www}}
+
+	err := dc.AddDelegation(&Delegation{
+		Label: "sub",
+		Nameservers: []DelegationNameserver{
+			{Name: "ns1.sub.example.com.", IPs: []net.IP{net.ParseIP("192.0.2.1"), net.ParseIP("2001:db8::1")}},
+			{Name: "ns.example.net"},
+		},
+	}, 300)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var got []string
+	for _, rc := range dc.Records {
+		got = append(got, rc.NameFQDN+" "+rc.Type+" "+rc.GetTargetField())
+	}
+	expected := []string{
+		"www.example.com A 192.0.2.80",
+		"sub.example.com NS ns1.sub.example.com.",
+		"sub.example.com NS ns.example.net.",
+		"ns1.sub.example.com A 192.0.2.1",
+		"ns1.sub.example.com AAAA 2001:db8::1",
+	}
+	if len(got) != len(expected) {
+		t.Fatalf("expected %v, got %v", expected, got)
+	}
+	for i := range expected {
+		if got[i] != expected[i] {
+			t.Errorf("record %d: expected %q, got %q", i, expected[i], got[i])
+		}
+	}
+}
+
+func TestDelegationGlueErrors(t *testing.T) {
+	tests := []struct {
+		name string
+		ns   DelegationNameserver
+	}{
+		{"in-bailiwick without glue", DelegationNameserver{Name: "ns1.sub.example.com"}},
+		{"glue outside of zone", DelegationNameserver{Name: "ns.example.net", IPs: []net.IP{net.ParseIP("192.0.2.1")}}},
+	}
+	for _, tst := range tests {
+		t.Run(tst.name, func(t *testing.T) {
+			d := &Delegation{Label: "sub", Nameservers: []DelegationNameserver{tst.ns}}
+			if _, err := d.Records("example.com", 300); err == nil {
+				t.Error("expected an error")
+			}
+		})
+	}
+}
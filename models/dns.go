@@ -111,6 +111,36 @@ func NameserversToStrings(nss []*Nameserver) (s []string) {
 type Correction struct {
 	F   func() error `json:"-"`
 	Msg string
+	// Informational corrections only report something to the user. They
+	// do not change anything, are not counted as changes and F is not run
+	// (it may be nil).
+	Informational bool `json:",omitempty"`
+}
+
+// FailFunc decides if a correction fails instead of running. It makes the
+// error handling of correction runners testable without a failing provider.
+type FailFunc func(*Correction) error
+
+// Run runs the correction. If fail returns an error for c, that error is
+// returned instead and F is not run. fail may be nil.
+func (c *Correction) Run(fail FailFunc) error {
+	if fail != nil {
+		if err := fail(c); err != nil {
+			return err
+		}
+	}
+	return c.F()
+}
+
+// CountChanges returns the number of corrections that are not informational.
+func CountChanges(corrections []*Correction) int {
+	n := 0
+	for _, c := range corrections {
+		if !c.Informational {
+			n++
+		}
+	}
+	return n
 }
 
 // DomainContainingFQDN finds the best domain from the dns config for the given record fqdn.
@@ -1,6 +1,7 @@
 package models
 
 import (
+	"fmt"
 	"testing"
 )
 
@@ -70,3 +71,31 @@ func TestDowncase(t *testing.T) {
 		t.Errorf("%v: target1 expected (%v) got (%v)\n", dc.Records, "targetmx", dc.Records[1].GetTargetField())
 	}
 }
+
+func TestCountChanges(t *testing.T) {
+	corrections := []*Correction{
+		{Msg: "create A www"},
+		{Msg: "note: record is unmanaged", Informational: true},
+		{Msg: "delete A old"},
+	}
+	if n := CountChanges(corrections); n != 2 {
+		t.Errorf("expected 2 changes, got %d", n)
+	}
+}
+
+func TestCorrectionRunFail(t *testing.T) {
+	ran := false
+	c := &Correction{Msg: "create A www", F: func() error { ran = true; return nil }}
+
+	injected := fmt.Errorf("injected failure")
+	if err := c.Run(func(*Correction) error { return injected }); err != injected {
+		t.Errorf("expected the injected error, got %v", err)
+	}
+	if ran {
+		t.Error("F must not run when the correction is made to fail")
+	}
+
+	if err := c.Run(func(*Correction) error { return nil }); err != nil || !ran {
+		t.Errorf("expected F to run, got err=%v ran=%v", err, ran)
+	}
+}
@@ -66,12 +66,22 @@ func RRtoRC(rr dns.RR, origin string) RecordConfig {
 		panicInvalid(rc.SetTarget(v.A.String()))
 	case *dns.AAAA:
 		panicInvalid(rc.SetTarget(v.AAAA.String()))
+	case *dns.APL:
+		panicInvalid(rc.SetTargetAPL(aplPrefixesFromRR(v.Prefixes)))
 	case *dns.CAA:
 		panicInvalid(rc.SetTargetCAA(v.Flag, v.Tag, v.Value))
 	case *dns.CNAME:
 		panicInvalid(rc.SetTarget(v.Target))
+	case *dns.DNAME:
+		panicInvalid(rc.SetTargetDNAME(v.Target))
 	case *dns.DS:
 		panicInvalid(rc.SetTargetDS(v.KeyTag, v.Algorithm, v.DigestType, v.Digest))
+	case *dns.CSYNC:
+		panicInvalid(rc.SetTargetCSYNC(v.Serial, v.Flags, csyncTypeNames(v.TypeBitMap)))
+	case *dns.HINFO:
+		panicInvalid(rc.SetTargetHINFO(v.Cpu, v.Os))
+	case *dns.LOC:
+		panicInvalid(rc.SetTargetLOC(v.Latitude, v.Longitude, v.Altitude, v.Size, v.HorizPre, v.VertPre))
 	case *dns.MX:
 		panicInvalid(rc.SetTargetMX(v.Preference, v.Mx))
 	case *dns.NS:
@@ -86,6 +96,10 @@ func RRtoRC(rr dns.RR, origin string) RecordConfig {
 		panicInvalid(rc.SetTargetSRV(v.Priority, v.Weight, v.Port, v.Target))
 	case *dns.SSHFP:
 		panicInvalid(rc.SetTargetSSHFP(v.Algorithm, v.Type, v.FingerPrint))
+	case *dns.SVCB:
+		panicInvalid(rc.setTargetSVCBValue(v.Priority, v.Target, v.Value))
+	case *dns.HTTPS:
+		panicInvalid(rc.setTargetSVCBValue(v.Priority, v.Target, v.Value))
 	case *dns.TLSA:
 		panicInvalid(rc.SetTargetTLSA(v.Usage, v.Selector, v.MatchingType, v.Certificate))
 	case *dns.TXT:
@@ -1,8 +1,6 @@
 package models
 
 import (
-	"fmt"
-
 	"github.com/qdm12/reprint"
 	"golang.org/x/net/idna"
 )
@@ -76,20 +74,8 @@ func (dc *DomainConfig) Punycode() error {
 		rec.SetLabelFromFQDN(t, dc.Name)
 
 		// Set the target:
-		switch rec.Type { // #rtype_variations
-		case "ALIAS", "MX", "NS", "CNAME", "PTR", "SRV", "URL", "URL301", "FRAME", "R53_ALIAS", "NS1_URLFWD":
-			// These rtypes are hostnames, therefore need to be converted (unlike, for example, an AAAA record)
-			t, err := idna.ToASCII(rec.GetTargetField())
-			if err != nil {
-				return err
-			}
-			rec.SetTarget(t)
-		case "CF_REDIRECT", "CF_TEMP_REDIRECT":
-			rec.SetTarget(rec.GetTargetField())
-		case "A", "AAAA", "CAA", "DS", "NAPTR", "SOA", "SSHFP", "TXT", "TLSA", "AZURE_ALIAS":
-			// Nothing to do.
-		default:
-			return fmt.Errorf("Punycode rtype %v unimplemented", rec.Type)
+		if err := rec.punycodeTarget(); err != nil {
+			return err
 		}
 	}
 	return nil
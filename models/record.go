@@ -11,6 +11,7 @@ import (
 	"github.com/miekg/dns"
 	"github.com/miekg/dns/dnsutil"
 	"github.com/qdm12/reprint"
+	"golang.org/x/net/idna"
 )
 
 // RecordConfig stores a DNS record.
@@ -19,8 +20,13 @@ import (
 //     A
 //     AAAA
 //     ANAME  // Technically not an official rtype yet.
+//     APL
 //     CAA
 //     CNAME
+//     CSYNC
+//     DNAME
+//     HINFO
+//     LOC
 //     MX
 //     NAPTR
 //     NS
@@ -28,6 +34,8 @@ import (
 //     SRV
 //     SOA
 //     SSHFP
+//     SVCB
+//     HTTPS
 //     TLSA
 //     TXT
 //   Pseudo-Types:
@@ -87,12 +95,24 @@ type RecordConfig struct {
 	SrvPriority      uint16            `json:"srvpriority,omitempty"`
 	SrvWeight        uint16            `json:"srvweight,omitempty"`
 	SrvPort          uint16            `json:"srvport,omitempty"`
+	AplPrefixes      []AplPrefix       `json:"aplprefixes,omitempty"` // In the order of the record, see SetTargetAPL.
 	CaaTag           string            `json:"caatag,omitempty"`
 	CaaFlag          uint8             `json:"caaflag,omitempty"`
+	CsyncSerial      uint32            `json:"csyncserial,omitempty"`
+	CsyncFlags       uint16            `json:"csyncflags,omitempty"`
+	CsyncTypes       []string          `json:"csynctypes,omitempty"` // Canonical, see SetTargetCSYNC.
 	DsKeyTag         uint16            `json:"dskeytag,omitempty"`
 	DsAlgorithm      uint8             `json:"dsalgorithm,omitempty"`
 	DsDigestType     uint8             `json:"dsdigesttype,omitempty"`
 	DsDigest         string            `json:"dsdigest,omitempty"`
+	HinfoCpu         string            `json:"hinfocpu,omitempty"`
+	HinfoOs          string            `json:"hinfoos,omitempty"`
+	LocSize          uint8             `json:"locsize,omitempty"` // The LOC fields are in wire format, see SetTargetLOC.
+	LocHorizPre      uint8             `json:"lochorizpre,omitempty"`
+	LocVertPre       uint8             `json:"locvertpre,omitempty"`
+	LocLatitude      uint32            `json:"loclatitude,omitempty"`
+	LocLongitude     uint32            `json:"loclongitude,omitempty"`
+	LocAltitude      uint32            `json:"localtitude,omitempty"`
 	NaptrOrder       uint16            `json:"naptrorder,omitempty"`
 	NaptrPreference  uint16            `json:"naptrpreference,omitempty"`
 	NaptrFlags       string            `json:"naptrflags,omitempty"`
@@ -100,6 +120,8 @@ type RecordConfig struct {
 	NaptrRegexp      string            `json:"naptrregexp,omitempty"`
 	SshfpAlgorithm   uint8             `json:"sshfpalgorithm,omitempty"`
 	SshfpFingerprint uint8             `json:"sshfpfingerprint,omitempty"`
+	SvcPriority      uint16            `json:"svcpriority,omitempty"`
+	SvcParams        string            `json:"svcparams,omitempty"` // Canonical presentation format, see SetTargetSVCB.
 	SoaMbox          string            `json:"soambox,omitempty"`
 	SoaSerial        uint32            `json:"soaserial,omitempty"`
 	SoaRefresh       uint32            `json:"soarefresh,omitempty"`
@@ -112,6 +134,7 @@ type RecordConfig struct {
 	TxtStrings       []string          `json:"txtstrings,omitempty"` // TxtStrings stores all strings (including the first). Target stores all the strings joined.
 	R53Alias         map[string]string `json:"r53_alias,omitempty"`
 	AzureAlias       map[string]string `json:"azure_alias,omitempty"`
+	Routing          map[string]string `json:"routing,omitempty"` // Routing policy (weight, region, ...), specific to the provider. Part of the diff.
 }
 
 // MarshalJSON marshals RecordConfig.
@@ -148,12 +171,24 @@ func (rc *RecordConfig) UnmarshalJSON(b []byte) error {
 		SrvPriority      uint16            `json:"srvpriority,omitempty"`
 		SrvWeight        uint16            `json:"srvweight,omitempty"`
 		SrvPort          uint16            `json:"srvport,omitempty"`
+		AplPrefixes      []AplPrefix       `json:"aplprefixes,omitempty"`
 		CaaTag           string            `json:"caatag,omitempty"`
 		CaaFlag          uint8             `json:"caaflag,omitempty"`
+		CsyncSerial      uint32            `json:"csyncserial,omitempty"`
+		CsyncFlags       uint16            `json:"csyncflags,omitempty"`
+		CsyncTypes       []string          `json:"csynctypes,omitempty"`
 		DsKeyTag         uint16            `json:"dskeytag,omitempty"`
 		DsAlgorithm      uint8             `json:"dsalgorithm,omitempty"`
 		DsDigestType     uint8             `json:"dsdigesttype,omitempty"`
 		DsDigest         string            `json:"dsdigest,omitempty"`
+		HinfoCpu         string            `json:"hinfocpu,omitempty"`
+		HinfoOs          string            `json:"hinfoos,omitempty"`
+		LocSize          uint8             `json:"locsize,omitempty"`
+		LocHorizPre      uint8             `json:"lochorizpre,omitempty"`
+		LocVertPre       uint8             `json:"locvertpre,omitempty"`
+		LocLatitude      uint32            `json:"loclatitude,omitempty"`
+		LocLongitude     uint32            `json:"loclongitude,omitempty"`
+		LocAltitude      uint32            `json:"localtitude,omitempty"`
 		NaptrOrder       uint16            `json:"naptrorder,omitempty"`
 		NaptrPreference  uint16            `json:"naptrpreference,omitempty"`
 		NaptrFlags       string            `json:"naptrflags,omitempty"`
@@ -161,6 +196,8 @@ func (rc *RecordConfig) UnmarshalJSON(b []byte) error {
 		NaptrRegexp      string            `json:"naptrregexp,omitempty"`
 		SshfpAlgorithm   uint8             `json:"sshfpalgorithm,omitempty"`
 		SshfpFingerprint uint8             `json:"sshfpfingerprint,omitempty"`
+		SvcPriority      uint16            `json:"svcpriority,omitempty"`
+		SvcParams        string            `json:"svcparams,omitempty"`
 		SoaMbox          string            `json:"soambox,omitempty"`
 		SoaSerial        uint32            `json:"soaserial,omitempty"`
 		SoaRefresh       uint32            `json:"soarefresh,omitempty"`
@@ -173,6 +210,7 @@ func (rc *RecordConfig) UnmarshalJSON(b []byte) error {
 		TxtStrings       []string          `json:"txtstrings,omitempty"` // TxtStrings stores all strings (including the first). Target stores only the first one.
 		R53Alias         map[string]string `json:"r53_alias,omitempty"`
 		AzureAlias       map[string]string `json:"azure_alias,omitempty"`
+		Routing          map[string]string `json:"routing,omitempty"`
 		// NB(tlim): If anyone can figure out how to do this without listing all
 		// the fields, please let us know!
 	}{}
@@ -230,8 +268,8 @@ func (rc *RecordConfig) SetLabel(short, origin string) {
 	// TODO(tlim): We should add more validation here or in a separate validation
 	// module.  We might want to check things like (\w+\.)+
 
-	short = strings.ToLower(short)
-	origin = strings.ToLower(origin)
+	short = toASCII(strings.ToLower(short))
+	origin = toASCII(strings.ToLower(origin))
 	if short == "" || short == "@" {
 		rc.Name = "@"
 		rc.NameFQDN = origin
@@ -267,8 +305,8 @@ func (rc *RecordConfig) SetLabelFromFQDN(fqdn, origin string) {
 		fqdn = fqdn[:len(fqdn)-1]
 	}
 
-	fqdn = strings.ToLower(fqdn)
-	origin = strings.ToLower(origin)
+	fqdn = toASCII(strings.ToLower(fqdn))
+	origin = toASCII(strings.ToLower(origin))
 	rc.Name = dnsutil.TrimDomainName(fqdn, origin)
 	rc.NameFQDN = fqdn
 }
@@ -282,20 +320,57 @@ func (rc *RecordConfig) GetLabel() string {
 	return rc.Name
 }
 
+// GetLabelUnicode is like GetLabel but returns internationalized labels in
+// their Unicode form, e.g. "café" instead of "xn--caf-dma". It is meant for
+// display only, the labels are compared and sent to providers in their
+// ASCII form.
+func (rc *RecordConfig) GetLabelUnicode() string {
+	u, err := idna.ToUnicode(rc.Name)
+	if err != nil {
+		return rc.Name
+	}
+	return u
+}
+
 // GetLabelFQDN returns the FQDN of the label associated with this RecordConfig.
 // It will not end with ".".
 func (rc *RecordConfig) GetLabelFQDN() string {
 	return rc.NameFQDN
 }
 
+// GetRoutingDiffable returns the routing policy of the record in a form
+// that is comparable by a differ, e.g. " routing.weight=10", or "" if it
+// has none.
+func (rc *RecordConfig) GetRoutingDiffable() string {
+	keys := make([]string, 0, len(rc.Routing))
+	for k := range rc.Routing {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	var content string
+	for _, k := range keys {
+		content += fmt.Sprintf(" routing.%s=%s", k, rc.Routing[k])
+	}
+	return content
+}
+
 // ToDiffable returns a string that is comparable by a differ.
 // extraMaps: a list of maps that should be included in the comparison.
 func (rc *RecordConfig) ToDiffable(extraMaps ...map[string]string) string {
 	content := fmt.Sprintf("%v ttl=%d", rc.GetTargetCombined(), rc.TTL)
+	if rc.IsSPF() {
+		// Compare the policy, not how it is split into strings.
+		content = fmt.Sprintf("%v ttl=%d", rc.spfDiffable(), rc.TTL)
+	}
+	if rc.Type == "DNAME" {
+		// The target is a name, compare it case-insensitively.
+		content = fmt.Sprintf("%s ttl=%d", strings.ToLower(rc.target), rc.TTL)
+	}
 	if rc.Type == "SOA" {
 		content = fmt.Sprintf("%s %v %d %d %d %d ttl=%d", rc.target, rc.SoaMbox, rc.SoaRefresh, rc.SoaRetry, rc.SoaExpire, rc.SoaMinttl, rc.TTL)
 		// SoaSerial is not used in comparison
 	}
+	content += rc.GetRoutingDiffable()
 	for _, valueMap := range extraMaps {
 		// sort the extra values map keys to perform a deterministic
 		// comparison since Golang maps iteration order is not guaranteed
@@ -344,13 +419,35 @@ func (rc *RecordConfig) ToRR() dns.RR {
 		rr.(*dns.A).A = rc.GetTargetIP()
 	case dns.TypeAAAA:
 		rr.(*dns.AAAA).AAAA = rc.GetTargetIP()
+	case dns.TypeAPL:
+		rr.(*dns.APL).Prefixes = aplPrefixesToRR(rc.AplPrefixes)
 	case dns.TypeCNAME:
 		rr.(*dns.CNAME).Target = rc.GetTargetField()
+	case dns.TypeCSYNC:
+		rr.(*dns.CSYNC).Serial = rc.CsyncSerial
+		rr.(*dns.CSYNC).Flags = rc.CsyncFlags
+		bitmap, err := csyncTypeBitMap(rc.CsyncTypes)
+		if err != nil {
+			panic(err)
+		}
+		rr.(*dns.CSYNC).TypeBitMap = bitmap
+	case dns.TypeDNAME:
+		rr.(*dns.DNAME).Target = rc.GetTargetField()
 	case dns.TypeDS:
 		rr.(*dns.DS).Algorithm = rc.DsAlgorithm
 		rr.(*dns.DS).DigestType = rc.DsDigestType
 		rr.(*dns.DS).Digest = rc.DsDigest
 		rr.(*dns.DS).KeyTag = rc.DsKeyTag
+	case dns.TypeHINFO:
+		rr.(*dns.HINFO).Cpu = rc.HinfoCpu
+		rr.(*dns.HINFO).Os = rc.HinfoOs
+	case dns.TypeLOC:
+		rr.(*dns.LOC).Size = rc.LocSize
+		rr.(*dns.LOC).HorizPre = rc.LocHorizPre
+		rr.(*dns.LOC).VertPre = rc.LocVertPre
+		rr.(*dns.LOC).Latitude = rc.LocLatitude
+		rr.(*dns.LOC).Longitude = rc.LocLongitude
+		rr.(*dns.LOC).Altitude = rc.LocAltitude
 	case dns.TypePTR:
 		rr.(*dns.PTR).Ptr = rc.GetTargetField()
 	case dns.TypeNAPTR:
@@ -382,6 +479,14 @@ func (rc *RecordConfig) ToRR() dns.RR {
 		rr.(*dns.SSHFP).Algorithm = rc.SshfpAlgorithm
 		rr.(*dns.SSHFP).Type = rc.SshfpFingerprint
 		rr.(*dns.SSHFP).FingerPrint = rc.GetTargetField()
+	case dns.TypeSVCB:
+		rr.(*dns.SVCB).Priority = rc.SvcPriority
+		rr.(*dns.SVCB).Target = rc.GetTargetField()
+		rr.(*dns.SVCB).Value = svcbParamsMustParse(rc.SvcParams)
+	case dns.TypeHTTPS:
+		rr.(*dns.HTTPS).Priority = rc.SvcPriority
+		rr.(*dns.HTTPS).Target = rc.GetTargetField()
+		rr.(*dns.HTTPS).Value = svcbParamsMustParse(rc.SvcParams)
 	case dns.TypeCAA:
 		rr.(*dns.CAA).Flag = rc.CaaFlag
 		rr.(*dns.CAA).Tag = rc.CaaTag
@@ -490,19 +595,62 @@ func (recs Records) GroupedByFQDN() ([]string, map[string]Records) {
 
 // PostProcessRecords does any post-processing of the downloaded DNS records.
 func PostProcessRecords(recs []*RecordConfig) {
+	punycode(recs)
 	downcase(recs)
 }
 
+// punycode converts internationalized labels and targets to their ASCII
+// form, in a list of RecordConfig. Providers may return either form.
+func punycode(recs []*RecordConfig) {
+	for _, r := range recs {
+		r.Name = toASCII(r.Name)
+		r.NameFQDN = toASCII(r.NameFQDN)
+		// Records that can not be converted are left alone.
+		_ = r.punycodeTarget()
+	}
+}
+
+// toASCII returns the ASCII (punycode) form of the name s. Names that can
+// not be converted are returned unchanged.
+func toASCII(s string) string {
+	a, err := idna.ToASCII(s)
+	if err != nil {
+		return s
+	}
+	return a
+}
+
+// punycodeTarget converts the target of rc to its ASCII form if it is a
+// hostname.
+func (rc *RecordConfig) punycodeTarget() error {
+	switch rc.Type { // #rtype_variations
+	case "ALIAS", "MX", "NS", "CNAME", "DNAME", "PTR", "SRV", "SVCB", "HTTPS", "URL", "URL301", "FRAME", "R53_ALIAS", "NS1_URLFWD":
+		// These rtypes are hostnames, therefore need to be converted (unlike, for example, an AAAA record)
+		t, err := idna.ToASCII(rc.GetTargetField())
+		if err != nil {
+			return err
+		}
+		rc.SetTarget(t)
+	case "CF_REDIRECT", "CF_TEMP_REDIRECT":
+		rc.SetTarget(rc.GetTargetField())
+	case "A", "AAAA", "APL", "CAA", "CSYNC", "DS", "HINFO", "LOC", "NAPTR", "SOA", "SSHFP", "TXT", "TLSA", "AZURE_ALIAS":
+		// Nothing to do.
+	default:
+		return fmt.Errorf("Punycode rtype %v unimplemented", rc.Type)
+	}
+	return nil
+}
+
 // Downcase converts all labels and targets to lowercase in a list of RecordConfig.
 func downcase(recs []*RecordConfig) {
 	for _, r := range recs {
 		r.Name = strings.ToLower(r.Name)
 		r.NameFQDN = strings.ToLower(r.NameFQDN)
 		switch r.Type { // #rtype_variations
-		case "ANAME", "CNAME", "DS", "MX", "NS", "PTR", "NAPTR", "SRV":
+		case "ANAME", "CNAME", "DNAME", "DS", "MX", "NS", "PTR", "NAPTR", "SRV", "SVCB", "HTTPS":
 			// These record types have a target that is case insensitive, so we downcase it.
 			r.target = strings.ToLower(r.target)
-		case "A", "AAAA", "ALIAS", "CAA", "IMPORT_TRANSFORM", "TLSA", "TXT", "SSHFP", "CF_REDIRECT", "CF_TEMP_REDIRECT":
+		case "A", "AAAA", "ALIAS", "APL", "CAA", "CSYNC", "HINFO", "IMPORT_TRANSFORM", "LOC", "TLSA", "TXT", "SSHFP", "CF_REDIRECT", "CF_TEMP_REDIRECT":
 			// These record types have a target that is case sensitive, or is an IP address. We leave them alone.
 			// Do nothing.
 		case "SOA":
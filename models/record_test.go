@@ -2,6 +2,7 @@ package models
 
 import (
 	"reflect"
+	"strings"
 	"testing"
 )
 
@@ -233,3 +234,338 @@ func TestRecordConfig_Copy(t *testing.T) {
 		})
 	}
 }
+
+func TestSetTargetSPF(t *testing.T) {
+	long := "v=spf1" + strings.Repeat(" ip4:192.0.2.1", 30) + " -all"
+	tests := []struct {
+		policy  string
+		chunks  int
+		wantErr bool
+	}{
+		{"v=spf1 -all", 1, false},
+		{"v=spf1 include:_spf.example.com ip4:192.0.2.0/24 ip6:2001:db8::/32 mx ~all", 1, false},
+		{"v=spf1 a:mail.example.com redirect=_spf.example.com", 1, false},
+		{long, 2, false},
+		{"include:_spf.example.com -all", 0, true},
+		{"v=spf1 includes:_spf.example.com", 0, true},
+		{"v=spf1 ip4:2001:db8::1", 0, true},
+		{"v=spf1 include:", 0, true},
+		{"v=spf1 -all:foo", 0, true},
+	}
+	for _, tst := range tests {
+		rc := &RecordConfig{Type: "TXT"}
+		err := rc.SetTargetSPF(tst.policy)
+		if (err != nil) != tst.wantErr {
+			t.Errorf("%q: expected error=%v, got %v", tst.policy, tst.wantErr, err)
+			continue
+		}
+		if err != nil {
+			continue
+		}
+		if len(rc.TxtStrings) != tst.chunks {
+			t.Errorf("%q: expected %d chunks, got %d", tst.policy, tst.chunks, len(rc.TxtStrings))
+		}
+		if strings.Join(rc.TxtStrings, "") != tst.policy {
+			t.Errorf("%q: policy was not preserved: %v", tst.policy, rc.TxtStrings)
+		}
+		if !rc.IsSPF() {
+			t.Errorf("%q: expected IsSPF() to be true", tst.policy)
+		}
+	}
+}
+
+func TestSetTargetSVCB(t *testing.T) {
+	tests := []struct {
+		in      string
+		params  string
+		wantErr string
+	}{
+		{`1 . alpn="h2,h3" ipv4hint=1.2.3.4`, `alpn="h2,h3" ipv4hint="1.2.3.4"`, ""},
+		{`1 . ipv4hint=1.2.3.4 alpn=h2,h3`, `alpn="h2,h3" ipv4hint="1.2.3.4"`, ""},
+		{`1 svc.example.com. port=8443 no-default-alpn mandatory=port,alpn alpn=h3`, `mandatory="alpn,port" alpn="h3" no-default-alpn port="8443"`, ""},
+		{`0 svc.example.com.`, ``, ""},
+		{`0 svc.example.com. alpn=h2`, ``, "priority 0 (AliasMode) must not have params"},
+		{`1 . alpn=h2 alpn=h3`, ``, "duplicate SVCB param"},
+		{`1 . bogus=1`, ``, "invalid SVCB params"},
+		{`1`, ``, "does not contain a priority and a target"},
+	}
+	for _, tst := range tests {
+		rc := &RecordConfig{Type: "HTTPS"}
+		err := rc.SetTargetSVCBString(tst.in)
+		if tst.wantErr != "" {
+			if err == nil || !strings.Contains(err.Error(), tst.wantErr) {
+				t.Errorf("%q: expected error containing %q, got %v", tst.in, tst.wantErr, err)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("%q: unexpected error %v", tst.in, err)
+			continue
+		}
+		if rc.SvcParams != tst.params {
+			t.Errorf("%q: expected params %q, got %q", tst.in, tst.params, rc.SvcParams)
+		}
+		// The params survive a round trip through the dns package.
+		rc.SetLabel("@", "example.com")
+		back := RRtoRC(rc.ToRR(), "example.com")
+		if back.SvcPriority != rc.SvcPriority || back.GetTargetField() != rc.GetTargetField() || back.SvcParams != rc.SvcParams {
+			t.Errorf("%q: round trip changed the record to %q", tst.in, back.GetTargetCombined())
+		}
+	}
+}
+
+func TestSetTargetDNAME(t *testing.T) {
+	rc := &RecordConfig{}
+	if err := rc.PopulateFromString("DNAME", "new.example.com", "example.com"); err == nil {
+		t.Error("expected an error for a target that is not fully qualified")
+	}
+	rc = &RecordConfig{}
+	if err := rc.PopulateFromString("DNAME", "new.example.com.", "example.com"); err != nil {
+		t.Fatal(err)
+	}
+	rc.SetLabel("old", "example.com")
+	if got := rc.GetTargetCombined(); got != "new.example.com." {
+		t.Errorf("expected target %q, got %q", "new.example.com.", got)
+	}
+	back := RRtoRC(rc.ToRR(), "example.com")
+	if back.Type != "DNAME" || back.GetTargetField() != "new.example.com." {
+		t.Errorf("round trip changed the record to %s %s", back.Type, back.GetTargetField())
+	}
+}
+
+func TestSetTargetLOC(t *testing.T) {
+	tests := []struct {
+		in      string
+		target  string
+		wantErr string
+	}{
+		{`52 22 23 N 4 53 32 E -2m`, `52 22 23.000 N 04 53 32.000 E -2m 1m 10000m 10m`, ""},
+		{`52 22 23.0 n 4 53 32.00 e -2.0 1.00m 10000.00m 10m`, `52 22 23.000 N 04 53 32.000 E -2m 1m 10000m 10m`, ""},
+		{`51 30 12.7484 N 0 7 39.6116 W 12.345m 30m 2m 1m`, `51 30 12.748 N 00 07 39.612 W 12.35m 30m 2m 1m`, ""},
+		{`42 S 73 W 0 1234m`, `42 00 0.000 S 73 00 0.000 W 0m 1000m 10000m 10m`, ""},
+		{`0 N 0 E 0 0.5 0.01`, `00 00 0.000 S 00 00 0.000 W 0m 0.50m 0.01m 10m`, ""},
+		{`91 N 0 E 0`, ``, "latitude"},
+		{`52 60 N 0 E 0`, ``, "latitude"},
+		{`52 N 181 E 0`, ``, "longitude"},
+		{`52 N 4 X 0`, ``, "longitude"},
+		{`52 N 4 E`, ``, "does not contain an altitude"},
+		{`52 N 4 E -100001m`, ``, "altitude"},
+		{`52 N 4 E 0 1 1 1 1`, ``, "too many fields"},
+	}
+	for _, tst := range tests {
+		rc := &RecordConfig{Type: "LOC"}
+		err := rc.SetTargetLOCString(tst.in)
+		if tst.wantErr != "" {
+			if err == nil || !strings.Contains(err.Error(), tst.wantErr) {
+				t.Errorf("%q: expected error containing %q, got %v", tst.in, tst.wantErr, err)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("%q: unexpected error %v", tst.in, err)
+			continue
+		}
+		rc.SetLabel("@", "example.com")
+		if got := rc.GetTargetCombined(); got != tst.target {
+			t.Errorf("%q: expected %q, got %q", tst.in, tst.target, got)
+		}
+		// Neither parsing the target again nor the dns package changes the record.
+		again := &RecordConfig{Type: "LOC"}
+		again.SetLabel("@", "example.com")
+		if err := again.SetTargetLOCString(rc.GetTargetField()); err != nil || again.GetTargetDebug() != rc.GetTargetDebug() {
+			t.Errorf("%q: parsing %q changed the record to %q (%v)", tst.in, rc.GetTargetField(), again.GetTargetField(), err)
+		}
+		back := RRtoRC(rc.ToRR(), "example.com")
+		if back.GetTargetField() != rc.GetTargetField() || back.LocAltitude != rc.LocAltitude {
+			t.Errorf("%q: round trip changed the record to %q", tst.in, back.GetTargetCombined())
+		}
+	}
+}
+
+func TestIDN(t *testing.T) {
+	rc := &RecordConfig{Type: "CNAME"}
+	rc.SetLabel("Café", "bücher.example")
+	if rc.GetLabel() != "xn--caf-dma" || rc.GetLabelFQDN() != "xn--caf-dma.xn--bcher-kva.example" {
+		t.Errorf("expected the label in ASCII, got %q (%q)", rc.GetLabel(), rc.GetLabelFQDN())
+	}
+	if rc.GetLabelUnicode() != "café" {
+		t.Errorf("expected the display form café, got %q", rc.GetLabelUnicode())
+	}
+	if err := rc.PopulateFromString("CNAME", "www.bücher.example.", "bücher.example"); err != nil {
+		t.Fatal(err)
+	}
+	if rc.GetTargetField() != "www.xn--bcher-kva.example." {
+		t.Errorf("expected the target in ASCII, got %q", rc.GetTargetField())
+	}
+
+	fqdn := &RecordConfig{Type: "A"}
+	fqdn.SetLabelFromFQDN("café.bücher.example", "bücher.example")
+	if fqdn.GetLabel() != "xn--caf-dma" {
+		t.Errorf("expected the label in ASCII, got %q", fqdn.GetLabel())
+	}
+
+	// Names that are ASCII already are not changed.
+	for _, label := range []string{"@", "*", "_dmarc", "xn--caf-dma"} {
+		rc := &RecordConfig{Type: "TXT"}
+		rc.SetLabel(label, "example.com")
+		if rc.GetLabel() != label {
+			t.Errorf("expected %q to be unchanged, got %q", label, rc.GetLabel())
+		}
+	}
+
+	mx := &RecordConfig{}
+	if err := mx.PopulateFromString("MX", "10 mail.bücher.example.", "example.com"); err != nil {
+		t.Fatal(err)
+	}
+	if mx.GetTargetField() != "mail.xn--bcher-kva.example." || mx.MxPreference != 10 {
+		t.Errorf("expected the MX target in ASCII, got %q", mx.GetTargetCombined())
+	}
+}
+
+func TestSetTargetHINFO(t *testing.T) {
+	tests := []struct {
+		in      string
+		cpu, os string
+		target  string
+	}{
+		{`"INTEL 386" "Linux"`, "INTEL 386", "Linux", `"INTEL 386" "Linux"`},
+		{`ARM FreeBSD`, "ARM", "FreeBSD", `"ARM" "FreeBSD"`},
+		{`"VAX  11/780" "Ultrix \"4\""`, "VAX  11/780", `Ultrix \"4\"`, `"VAX  11/780" "Ultrix \"4\""`},
+	}
+	for _, tst := range tests {
+		rc := &RecordConfig{Type: "HINFO"}
+		rc.SetLabel("@", "example.com")
+		if err := rc.SetTargetHINFOString(tst.in); err != nil {
+			t.Errorf("%q: unexpected error %v", tst.in, err)
+			continue
+		}
+		if rc.HinfoCpu != tst.cpu || rc.HinfoOs != tst.os {
+			t.Errorf("%q: expected cpu %q os %q, got %q %q", tst.in, tst.cpu, tst.os, rc.HinfoCpu, rc.HinfoOs)
+		}
+		if got := rc.GetTargetCombined(); got != tst.target {
+			t.Errorf("%q: expected %q, got %q", tst.in, tst.target, got)
+		}
+		back := RRtoRC(rc.ToRR(), "example.com")
+		if back.GetTargetField() != rc.GetTargetField() || back.HinfoCpu != rc.HinfoCpu || back.HinfoOs != rc.HinfoOs {
+			t.Errorf("%q: round trip changed the record to %q", tst.in, back.GetTargetField())
+		}
+	}
+
+	for _, in := range []string{`"INTEL 386"`, `INTEL 386 Linux`, ``} {
+		rc := &RecordConfig{Type: "HINFO"}
+		if err := rc.SetTargetHINFOString(in); err == nil {
+			t.Errorf("%q: expected an error", in)
+		}
+	}
+
+	// The fields are compared case sensitively.
+	a := &RecordConfig{Type: "HINFO"}
+	a.SetTargetHINFO("Intel", "Linux")
+	b := &RecordConfig{Type: "HINFO"}
+	b.SetTargetHINFO("INTEL", "Linux")
+	if a.ToDiffable() == b.ToDiffable() {
+		t.Errorf("expected %q and %q to differ", a.GetTargetCombined(), b.GetTargetCombined())
+	}
+	downcase([]*RecordConfig{a})
+	if a.HinfoCpu != "Intel" || a.GetTargetField() != `"Intel" "Linux"` {
+		t.Errorf("expected the record not to be downcased, got %q", a.GetTargetField())
+	}
+}
+
+func TestSetTargetAPL(t *testing.T) {
+	tests := []struct {
+		in     string
+		target string
+	}{
+		{`1:192.168.0.0/16 !1:192.168.38.0/28`, `1:192.168.0.0/16 !1:192.168.38.0/28`},
+		{`2:2001:DB8::/32`, `2:2001:db8::/32`},
+		{`  1:10.0.0.0/8   2:::ffff:10.0.0.0/104 `, `1:10.0.0.0/8 2:::ffff:10.0.0.0/104`},
+		{``, ``},
+	}
+	for _, tst := range tests {
+		rc := &RecordConfig{Type: "APL"}
+		rc.SetLabel("@", "example.com")
+		if err := rc.SetTargetAPLString(tst.in); err != nil {
+			t.Errorf("%q: unexpected error %v", tst.in, err)
+			continue
+		}
+		if got := rc.GetTargetCombined(); got != tst.target {
+			t.Errorf("%q: expected %q, got %q", tst.in, tst.target, got)
+		}
+		back := RRtoRC(rc.ToRR(), "example.com")
+		if back.GetTargetField() != rc.GetTargetField() || len(back.AplPrefixes) != len(rc.AplPrefixes) {
+			t.Errorf("%q: round trip changed the record to %q", tst.in, back.GetTargetField())
+		}
+	}
+
+	for _, in := range []string{
+		`192.168.0.0/16`,   // no family
+		`1:192.168.0.1/16`, // host bits
+		`2:192.168.0.0/16`, // family mismatch
+		`1:2001:db8::/32`,  // family mismatch
+		`3:192.168.0.0/16`, // unknown family
+		`1:192.168.0.0`,    // no prefix length
+		`x:192.168.0.0/16`, // invalid family
+	} {
+		rc := &RecordConfig{Type: "APL"}
+		if err := rc.SetTargetAPLString(in); err == nil {
+			t.Errorf("%q: expected an error", in)
+		}
+	}
+
+	// The order of the entries is significant.
+	a := &RecordConfig{Type: "APL"}
+	a.SetTargetAPLString("1:192.168.0.0/16 !1:192.168.38.0/28")
+	b := &RecordConfig{Type: "APL"}
+	b.SetTargetAPLString("!1:192.168.38.0/28 1:192.168.0.0/16")
+	if a.ToDiffable() == b.ToDiffable() {
+		t.Errorf("expected %q and %q to differ", a.GetTargetCombined(), b.GetTargetCombined())
+	}
+}
+
+func TestSetTargetCSYNC(t *testing.T) {
+	tests := []struct {
+		in     string
+		types  []string
+		target string
+	}{
+		{`0 3 A NS AAAA`, []string{"A", "NS", "AAAA"}, `0 3 A NS AAAA`},
+		{`0 3 aaaa ns A NS`, []string{"A", "NS", "AAAA"}, `0 3 A NS AAAA`},
+		{`66 1 TYPE28 A`, []string{"A", "AAAA"}, `66 1 A AAAA`},
+		{`0 0`, []string{}, `0 0`},
+	}
+	for _, tst := range tests {
+		rc := &RecordConfig{Type: "CSYNC"}
+		rc.SetLabel("@", "example.com")
+		if err := rc.SetTargetCSYNCString(tst.in); err != nil {
+			t.Errorf("%q: unexpected error %v", tst.in, err)
+			continue
+		}
+		if strings.Join(rc.CsyncTypes, " ") != strings.Join(tst.types, " ") {
+			t.Errorf("%q: expected types %v, got %v", tst.in, tst.types, rc.CsyncTypes)
+		}
+		if got := rc.GetTargetCombined(); got != tst.target {
+			t.Errorf("%q: expected %q, got %q", tst.in, tst.target, got)
+		}
+		back := RRtoRC(rc.ToRR(), "example.com")
+		if back.GetTargetField() != rc.GetTargetField() || back.CsyncSerial != rc.CsyncSerial || back.CsyncFlags != rc.CsyncFlags {
+			t.Errorf("%q: round trip changed the record to %q", tst.in, back.GetTargetField())
+		}
+	}
+
+	for _, in := range []string{`0`, `x 3 A`, `0 70000 A`, `0 3 NOTATYPE`} {
+		rc := &RecordConfig{Type: "CSYNC"}
+		if err := rc.SetTargetCSYNCString(in); err == nil {
+			t.Errorf("%q: expected an error", in)
+		}
+	}
+
+	// Equivalent type bitmaps do not differ.
+	a := &RecordConfig{Type: "CSYNC"}
+	a.SetTargetCSYNC(0, 3, []string{"NS", "A", "AAAA"})
+	b := &RecordConfig{Type: "CSYNC"}
+	b.SetTargetCSYNC(0, 3, []string{"aaaa", "A", "NS", "A"})
+	if a.ToDiffable() != b.ToDiffable() {
+		t.Errorf("expected %q and %q not to differ", a.GetTargetCombined(), b.GetTargetCombined())
+	}
+}
@@ -0,0 +1,134 @@
+package models
+
+import (
+	"fmt"
+	"net"
+	"strconv"
+	"strings"
+
+	"github.com/miekg/dns"
+)
+
+// AplPrefix is an entry of an APL record (RFC 3123).
+type AplPrefix struct {
+	Family   uint16 `json:"family"`             // 1 for IPv4, 2 for IPv6.
+	Prefix   string `json:"prefix"`             // The network in CIDR notation, e.g. 192.168.0.0/16.
+	Negation bool   `json:"negation,omitempty"` // The prefix is excluded.
+}
+
+// String returns the entry in presentation format, e.g. `!1:192.168.38.0/28`.
+func (p AplPrefix) String() string {
+	s := fmt.Sprintf("%d:%s", p.Family, p.Prefix)
+	if p.Negation {
+		s = "!" + s
+	}
+	return s
+}
+
+// network returns the network of the entry, or an error if it is not a
+// network of the family without host bits.
+func (p AplPrefix) network() (net.IPNet, error) {
+	ip, network, err := net.ParseCIDR(p.Prefix)
+	if err != nil {
+		return net.IPNet{}, fmt.Errorf("APL prefix %q is invalid: %w", p.String(), err)
+	}
+	if !ip.Equal(network.IP) {
+		return net.IPNet{}, fmt.Errorf("APL prefix %q has bits set after the prefix length", p.String())
+	}
+	switch {
+	case p.Family == 1 && len(network.IP) == net.IPv4len:
+	case p.Family == 2 && len(network.IP) == net.IPv6len:
+	case p.Family == 1 || p.Family == 2:
+		return net.IPNet{}, fmt.Errorf("APL prefix %q does not match the address family", p.String())
+	default:
+		return net.IPNet{}, fmt.Errorf("APL prefix %q has the unknown address family %d", p.String(), p.Family)
+	}
+	return *network, nil
+}
+
+// SetTargetAPL sets the APL entries. Their order is significant and kept.
+// The target is set to the presentation format, e.g. `1:192.168.0.0/16
+// !1:192.168.38.0/28`.
+func (rc *RecordConfig) SetTargetAPL(prefixes []AplPrefix) error {
+	if rc.Type == "" {
+		rc.Type = "APL"
+	}
+	if rc.Type != "APL" {
+		panic("assertion failed: SetTargetAPL called when .Type is not APL")
+	}
+	canonical := make([]AplPrefix, len(prefixes))
+	for i, p := range prefixes {
+		network, err := p.network()
+		if err != nil {
+			return err
+		}
+		canonical[i] = aplPrefixFromNetwork(p.Negation, network)
+	}
+	rc.AplPrefixes = canonical
+	rc.SetTarget(rc.zoneFileQuoted())
+
+	return nil
+}
+
+// SetTargetAPLString is like SetTargetAPL but accepts the presentation
+// format, e.g. `1:192.168.0.0/16 !1:192.168.38.0/28`.
+func (rc *RecordConfig) SetTargetAPLString(s string) error {
+	var prefixes []AplPrefix
+	for _, field := range strings.Fields(s) {
+		p := AplPrefix{}
+		if strings.HasPrefix(field, "!") {
+			p.Negation = true
+			field = field[1:]
+		}
+		parts := strings.SplitN(field, ":", 2)
+		if len(parts) != 2 {
+			return fmt.Errorf("APL value has an entry without family (%#v)", s)
+		}
+		family, err := strconv.ParseUint(parts[0], 10, 16)
+		if err != nil {
+			return fmt.Errorf("APL value has an invalid family (%#v): %w", s, err)
+		}
+		p.Family = uint16(family)
+		p.Prefix = parts[1]
+		prefixes = append(prefixes, p)
+	}
+	return rc.SetTargetAPL(prefixes)
+}
+
+// aplPrefixesToRR converts the entries for the dns package. The entries
+// were validated by SetTargetAPL.
+func aplPrefixesToRR(prefixes []AplPrefix) []dns.APLPrefix {
+	out := make([]dns.APLPrefix, 0, len(prefixes))
+	for _, p := range prefixes {
+		network, err := p.network()
+		if err != nil {
+			panic(err)
+		}
+		out = append(out, dns.APLPrefix{Negation: p.Negation, Network: network})
+	}
+	return out
+}
+
+// aplPrefixesFromRR converts the entries of the dns package.
+func aplPrefixesFromRR(prefixes []dns.APLPrefix) []AplPrefix {
+	out := make([]AplPrefix, len(prefixes))
+	for i, p := range prefixes {
+		out[i] = aplPrefixFromNetwork(p.Negation, p.Network)
+	}
+	return out
+}
+
+func aplPrefixFromNetwork(negation bool, network net.IPNet) AplPrefix {
+	p := AplPrefix{Family: 1, Negation: negation}
+	ip := network.IP.String()
+	if len(network.IP) == net.IPv6len {
+		p.Family = 2
+		if network.IP.To4() != nil {
+			// An IPv4-mapped IPv6 address, keep it IPv6.
+			ip = "::ffff:" + ip
+		}
+	}
+	size, _ := network.Mask.Size()
+	p.Prefix = ip + "/" + strconv.Itoa(size)
+	return p
+}
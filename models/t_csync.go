@@ -0,0 +1,84 @@
+package models
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/miekg/dns"
+)
+
+// SetTargetCSYNC sets the CSYNC fields (RFC 7477). The types are
+// canonicalized: upper case, sorted by their number and without duplicates,
+// so that equivalent type bitmaps compare equal. The target is set to the
+// presentation format, e.g. `0 3 A NS AAAA`.
+func (rc *RecordConfig) SetTargetCSYNC(serial uint32, flags uint16, types []string) error {
+	if rc.Type == "" {
+		rc.Type = "CSYNC"
+	}
+	if rc.Type != "CSYNC" {
+		panic("assertion failed: SetTargetCSYNC called when .Type is not CSYNC")
+	}
+	bitmap, err := csyncTypeBitMap(types)
+	if err != nil {
+		return err
+	}
+	rc.CsyncSerial = serial
+	rc.CsyncFlags = flags
+	rc.CsyncTypes = csyncTypeNames(bitmap)
+	rc.SetTarget(rc.zoneFileQuoted())
+
+	return nil
+}
+
+// SetTargetCSYNCString is like SetTargetCSYNC but accepts the presentation
+// format, e.g. `0 3 A NS AAAA`.
+func (rc *RecordConfig) SetTargetCSYNCString(s string) error {
+	fields := strings.Fields(s)
+	if len(fields) < 2 {
+		return fmt.Errorf("CSYNC value does not contain serial and flags: (%#v)", s)
+	}
+	serial, err := strconv.ParseUint(fields[0], 10, 32)
+	if err != nil {
+		return fmt.Errorf("CSYNC serial is invalid: (%#v)", s)
+	}
+	flags, err := strconv.ParseUint(fields[1], 10, 16)
+	if err != nil {
+		return fmt.Errorf("CSYNC flags are invalid: (%#v)", s)
+	}
+	return rc.SetTargetCSYNC(uint32(serial), uint16(flags), fields[2:])
+}
+
+// csyncTypeBitMap returns the numbers of the types, sorted and without
+// duplicates. Types may be given by name, e.g. "AAAA", or number, e.g.
+// "TYPE28".
+func csyncTypeBitMap(types []string) ([]uint16, error) {
+	seen := map[uint16]bool{}
+	bitmap := []uint16{}
+	for _, name := range types {
+		t, ok := dns.StringToType[strings.ToUpper(name)]
+		if !ok {
+			n, err := strconv.ParseUint(strings.TrimPrefix(strings.ToUpper(name), "TYPE"), 10, 16)
+			if err != nil || !strings.HasPrefix(strings.ToUpper(name), "TYPE") {
+				return nil, fmt.Errorf("CSYNC type %q is unknown", name)
+			}
+			t = uint16(n)
+		}
+		if !seen[t] {
+			seen[t] = true
+			bitmap = append(bitmap, t)
+		}
+	}
+	sort.Slice(bitmap, func(i, j int) bool { return bitmap[i] < bitmap[j] })
+	return bitmap, nil
+}
+
+// csyncTypeNames returns the names of the types of a bitmap.
+func csyncTypeNames(bitmap []uint16) []string {
+	names := make([]string, len(bitmap))
+	for i, t := range bitmap {
+		names[i] = dns.Type(t).String()
+	}
+	return names
+}
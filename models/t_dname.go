@@ -0,0 +1,24 @@
+package models
+
+import (
+	"fmt"
+	"strings"
+)
+
+// SetTargetDNAME sets the target of a DNAME record. Unlike a CNAME, the
+// target of a DNAME from a provider must be fully qualified.
+func (rc *RecordConfig) SetTargetDNAME(target string) error {
+	if !strings.HasSuffix(target, ".") {
+		return fmt.Errorf("DNAME target must be fully qualified (end with a dot): %q", target)
+	}
+	rc.SetTarget(target)
+
+	if rc.Type == "" {
+		rc.Type = "DNAME"
+	}
+	if rc.Type != "DNAME" {
+		panic("assertion failed: SetTargetDNAME called when .Type is not DNAME")
+	}
+
+	return nil
+}
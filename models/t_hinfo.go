@@ -0,0 +1,38 @@
+package models
+
+import (
+	"fmt"
+
+	"github.com/miekg/dns"
+)
+
+// SetTargetHINFO sets the HINFO fields. The target is set to the
+// presentation format, both fields quoted, e.g. `"INTEL 386" "Linux"`.
+// The fields are case sensitive and may contain spaces.
+func (rc *RecordConfig) SetTargetHINFO(cpu, os string) error {
+	rc.HinfoCpu = cpu
+	rc.HinfoOs = os
+	if rc.Type == "" {
+		rc.Type = "HINFO"
+	}
+	if rc.Type != "HINFO" {
+		panic("assertion failed: SetTargetHINFO called when .Type is not HINFO")
+	}
+	rc.SetTarget(rc.zoneFileQuoted())
+
+	return nil
+}
+
+// SetTargetHINFOString is like SetTargetHINFO but accepts the
+// presentation format, e.g. `"INTEL 386" "Linux"`. Fields without spaces
+// do not need to be quoted.
+func (rc *RecordConfig) SetTargetHINFOString(s string) error {
+	// The fields are character-strings like those of a TXT record, let the
+	// dns package deal with the quoting.
+	rr, err := dns.NewRR(". TXT " + s)
+	if err != nil || rr == nil || len(rr.(*dns.TXT).Txt) != 2 {
+		return fmt.Errorf("HINFO value does not contain 2 fields: (%#v)", s)
+	}
+	txt := rr.(*dns.TXT).Txt
+	return rc.SetTargetHINFO(txt[0], txt[1])
+}
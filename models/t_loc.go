@@ -0,0 +1,160 @@
+package models
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+
+	"github.com/miekg/dns"
+)
+
+// The defaults of the optional LOC fields (RFC 1876, Section 3): a size of
+// 1m, a horizontal precision of 10000m and a vertical precision of 10m.
+const (
+	locDefaultSize     = 0x12
+	locDefaultHorizPre = 0x16
+	locDefaultVertPre  = 0x13
+)
+
+// SetTargetLOC sets the LOC fields from their wire format. The target is
+// set to the presentation format, e.g. `52 22 23.000 N 4 53 32.000 E -2.00m
+// 0.00m 10000m 10m`.
+func (rc *RecordConfig) SetTargetLOC(latitude, longitude, altitude uint32, size, horizPre, vertPre uint8) error {
+	for _, p := range []uint8{size, horizPre, vertPre} {
+		if p>>4 > 9 || p&0x0f > 9 {
+			return fmt.Errorf("LOC size or precision 0x%02x is invalid", p)
+		}
+	}
+	rc.LocLatitude = latitude
+	rc.LocLongitude = longitude
+	rc.LocAltitude = altitude
+	rc.LocSize = size
+	rc.LocHorizPre = horizPre
+	rc.LocVertPre = vertPre
+	rc.SetTarget(locString(rc))
+
+	if rc.Type == "" {
+		rc.Type = "LOC"
+	}
+	if rc.Type != "LOC" {
+		panic("assertion failed: SetTargetLOC called when .Type is not LOC")
+	}
+
+	return nil
+}
+
+// SetTargetLOCString is like SetTargetLOC but accepts the presentation
+// format, e.g. `52 22 23 N 4 53 32 E -2m`. Seconds are rounded to the
+// millisecond and meters to the centimeter, the resolution of the wire
+// format. Therefore reading back the target of a record does not change
+// it.
+func (rc *RecordConfig) SetTargetLOCString(s string) error {
+	fields := strings.Fields(s)
+	latitude, fields, err := parseLOCCoordinate(fields, "N", "S", 90)
+	if err != nil {
+		return fmt.Errorf("LOC latitude in (%#v): %w", s, err)
+	}
+	longitude, fields, err := parseLOCCoordinate(fields, "E", "W", 180)
+	if err != nil {
+		return fmt.Errorf("LOC longitude in (%#v): %w", s, err)
+	}
+	if len(fields) == 0 {
+		return fmt.Errorf("LOC value does not contain an altitude: (%#v)", s)
+	}
+	altitude, err := parseLOCMeters(fields[0], -100000, 42849672.95)
+	if err != nil {
+		return fmt.Errorf("LOC altitude in (%#v): %w", s, err)
+	}
+	fields = fields[1:]
+
+	precisions := []uint8{locDefaultSize, locDefaultHorizPre, locDefaultVertPre}
+	if len(fields) > len(precisions) {
+		return fmt.Errorf("LOC value has too many fields: (%#v)", s)
+	}
+	for i, f := range fields {
+		cm, err := parseLOCMeters(f, 0, 90000000)
+		if err != nil {
+			return fmt.Errorf("LOC size or precision in (%#v): %w", s, err)
+		}
+		precisions[i] = locPrecision(uint64(cm))
+	}
+
+	return rc.SetTargetLOC(latitude, longitude, uint32(altitude+10000000), precisions[0], precisions[1], precisions[2])
+}
+
+// parseLOCCoordinate parses `d [m [s]] {pos|neg}` at the start of fields
+// and returns it in the wire format along with the remaining fields.
+func parseLOCCoordinate(fields []string, pos, neg string, maxDegrees int64) (uint32, []string, error) {
+	var parts []string
+	for len(fields) > 0 && len(parts) < 4 {
+		parts = append(parts, fields[0])
+		fields = fields[1:]
+		if last := strings.ToUpper(parts[len(parts)-1]); last == pos || last == neg {
+			break
+		}
+	}
+	if len(parts) < 2 {
+		return 0, nil, fmt.Errorf("incomplete coordinate")
+	}
+	hemisphere := strings.ToUpper(parts[len(parts)-1])
+	if hemisphere != pos && hemisphere != neg {
+		return 0, nil, fmt.Errorf("expected %s or %s after %q", pos, neg, strings.Join(parts, " "))
+	}
+
+	var ms int64
+	limits := []struct {
+		max   float64
+		scale int64
+	}{{float64(maxDegrees), 3600000}, {59, 60000}, {59.999, 1000}}
+	for i, p := range parts[:len(parts)-1] {
+		v, err := strconv.ParseFloat(p, 64)
+		if err != nil || v < 0 || v > limits[i].max || (i < 2 && v != math.Trunc(v)) {
+			return 0, nil, fmt.Errorf("invalid value %q", p)
+		}
+		ms += int64(math.Round(v * float64(limits[i].scale)))
+	}
+	if ms > maxDegrees*3600000 {
+		return 0, nil, fmt.Errorf("%q is out of range", strings.Join(parts, " "))
+	}
+
+	if hemisphere == neg {
+		return uint32(1<<31 - ms), fields, nil
+	}
+	return uint32(1<<31 + ms), fields, nil
+}
+
+// parseLOCMeters parses a distance in meters, optionally followed by "m",
+// and returns it in centimeters.
+func parseLOCMeters(s string, min, max float64) (int64, error) {
+	v, err := strconv.ParseFloat(strings.TrimSuffix(strings.ToLower(s), "m"), 64)
+	if err != nil || v < min || v > max {
+		return 0, fmt.Errorf("invalid distance %q", s)
+	}
+	return int64(math.Round(v * 100)), nil
+}
+
+// locPrecision returns cm in the format of the LOC size and precision
+// fields: a mantissa and a power of ten, both 0 to 9.
+func locPrecision(cm uint64) uint8 {
+	var exponent uint8
+	for cm > 9 {
+		cm /= 10
+		exponent++
+	}
+	return uint8(cm)<<4 | exponent
+}
+
+// locString returns the LOC fields of rc in presentation format.
+func locString(rc *RecordConfig) string {
+	rr := &dns.LOC{
+		Hdr:       dns.RR_Header{Name: ".", Rrtype: dns.TypeLOC, Class: dns.ClassINET},
+		Size:      rc.LocSize,
+		HorizPre:  rc.LocHorizPre,
+		VertPre:   rc.LocVertPre,
+		Latitude:  rc.LocLatitude,
+		Longitude: rc.LocLongitude,
+		Altitude:  rc.LocAltitude,
+	}
+	return strings.TrimPrefix(rr.String(), rr.Hdr.String())
+}
@@ -12,6 +12,9 @@ import (
 //
 // If this doesn't work for all rtypes, process the special cases then
 // call this for the remainder.
+//
+// Internationalized hostnames in the targets are converted to their ASCII
+// form, as SetLabel does for the labels.
 func (r *RecordConfig) PopulateFromString(rtype, contents, origin string) error {
 	if r.Type != "" && r.Type != rtype {
 		panic(fmt.Errorf("assertion failed: rtype already set (%s) (%s)", rtype, r.Type))
@@ -30,21 +33,42 @@ func (r *RecordConfig) PopulateFromString(rtype, contents, origin string) error
 		}
 		return r.SetTargetIP(ip) // Reformat to canonical form.
 	case "ALIAS", "ANAME", "CNAME", "NS", "PTR":
-		return r.SetTarget(contents)
+		return r.SetTarget(toASCII(contents))
+	case "APL":
+		return r.SetTargetAPLString(contents)
 	case "CAA":
 		return r.SetTargetCAAString(contents)
+	case "DNAME":
+		return r.SetTargetDNAME(toASCII(contents))
 	case "DS":
 		return r.SetTargetDSString(contents)
+	case "CSYNC":
+		return r.SetTargetCSYNCString(contents)
+	case "HINFO":
+		return r.SetTargetHINFOString(contents)
+	case "LOC":
+		return r.SetTargetLOCString(contents)
 	case "MX":
-		return r.SetTargetMXString(contents)
+		if err := r.SetTargetMXString(contents); err != nil {
+			return err
+		}
+		return r.SetTarget(toASCII(r.GetTargetField()))
 	case "NAPTR":
 		return r.SetTargetNAPTRString(contents)
 	case "SRV":
-		return r.SetTargetSRVString(contents)
+		if err := r.SetTargetSRVString(contents); err != nil {
+			return err
+		}
+		return r.SetTarget(toASCII(r.GetTargetField()))
 	case "SOA":
 		return r.SetTargetSOAString(contents)
 	case "SSHFP":
 		return r.SetTargetSSHFPString(contents)
+	case "SVCB", "HTTPS":
+		if err := r.SetTargetSVCBString(contents); err != nil {
+			return err
+		}
+		return r.SetTarget(toASCII(r.GetTargetField()))
 	case "TLSA":
 		return r.SetTargetTLSAString(contents)
 	case "SPF", "TXT":
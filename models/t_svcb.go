@@ -0,0 +1,134 @@
+package models
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/miekg/dns"
+)
+
+// SetTargetSVCB sets the SVCB (or HTTPS) fields. params is in presentation
+// format, e.g. `alpn="h2,h3" ipv4hint=192.0.2.1`. It is stored in a
+// canonical form, so that the order of the params does not matter when
+// comparing records.
+func (rc *RecordConfig) SetTargetSVCB(priority uint16, target string, params string) error {
+	canonical, err := canonicalSVCBParams(params)
+	if err != nil {
+		return err
+	}
+	if priority == 0 && canonical != "" {
+		return fmt.Errorf("SVCB priority 0 (AliasMode) must not have params: %q", params)
+	}
+	rc.SvcPriority = priority
+	rc.SvcParams = canonical
+	rc.SetTarget(target)
+
+	if rc.Type == "" {
+		rc.Type = "SVCB"
+	}
+	if rc.Type != "SVCB" && rc.Type != "HTTPS" {
+		panic("assertion failed: SetTargetSVCB called when .Type is not SVCB or HTTPS")
+	}
+
+	return nil
+}
+
+// SetTargetSVCBStrings is like SetTargetSVCB but accepts strings.
+func (rc *RecordConfig) SetTargetSVCBStrings(priority, target, params string) error {
+	i64priority, err := strconv.ParseUint(priority, 10, 16)
+	if err != nil {
+		return fmt.Errorf("SVCB priority does not fit in 16 bits: %w", err)
+	}
+	return rc.SetTargetSVCB(uint16(i64priority), target, params)
+}
+
+// SetTargetSVCBString is like SetTargetSVCB but accepts one big string,
+// e.g. `1 . alpn="h2,h3" ipv4hint=192.0.2.1`.
+func (rc *RecordConfig) SetTargetSVCBString(s string) error {
+	priority, rest := nextField(s)
+	target, params := nextField(rest)
+	if priority == "" || target == "" {
+		return fmt.Errorf("SVCB value does not contain a priority and a target: (%#v)", s)
+	}
+	return rc.SetTargetSVCBStrings(priority, target, params)
+}
+
+// setTargetSVCBValue is like SetTargetSVCB but accepts parsed params.
+func (rc *RecordConfig) setTargetSVCBValue(priority uint16, target string, kvs []dns.SVCBKeyValue) error {
+	params, err := formatSVCBParams(kvs)
+	if err != nil {
+		return err
+	}
+	return rc.SetTargetSVCB(priority, target, params)
+}
+
+// nextField splits s into its first whitespace separated field and the rest.
+func nextField(s string) (field, rest string) {
+	s = strings.TrimSpace(s)
+	if i := strings.IndexAny(s, " \t"); i >= 0 {
+		return s[:i], strings.TrimSpace(s[i:])
+	}
+	return s, ""
+}
+
+// parseSVCBParams parses SVCB params in presentation format.
+func parseSVCBParams(params string) ([]dns.SVCBKeyValue, error) {
+	if strings.TrimSpace(params) == "" {
+		return nil, nil
+	}
+	// Let the dns package do the parsing, the priority and target are dummies.
+	rr, err := dns.NewRR(". 0 IN SVCB 1 . " + params)
+	if err != nil {
+		return nil, fmt.Errorf("invalid SVCB params %q: %w", params, err)
+	}
+	return rr.(*dns.SVCB).Value, nil
+}
+
+// canonicalSVCBParams returns params with the keys in ascending order and
+// the values quoted.
+func canonicalSVCBParams(params string) (string, error) {
+	kvs, err := parseSVCBParams(params)
+	if err != nil {
+		return "", err
+	}
+	return formatSVCBParams(kvs)
+}
+
+// formatSVCBParams returns kvs in canonical presentation format: sorted by
+// key, as the keys must be in the wire format, with every value quoted.
+// The order within a value, e.g. of the alpn ids, is significant and kept,
+// except for the keys of mandatory.
+func formatSVCBParams(kvs []dns.SVCBKeyValue) (string, error) {
+	kvs = append([]dns.SVCBKeyValue(nil), kvs...)
+	sort.SliceStable(kvs, func(i, j int) bool { return kvs[i].Key() < kvs[j].Key() })
+	parts := make([]string, 0, len(kvs))
+	for i, kv := range kvs {
+		if i > 0 && kv.Key() == kvs[i-1].Key() {
+			return "", fmt.Errorf("duplicate SVCB param %q", kv.Key())
+		}
+		if m, ok := kv.(*dns.SVCBMandatory); ok {
+			codes := append([]dns.SVCBKey(nil), m.Code...)
+			sort.Slice(codes, func(i, j int) bool { return codes[i] < codes[j] })
+			kv = &dns.SVCBMandatory{Code: codes}
+		}
+		value := kv.String()
+		if value == "" {
+			parts = append(parts, kv.Key().String())
+			continue
+		}
+		parts = append(parts, kv.Key().String()+"=\""+value+"\"")
+	}
+	return strings.Join(parts, " "), nil
+}
+
+// svcbParamsMustParse is like parseSVCBParams but panics on error.
+// It is used for params that were canonicalized already.
+func svcbParamsMustParse(params string) []dns.SVCBKeyValue {
+	kvs, err := parseSVCBParams(params)
+	if err != nil {
+		panic(err)
+	}
+	return kvs
+}
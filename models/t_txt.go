@@ -1,5 +1,11 @@
 package models
 
+import (
+	"fmt"
+	"net"
+	"strings"
+)
+
 /*
 Sadly many providers handle TXT records in strange and non-compliant ways.
 
@@ -79,3 +85,98 @@ func (rc *RecordConfig) SetTargetTXTs(s []string) error {
 func (rc *RecordConfig) SetTargetTXTString(s string) error {
 	return rc.SetTargetTXTs(ParseQuotedTxt(s))
 }
+
+// SetTargetSPF sets the TXT fields to an SPF policy (RFC 7208). The
+// policy is checked for basic syntax errors and split into 255-octet
+// chunks. The diff engine compares SPF records by their reassembled
+// policy, therefore the chunking never causes a change by itself.
+func (rc *RecordConfig) SetTargetSPF(policy string) error {
+	if err := ValidateSPF(policy); err != nil {
+		return err
+	}
+	var chunks []string
+	for len(policy) > 255 {
+		chunks = append(chunks, policy[:255])
+		policy = policy[255:]
+	}
+	chunks = append(chunks, policy)
+	return rc.SetTargetTXTs(chunks)
+}
+
+// IsSPF returns true if the record is a TXT (or SPF) record holding an SPF policy.
+func (rc *RecordConfig) IsSPF() bool {
+	if !rc.HasFormatIdenticalToTXT() {
+		return false
+	}
+	p := strings.Join(rc.TxtStrings, "")
+	return p == "v=spf1" || strings.HasPrefix(p, "v=spf1 ")
+}
+
+// spfDiffable returns the SPF policy reassembled into a single
+// string, quoted as in a zonefile.
+func (rc *RecordConfig) spfDiffable() string {
+	joined := *rc
+	joined.TxtStrings = []string{strings.Join(rc.TxtStrings, "")}
+	return joined.zoneFileQuoted()
+}
+
+var spfMechanisms = map[string]bool{
+	"all": true, "include": true, "a": true, "mx": true,
+	"ptr": true, "ip4": true, "ip6": true, "exists": true,
+}
+
+// ValidateSPF checks an SPF policy for basic syntax errors. It does not
+// perform any DNS lookups.
+func ValidateSPF(policy string) error {
+	terms := strings.Fields(policy)
+	if len(terms) == 0 || terms[0] != "v=spf1" {
+		return fmt.Errorf("SPF policy must start with \"v=spf1\": %q", policy)
+	}
+	for _, term := range terms[1:] {
+		if i := strings.IndexAny(term, "=:/"); i > 0 && term[i] == '=' {
+			// A modifier such as redirect=example.com
+			if term[i+1:] == "" {
+				return fmt.Errorf("SPF modifier %q has no value", term)
+			}
+			continue
+		}
+		mech := strings.TrimLeft(term, "+-~?")
+		if len(term)-len(mech) > 1 {
+			return fmt.Errorf("SPF term %q has more than one qualifier", term)
+		}
+		name, arg := mech, ""
+		if i := strings.IndexAny(mech, ":/"); i >= 0 {
+			name, arg = mech[:i], mech[i:]
+		}
+		name = strings.ToLower(name)
+		if !spfMechanisms[name] {
+			return fmt.Errorf("unknown SPF mechanism %q", term)
+		}
+		switch name {
+		case "all":
+			if arg != "" {
+				return fmt.Errorf("SPF mechanism %q takes no argument", term)
+			}
+		case "include", "exists":
+			if !strings.HasPrefix(arg, ":") || len(arg) == 1 {
+				return fmt.Errorf("SPF mechanism %q requires a domain", term)
+			}
+		case "ip4", "ip6":
+			if !strings.HasPrefix(arg, ":") {
+				return fmt.Errorf("SPF mechanism %q requires an address", term)
+			}
+			addr := arg[1:]
+			if !strings.Contains(addr, "/") {
+				addr += "/128"
+				if name == "ip4" {
+					addr = arg[1:] + "/32"
+				}
+			}
+			ip, _, err := net.ParseCIDR(addr)
+			if err != nil || (name == "ip4") != (ip.To4() != nil) {
+				return fmt.Errorf("SPF mechanism %q has an invalid address", term)
+			}
+		}
+	}
+	return nil
+}
@@ -91,12 +91,24 @@ func (rc *RecordConfig) GetTargetSortable() string {
 func (rc *RecordConfig) GetTargetDebug() string {
 	content := fmt.Sprintf("%s %s %s %d", rc.Type, rc.NameFQDN, rc.target, rc.TTL)
 	switch rc.Type { // #rtype_variations
-	case "A", "AAAA", "CNAME", "NS", "PTR", "TXT":
+	case "A", "AAAA", "CNAME", "DNAME", "NS", "PTR", "TXT":
 		// Nothing special.
+	case "APL":
+		var prefixes []string
+		for _, p := range rc.AplPrefixes {
+			prefixes = append(prefixes, p.String())
+		}
+		content += fmt.Sprintf(" aplprefixes=%q", strings.Join(prefixes, " "))
 	case "DS":
 		content += fmt.Sprintf(" ds_algorithm=%d ds_keytag=%d ds_digesttype=%d ds_digest=%s", rc.DsAlgorithm, rc.DsKeyTag, rc.DsDigestType, rc.DsDigest)
 	case "NAPTR":
 		content += fmt.Sprintf(" naptrorder=%d naptrpreference=%d naptrflags=%s naptrservice=%s naptrregexp=%s", rc.NaptrOrder, rc.NaptrPreference, rc.NaptrFlags, rc.NaptrService, rc.NaptrRegexp)
+	case "CSYNC":
+		content += fmt.Sprintf(" csyncserial=%d csyncflags=%d csynctypes=%q", rc.CsyncSerial, rc.CsyncFlags, strings.Join(rc.CsyncTypes, " "))
+	case "HINFO":
+		content += fmt.Sprintf(" hinfocpu=%q hinfoos=%q", rc.HinfoCpu, rc.HinfoOs)
+	case "LOC":
+		content += fmt.Sprintf(" loclatitude=%d loclongitude=%d localtitude=%d locsize=%d lochorizpre=%d locvertpre=%d", rc.LocLatitude, rc.LocLongitude, rc.LocAltitude, rc.LocSize, rc.LocHorizPre, rc.LocVertPre)
 	case "MX":
 		content += fmt.Sprintf(" pref=%d", rc.MxPreference)
 	case "SOA":
@@ -105,6 +117,8 @@ func (rc *RecordConfig) GetTargetDebug() string {
 		content += fmt.Sprintf(" srvpriority=%d srvweight=%d srvport=%d", rc.SrvPriority, rc.SrvWeight, rc.SrvPort)
 	case "SSHFP":
 		content += fmt.Sprintf(" sshfpalgorithm=%d sshfpfingerprint=%d", rc.SshfpAlgorithm, rc.SshfpFingerprint)
+	case "SVCB", "HTTPS":
+		content += fmt.Sprintf(" svcpriority=%d svcparams=%s", rc.SvcPriority, rc.SvcParams)
 	case "TLSA":
 		content += fmt.Sprintf(" tlsausage=%d tlsaselector=%d tlsamatchingtype=%d", rc.TlsaUsage, rc.TlsaSelector, rc.TlsaMatchingType)
 	case "CAA":
@@ -121,6 +135,7 @@ func (rc *RecordConfig) GetTargetDebug() string {
 	for k, v := range rc.Metadata {
 		content += fmt.Sprintf(" %s=%s", k, v)
 	}
+	content += rc.GetRoutingDiffable()
 	return content
 }
 
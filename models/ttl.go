@@ -0,0 +1,71 @@
+package models
+
+import (
+	"fmt"
+	"strconv"
+)
+
+// The metadata of a domain that limits the TTLs of its records, see
+// MIN_TTL and MAX_TTL.
+const (
+	minTTLMetadata = "min_ttl"
+	maxTTLMetadata = "max_ttl"
+)
+
+// TTLLimits returns the lowest and highest TTL the records of the domain
+// may have. 0 means there is no limit.
+func (dc *DomainConfig) TTLLimits() (min, max uint32, err error) {
+	parse := func(key string) (uint32, error) {
+		s, ok := dc.Metadata[key]
+		if !ok {
+			return 0, nil
+		}
+		v, err := strconv.ParseUint(s, 10, 32)
+		if err != nil {
+			return 0, fmt.Errorf("%s for %s (%s) is not a valid TTL", key, dc.Name, s)
+		}
+		return uint32(v), nil
+	}
+	if min, err = parse(minTTLMetadata); err != nil {
+		return 0, 0, err
+	}
+	if max, err = parse(maxTTLMetadata); err != nil {
+		return 0, 0, err
+	}
+	if min != 0 && max != 0 && min > max {
+		return 0, 0, fmt.Errorf("MIN_TTL(%d) of %s is above its MAX_TTL(%d)", min, dc.Name, max)
+	}
+	return min, max, nil
+}
+
+// ClampTTL returns ttl raised to the MIN_TTL and lowered to the MAX_TTL of
+// the domain. Invalid limits are ignored, ClampTTLs reports them.
+func (dc *DomainConfig) ClampTTL(ttl uint32) uint32 {
+	min, max, err := dc.TTLLimits()
+	if err != nil {
+		return ttl
+	}
+	if min != 0 && ttl < min {
+		ttl = min
+	}
+	if max != 0 && ttl > max {
+		ttl = max
+	}
+	return ttl
+}
+
+// ClampTTLs applies ClampTTL to all records of the domain and returns the
+// number of records that were changed.
+func (dc *DomainConfig) ClampTTLs() (int, error) {
+	if _, _, err := dc.TTLLimits(); err != nil {
+		return 0, err
+	}
+	clamped := 0
+	for _, rc := range dc.Records {
+		if ttl := dc.ClampTTL(rc.TTL); ttl != rc.TTL {
+			rc.TTL = ttl
+			clamped++
+		}
+	}
+	return clamped, nil
+}
@@ -2,38 +2,115 @@
 package acme
 
 import (
+	"context"
 	"crypto/x509"
 	"encoding/pem"
 	"fmt"
-	"io/ioutil"
-	"log"
+	"hash/fnv"
+	"net/http"
 	"net/url"
 	"sort"
 	"strings"
 	"time"
 
 	"github.com/StackExchange/dnscontrol/v3/models"
+	"github.com/StackExchange/dnscontrol/v3/pkg/diff"
 	"github.com/StackExchange/dnscontrol/v3/pkg/nameservers"
 	"github.com/StackExchange/dnscontrol/v3/pkg/notifications"
+	"github.com/StackExchange/dnscontrol/v3/providers"
 	"github.com/go-acme/lego/certcrypto"
 	"github.com/go-acme/lego/certificate"
 	"github.com/go-acme/lego/challenge"
 	"github.com/go-acme/lego/challenge/dns01"
 	"github.com/go-acme/lego/lego"
-	acmelog "github.com/go-acme/lego/log"
+	"github.com/go-acme/lego/registration"
+	"github.com/gobwas/glob"
 )
 
 // CertConfig describes a certificate's configuration.
 type CertConfig struct {
-	CertName   string   `json:"cert_name"`
-	Names      []string `json:"names"`
-	UseECC     bool     `json:"use_ecc"`
-	MustStaple bool     `json:"must_staple"`
+	CertName string   `json:"cert_name"`
+	Names    []string `json:"names"`
+	// UseECC requests an EC256 instead of an RSA2048 key.
+	// Deprecated: use KeyType, which takes precedence.
+	UseECC     bool `json:"use_ecc"`
+	MustStaple bool `json:"must_staple"`
+	// KeyType is the type of the certificate's key, see keyTypes.
+	KeyType string `json:"key_type,omitempty"`
+	// Profile is the ACME certificate profile (e.g. a short lifetime)
+	// to request. It is not supported yet, see IssueOrRenewCert.
+	Profile string `json:"profile,omitempty"`
+	// PreferredChain is the common name of the root (e.g. "ISRG Root X1")
+	// whose chain to use, if the CA offers alternate chains.
+	PreferredChain string `json:"preferred_chain,omitempty"`
+	// RenewFraction renews the certificate once less than this fraction
+	// of its lifetime remains, instead of the renewUnder threshold. Use
+	// this for short-lived certificates.
+	RenewFraction float64 `json:"renew_fraction,omitempty"`
+	// RenewUnder is the renewal threshold of this certificate, in days
+	// or as a percentage of its validity. It overrides the renewUnder
+	// threshold of IssueOrRenewCert, RenewFraction overrides it.
+	RenewUnder *RenewUnder `json:"renew_under,omitempty"`
+	// TLSA are the TLSA records to publish for the certificate whenever
+	// it is issued or renewed.
+	TLSA []TLSAConfig `json:"tlsa,omitempty"`
+	// EnsureCAA adds a CAA record permitting only the CA to issue to the
+	// domains of the certificate while it is issued, unless they have CAA
+	// records already. The record is removed again when cleaning up.
+	EnsureCAA bool `json:"ensure_caa,omitempty"`
+	// ChallengeAliases redirect the challenges of names of the certificate
+	// (without "*.") to the FQDN the CNAME at _acme-challenge.<name>
+	// points to. The TXT records are written there instead, e.g. for names
+	// in zones at providers that dnscontrol can not write to.
+	ChallengeAliases map[string]string `json:"challenge_aliases,omitempty"`
+	// ChallengeProviders are the names of the DNS providers that receive
+	// the challenge records of this certificate, e.g. the one side of a
+	// zone at two providers that is not a read-only secondary. The other
+	// providers of the zones are not touched.
+	ChallengeProviders []string `json:"challenge_providers,omitempty"`
+	// IgnoredProviders are the names of the DNS providers that do not
+	// receive the challenge records of this certificate. It is ignored
+	// if ChallengeProviders is set.
+	// Both take precedence over the global IgnoredProviders.
+	IgnoredProviders []string `json:"ignored_providers,omitempty"`
+}
+
+// keyTypes are the valid values of CertConfig.KeyType.
+var keyTypes = map[string]certcrypto.KeyType{
+	"rsa2048": certcrypto.RSA2048,
+	"rsa4096": certcrypto.RSA4096,
+	"rsa8192": certcrypto.RSA8192,
+	"ec256":   certcrypto.EC256,
+	"ec384":   certcrypto.EC384,
+}
+
+// ParseKeyType returns the key type of the certificate. It is RSA2048
+// unless KeyType or UseECC is set.
+func (cfg *CertConfig) ParseKeyType() (certcrypto.KeyType, error) {
+	if cfg.KeyType != "" {
+		kt, ok := keyTypes[strings.ToLower(cfg.KeyType)]
+		if !ok {
+			return "", fmt.Errorf("certificate '%s' has invalid key_type '%s'", cfg.CertName, cfg.KeyType)
+		}
+		return kt, nil
+	}
+	if cfg.UseECC {
+		return certcrypto.EC256, nil
+	}
+	return certcrypto.RSA2048, nil
 }
 
 // Client is an interface for systems that issue or renew certs.
 type Client interface {
-	IssueOrRenewCert(config *CertConfig, renewUnder int, verbose bool) (bool, error)
+	IssueOrRenewCert(config *CertConfig, renewUnder RenewUnder, verbose bool) (bool, error)
+	IssueOrRenewCerts(configs []*CertConfig, renewUnder RenewUnder, verbose bool, concurrency int) []CertResult
+	VerifyChallengeCapability(config *CertConfig) error
+	ExportAccount() ([]byte, error)
+	ImportAccount(data []byte) error
+	GetCertificateParts(certName string) (leaf []byte, chain []byte, key []byte, err error)
+	CertInfo(certName string) (names []string, notAfter time.Time, issuer string, err error)
+	ListCerts(renewUnder RenewUnder) ([]CertStatus, error)
+	RevokeCert(certName string, reason int) error
 }
 
 type certManager struct {
@@ -47,9 +124,42 @@ type certManager struct {
 	originalDomains []*models.DomainConfig
 
 	notifier notifications.Notifier
+	ctx      context.Context // of the requests to the providers, see WithContext
+	logger   Logger          // see WithLogger, the text logger is used if nil
+	logLevel Level           // the lowest level logged, see WithLogLevel
 
 	account    *Account
 	waitedOnce bool
+	pending    []string // domains with challenge records not written yet
+
+	preCheckResolvers        []string
+	authoritativeNameservers []string // queried by the pre-check instead of the domain's nameservers
+	skipPreCheck             bool
+	maxFailedChecks          int
+	failedChecks             int
+	lastFailedFQDN           string
+	lastFailedValue          string
+	checkDeadline            time.Time    // of the DNS checks of the certificate being issued
+	exchange                 exchangeFunc // for tests, exchange is used if nil
+	renewalJitter            int
+	expiryWarning            int // days
+	caPool                   *x509.CertPool
+	forcePurgeLabels         []glob.Glob
+	checkSCT                 bool
+	failFunc                 models.FailFunc
+	caaIdentifier            string
+	ensureCAA                bool              // of the certificate being issued, see CertConfig.EnsureCAA
+	caaLookup                caaLookupFunc     // for tests, lookupCAA is used if nil
+	challengeAliases         map[string]string // of the certificate being issued, see CertConfig.ChallengeAliases
+	challengeProviders       map[string]bool   // of the certificate being issued, see CertConfig.ChallengeProviders
+	ignoredProviders         map[string]bool   // of the certificate being issued, see CertConfig.IgnoredProviders
+	cnameLookup              cnameLookupFunc   // for tests, lookupCNAME is used if nil
+	forcePending             bool              // see WithForcePendingCorrections
+	forcePendingExcept       map[string]bool   // record types whose pending changes still abort
+	forcedDomains            map[string]bool   // domains whose pending corrections were forced through
+	eabKID                   string
+	eabHMAC                  string
+	importedAccount          *Account // see WithImportedAccount
 }
 
 const (
@@ -60,11 +170,11 @@ const (
 )
 
 // New is a factory for acme clients.
-func New(cfg *models.DNSConfig, directory string, email string, server string, notify notifications.Notifier) (Client, error) {
-	return commonNew(cfg, directoryStorage(directory), email, server, notify)
+func New(cfg *models.DNSConfig, directory string, email string, server string, notify notifications.Notifier, opts ...Option) (Client, error) {
+	return commonNew(cfg, directoryStorage(directory), email, server, notify, opts...)
 }
 
-func commonNew(cfg *models.DNSConfig, storage Storage, email string, server string, notify notifications.Notifier) (Client, error) {
+func commonNew(cfg *models.DNSConfig, storage Storage, email string, server string, notify notifications.Notifier, opts ...Option) (Client, error) {
 	u, err := url.Parse(server)
 	if err != nil || u.Host == "" {
 		return nil, fmt.Errorf("ACME directory '%s' is not a valid URL", server)
@@ -78,7 +188,19 @@ func commonNew(cfg *models.DNSConfig, storage Storage, email string, server stri
 		domains:       map[string]*models.DomainConfig{},
 		notifier:      notify,
 	}
+	for _, opt := range opts {
+		if err := opt(c); err != nil {
+			return nil, err
+		}
+	}
 
+	if c.importedAccount != nil {
+		// Store it without registering, which would need the account.
+		if err := c.storeAccount(c.importedAccount); err != nil {
+			return nil, err
+		}
+		return c, nil
+	}
 	acct, err := c.getOrCreateAccount()
 	if err != nil {
 		return nil, err
@@ -87,25 +209,72 @@ func commonNew(cfg *models.DNSConfig, storage Storage, email string, server stri
 	return c, nil
 }
 
+// context returns the context of the requests to the providers.
+func (c *certManager) context() context.Context {
+	if c.ctx == nil {
+		return context.Background()
+	}
+	return c.ctx
+}
+
 // NewVault is a factory for new vaunt clients.
-func NewVault(cfg *models.DNSConfig, vaultPath string, email string, server string, notify notifications.Notifier) (Client, error) {
+func NewVault(cfg *models.DNSConfig, vaultPath string, email string, server string, notify notifications.Notifier, opts ...Option) (Client, error) {
 	storage, err := makeVaultStorage(vaultPath)
 	if err != nil {
 		return nil, err
 	}
-	return commonNew(cfg, storage, email, server, notify)
+	return commonNew(cfg, storage, email, server, notify, opts...)
+}
+
+// NewVaultAndDirectory is a factory for clients that store certificates
+// both on disk and in vault. Existing data is read from disk first.
+func NewVaultAndDirectory(cfg *models.DNSConfig, directory string, vaultPath string, email string, server string, notify notifications.Notifier, opts ...Option) (Client, error) {
+	vault, err := makeVaultStorage(vaultPath)
+	if err != nil {
+		return nil, err
+	}
+	storage := NewMultiStorage(directoryStorage(directory), vault)
+	return commonNew(cfg, storage, email, server, notify, opts...)
+}
+
+// NewK8s is a factory for clients that store certificates as Kubernetes
+// Secrets in the given namespace. The names of the Secrets start with secretName.
+func NewK8s(cfg *models.DNSConfig, namespace string, secretName string, email string, server string, notify notifications.Notifier, opts ...Option) (Client, error) {
+	storage, err := makeK8sStorage(namespace, secretName)
+	if err != nil {
+		return nil, err
+	}
+	return commonNew(cfg, storage, email, server, notify, opts...)
 }
 
 // IssueOrRenewCert will obtain a certificate with the given name if it does not exist,
 // or renew it if it is close enough to the expiration date.
 // It will return true if it issued or updated the certificate.
-func (c *certManager) IssueOrRenewCert(cfg *CertConfig, renewUnder int, verbose bool) (bool, error) {
-	if !verbose {
-		acmelog.Logger = log.New(ioutil.Discard, "", 0)
-	}
-	defer c.finalCleanUp()
+func (c *certManager) IssueOrRenewCert(cfg *CertConfig, renewUnder RenewUnder, verbose bool) (bool, error) {
+	c.setVerbose(verbose)
+	return c.issueOrRenew(cfg, renewUnder)
+}
 
-	log.Printf("Checking certificate [%s]", cfg.CertName)
+func (c *certManager) issueOrRenew(cfg *CertConfig, renewUnder RenewUnder) (issued bool, err error) {
+	defer func() {
+		// Challenge records left behind are an error, even if the
+		// certificate was issued.
+		if cerr := c.finalCleanUp(); cerr != nil && err == nil {
+			err = fmt.Errorf("cert %s: cleaning up the challenge records: %w", cfg.CertName, cerr)
+		}
+	}()
+
+	c.infof("Checking certificate [%s]", cfg.CertName)
+	if cfg.Profile != "" {
+		// The ACME client library (lego v2) can not send the profile with
+		// the order. Fail instead of silently issuing a default certificate.
+		return false, fmt.Errorf("cert %s: ACME profiles are not supported by this version of dnscontrol (requested %q)", cfg.CertName, cfg.Profile)
+	}
+	c.ensureCAA = cfg.EnsureCAA
+	c.challengeAliases = cfg.ChallengeAliases
+	if c.challengeProviders, c.ignoredProviders, err = c.certProviders(cfg); err != nil {
+		return false, err
+	}
 	existing, err := c.storage.GetCertificate(cfg.CertName)
 	if err != nil {
 		return false, err
@@ -113,44 +282,75 @@ func (c *certManager) IssueOrRenewCert(cfg *CertConfig, renewUnder int, verbose
 
 	var client *lego.Client
 
+	wanted := uniqueNames(cfg.Names)
 	var action = func() (*certificate.Resource, error) {
 		return client.Certificate.Obtain(certificate.ObtainRequest{
 			Bundle:     true,
-			Domains:    cfg.Names,
+			Domains:    wanted,
 			MustStaple: cfg.MustStaple,
 		})
 	}
 
 	if existing == nil {
-		log.Println("No existing cert found. Issuing new...")
+		c.infof("No existing cert found. Issuing new...")
 	} else {
-		names, daysLeft, err := getCertInfo(existing.Certificate)
+		var names []string
+		var notBefore, notAfter time.Time
+		names, notBefore, notAfter, err = getCertInfo(existing.Certificate)
 		if err != nil {
 			return false, err
 		}
-		log.Printf("Found existing cert. %0.2f days remaining.", daysLeft)
-		namesOK := dnsNamesEqual(cfg.Names, names)
-		if daysLeft >= float64(renewUnder) && namesOK {
-			log.Println("Nothing to do")
+		daysLeft, lifetime := validityDays(notBefore, notAfter)
+		c.infof("Found existing cert. %0.2f days remaining.", daysLeft)
+		if c.expiryWarning > 0 && daysLeft < float64(c.expiryWarning) {
+			// Warn unless a new cert is issued, whether it was not due yet or
+			// failed. err is the result of issueOrRenew here.
+			defer func() {
+				if !issued {
+					c.warnExpiry(cfg.CertName, notAfter, daysLeft, err)
+				}
+			}()
+		}
+		added, removed := diffNames(wanted, names)
+		namesOK := len(added) == 0 && len(removed) == 0
+		// The threshold of the certificate takes precedence over the
+		// global one, and its renew_fraction over both.
+		if cfg.RenewUnder != nil {
+			renewUnder = *cfg.RenewUnder
+		}
+		due := c.shouldRenew(cfg.CertName, daysLeft, renewUnder.days(lifetime))
+		if cfg.RenewFraction > 0 {
+			due = daysLeft < cfg.RenewFraction*lifetime
+		}
+		if !due && namesOK {
+			c.infof("Nothing to do")
 			//nothing to do
 			return false, nil
 		}
 		if !namesOK {
-			log.Println("DNS Names don't match expected set. Reissuing.")
+			c.infof("DNS Names don't match expected set (added: %v, removed: %v). Reissuing.", added, removed)
 		} else {
-			log.Println("Renewing cert")
+			c.infof("Renewing cert")
 			action = func() (*certificate.Resource, error) {
 				return client.Certificate.Renew(*existing, true, cfg.MustStaple)
 			}
 		}
 	}
 
-	kt := certcrypto.RSA2048
-	if cfg.UseECC {
-		kt = certcrypto.EC256
+	if c.caaIdentifier != "" {
+		if err := c.checkCAA(wanted); err != nil {
+			return false, fmt.Errorf("cert %s: %w", cfg.CertName, err)
+		}
 	}
-	config := lego.NewConfig(c.account)
-	config.CADirURL = c.acmeDirectory
+	if err := c.checkChallengeAliases(cfg.ChallengeAliases); err != nil {
+		return false, fmt.Errorf("cert %s: %w", cfg.CertName, err)
+	}
+
+	kt, err := cfg.ParseKeyType()
+	if err != nil {
+		return false, err
+	}
+	config := c.legoConfig(c.account)
 	config.Certificate.KeyType = kt
 	client, err = lego.NewClient(config)
 	if err != nil {
@@ -158,50 +358,274 @@ func (c *certManager) IssueOrRenewCert(cfg *CertConfig, renewUnder int, verbose
 	}
 	client.Challenge.Remove(challenge.HTTP01)
 	client.Challenge.Remove(challenge.TLSALPN01)
+	// The pre-check resolvers are queried by preCheckDNS itself, lego only
+	// has a package-global setting for them.
 	client.Challenge.SetDNS01Provider(c, dns01.WrapPreCheck(c.preCheckDNS))
 
+	c.failedChecks = 0
+	timeout, _ := c.Timeout()
+	c.checkDeadline = time.Now().Add(timeout)
 	certResource, err := action()
 	if err != nil {
-		return false, err
+		return false, c.propagationError(err)
 	}
-	fmt.Printf("Obtained certificate for %s\n", cfg.CertName)
+	if cfg.PreferredChain != "" {
+		certResource = c.selectChain(certResource, cfg.PreferredChain)
+	}
+	c.printf("Obtained certificate for %s\n", cfg.CertName)
 	if err = c.storage.StoreCertificate(cfg.CertName, certResource); err != nil {
 		return true, err
 	}
+	if c.checkSCT {
+		c.checkSCTs(cfg.CertName, certResource.Certificate)
+	}
+	if len(cfg.TLSA) > 0 {
+		if err = c.publishTLSA(cfg, certResource.Certificate); err != nil {
+			return true, fmt.Errorf("cert %s: failed publishing TLSA records: %w", cfg.CertName, err)
+		}
+	}
 
 	return true, nil
 }
 
-func getCertInfo(pemBytes []byte) (names []string, remaining float64, err error) {
+// legoConfig returns the lego configuration for user and the ACME
+// directory, trusting the custom CA bundle if one was given.
+func (c *certManager) legoConfig(user registration.User) *lego.Config {
+	config := lego.NewConfig(user)
+	config.CADirURL = c.acmeDirectory
+	if c.caPool != nil {
+		if t, ok := config.HTTPClient.Transport.(*http.Transport); ok {
+			t.TLSClientConfig.RootCAs = c.caPool
+		}
+	}
+	return config
+}
+
+// shouldRenew decides if a certificate with daysLeft is due for renewal,
+// at a threshold of renewUnder days. With a renewal jitter, the threshold
+// is moved by up to that many days, see renewalThreshold.
+func (c *certManager) shouldRenew(certName string, daysLeft float64, renewUnder float64) bool {
+	return daysLeft < renewalThreshold(certName, renewUnder, c.renewalJitter)
+}
+
+// renewalThreshold returns renewUnder moved by an offset in [-jitter, jitter]
+// days. The offset is derived from a hash of the certificate name, hence a
+// certificate always gets the same threshold, while different certificates
+// are spread over the window. The threshold is at least one day.
+func renewalThreshold(certName string, renewUnder float64, jitter int) float64 {
+	if jitter <= 0 {
+		return renewUnder
+	}
+	h := fnv.New32a()
+	h.Write([]byte(certName))
+	offset := int(h.Sum32()%uint32(2*jitter+1)) - jitter
+	threshold := renewUnder + float64(offset)
+	if threshold < 1 {
+		threshold = 1
+	}
+	return threshold
+}
+
+// GetCertificateParts returns the PEM encoded leaf certificate, the chain
+// of intermediates and the private key of a stored certificate. Use this
+// for deployments that need the leaf and the chain separately.
+func (c *certManager) GetCertificateParts(certName string) (leaf []byte, chain []byte, key []byte, err error) {
+	cert, err := c.storage.GetCertificate(certName)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	if cert == nil {
+		return nil, nil, nil, fmt.Errorf("certificate %s not found", certName)
+	}
+	leaf, chain, err = splitBundle(cert.Certificate)
+	if err != nil {
+		return nil, nil, nil, fmt.Errorf("certificate %s: %w", certName, err)
+	}
+	key, err = getPrivateKey(c.storage, certName, cert)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	return leaf, chain, key, nil
+}
+
+// CertInfo returns the names, the expiry and the common name of the issuer
+// of a stored certificate, e.g. for dashboards. The issuer tells apart
+// certificates of staging and production CAs.
+func (c *certManager) CertInfo(certName string) (names []string, notAfter time.Time, issuer string, err error) {
+	cert, err := c.storage.GetCertificate(certName)
+	if err != nil {
+		return nil, time.Time{}, "", err
+	}
+	if cert == nil {
+		return nil, time.Time{}, "", fmt.Errorf("certificate %s not found", certName)
+	}
+	leaf, err := parseLeaf(cert.Certificate)
+	if err != nil {
+		return nil, time.Time{}, "", fmt.Errorf("certificate %s: %w", certName, err)
+	}
+	return leaf.DNSNames, leaf.NotAfter, leaf.Issuer.CommonName, nil
+}
+
+// CertStatus is the state of a stored certificate, see ListCerts.
+type CertStatus struct {
+	Name      string
+	Names     []string
+	NotBefore time.Time
+	NotAfter  time.Time
+	// Due is true if the certificate is past the renewal threshold.
+	Due bool
+	// Err is the error reading the certificate, the other fields are
+	// empty then.
+	Err error
+}
+
+// ListCerts returns the state of all stored certificates, the soonest to
+// expire first. Certificates that can not be read are listed last. Due is
+// decided by renewUnder and the renewal jitter, as by IssueOrRenewCert,
+// without the thresholds of the certificates' configurations.
+func (c *certManager) ListCerts(renewUnder RenewUnder) ([]CertStatus, error) {
+	names, err := c.storage.ListCertificates()
+	if err != nil {
+		return nil, err
+	}
+	var list []CertStatus
+	for _, name := range names {
+		status := CertStatus{Name: name}
+		cert, err := c.storage.GetCertificate(name)
+		if err == nil && cert == nil {
+			continue
+		}
+		if err == nil {
+			status.Names, status.NotBefore, status.NotAfter, err = getCertInfo(cert.Certificate)
+		}
+		if err != nil {
+			status.Err = err
+		} else {
+			daysLeft, lifetime := validityDays(status.NotBefore, status.NotAfter)
+			status.Due = c.shouldRenew(name, daysLeft, renewUnder.days(lifetime))
+		}
+		list = append(list, status)
+	}
+	sort.SliceStable(list, func(i, j int) bool {
+		if (list[i].Err == nil) != (list[j].Err == nil) {
+			return list[i].Err == nil
+		}
+		if !list[i].NotAfter.Equal(list[j].NotAfter) {
+			return list[i].NotAfter.Before(list[j].NotAfter)
+		}
+		return list[i].Name < list[j].Name
+	})
+	return list, nil
+}
+
+// splitBundle splits a PEM bundle into the first certificate and the rest.
+func splitBundle(bundle []byte) (leaf []byte, chain []byte, err error) {
+	for block, rest := pem.Decode(bundle); block != nil; block, rest = pem.Decode(rest) {
+		if block.Type != "CERTIFICATE" {
+			continue
+		}
+		if leaf == nil {
+			leaf = pem.EncodeToMemory(block)
+		} else {
+			chain = append(chain, pem.EncodeToMemory(block)...)
+		}
+	}
+	if leaf == nil {
+		return nil, nil, fmt.Errorf("no certificate found in PEM data")
+	}
+	return leaf, chain, nil
+}
+
+// getCertInfo returns the names of a certificate and the start and the
+// end of its validity.
+func getCertInfo(pemBytes []byte) (names []string, notBefore time.Time, notAfter time.Time, err error) {
+	cert, err := parseLeaf(pemBytes)
+	if err != nil {
+		return nil, time.Time{}, time.Time{}, err
+	}
+	return cert.DNSNames, cert.NotBefore, cert.NotAfter, nil
+}
+
+// validityDays returns the days remaining until notAfter, and the days
+// from notBefore to notAfter.
+func validityDays(notBefore, notAfter time.Time) (remaining float64, lifetime float64) {
+	day := float64(time.Hour * 24)
+	return float64(time.Until(notAfter)) / day, float64(notAfter.Sub(notBefore)) / day
+}
+
+// parseLeaf parses the first certificate in pemBytes.
+func parseLeaf(pemBytes []byte) (*x509.Certificate, error) {
 	block, _ := pem.Decode(pemBytes)
 	if block == nil {
-		return nil, 0, fmt.Errorf("invalid certificate PEM data")
+		return nil, fmt.Errorf("invalid certificate PEM data")
 	}
-	cert, err := x509.ParseCertificate(block.Bytes)
-	if err != nil {
-		return nil, 0, err
+	return x509.ParseCertificate(block.Bytes)
+}
+
+// warnExpiry notifies that the certificate certName expires within the
+// days of WithExpiryWarning. renewErr is the error renewing it, if any.
+func (c *certManager) warnExpiry(certName string, notAfter time.Time, daysLeft float64, renewErr error) {
+	warning := fmt.Errorf("certificate %s expires on %s (%0.2f days left, warning below %d days)",
+		certName, notAfter.UTC().Format(time.RFC3339), daysLeft, c.expiryWarning)
+	if renewErr != nil {
+		warning = fmt.Errorf("%v; renewing failed: %w", warning, renewErr)
+	}
+	c.warnf("%s", warning)
+	c.notifier.Notify(certName, "certificate", "Certificate expires soon", warning, false)
+}
+
+// uniqueNames returns names without duplicates, in their original order.
+func uniqueNames(names []string) []string {
+	seen := map[string]bool{}
+	var unique []string
+	for _, n := range names {
+		if !seen[n] {
+			seen[n] = true
+			unique = append(unique, n)
+		}
 	}
-	var daysLeft = float64(cert.NotAfter.Sub(time.Now())) / float64(time.Hour*24)
-	return cert.DNSNames, daysLeft, nil
+	return unique
 }
 
-// checks two lists of sans to make sure they have all the same names in them.
-func dnsNamesEqual(a []string, b []string) bool {
-	if len(a) != len(b) {
-		return false
+// diffNames returns the sorted names that are in wanted but not in have,
+// and those in have but not in wanted. Order and duplicates do not matter.
+func diffNames(wanted []string, have []string) (added []string, removed []string) {
+	inWanted, inHave := map[string]bool{}, map[string]bool{}
+	for _, n := range wanted {
+		inWanted[n] = true
+	}
+	for _, n := range have {
+		inHave[n] = true
+	}
+	for n := range inWanted {
+		if !inHave[n] {
+			added = append(added, n)
+		}
 	}
-	sort.Strings(a)
-	sort.Strings(b)
-	for i, s := range a {
-		if b[i] != s {
-			return false
+	for n := range inHave {
+		if !inWanted[n] {
+			removed = append(removed, n)
 		}
 	}
-	return true
+	sort.Strings(added)
+	sort.Strings(removed)
+	return added, removed
 }
 
 func (c *certManager) Present(domain, token, keyAuth string) (e error) {
-	d := c.cfg.DomainContainingFQDN(domain)
+	defer func() {
+		if e != nil {
+			// The order fails, its challenges are not checked anymore.
+			c.pending = nil
+		}
+	}()
+	// With a challenge alias, the record is in the zone of the CNAME's
+	// target instead of the zone of domain.
+	fqdn := challengeFQDN(c.challengeAliases, domain)
+	d := c.cfg.DomainContainingFQDN(fqdn)
+	if d == nil {
+		return fmt.Errorf("no domain in the configuration contains the challenge record %s", fqdn)
+	}
 	name := d.Name
 	if seen := c.domains[name]; seen != nil {
 		// we've already pre-processed this domain, just need to add to it.
@@ -210,18 +634,25 @@ func (c *certManager) Present(domain, token, keyAuth string) (e error) {
 		// one-time tasks to get this domain ready.
 		// if multiple validations on a single domain, we don't need to rebuild all this.
 
-		// fix NS records for this domain's DNS providers
+		// fix NS records for this domain's DNS providers. The config is
+		// shared by concurrent issuances (see IssueOrRenewCerts), change a copy.
 		nsList, err := nameservers.DetermineNameservers(d)
 		if err != nil {
 			return err
 		}
+		if d, err = d.Copy(); err != nil {
+			return err
+		}
 		d.Nameservers = nsList
 		nameservers.AddNSRecords(d)
 
 		// make sure we have the latest config before we change anything.
 		// alternately, we could avoid a lot of this trouble if we really really trusted no-purge in all cases
-		if err := c.ensureNoPendingCorrections(d); err != nil {
-			return err
+		// Challenge-only domains never touch anything but the challenge records, so drift does not matter.
+		if !ChallengeOnlyDomains[name] {
+			if err := c.ensureNoPendingCorrections(d); err != nil {
+				return err
+			}
 		}
 
 		// copy domain and work from copy from now on. That way original config can be used to "restore" when we are all done.
@@ -229,72 +660,311 @@ func (c *certManager) Present(domain, token, keyAuth string) (e error) {
 		if err != nil {
 			return err
 		}
+		if c.ensureCAA {
+			if err := c.addCAA(copy); err != nil {
+				return err
+			}
+		}
 		c.originalDomains = append(c.originalDomains, d)
 		c.domains[name] = copy
 		d = copy
 	}
 
-	fqdn, val := dns01.GetRecord(domain, keyAuth)
+	_, val := dns01.GetRecord(domain, keyAuth)
 	txt := &models.RecordConfig{Type: "TXT"}
 	txt.SetTargetTXT(val)
 	txt.SetLabelFromFQDN(fqdn, d.Name)
 	d.Records = append(d.Records, txt)
-	return c.getAndRunCorrections(d)
+	// The records of all names are written together by flushChallenges,
+	// before the first DNS check. lego presents all challenges first.
+	for _, p := range c.pending {
+		if p == name {
+			return nil
+		}
+	}
+	c.pending = append(c.pending, name)
+	return nil
+}
+
+// flushChallenges runs the corrections for all domains with challenge
+// records added by Present since the last flush, one batch per domain.
+func (c *certManager) flushChallenges() error {
+	for len(c.pending) > 0 {
+		if err := c.getAndRunCorrections(c.context(), c.domains[c.pending[0]]); err != nil {
+			// The next DNS check must not run the corrections again.
+			c.pending = nil
+			return err
+		}
+		c.pending = c.pending[1:]
+	}
+	return nil
 }
 
 func (c *certManager) ensureNoPendingCorrections(d *models.DomainConfig) error {
-	corrections, err := c.getCorrections(d)
+	corrections, err := c.getCorrections(c.context(), d)
 	if err != nil {
 		return err
 	}
-	if len(corrections) != 0 {
-		// TODO: maybe allow forcing through this check.
-		for _, c := range corrections {
-			fmt.Println(c.Msg)
+	n := models.CountChanges(corrections)
+	if n == 0 {
+		return nil
+	}
+	if !c.forcePending {
+		for _, corr := range corrections {
+			c.printf("%s", corr.Msg)
 		}
-		return fmt.Errorf("found %d pending corrections for %s. Not going to proceed issuing certificates", len(corrections), d.Name)
+		return fmt.Errorf("found %d pending corrections for %s. Not going to proceed issuing certificates", n, d.Name)
 	}
+	if len(c.forcePendingExcept) != 0 {
+		types, err := c.pendingTypes(c.context(), d)
+		if err != nil {
+			return err
+		}
+		var blocking []string
+		for _, t := range types {
+			if c.forcePendingExcept[t] {
+				blocking = append(blocking, t)
+			}
+		}
+		if len(blocking) != 0 {
+			for _, corr := range corrections {
+				c.printf("%s", corr.Msg)
+			}
+			return fmt.Errorf("found %d pending corrections for %s, changing %s records. Not going to proceed issuing certificates", n, d.Name, strings.Join(blocking, ", "))
+		}
+	}
+	for _, corr := range corrections {
+		c.warnf("ignoring pending correction: %s", corr.Msg)
+	}
+	c.warnf("proceeding with %d pending corrections for %s, only its challenge records are changed", n, d.Name)
+	if c.forcedDomains == nil {
+		c.forcedDomains = map[string]bool{}
+	}
+	c.forcedDomains[d.Name] = true
 	return nil
 }
 
+// pendingTypes returns the sorted record types with pending changes of d
+// at any of its providers.
+func (c *certManager) pendingTypes(ctx context.Context, d *models.DomainConfig) ([]string, error) {
+	dc, err := d.Copy()
+	if err != nil {
+		return nil, err
+	}
+	if err := dc.Punycode(); err != nil {
+		return nil, err
+	}
+	seen := map[string]bool{}
+	for _, p := range dc.DNSProviderInstances {
+		if !c.useProvider(p.Name) {
+			continue
+		}
+		existing, err := providers.GetZoneRecordsContext(ctx, p.Driver, dc.Name)
+		if err != nil {
+			return nil, fmt.Errorf("can not tell the record types of the pending corrections at %s: %w", p.Name, err)
+		}
+		models.PostProcessRecords(existing)
+		_, create, del, modify, err := diff.New(dc).IncrementalDiff(existing)
+		if err != nil {
+			return nil, err
+		}
+		for _, set := range []diff.Changeset{create, del, modify} {
+			for _, cor := range set {
+				if cor.Desired != nil {
+					seen[cor.Desired.Type] = true
+				}
+				if cor.Existing != nil {
+					seen[cor.Existing.Type] = true
+				}
+			}
+		}
+	}
+	types := make([]string, 0, len(seen))
+	for t := range seen {
+		types = append(types, t)
+	}
+	sort.Strings(types)
+	return types, nil
+}
+
+// challengeOnly returns whether only the challenge records of the domain
+// may be changed, see ChallengeOnlyDomains and WithForcePendingCorrections.
+func (c *certManager) challengeOnly(name string) bool {
+	return ChallengeOnlyDomains[name] || c.forcedDomains[name]
+}
+
 // IgnoredProviders is a lit of provider names that should not be used to fill challenges.
+// The providers of a certificate take precedence, see CertConfig.ChallengeProviders.
 var IgnoredProviders = map[string]bool{}
 
-func (c *certManager) getCorrections(d *models.DomainConfig) ([]*models.Correction, error) {
+// certProviders returns the sets of the ChallengeProviders and the
+// IgnoredProviders of cfg, or an error if they name unknown providers.
+func (c *certManager) certProviders(cfg *CertConfig) (use, ignore map[string]bool, err error) {
+	if len(cfg.ChallengeProviders) == 0 && len(cfg.IgnoredProviders) == 0 {
+		return nil, nil, nil
+	}
+	known := map[string]bool{}
+	for _, p := range c.cfg.DNSProviders {
+		known[p.Name] = true
+	}
+	toSet := func(field string, names []string) (map[string]bool, error) {
+		if len(names) == 0 {
+			return nil, nil
+		}
+		set := map[string]bool{}
+		for _, name := range names {
+			if !known[name] {
+				return nil, fmt.Errorf("cert %s: %s: there is no DNS provider %q", cfg.CertName, field, name)
+			}
+			set[name] = true
+		}
+		return set, nil
+	}
+	if use, err = toSet("challenge_providers", cfg.ChallengeProviders); err != nil {
+		return nil, nil, err
+	}
+	if ignore, err = toSet("ignored_providers", cfg.IgnoredProviders); err != nil {
+		return nil, nil, err
+	}
+	return use, ignore, nil
+}
+
+// useProvider returns true if the challenge records of the certificate
+// being issued are written to the provider.
+func (c *certManager) useProvider(name string) bool {
+	switch {
+	case c.challengeProviders != nil:
+		return c.challengeProviders[name]
+	case c.ignoredProviders != nil:
+		return !c.ignoredProviders[name]
+	default:
+		return !IgnoredProviders[name]
+	}
+}
+
+// ChallengeOnlyDomains is a list of domain names for which only the
+// challenge records are managed. Any other difference between the
+// configuration and the zone (drift) is left untouched.
+var ChallengeOnlyDomains = map[string]bool{}
+
+// challengeLabelPrefix is the label the dns-01 challenge records live under.
+const challengeLabelPrefix = "_acme-challenge."
+
+// scopeToChallenges replaces all records in dc but the challenge records
+// with the records currently existing at the provider. The diff then can
+// only ever touch the challenge records. The existing records are
+// normalized like the records of dc, so that they do not differ from
+// themselves.
+func scopeToChallenges(ctx context.Context, dc *models.DomainConfig, p *models.DNSProviderInstance) error {
+	existing, err := providers.GetZoneRecordsContext(ctx, p.Driver, dc.Name)
+	if err != nil {
+		return err
+	}
+	models.PostProcessRecords(existing)
+	recs := models.Records{}
+	for _, r := range existing {
+		if isChallengeRecord(r) {
+			continue
+		}
+		if r.TTL == 0 {
+			r.TTL = models.DefaultTTL
+		}
+		recs = append(recs, r)
+	}
+	for _, r := range dc.Records {
+		if isChallengeRecord(r) {
+			recs = append(recs, r)
+		}
+	}
+	dc.Records = recs
+	return nil
+}
+
+// isChallengeRecord returns true if r is a dns-01 challenge record.
+func isChallengeRecord(r *models.RecordConfig) bool {
+	return strings.HasPrefix(strings.ToLower(r.GetLabelFQDN()), challengeLabelPrefix)
+}
+
+// getCorrections returns the corrections of d at all providers. Cancelling
+// ctx aborts the requests of providers that support it.
+func (c *certManager) getCorrections(ctx context.Context, d *models.DomainConfig) ([]*models.Correction, error) {
 	cs := []*models.Correction{}
 	for _, p := range d.DNSProviderInstances {
-		if IgnoredProviders[p.Name] {
+		if !c.useProvider(p.Name) {
 			continue
 		}
-		dc, err := d.Copy()
-		if err != nil {
-			return nil, err
-		}
-		corrections, err := p.Driver.GetDomainCorrections(dc)
+		corrections, err := c.getProviderCorrections(ctx, d, p)
 		if err != nil {
 			return nil, err
 		}
-		for _, c := range corrections {
-			c.Msg = fmt.Sprintf("[%s] %s", p.Name, strings.TrimSpace(c.Msg))
-		}
 		cs = append(cs, corrections...)
 	}
 	return cs, nil
 }
 
-func (c *certManager) getAndRunCorrections(d *models.DomainConfig) error {
-	cs, err := c.getCorrections(d)
+// getProviderCorrections returns the corrections of d at provider p.
+func (c *certManager) getProviderCorrections(ctx context.Context, d *models.DomainConfig, p *models.DNSProviderInstance) ([]*models.Correction, error) {
+	dc, err := d.Copy()
 	if err != nil {
-		return err
+		return nil, err
+	}
+	if c.challengeOnly(d.Name) {
+		if err := scopeToChallenges(ctx, dc, p); err != nil {
+			return nil, err
+		}
+	}
+	corrections, err := providers.GetDomainCorrectionsContext(ctx, p.Driver, dc)
+	if err != nil {
+		return nil, err
+	}
+	for _, c := range corrections {
+		c.Msg = fmt.Sprintf("[%s] %s", p.Name, strings.TrimSpace(c.Msg))
+	}
+	return corrections, nil
+}
+
+// getAndRunCorrections computes the corrections of all providers of d
+// first, and then runs them provider by provider.
+func (c *certManager) getAndRunCorrections(ctx context.Context, d *models.DomainConfig) error {
+	var names []string
+	var perProvider [][]*models.Correction
+	total := 0
+	for _, p := range d.DNSProviderInstances {
+		if !c.useProvider(p.Name) {
+			continue
+		}
+		cs, err := c.getProviderCorrections(ctx, d, p)
+		if err != nil {
+			return err
+		}
+		names = append(names, p.Name)
+		perProvider = append(perProvider, cs)
+		total += models.CountChanges(cs)
 	}
-	fmt.Printf("%d corrections\n", len(cs))
+	c.printf("%d corrections\n", total)
+	for i, cs := range perProvider {
+		if err := c.runCorrections(d, names[i], cs); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (c *certManager) runCorrections(d *models.DomainConfig, provider string, cs []*models.Correction) error {
+	var err error
 	for _, corr := range cs {
-		fmt.Printf("Running [%s]\n", corr.Msg)
-		err = corr.F()
-		c.notifier.Notify(d.Name, "certs", corr.Msg, err, false)
+		if corr.Informational {
+			c.printf("%s\n", corr.Msg)
+			continue
+		}
+		c.printf("Running [%s]\n", corr.Msg)
+		start := time.Now()
+		err = corr.Run(c.failFunc)
+		notifications.NotifyEvent(c.notifier, notifications.NewEvent(d.Name, provider, corr.Msg, err, false, start))
 		if err != nil {
 			return err
 		}
+		c.debugf("Ran [%s] at %s in %s", corr.Msg, provider, time.Since(start))
 	}
 	return nil
 }
@@ -304,14 +974,114 @@ func (c *certManager) CleanUp(domain, token, keyAuth string) error {
 	return nil
 }
 
+// cleanupAttempts is how often the clean up of a domain is tried, so that
+// a transient error of a provider does not leave challenge records behind.
+const cleanupAttempts = 3
+
+// cleanupBackoff is the wait before the first retry of a clean up. It
+// doubles with every retry.
+var cleanupBackoff = 5 * time.Second
+
+// finalCleanUp restores all domains we changed. It returns the errors of
+// the domains that could not be cleaned up, even after retrying.
 func (c *certManager) finalCleanUp() error {
-	log.Println("Cleaning up all records we made")
-	var lastError error
+	c.infof("Cleaning up all records we made")
+	var errs error
 	for _, d := range c.originalDomains {
-		if err := c.getAndRunCorrections(d); err != nil {
-			log.Printf("ERROR cleaning up: %s", err)
-			lastError = err
+		if err := c.cleanUpDomain(d); err != nil {
+			c.errorf("cleaning up %s: %s", d.Name, err)
+			err = fmt.Errorf("%s: %w", d.Name, err)
+			if errs == nil {
+				errs = err
+			} else {
+				errs = fmt.Errorf("%w; %v", errs, err)
+			}
+		}
+	}
+	return errs
+}
+
+// cleanUpDomain restores d, retrying with backoff if that fails. It runs
+// even if the context of c was cancelled, not to leave challenge records
+// behind.
+func (c *certManager) cleanUpDomain(d *models.DomainConfig) error {
+	ctx := context.Background()
+	wait := cleanupBackoff
+	for attempt := 1; ; attempt++ {
+		err := c.getAndRunCorrections(ctx, d)
+		if perr := c.forcePurge(ctx, d); perr != nil {
+			if err == nil {
+				err = perr
+			} else {
+				err = fmt.Errorf("%w; %v", err, perr)
+			}
 		}
+		if err == nil || attempt == cleanupAttempts {
+			return err
+		}
+		c.infof("Cleaning up %s failed (attempt %d of %d), retrying in %s: %s", d.Name, attempt, cleanupAttempts, wait, err)
+		time.Sleep(wait)
+		wait *= 2
+	}
+}
+
+// mustPurge returns true if the label matches one of the ForcePurgeLabels.
+func (c *certManager) mustPurge(label string) bool {
+	for _, g := range c.forcePurgeLabels {
+		if g.Match(label) {
+			return true
+		}
+	}
+	return false
+}
+
+// forcePurge removes the records of d at labels matching ForcePurgeLabels
+// that are not in the configuration, even if d is NO_PURGE. All other
+// records are left as they are.
+func (c *certManager) forcePurge(ctx context.Context, d *models.DomainConfig) error {
+	if len(c.forcePurgeLabels) == 0 {
+		return nil
 	}
-	return lastError
+	for _, p := range d.DNSProviderInstances {
+		if !c.useProvider(p.Name) {
+			continue
+		}
+		existing, err := providers.GetZoneRecordsContext(ctx, p.Driver, d.Name)
+		if err != nil {
+			return err
+		}
+		recs := models.Records{}
+		for _, r := range existing {
+			if !c.mustPurge(r.GetLabel()) {
+				recs = append(recs, r)
+			}
+		}
+		if len(recs) == len(existing) {
+			// Nothing to purge.
+			continue
+		}
+		dc, err := d.Copy()
+		if err != nil {
+			return err
+		}
+		for _, r := range dc.Records {
+			if c.mustPurge(r.GetLabel()) {
+				recs = append(recs, r)
+			}
+		}
+		dc.Records = recs
+		dc.KeepUnknown = false
+		corrections, err := providers.GetDomainCorrectionsContext(ctx, p.Driver, dc)
+		if err != nil {
+			return err
+		}
+		for _, corr := range corrections {
+			corr.Msg = fmt.Sprintf("[%s] %s", p.Name, strings.TrimSpace(corr.Msg))
+		}
+		c.printf("%d corrections\n", models.CountChanges(corrections))
+		if err := c.runCorrections(d, p.Name, corrections); err != nil {
+			return err
+		}
+	}
+	return nil
 }
@@ -0,0 +1,70 @@
+package acme
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/miekg/dns"
+)
+
+// ChallengeFQDN returns the FQDN of the TXT record of the challenge for
+// name, a name of the certificate. That is _acme-challenge.<name>, or the
+// target of its CNAME if name has a challenge alias.
+func (cfg *CertConfig) ChallengeFQDN(name string) string {
+	return challengeFQDN(cfg.ChallengeAliases, name)
+}
+
+func challengeFQDN(aliases map[string]string, name string) string {
+	name = strings.ToLower(strings.TrimPrefix(strings.TrimSuffix(name, "."), "*."))
+	for alias, target := range aliases {
+		if strings.EqualFold(strings.TrimSuffix(alias, "."), name) {
+			return dns.Fqdn(strings.ToLower(target))
+		}
+	}
+	return dns.Fqdn("_acme-challenge." + name)
+}
+
+// cnameLookupFunc returns the target of the CNAME at fqdn, or "" if there
+// is none.
+type cnameLookupFunc func(fqdn string) (string, error)
+
+// checkChallengeAliases verifies that the CNAME records of the challenge
+// aliases exist and point to their targets. Otherwise the CA would look
+// for the challenge records in the wrong place, and the order fail after
+// all records were written.
+func (c *certManager) checkChallengeAliases(aliases map[string]string) error {
+	lookup := c.cnameLookup
+	if lookup == nil {
+		lookup = c.lookupCNAME
+	}
+	for name := range aliases {
+		fqdn := dns.Fqdn("_acme-challenge." + strings.TrimPrefix(name, "*."))
+		want := challengeFQDN(aliases, name)
+		got, err := lookup(fqdn)
+		if err != nil {
+			return fmt.Errorf("challenge alias of %s: %w", name, err)
+		}
+		if got == "" {
+			return fmt.Errorf("challenge alias of %s: there is no CNAME at %s, add one pointing to %s", name, fqdn, want)
+		}
+		if !strings.EqualFold(dns.Fqdn(got), want) {
+			return fmt.Errorf("challenge alias of %s: the CNAME at %s points to %s instead of %s", name, fqdn, got, want)
+		}
+	}
+	return nil
+}
+
+// lookupCNAME queries the CNAME of fqdn from the pre-check resolvers, or
+// the system resolvers if there are none.
+func (c *certManager) lookupCNAME(fqdn string) (string, error) {
+	r, err := c.queryResolvers(fqdn, dns.TypeCNAME)
+	if err != nil {
+		return "", err
+	}
+	for _, rr := range r.Answer {
+		if cname, ok := rr.(*dns.CNAME); ok && strings.EqualFold(cname.Hdr.Name, fqdn) {
+			return cname.Target, nil
+		}
+	}
+	return "", nil
+}
@@ -0,0 +1,83 @@
+package acme
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/StackExchange/dnscontrol/v3/models"
+)
+
+func TestChallengeFQDN(t *testing.T) {
+	cfg := &CertConfig{ChallengeAliases: map[string]string{"restricted.example": "Restricted.acme.example.net"}}
+	tests := map[string]string{
+		"www.example.com":        "_acme-challenge.www.example.com.",
+		"restricted.example":     "restricted.acme.example.net.",
+		"*.restricted.example":   "restricted.acme.example.net.",
+		"www.restricted.example": "_acme-challenge.www.restricted.example.",
+	}
+	for name, want := range tests {
+		if got := cfg.ChallengeFQDN(name); got != want {
+			t.Errorf("%s: expected %s, got %s", name, want, got)
+		}
+	}
+}
+
+func TestCheckChallengeAliases(t *testing.T) {
+	cnames := map[string]string{
+		"_acme-challenge.good.example.":  "good.acme.example.net.",
+		"_acme-challenge.wrong.example.": "elsewhere.example.net.",
+	}
+	c := &certManager{cnameLookup: func(fqdn string) (string, error) { return cnames[fqdn], nil }}
+
+	tests := []struct {
+		name, target string
+		wantErr      string
+	}{
+		{"good.example", "good.acme.example.net", ""},
+		{"good.example", "GOOD.acme.example.net.", ""},
+		{"wrong.example", "wrong.acme.example.net", "points to elsewhere.example.net."},
+		{"missing.example", "missing.acme.example.net", "there is no CNAME"},
+	}
+	for _, tst := range tests {
+		err := c.checkChallengeAliases(map[string]string{tst.name: tst.target})
+		if tst.wantErr == "" && err != nil {
+			t.Errorf("%s: unexpected error %v", tst.name, err)
+		}
+		if tst.wantErr != "" && (err == nil || !strings.Contains(err.Error(), tst.wantErr)) {
+			t.Errorf("%s: expected an error containing %q, got %v", tst.name, tst.wantErr, err)
+		}
+	}
+}
+
+func TestPresentChallengeAlias(t *testing.T) {
+	provider := &purgeProvider{}
+	cfg := &models.DNSConfig{Domains: []*models.DomainConfig{{
+		Name: "acme.example.net",
+		DNSProviderInstances: []*models.DNSProviderInstance{{
+			ProviderBase: models.ProviderBase{Name: "test"},
+			Driver:       provider,
+		}},
+	}}}
+	c := &certManager{
+		cfg:              cfg,
+		domains:          map[string]*models.DomainConfig{},
+		notifier:         noNotifier(t),
+		challengeAliases: map[string]string{"restricted.example": "restricted.acme.example.net"},
+	}
+
+	// restricted.example is not in the configuration at all.
+	if err := c.Present("restricted.example", "token", "keyAuth"); err != nil {
+		t.Fatal(err)
+	}
+	d := c.domains["acme.example.net"]
+	if d == nil || len(d.Records) != 1 || d.Records[0].GetLabel() != "restricted" || d.Records[0].Type != "TXT" {
+		t.Fatalf("expected the challenge record in the zone of the alias, got %+v", d)
+	}
+	if len(c.originalDomains) != 1 || len(c.originalDomains[0].Records) != 0 {
+		t.Error("expected the original zone without the record, so that it is cleaned up")
+	}
+
+	if err := c.Present("other.example", "token", "keyAuth"); err == nil {
+		t.Error("expected an error for a name without alias outside of the configuration")
+	}
+}
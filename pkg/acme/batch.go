@@ -0,0 +1,140 @@
+package acme
+
+import (
+	"sort"
+	"sync"
+
+	"github.com/StackExchange/dnscontrol/v3/models"
+	"github.com/StackExchange/dnscontrol/v3/pkg/notifications"
+	"github.com/StackExchange/dnscontrol/v3/providers"
+)
+
+// CertResult is the outcome of issuing or renewing one certificate.
+type CertResult struct {
+	Issued bool
+	Err    error
+}
+
+// IssueOrRenewCerts issues or renews the certificates like IssueOrRenewCert,
+// with up to concurrency certificates at a time. Certificates with names in
+// the same zone are issued one after another, as are those using providers
+// without the CanRunConcurrently capability. The results are in the order
+// of configs.
+func (c *certManager) IssueOrRenewCerts(configs []*CertConfig, renewUnder RenewUnder, verbose bool, concurrency int) []CertResult {
+	c.setVerbose(verbose)
+	if concurrency < 1 {
+		concurrency = 1
+	}
+	results := make([]CertResult, len(configs))
+	locks := &zoneLocks{locks: map[string]*sync.Mutex{}}
+	notifier := &syncNotifier{n: c.notifier}
+	sem := make(chan struct{}, concurrency)
+	var wg sync.WaitGroup
+	for i, cfg := range configs {
+		wg.Add(1)
+		go func(i int, cfg *CertConfig) {
+			defer wg.Done()
+			// The zone locks are taken first, a cert waiting for the zone
+			// of another one does not hold one of the slots.
+			unlock := locks.lock(c.lockKeys(cfg))
+			defer unlock()
+			sem <- struct{}{}
+			defer func() { <-sem }()
+			results[i].Issued, results[i].Err = c.worker(notifier).issueOrRenew(cfg, renewUnder)
+		}(i, cfg)
+	}
+	wg.Wait()
+	return results
+}
+
+// worker returns a copy of c with its own state of an issuance, sharing
+// the account, storage and options.
+func (c *certManager) worker(notifier notifications.Notifier) *certManager {
+	w := *c
+	w.domains = map[string]*models.DomainConfig{}
+	w.originalDomains = nil
+	w.pending = nil
+	w.forcedDomains = nil
+	w.waitedOnce = false
+	w.failedChecks = 0
+	w.notifier = notifier
+	return &w
+}
+
+// lockKeys returns the sorted keys of the zone locks a certificate needs.
+// Zones only using providers that are safe for concurrent use get their
+// own key, all others share a key.
+func (c *certManager) lockKeys(cfg *CertConfig) []string {
+	seen := map[string]bool{}
+	var keys []string
+	for _, name := range cfg.Names {
+		d := c.cfg.DomainContainingFQDN(cfg.ChallengeFQDN(name))
+		if d == nil {
+			continue
+		}
+		key := d.Name
+		for _, p := range d.DNSProviderInstances {
+			if !providers.ProviderHasCapability(p.ProviderType, providers.CanRunConcurrently) {
+				key = ""
+			}
+		}
+		if !seen[key] {
+			seen[key] = true
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// zoneLocks hands out a mutex per key.
+type zoneLocks struct {
+	mu    sync.Mutex
+	locks map[string]*sync.Mutex
+}
+
+// lock locks the mutexes of keys and returns a function unlocking them.
+// keys must be sorted, so that concurrent callers can not deadlock.
+func (z *zoneLocks) lock(keys []string) func() {
+	var held []*sync.Mutex
+	for _, key := range keys {
+		z.mu.Lock()
+		m := z.locks[key]
+		if m == nil {
+			m = &sync.Mutex{}
+			z.locks[key] = m
+		}
+		z.mu.Unlock()
+		m.Lock()
+		held = append(held, m)
+	}
+	return func() {
+		for _, m := range held {
+			m.Unlock()
+		}
+	}
+}
+
+// syncNotifier makes a notifier safe for concurrent use.
+type syncNotifier struct {
+	mu sync.Mutex
+	n  notifications.Notifier
+}
+
+func (s *syncNotifier) Notify(domain, provider string, message string, err error, preview bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.n.Notify(domain, provider, message, err, preview)
+}
+
+func (s *syncNotifier) NotifyEvent(e notifications.Event) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	notifications.NotifyEvent(s.n, e)
+}
+
+func (s *syncNotifier) Done() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.n.Done()
+}
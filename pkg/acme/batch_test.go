@@ -0,0 +1,97 @@
+package acme
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/StackExchange/dnscontrol/v3/models"
+	"github.com/StackExchange/dnscontrol/v3/providers"
+)
+
+func init() {
+	providers.RegisterDomainServiceProviderType("ACMETEST_CONCURRENT", providers.DspFuncs{}, providers.CanRunConcurrently)
+}
+
+func TestLockKeys(t *testing.T) {
+	domain := func(name, pType string) *models.DomainConfig {
+		return &models.DomainConfig{
+			Name:                 name,
+			DNSProviderInstances: []*models.DNSProviderInstance{{ProviderBase: models.ProviderBase{ProviderType: pType}}},
+		}
+	}
+	c := &certManager{cfg: &models.DNSConfig{Domains: []*models.DomainConfig{
+		domain("example.com", "ACMETEST_CONCURRENT"),
+		domain("example.net", "ACMETEST_CONCURRENT"),
+		domain("example.org", "BIND"),
+		domain("example.info", "BIND"),
+	}}}
+
+	for _, tst := range []struct {
+		names    []string
+		expected string
+	}{
+		{[]string{"www.example.net", "example.com", "*.example.com"}, "[example.com example.net]"},
+		{[]string{"example.org", "example.info", "example.com"}, "[ example.com]"},
+	} {
+		got := fmt.Sprint(c.lockKeys(&CertConfig{Names: tst.names}))
+		if got != tst.expected {
+			t.Errorf("%v: expected keys %s, got %s", tst.names, tst.expected, got)
+		}
+	}
+}
+
+func TestZoneLocks(t *testing.T) {
+	locks := &zoneLocks{locks: map[string]*sync.Mutex{}}
+	var mu sync.Mutex
+	active := map[string]int{}
+	var wg sync.WaitGroup
+	for i := 0; i < 20; i++ {
+		keys := [][]string{{"a"}, {"a", "b"}, {"b"}, {"c"}}[i%4]
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			unlock := locks.lock(keys)
+			defer unlock()
+			mu.Lock()
+			for _, k := range keys {
+				active[k]++
+				if active[k] > 1 {
+					t.Errorf("key %s locked twice", k)
+				}
+			}
+			mu.Unlock()
+			time.Sleep(time.Millisecond)
+			mu.Lock()
+			for _, k := range keys {
+				active[k]--
+			}
+			mu.Unlock()
+		}()
+	}
+	wg.Wait()
+}
+
+func TestIssueOrRenewCertsResultOrder(t *testing.T) {
+	c := &certManager{
+		cfg:      &models.DNSConfig{},
+		domains:  map[string]*models.DomainConfig{},
+		notifier: noNotifier(t),
+	}
+	var configs []*CertConfig
+	for i := 0; i < 5; i++ {
+		// Profiles are rejected before anything else is done.
+		configs = append(configs, &CertConfig{CertName: fmt.Sprintf("cert%d", i), Profile: "short"})
+	}
+	results := c.IssueOrRenewCerts(configs, RenewUnder{Days: 15}, false, 3)
+	if len(results) != len(configs) {
+		t.Fatalf("expected %d results, got %d", len(configs), len(results))
+	}
+	for i, r := range results {
+		if r.Issued || r.Err == nil || !strings.Contains(r.Err.Error(), configs[i].CertName+":") {
+			t.Errorf("unexpected result %d: %+v", i, r)
+		}
+	}
+}
@@ -0,0 +1,221 @@
+package acme
+
+import (
+	"fmt"
+	"net"
+	"strings"
+
+	"github.com/StackExchange/dnscontrol/v3/models"
+	"github.com/StackExchange/dnscontrol/v3/providers"
+	"github.com/miekg/dns"
+)
+
+// caaIdentifiers are the CAA identifiers of CAs by the host of their ACME
+// directory.
+var caaIdentifiers = map[string]string{
+	"acme-v02.api.letsencrypt.org":         "letsencrypt.org",
+	"acme-staging-v02.api.letsencrypt.org": "letsencrypt.org",
+	"acme.zerossl.com":                     "sectigo.com",
+	"dv.acme-v02.api.pki.goog":             "pki.goog",
+	"dv.acme-v02.test-api.pki.goog":        "pki.goog",
+	"api.buypass.com":                      "buypass.com",
+	"api.test4.buypass.no":                 "buypass.com",
+	"acme.ssl.com":                         "ssl.com",
+}
+
+// caaIssuer returns the CAA identifier of the CA: the one of the CAA check
+// if set, else the one of a well-known ACME directory.
+func (c *certManager) caaIssuer() (string, error) {
+	if c.caaIdentifier != "" {
+		return c.caaIdentifier, nil
+	}
+	if id, ok := caaIdentifiers[c.acmeHost]; ok {
+		return id, nil
+	}
+	return "", fmt.Errorf("the CAA identifier of %s is not known, set it with the CAA check", c.acmeHost)
+}
+
+// addCAA adds a CAA record to d that permits only our CA to issue,
+// unless d has CAA records already. d is the working copy of Present, so
+// the record is removed again by the final clean up.
+func (c *certManager) addCAA(d *models.DomainConfig) error {
+	if c.challengeOnly(d.Name) {
+		c.infof("Not adding a CAA record to challenge-only domain %s", d.Name)
+		return nil
+	}
+	for _, r := range d.Records {
+		if r.Type == "CAA" {
+			c.infof("%s has CAA records already, not adding one", d.Name)
+			return nil
+		}
+	}
+	for _, p := range d.DNSProviderInstances {
+		if !c.useProvider(p.Name) {
+			continue
+		}
+		if !providers.ProviderHasCapability(p.ProviderType, providers.CanUseCAA) {
+			return fmt.Errorf("can not add a CAA record to %s: provider %s does not support CAA records", d.Name, p.Name)
+		}
+		if !d.KeepUnknown {
+			continue
+		}
+		// With NO_PURGE, the provider may have CAA records that are not in
+		// the configuration.
+		existing, err := providers.GetZoneRecordsContext(c.context(), p.Driver, d.Name)
+		if err != nil {
+			return err
+		}
+		for _, r := range existing {
+			if r.Type == "CAA" {
+				c.infof("%s has CAA records at %s already, not adding one", d.Name, p.Name)
+				return nil
+			}
+		}
+	}
+	id, err := c.caaIssuer()
+	if err != nil {
+		return err
+	}
+	rc := &models.RecordConfig{Type: "CAA", TTL: models.DefaultTTL, Metadata: map[string]string{}}
+	rc.SetLabel("@", d.Name)
+	if err := rc.SetTargetCAA(0, "issue", id); err != nil {
+		return err
+	}
+	c.infof("Adding CAA record 0 issue %q to %s", id, d.Name)
+	d.Records = append(d.Records, rc)
+	return nil
+}
+
+// caaLookupFunc returns the CAA records at fqdn. It returns no records and
+// no error if there are none.
+type caaLookupFunc func(fqdn string) ([]*dns.CAA, error)
+
+// checkCAA verifies that the CAA records of every name permit the CA
+// identified by c.caaIdentifier to issue for it. It fails early instead
+// of having the CA reject the order after all challenges were fulfilled.
+func (c *certManager) checkCAA(names []string) error {
+	lookup := c.caaLookup
+	if lookup == nil {
+		lookup = c.lookupCAA
+	}
+	for _, name := range names {
+		set, at, err := relevantCAASet(name, lookup)
+		if err != nil {
+			return fmt.Errorf("CAA check of %s: %w", name, err)
+		}
+		if !caaPermits(name, c.caaIdentifier, set) {
+			return fmt.Errorf("CAA records at %s do not permit %s to issue for %s", at, c.caaIdentifier, name)
+		}
+	}
+	return nil
+}
+
+// relevantCAASet climbs the DNS tree from name towards the TLD and returns
+// the first non-empty set of CAA records and where it was found (RFC 8659,
+// section 3). The set is empty if there are no CAA records at all.
+func relevantCAASet(name string, lookup caaLookupFunc) ([]*dns.CAA, string, error) {
+	name = strings.TrimPrefix(strings.TrimSuffix(name, "."), "*.")
+	labels := dns.SplitDomainName(name)
+	for i := range labels {
+		fqdn := dns.Fqdn(strings.Join(labels[i:], "."))
+		set, err := lookup(fqdn)
+		if err != nil {
+			return nil, "", err
+		}
+		if len(set) > 0 {
+			return set, fqdn, nil
+		}
+	}
+	return nil, "", nil
+}
+
+// caaPermits returns true if the CAA record set permits the CA with
+// identifier to issue a certificate for name (RFC 8659, section 4).
+func caaPermits(name string, identifier string, set []*dns.CAA) bool {
+	var issue, issuewild []*dns.CAA
+	for _, caa := range set {
+		switch strings.ToLower(caa.Tag) {
+		case "issue":
+			issue = append(issue, caa)
+		case "issuewild":
+			issuewild = append(issuewild, caa)
+		case "iodef":
+		default:
+			if caa.Flag&128 != 0 {
+				// Unknown critical property, no CA may issue.
+				return false
+			}
+		}
+	}
+
+	relevant := issue
+	if strings.HasPrefix(name, "*.") && len(issuewild) > 0 {
+		relevant = issuewild
+	}
+	if len(relevant) == 0 {
+		// The set does not restrict issuance.
+		return true
+	}
+	for _, caa := range relevant {
+		domain := strings.TrimSpace(strings.SplitN(caa.Value, ";", 2)[0])
+		if strings.EqualFold(domain, identifier) {
+			return true
+		}
+	}
+	return false
+}
+
+// lookupCAA queries the CAA records of fqdn from the pre-check resolvers,
+// or the system resolvers if there are none.
+func (c *certManager) lookupCAA(fqdn string) ([]*dns.CAA, error) {
+	r, err := c.queryResolvers(fqdn, dns.TypeCAA)
+	if err != nil {
+		return nil, err
+	}
+	var set []*dns.CAA
+	for _, rr := range r.Answer {
+		if caa, ok := rr.(*dns.CAA); ok {
+			set = append(set, caa)
+		}
+	}
+	return set, nil
+}
+
+// queryResolvers queries the records of type qtype at fqdn from the
+// pre-check resolvers, or the system resolvers if there are none. The
+// answer of the first resolver that answers is returned, a name that does
+// not exist is not an error.
+func (c *certManager) queryResolvers(fqdn string, qtype uint16) (*dns.Msg, error) {
+	servers := c.preCheckResolvers
+	if len(servers) == 0 {
+		conf, err := dns.ClientConfigFromFile("/etc/resolv.conf")
+		if err != nil {
+			return nil, err
+		}
+		for _, s := range conf.Servers {
+			servers = append(servers, net.JoinHostPort(s, conf.Port))
+		}
+	}
+	m := new(dns.Msg)
+	m.SetQuestion(fqdn, qtype)
+	var lastErr error
+	for _, server := range servers {
+		if _, _, err := net.SplitHostPort(server); err != nil {
+			server = net.JoinHostPort(server, "53")
+		}
+		r, err := dns.Exchange(m, server)
+		if err != nil {
+			lastErr = err
+			continue
+		}
+		if r.Rcode != dns.RcodeSuccess && r.Rcode != dns.RcodeNameError {
+			lastErr = fmt.Errorf("%s: %s", fqdn, dns.RcodeToString[r.Rcode])
+			continue
+		}
+		return r, nil
+	}
+	if lastErr == nil {
+		lastErr = fmt.Errorf("no resolvers")
+	}
+	return nil, lastErr
+}
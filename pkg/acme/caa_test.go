@@ -0,0 +1,132 @@
+package acme
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/StackExchange/dnscontrol/v3/models"
+	"github.com/StackExchange/dnscontrol/v3/providers"
+	"github.com/miekg/dns"
+)
+
+func init() {
+	providers.RegisterDomainServiceProviderType("ACMETEST_CAA", providers.DspFuncs{}, providers.CanUseCAA)
+}
+
+func caa(flag uint8, tag, value string) *dns.CAA {
+	return &dns.CAA{Flag: flag, Tag: tag, Value: value}
+}
+
+func TestCheckCAA(t *testing.T) {
+	records := map[string][]*dns.CAA{
+		"example.com.":           {caa(0, "issue", "letsencrypt.org"), caa(0, "issuewild", ";")},
+		"other.example.com.":     {caa(0, "issue", "ca.example.net; account=1")},
+		"critical.example.com.":  {caa(0, "issue", "letsencrypt.org"), caa(128, "unknown", "x")},
+		"iodefonly.example.com.": {caa(0, "iodef", "mailto:hostmaster@example.com")},
+	}
+	var queried []string
+	c := &certManager{
+		caaIdentifier: "letsencrypt.org",
+		caaLookup: func(fqdn string) ([]*dns.CAA, error) {
+			queried = append(queried, fqdn)
+			return records[fqdn], nil
+		},
+	}
+
+	tests := []struct {
+		name    string
+		allowed bool
+	}{
+		{"example.com", true},
+		{"www.example.com", true}, // inherited from the apex
+		{"*.example.com", false},  // issuewild forbids all
+		{"other.example.com", false},
+		{"www.other.example.com", false},
+		{"critical.example.com", false},
+		{"iodefonly.example.com", true},
+		{"example.org", true}, // no CAA records at all
+	}
+	for _, tst := range tests {
+		err := c.checkCAA([]string{tst.name})
+		if tst.allowed && err != nil {
+			t.Errorf("%s: expected issuance to be permitted, got %v", tst.name, err)
+		}
+		if !tst.allowed && err == nil {
+			t.Errorf("%s: expected issuance to be refused", tst.name)
+		}
+	}
+
+	queried = nil
+	c.checkCAA([]string{"a.b.example.com"})
+	if strings.Join(queried, " ") != "a.b.example.com. b.example.com. example.com." {
+		t.Errorf("expected to climb up to the first CAA set, queried %v", queried)
+	}
+}
+
+func TestPresentEnsuresCAA(t *testing.T) {
+	managed := &models.RecordConfig{Type: "CAA"}
+	managed.SetLabel("@", "example.net")
+	managed.SetTargetCAA(0, "issue", "pki.goog")
+	domain := func(name, pType string, records ...*models.RecordConfig) *models.DomainConfig {
+		return &models.DomainConfig{
+			Name:    name,
+			Records: records,
+			DNSProviderInstances: []*models.DNSProviderInstance{{
+				ProviderBase: models.ProviderBase{Name: "test", ProviderType: pType},
+				Driver:       &purgeProvider{},
+			}},
+		}
+	}
+	cfg := &models.DNSConfig{Domains: []*models.DomainConfig{
+		domain("example.com", "ACMETEST_CAA"),
+		domain("example.net", "ACMETEST_CAA", managed),
+		domain("example.org", ""),
+	}}
+	c := &certManager{
+		cfg:       cfg,
+		acmeHost:  "acme-v02.api.letsencrypt.org",
+		domains:   map[string]*models.DomainConfig{},
+		notifier:  noNotifier(t),
+		ensureCAA: true,
+	}
+
+	caaRecords := func(d *models.DomainConfig) []string {
+		var caas []string
+		for _, r := range d.Records {
+			if r.Type == "CAA" {
+				caas = append(caas, r.GetTargetCombined())
+			}
+		}
+		return caas
+	}
+
+	if err := c.Present("www.example.com", "token", "keyAuth"); err != nil {
+		t.Fatal(err)
+	}
+	if got := caaRecords(c.domains["example.com"]); len(got) != 1 || got[0] != `0 issue "letsencrypt.org"` {
+		t.Errorf("expected a CAA record for letsencrypt.org, got %v", got)
+	}
+	if len(c.originalDomains[0].Records) != 0 {
+		t.Error("expected the CAA record not to be in the original config, so that it is cleaned up")
+	}
+
+	if err := c.Present("example.net", "token", "keyAuth"); err != nil {
+		t.Fatal(err)
+	}
+	if got := caaRecords(c.domains["example.net"]); len(got) != 1 || !strings.Contains(got[0], "pki.goog") {
+		t.Errorf("expected the managed CAA record to be left alone, got %v", got)
+	}
+
+	if err := c.Present("example.org", "token", "keyAuth"); err == nil {
+		t.Error("expected an error for a provider without CAA support")
+	}
+
+	c.acmeHost = "acme.internal"
+	if _, err := c.caaIssuer(); err == nil {
+		t.Error("expected an error for an unknown CA")
+	}
+	c.caaIdentifier = "internal"
+	if id, _ := c.caaIssuer(); id != "internal" {
+		t.Errorf("expected the identifier of the CAA check, got %q", id)
+	}
+}
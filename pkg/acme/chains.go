@@ -0,0 +1,216 @@
+package acme
+
+import (
+	"bytes"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/rand"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"regexp"
+
+	"github.com/go-acme/lego/certificate"
+)
+
+// selectChain returns cert with the chain whose topmost certificate is
+// issued by preferred, the common name of a root (e.g. "ISRG Root X1").
+// The ACME client library does not support alternate chains, so they are
+// fetched here. If the CA offers no such chain, cert is returned as is.
+func (c *certManager) selectChain(cert *certificate.Resource, preferred string) *certificate.Resource {
+	if chainIssuedBy(cert.Certificate, preferred) {
+		return cert
+	}
+	client := c.legoConfig(nil).HTTPClient
+	alternates, err := c.alternateChains(client, cert.CertURL)
+	if err == nil {
+		for _, alt := range alternates {
+			if chainIssuedBy(alt, preferred) {
+				selected := *cert
+				selected.Certificate = alt
+				if _, chain, err := splitBundle(alt); err == nil {
+					selected.IssuerCertificate = chain
+				}
+				c.infof("Using the alternate chain issued by %q for %s", preferred, cert.Domain)
+				return &selected
+			}
+		}
+	}
+	if err != nil {
+		c.warnf("could not fetch alternate chains for %s: %s", cert.Domain, err)
+	}
+	c.warnf("the CA offers no chain issued by %q for %s, using the default chain", preferred, cert.Domain)
+	return cert
+}
+
+// chainIssuedBy returns true if the topmost certificate of the PEM bundle
+// is issued by a CA with the common name issuer.
+func chainIssuedBy(bundle []byte, issuer string) bool {
+	var top *x509.Certificate
+	for block, rest := pem.Decode(bundle); block != nil; block, rest = pem.Decode(rest) {
+		if block.Type != "CERTIFICATE" {
+			continue
+		}
+		cert, err := x509.ParseCertificate(block.Bytes)
+		if err != nil {
+			return false
+		}
+		top = cert
+	}
+	return top != nil && top.Issuer.CommonName == issuer
+}
+
+var alternateLinkRegex = regexp.MustCompile(`<(.+?)>\s*;\s*rel="?alternate"?`)
+
+// alternateChains downloads the alternate chains the CA links to from the
+// certificate at certURL (RFC 8555, section 7.4.2).
+func (c *certManager) alternateChains(client *http.Client, certURL string) ([][]byte, error) {
+	if certURL == "" {
+		return nil, fmt.Errorf("no certificate URL")
+	}
+	j := c.jwsClient(client)
+	resp, _, err := j.postAsGet(certURL)
+	if err != nil {
+		return nil, err
+	}
+	var chains [][]byte
+	for _, link := range resp.Header["Link"] {
+		for _, m := range alternateLinkRegex.FindAllStringSubmatch(link, -1) {
+			_, body, err := j.postAsGet(m[1])
+			if err != nil {
+				return chains, err
+			}
+			chains = append(chains, body)
+		}
+	}
+	return chains, nil
+}
+
+// jwsClient sends requests of RFC 8555 the ACME client library does not
+// support, signed with the account key.
+type jwsClient struct {
+	client       *http.Client
+	directoryURL string
+	account      *Account
+	nonce        string
+	directory    *struct {
+		NewNonce   string `json:"newNonce"`
+		RevokeCert string `json:"revokeCert"`
+	}
+}
+
+func (c *certManager) jwsClient(client *http.Client) *jwsClient {
+	return &jwsClient{client: client, directoryURL: c.acmeDirectory, account: c.account}
+}
+
+func (j *jwsClient) getDirectory() error {
+	if j.directory != nil {
+		return nil
+	}
+	resp, err := j.client.Get(j.directoryURL)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if err := json.NewDecoder(resp.Body).Decode(&j.directory); err != nil {
+		return fmt.Errorf("ACME directory: %w", err)
+	}
+	return nil
+}
+
+func (j *jwsClient) getNonce() (string, error) {
+	if j.nonce != "" {
+		nonce := j.nonce
+		j.nonce = ""
+		return nonce, nil
+	}
+	if err := j.getDirectory(); err != nil {
+		return "", err
+	}
+	resp, err := j.client.Head(j.directory.NewNonce)
+	if err != nil {
+		return "", err
+	}
+	resp.Body.Close()
+	nonce := resp.Header.Get("Replay-Nonce")
+	if nonce == "" {
+		return "", fmt.Errorf("ACME server sent no nonce")
+	}
+	return nonce, nil
+}
+
+func (j *jwsClient) postAsGet(url string) (*http.Response, []byte, error) {
+	return j.post(url, nil)
+}
+
+// post sends payload, which is empty for a POST-as-GET request, to url.
+func (j *jwsClient) post(url string, payload []byte) (*http.Response, []byte, error) {
+	if j.account == nil || j.account.Registration == nil || j.account.key == nil {
+		return nil, nil, fmt.Errorf("no ACME account")
+	}
+	nonce, err := j.getNonce()
+	if err != nil {
+		return nil, nil, err
+	}
+	body, err := signJWS(j.account.key, j.account.Registration.URI, nonce, url, payload)
+	if err != nil {
+		return nil, nil, err
+	}
+	resp, err := j.client.Post(url, "application/jose+json", bytes.NewReader(body))
+	if err != nil {
+		return nil, nil, err
+	}
+	defer resp.Body.Close()
+	j.nonce = resp.Header.Get("Replay-Nonce")
+	data, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return nil, nil, err
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, nil, fmt.Errorf("%s: %s", url, resp.Status)
+	}
+	return resp, data, nil
+}
+
+// signJWS returns a flattened JWS of payload for url, signed with the
+// ECDSA account key identified by kid.
+func signJWS(key *ecdsa.PrivateKey, kid, nonce, url string, payload []byte) ([]byte, error) {
+	var alg string
+	var hash crypto.Hash
+	switch size := key.Curve.Params().BitSize; size {
+	case 256:
+		alg, hash = "ES256", crypto.SHA256
+	case 384:
+		alg, hash = "ES384", crypto.SHA384
+	case 521:
+		alg, hash = "ES512", crypto.SHA512
+	default:
+		return nil, fmt.Errorf("unsupported account key size %d", size)
+	}
+	header, err := json.Marshal(map[string]string{"alg": alg, "kid": kid, "nonce": nonce, "url": url})
+	if err != nil {
+		return nil, err
+	}
+	protected := base64.RawURLEncoding.EncodeToString(header)
+	encodedPayload := base64.RawURLEncoding.EncodeToString(payload)
+	h := hash.New()
+	h.Write([]byte(protected + "." + encodedPayload))
+	r, s, err := ecdsa.Sign(rand.Reader, key, h.Sum(nil))
+	if err != nil {
+		return nil, err
+	}
+	n := (key.Curve.Params().BitSize + 7) / 8
+	sig := make([]byte, 2*n)
+	rb, sb := r.Bytes(), s.Bytes()
+	copy(sig[n-len(rb):n], rb)
+	copy(sig[2*n-len(sb):], sb)
+	return json.Marshal(map[string]string{
+		"protected": protected,
+		"payload":   encodedPayload,
+		"signature": base64.RawURLEncoding.EncodeToString(sig),
+	})
+}
@@ -0,0 +1,112 @@
+package acme
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha512"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/go-acme/lego/certificate"
+	"github.com/go-acme/lego/registration"
+)
+
+// issuedBy returns a PEM encoded certificate for cn with the issuer
+// issuerCN. The signature is not valid, it is never verified.
+func issuedBy(t *testing.T, cn, issuerCN string) []byte {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tmpl := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: cn},
+		NotBefore:    time.Now(),
+		NotAfter:     time.Now().Add(time.Hour),
+	}
+	parent := &x509.Certificate{Subject: pkix.Name{CommonName: issuerCN}}
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, parent, &key.PublicKey, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der})
+}
+
+func TestSelectChain(t *testing.T) {
+	leaf := issuedBy(t, "example.com", "R3")
+	long := append(append(append([]byte{}, leaf...), issuedBy(t, "R3", "ISRG Root X1")...), issuedBy(t, "ISRG Root X1", "DST Root CA X3")...)
+	short := append(append([]byte{}, leaf...), issuedBy(t, "R3", "ISRG Root X1")...)
+
+	key, err := ecdsa.GenerateKey(elliptic.P384(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var srv *httptest.Server
+	mux := http.NewServeMux()
+	mux.HandleFunc("/directory", func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(map[string]string{"newNonce": srv.URL + "/nonce"})
+	})
+	mux.HandleFunc("/nonce", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Replay-Nonce", "nonce")
+	})
+	verify := func(r *http.Request) bool {
+		jws := map[string]string{}
+		if err := json.NewDecoder(r.Body).Decode(&jws); err != nil {
+			return false
+		}
+		header, _ := base64.RawURLEncoding.DecodeString(jws["protected"])
+		protected := map[string]string{}
+		json.Unmarshal(header, &protected)
+		sig, _ := base64.RawURLEncoding.DecodeString(jws["signature"])
+		hash := sha512.Sum384([]byte(jws["protected"] + "."))
+		return protected["kid"] == srv.URL+"/acct" && protected["url"] == srv.URL+r.URL.Path &&
+			protected["alg"] == "ES384" && len(sig) == 96 &&
+			ecdsa.Verify(&key.PublicKey, hash[:], new(big.Int).SetBytes(sig[:48]), new(big.Int).SetBytes(sig[48:]))
+	}
+	mux.HandleFunc("/cert", func(w http.ResponseWriter, r *http.Request) {
+		if !verify(r) {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		w.Header().Add("Link", `<`+srv.URL+`/cert/1>;rel="alternate"`)
+		w.Write(long)
+	})
+	mux.HandleFunc("/cert/1", func(w http.ResponseWriter, r *http.Request) {
+		if !verify(r) {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		w.Write(short)
+	})
+	srv = httptest.NewServer(mux)
+	defer srv.Close()
+
+	c := &certManager{
+		acmeDirectory: srv.URL + "/directory",
+		account:       &Account{key: key, Registration: &registration.Resource{URI: srv.URL + "/acct"}},
+	}
+	cert := &certificate.Resource{Domain: "example.com", CertURL: srv.URL + "/cert", Certificate: long}
+
+	if got := c.selectChain(cert, "DST Root CA X3"); got != cert {
+		t.Error("expected the default chain to be kept")
+	}
+	got := c.selectChain(cert, "ISRG Root X1")
+	if string(got.Certificate) != string(short) {
+		t.Error("expected the alternate chain")
+	}
+	if !chainIssuedBy(got.IssuerCertificate, "ISRG Root X1") {
+		t.Error("expected the issuer certificate of the alternate chain")
+	}
+	if got := c.selectChain(cert, "Unknown Root"); got != cert {
+		t.Error("expected a fall back to the default chain")
+	}
+}
@@ -1,10 +1,14 @@
 package acme
 
 import (
-	"log"
+	"context"
+	"fmt"
+	"net"
+	"strings"
 	"time"
 
 	"github.com/go-acme/lego/challenge/dns01"
+	"github.com/miekg/dns"
 )
 
 func (c *certManager) preCheckDNS(domain, fqdn, value string, native dns01.PreCheckFunc) (bool, error) {
@@ -12,20 +16,187 @@ func (c *certManager) preCheckDNS(domain, fqdn, value string, native dns01.PreCh
 	// have the expected records.
 	// Sometimes the Let's Encrypt verification fails anyway because records have not propagated the provider's network fully.
 	// So we add an additional 60 second sleep just for safety.
-	v, err := native(fqdn, value)
-	if err != nil {
+	if err := c.flushChallenges(); err != nil {
+		return false, err
+	}
+	if c.skipPreCheck {
+		return true, nil
+	}
+	// Check the record where Present wrote it. This differs from the fqdn
+	// of lego for challenge aliases.
+	fqdn = challengeFQDN(c.challengeAliases, domain)
+	check := native
+	servers, recursive := c.authoritativeServers(fqdn), false
+	if len(servers) == 0 && len(c.preCheckResolvers) > 0 {
+		servers, recursive = c.preCheckResolvers, true
+	}
+	if len(servers) > 0 {
+		check = func(fqdn, value string) (bool, error) {
+			// lego only enforces its timeout between checks, a check
+			// with nameservers that do not answer has to stop by itself.
+			deadline := c.checkDeadline
+			if deadline.IsZero() {
+				timeout, _ := c.Timeout()
+				deadline = time.Now().Add(timeout)
+			}
+			ctx, cancel := context.WithDeadline(context.Background(), deadline)
+			defer cancel()
+			return c.checkNameservers(ctx, servers, fqdn, value, recursive), nil
+		}
+	}
+	v, err := check(fqdn, value)
+	if err != nil || !v {
+		c.failedChecks++
+		c.debugf("Challenge record %s is not visible yet (check %d)", fqdn, c.failedChecks)
+		c.lastFailedFQDN, c.lastFailedValue = fqdn, value
 		return v, err
 	}
+	c.failedChecks = 0
 	if !c.waitedOnce {
-		log.Printf("DNS ok. Waiting another 60s to ensure stability.")
+		c.infof("DNS ok. Waiting another 60s to ensure stability.")
 		time.Sleep(60 * time.Second)
 		c.waitedOnce = true
 	}
-	log.Printf("DNS records seem to exist. Proceeding to request validation")
+	c.infof("DNS records seem to exist. Proceeding to request validation")
 	return v, err
 }
 
+// authoritativeServers returns the nameservers the pre-check queries
+// directly for the challenge record fqdn: the override nameservers if
+// set, else the nameservers of the domain containing it in the config. If none are
+// known, or pre-check resolvers are set, the pre-check resolvers or the
+// recursive lookup of lego are used instead.
+func (c *certManager) authoritativeServers(fqdn string) []string {
+	if len(c.authoritativeNameservers) > 0 {
+		return c.authoritativeNameservers
+	}
+	if len(c.preCheckResolvers) > 0 {
+		return nil
+	}
+	d := c.cfg.DomainContainingFQDN(fqdn)
+	if d == nil {
+		return nil
+	}
+	if seen := c.domains[d.Name]; seen != nil {
+		d = seen
+	}
+	var servers []string
+	for _, ns := range d.Nameservers {
+		servers = append(servers, ns.Name)
+	}
+	return servers
+}
+
+// checkNameservers returns true if all servers return value for fqdn.
+// recursive is set for resolvers, unset for authoritative nameservers.
+func (c *certManager) checkNameservers(ctx context.Context, servers []string, fqdn, value string, recursive bool) bool {
+	for _, ns := range servers {
+		if c.observe(ctx, ns, fqdn, value, recursive) != "ok" {
+			return false
+		}
+	}
+	return true
+}
+
 // Timeout increases the client-side polling check time to five minutes with one second waits in-between.
+// With WithMaxFailedChecks, polling gives up after about that many checks instead.
 func (c *certManager) Timeout() (timeout, interval time.Duration) {
-	return 5 * time.Minute, time.Second
+	interval = time.Second
+	if c.maxFailedChecks > 0 {
+		return time.Duration(c.maxFailedChecks) * interval, interval
+	}
+	return 5 * time.Minute, interval
+}
+
+// observeTimeout limits the time propagationError spends asking the
+// nameservers.
+var observeTimeout = 10 * time.Second
+
+// propagationError adds what each authoritative nameserver returned for the
+// last challenge record that failed the pre-check to err.
+func (c *certManager) propagationError(err error) error {
+	if c.failedChecks == 0 {
+		return err
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), observeTimeout)
+	defer cancel()
+	var observations []string
+	if servers := c.authoritativeServers(c.lastFailedFQDN); len(servers) > 0 {
+		for _, ns := range servers {
+			observations = append(observations, ns+": "+c.observeNameserver(ctx, ns, c.lastFailedFQDN, c.lastFailedValue))
+		}
+	} else {
+		observations = c.observeNameservers(ctx, c.lastFailedFQDN, c.lastFailedValue)
+	}
+	return fmt.Errorf("%w\nchallenge record %s failed %d DNS checks, authoritative nameservers returned:\n\t%s",
+		err, c.lastFailedFQDN, c.failedChecks, strings.Join(observations, "\n\t"))
+}
+
+// observeNameservers queries every authoritative nameserver of fqdn for
+// its TXT records and describes whether value was found.
+func (c *certManager) observeNameservers(ctx context.Context, fqdn, value string) []string {
+	zone, err := dns01.FindZoneByFqdn(fqdn)
+	if err != nil {
+		return []string{fmt.Sprintf("could not determine zone: %s", err)}
+	}
+	nss, err := net.DefaultResolver.LookupNS(ctx, dns01.UnFqdn(zone))
+	if err != nil {
+		return []string{fmt.Sprintf("could not determine nameservers of %s: %s", zone, err)}
+	}
+	var observations []string
+	for _, ns := range nss {
+		observations = append(observations, ns.Host+": "+c.observeNameserver(ctx, ns.Host, fqdn, value))
+	}
+	return observations
+}
+
+// exchangeFunc sends the DNS query m to the nameserver at addr.
+type exchangeFunc func(ctx context.Context, m *dns.Msg, addr string) (*dns.Msg, error)
+
+func exchange(ctx context.Context, m *dns.Msg, addr string) (*dns.Msg, error) {
+	r, _, err := new(dns.Client).ExchangeContext(ctx, m, addr)
+	return r, err
+}
+
+// observeNameserver queries ns (host or host:port) for the TXT records
+// of fqdn and returns "ok" if value was found, or what was found instead.
+func (c *certManager) observeNameserver(ctx context.Context, ns, fqdn, value string) string {
+	return c.observe(ctx, ns, fqdn, value, false)
+}
+
+// observe is like observeNameserver, with recursion desired if recursive
+// is set.
+func (c *certManager) observe(ctx context.Context, ns, fqdn, value string, recursive bool) string {
+	m := new(dns.Msg)
+	m.SetQuestion(dns.Fqdn(fqdn), dns.TypeTXT)
+	m.RecursionDesired = recursive
+	addr := ns
+	if _, _, err := net.SplitHostPort(ns); err != nil {
+		addr = net.JoinHostPort(strings.TrimSuffix(ns, "."), "53")
+	}
+	exchangeFn := c.exchange
+	if exchangeFn == nil {
+		exchangeFn = exchange
+	}
+	r, err := exchangeFn(ctx, m, addr)
+	if err != nil {
+		return fmt.Sprintf("query failed: %s", err)
+	}
+	if r.Rcode != dns.RcodeSuccess {
+		return dns.RcodeToString[r.Rcode]
+	}
+	var found []string
+	for _, rr := range r.Answer {
+		if txt, ok := rr.(*dns.TXT); ok {
+			s := strings.Join(txt.Txt, "")
+			if s == value {
+				return "ok"
+			}
+			found = append(found, fmt.Sprintf("%q", s))
+		}
+	}
+	if len(found) == 0 {
+		return "no TXT records"
+	}
+	return "expected value missing, found " + strings.Join(found, ", ")
 }
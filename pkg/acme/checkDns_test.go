@@ -0,0 +1,92 @@
+package acme
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/miekg/dns"
+)
+
+// hangingExchange is a nameserver that never answers.
+func hangingExchange(ctx context.Context, m *dns.Msg, addr string) (*dns.Msg, error) {
+	<-ctx.Done()
+	return nil, ctx.Err()
+}
+
+func TestPreCheckDeadline(t *testing.T) {
+	c := &certManager{
+		authoritativeNameservers: []string{"ns1.example.net", "ns2.example.net"},
+		checkDeadline:            time.Now().Add(50 * time.Millisecond),
+		exchange:                 hangingExchange,
+		notifier:                 noNotifier(t),
+	}
+	start := time.Now()
+	ok, err := c.preCheckDNS("example.com", "_acme-challenge.example.com.", "value", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ok {
+		t.Error("expected the check to fail")
+	}
+	if elapsed := time.Since(start); elapsed > 5*time.Second {
+		t.Errorf("expected the check to stop at the deadline, took %s", elapsed)
+	}
+}
+
+func TestPropagationErrorTimeout(t *testing.T) {
+	defer func(timeout time.Duration) { observeTimeout = timeout }(observeTimeout)
+	observeTimeout = 50 * time.Millisecond
+
+	c := &certManager{
+		authoritativeNameservers: []string{"ns1.example.net", "ns2.example.net"},
+		exchange:                 hangingExchange,
+		failedChecks:             3,
+		lastFailedFQDN:           "_acme-challenge.example.com.",
+		lastFailedValue:          "value",
+	}
+	start := time.Now()
+	err := c.propagationError(context.Canceled)
+	if elapsed := time.Since(start); elapsed > 5*time.Second {
+		t.Errorf("expected the observations to stop at the timeout, took %s", elapsed)
+	}
+	for _, ns := range c.authoritativeNameservers {
+		if !strings.Contains(err.Error(), ns+": query failed: context deadline exceeded") {
+			t.Errorf("expected the timeout of %s to be reported, got %v", ns, err)
+		}
+	}
+}
+
+func TestPreCheckResolvers(t *testing.T) {
+	var queried []string
+	c := &certManager{
+		waitedOnce: true,
+		exchange: func(ctx context.Context, m *dns.Msg, addr string) (*dns.Msg, error) {
+			queried = append(queried, fmt.Sprintf("%s rd=%v", addr, m.RecursionDesired))
+			r := new(dns.Msg)
+			r.SetReply(m)
+			r.Answer = append(r.Answer, &dns.TXT{
+				Hdr: dns.RR_Header{Name: m.Question[0].Name, Rrtype: dns.TypeTXT, Class: dns.ClassINET},
+				Txt: []string{"value"},
+			})
+			return r, nil
+		},
+		notifier: noNotifier(t),
+	}
+	if err := WithPreCheckResolvers([]string{"192.0.2.53", "192.0.2.54:5353"})(c); err != nil {
+		t.Fatal(err)
+	}
+	native := func(fqdn, value string) (bool, error) {
+		t.Error("expected the resolvers to be queried instead of the lego check")
+		return false, nil
+	}
+	ok, err := c.preCheckDNS("example.com", "_acme-challenge.example.com.", "value", native)
+	if err != nil || !ok {
+		t.Fatalf("expected the check to succeed, got %v, %v", ok, err)
+	}
+	if fmt.Sprint(queried) != "[192.0.2.53:53 rd=true 192.0.2.54:5353 rd=true]" {
+		t.Errorf("unexpected queries %v", queried)
+	}
+}
@@ -61,17 +61,25 @@ func (d directoryStorage) GetCertificate(name string) (*certificate.Resource, er
 	return cr, nil
 }
 
+// GetPrivateKey returns the private key of a certificate. It is not part
+// of GetCertificate so that renewals do not reuse the key.
+func (d directoryStorage) GetPrivateKey(name string) ([]byte, error) {
+	return ioutil.ReadFile(d.certFile(name, "key"))
+}
+
 func (d directoryStorage) StoreCertificate(name string, cert *certificate.Resource) error {
 	// make sure actual cert data never gets into metadata json
 	if err := os.MkdirAll(d.certDir(name), dirPerms); err != nil {
 		return err
 	}
 	pub := cert.Certificate
-	cert.Certificate = nil
 	priv := cert.PrivateKey
-	cert.PrivateKey = nil
+	// Work on a copy, the caller (and other storages) still need the data.
+	meta := *cert
+	meta.Certificate = nil
+	meta.PrivateKey = nil
 	combined := []byte(string(pub) + "\n" + string(priv))
-	jDAt, err := json.MarshalIndent(cert, "", "  ")
+	jDAt, err := json.MarshalIndent(&meta, "", "  ")
 	if err != nil {
 		return err
 	}
@@ -87,6 +95,33 @@ func (d directoryStorage) StoreCertificate(name string, cert *certificate.Resour
 	return ioutil.WriteFile(d.certFile(name, "key"), priv, perms)
 }
 
+// DeleteCertificate removes the directory of a certificate.
+func (d directoryStorage) DeleteCertificate(name string) error {
+	return os.RemoveAll(d.certDir(name))
+}
+
+// ListCertificates returns the names of the certificate directories that
+// contain a certificate.
+func (d directoryStorage) ListCertificates() ([]string, error) {
+	entries, err := ioutil.ReadDir(filepath.Join(string(d), "certificates"))
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var names []string
+	for _, e := range entries {
+		if !e.IsDir() {
+			continue
+		}
+		if _, err := os.Stat(d.certFile(e.Name(), "json")); err == nil {
+			names = append(names, e.Name())
+		}
+	}
+	return names, nil
+}
+
 func (d directoryStorage) GetAccount(acmeHost string) (*Account, error) {
 	f, err := os.Open(d.accountFile(acmeHost))
 	if err != nil && os.IsNotExist(err) {
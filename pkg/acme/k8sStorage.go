@@ -0,0 +1,446 @@
+package acme
+
+import (
+	"bytes"
+	"crypto/ecdsa"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"fmt"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/go-acme/lego/certificate"
+	"gopkg.in/yaml.v2"
+)
+
+// k8sStorage stores certificates as kubernetes.io/tls Secrets. The Secret
+// of a certificate is named secretName-<cert name>, the account is kept in
+// a separate Opaque Secret named secretName-account-<acme host>.
+type k8sStorage struct {
+	namespace  string
+	secretName string
+	client     *k8sClient
+}
+
+func makeK8sStorage(namespace, secretName string) (Storage, error) {
+	client, err := newK8sClient()
+	if err != nil {
+		return nil, err
+	}
+	return &k8sStorage{namespace: namespace, secretName: secretName, client: client}, nil
+}
+
+// k8sSecret is the subset of a Secret used here. []byte fields are base64
+// encoded by encoding/json, as the API expects.
+type k8sSecret struct {
+	APIVersion string            `json:"apiVersion"`
+	Kind       string            `json:"kind"`
+	Metadata   k8sObjectMeta     `json:"metadata"`
+	Type       string            `json:"type"`
+	Data       map[string][]byte `json:"data"`
+}
+
+type k8sObjectMeta struct {
+	Name            string `json:"name"`
+	Namespace       string `json:"namespace"`
+	ResourceVersion string `json:"resourceVersion,omitempty"`
+}
+
+// k8sInvalidChars are the characters not allowed in names of Secrets.
+var k8sInvalidChars = regexp.MustCompile(`[^a-z0-9.-]`)
+
+// k8sName makes s usable in the name of a Secret (RFC 1123 subdomain).
+func k8sName(s string) string {
+	s = strings.Replace(strings.ToLower(s), "@", "-at-", -1)
+	return k8sInvalidChars.ReplaceAllString(s, "-")
+}
+
+func (k *k8sStorage) certSecret(name string) string {
+	return k8sName(k.secretName + "-" + name)
+}
+
+func (k *k8sStorage) accountSecret(acmeHost string) string {
+	return k8sName(k.secretName + "-account-" + acmeHost)
+}
+
+func (k *k8sStorage) GetCertificate(name string) (*certificate.Resource, error) {
+	secret, err := k.client.getSecret(k.namespace, k.certSecret(name))
+	if err != nil || secret == nil {
+		return nil, err
+	}
+	cert := &certificate.Resource{}
+	if err := json.Unmarshal(secret.Data["meta.json"], cert); err != nil {
+		return nil, fmt.Errorf("secret %s: %w", secret.Metadata.Name, err)
+	}
+	cert.Certificate = secret.Data["tls.crt"]
+	cert.PrivateKey = secret.Data["tls.key"]
+	return cert, nil
+}
+
+func (k *k8sStorage) StoreCertificate(name string, cert *certificate.Resource) error {
+	meta, err := json.MarshalIndent(cert, "", "  ")
+	if err != nil {
+		return err
+	}
+	return k.client.putSecret(&k8sSecret{
+		Metadata: k8sObjectMeta{Name: k.certSecret(name), Namespace: k.namespace},
+		Type:     "kubernetes.io/tls",
+		Data: map[string][]byte{
+			"tls.crt":   cert.Certificate,
+			"tls.key":   cert.PrivateKey,
+			"meta.json": meta,
+		},
+	})
+}
+
+func (k *k8sStorage) DeleteCertificate(name string) error {
+	_, err := k.client.do("DELETE", secretsPath(k.namespace)+"/"+k.certSecret(name), nil, nil)
+	return err
+}
+
+// ListCertificates returns the names of the certificates from the names of
+// their Secrets. These are the names as changed by k8sName, e.g.
+// "main-cert" for "main_Cert", which GetCertificate accepts as well.
+func (k *k8sStorage) ListCertificates() ([]string, error) {
+	secrets, err := k.client.listSecrets(k.namespace, "kubernetes.io/tls")
+	if err != nil {
+		return nil, err
+	}
+	prefix := k8sName(k.secretName + "-")
+	var names []string
+	for _, s := range secrets {
+		if strings.HasPrefix(s.Metadata.Name, prefix) {
+			names = append(names, strings.TrimPrefix(s.Metadata.Name, prefix))
+		}
+	}
+	return names, nil
+}
+
+func (k *k8sStorage) GetAccount(acmeHost string) (*Account, error) {
+	secret, err := k.client.getSecret(k.namespace, k.accountSecret(acmeHost))
+	if err != nil || secret == nil {
+		return nil, err
+	}
+	acct := &Account{}
+	if err := json.Unmarshal(secret.Data["registration.json"], acct); err != nil {
+		return nil, fmt.Errorf("secret %s: %w", secret.Metadata.Name, err)
+	}
+	block, _ := pem.Decode(secret.Data["account.key"])
+	if block == nil {
+		return nil, fmt.Errorf("error decoding account private key")
+	}
+	var key *ecdsa.PrivateKey
+	if key, err = x509.ParseECPrivateKey(block.Bytes); err != nil {
+		return nil, err
+	}
+	acct.key = key
+	return acct, nil
+}
+
+func (k *k8sStorage) StoreAccount(acmeHost string, account *Account) error {
+	acctBytes, err := json.MarshalIndent(account, "", "  ")
+	if err != nil {
+		return err
+	}
+	keyBytes, err := x509.MarshalECPrivateKey(account.key)
+	if err != nil {
+		return err
+	}
+	return k.client.putSecret(&k8sSecret{
+		Metadata: k8sObjectMeta{Name: k.accountSecret(acmeHost), Namespace: k.namespace},
+		Type:     "Opaque",
+		Data: map[string][]byte{
+			"registration.json": acctBytes,
+			"account.key":       pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyBytes}),
+		},
+	})
+}
+
+// k8sClient is a minimal client for the Secrets of the Kubernetes API.
+type k8sClient struct {
+	server    string
+	token     string
+	tokenFile string // read for every request, the token may be rotated
+	http      *http.Client
+}
+
+const serviceAccountDir = "/var/run/secrets/kubernetes.io/serviceaccount"
+
+// newK8sClient uses the in-cluster configuration when running in a pod,
+// and the current context of the kubeconfig otherwise.
+func newK8sClient() (*k8sClient, error) {
+	host, port := os.Getenv("KUBERNETES_SERVICE_HOST"), os.Getenv("KUBERNETES_SERVICE_PORT")
+	if host != "" && port != "" {
+		client := &k8sClient{
+			server:    "https://" + net.JoinHostPort(host, port),
+			tokenFile: filepath.Join(serviceAccountDir, "token"),
+		}
+		if _, err := client.bearerToken(); err != nil {
+			return nil, err
+		}
+		ca, err := ioutil.ReadFile(filepath.Join(serviceAccountDir, "ca.crt"))
+		if err != nil {
+			return nil, err
+		}
+		tlsConfig, err := k8sTLSConfig(ca, nil, nil, false)
+		if err != nil {
+			return nil, err
+		}
+		client.http = &http.Client{Transport: &http.Transport{TLSClientConfig: tlsConfig}}
+		return client, nil
+	}
+	return kubeconfigClient()
+}
+
+// kubeconfig is the subset of a kubeconfig file used here.
+type kubeconfig struct {
+	CurrentContext string `yaml:"current-context"`
+	Clusters       []struct {
+		Name    string `yaml:"name"`
+		Cluster struct {
+			Server                   string `yaml:"server"`
+			CertificateAuthority     string `yaml:"certificate-authority"`
+			CertificateAuthorityData string `yaml:"certificate-authority-data"`
+			InsecureSkipTLSVerify    bool   `yaml:"insecure-skip-tls-verify"`
+		} `yaml:"cluster"`
+	} `yaml:"clusters"`
+	Contexts []struct {
+		Name    string `yaml:"name"`
+		Context struct {
+			Cluster string `yaml:"cluster"`
+			User    string `yaml:"user"`
+		} `yaml:"context"`
+	} `yaml:"contexts"`
+	Users []struct {
+		Name string `yaml:"name"`
+		User struct {
+			Token                 string      `yaml:"token"`
+			TokenFile             string      `yaml:"tokenFile"`
+			ClientCertificate     string      `yaml:"client-certificate"`
+			ClientCertificateData string      `yaml:"client-certificate-data"`
+			ClientKey             string      `yaml:"client-key"`
+			ClientKeyData         string      `yaml:"client-key-data"`
+			Exec                  interface{} `yaml:"exec"`
+			AuthProvider          interface{} `yaml:"auth-provider"`
+		} `yaml:"user"`
+	} `yaml:"users"`
+}
+
+// kubeconfigClient reads the kubeconfig from $KUBECONFIG or ~/.kube/config.
+// Only tokens and client certificates are supported for authentication.
+// Relative file names in the kubeconfig are relative to its directory.
+func kubeconfigClient() (*k8sClient, error) {
+	path := strings.Split(os.Getenv("KUBECONFIG"), string(os.PathListSeparator))[0]
+	if path == "" {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return nil, err
+		}
+		path = filepath.Join(home, ".kube", "config")
+	}
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("not running in a cluster and no kubeconfig: %w", err)
+	}
+	kc := &kubeconfig{}
+	if err := yaml.Unmarshal(data, kc); err != nil {
+		return nil, fmt.Errorf("kubeconfig %s: %w", path, err)
+	}
+	resolve := func(file string) string {
+		if file == "" || filepath.IsAbs(file) {
+			return file
+		}
+		return filepath.Join(filepath.Dir(path), file)
+	}
+
+	var clusterName, userName string
+	for _, c := range kc.Contexts {
+		if c.Name == kc.CurrentContext {
+			clusterName, userName = c.Context.Cluster, c.Context.User
+		}
+	}
+	client := &k8sClient{}
+	var ca, cert, key []byte
+	var insecure bool
+	found := false
+	for _, c := range kc.Clusters {
+		if c.Name != clusterName {
+			continue
+		}
+		found = true
+		client.server = c.Cluster.Server
+		insecure = c.Cluster.InsecureSkipTLSVerify
+		if ca, err = kubeconfigData(c.Cluster.CertificateAuthorityData, resolve(c.Cluster.CertificateAuthority)); err != nil {
+			return nil, err
+		}
+	}
+	if !found {
+		return nil, fmt.Errorf("kubeconfig %s: no cluster for context %q", path, kc.CurrentContext)
+	}
+	for _, u := range kc.Users {
+		if u.Name != userName {
+			continue
+		}
+		if u.User.Exec != nil || u.User.AuthProvider != nil {
+			return nil, fmt.Errorf("kubeconfig %s: user %q uses an exec or auth provider plugin, which is not supported", path, userName)
+		}
+		client.token = u.User.Token
+		if u.User.TokenFile != "" {
+			client.tokenFile = resolve(u.User.TokenFile)
+			if _, err := client.bearerToken(); err != nil {
+				return nil, err
+			}
+		}
+		if cert, err = kubeconfigData(u.User.ClientCertificateData, resolve(u.User.ClientCertificate)); err != nil {
+			return nil, err
+		}
+		if key, err = kubeconfigData(u.User.ClientKeyData, resolve(u.User.ClientKey)); err != nil {
+			return nil, err
+		}
+	}
+	tlsConfig, err := k8sTLSConfig(ca, cert, key, insecure)
+	if err != nil {
+		return nil, err
+	}
+	client.http = &http.Client{Transport: &http.Transport{TLSClientConfig: tlsConfig}}
+	return client, nil
+}
+
+// kubeconfigData returns the base64 encoded data, or the content of file.
+func kubeconfigData(data, file string) ([]byte, error) {
+	if data != "" {
+		return base64.StdEncoding.DecodeString(data)
+	}
+	if file != "" {
+		return ioutil.ReadFile(file)
+	}
+	return nil, nil
+}
+
+func k8sTLSConfig(ca, cert, key []byte, insecure bool) (*tls.Config, error) {
+	config := &tls.Config{InsecureSkipVerify: insecure}
+	if len(ca) > 0 {
+		config.RootCAs = x509.NewCertPool()
+		if !config.RootCAs.AppendCertsFromPEM(ca) {
+			return nil, fmt.Errorf("no certificates found in the Kubernetes CA data")
+		}
+	}
+	if len(cert) > 0 {
+		pair, err := tls.X509KeyPair(cert, key)
+		if err != nil {
+			return nil, err
+		}
+		config.Certificates = []tls.Certificate{pair}
+	}
+	return config, nil
+}
+
+// bearerToken returns the token of the client, from its token file if
+// it has one.
+func (c *k8sClient) bearerToken() (string, error) {
+	if c.tokenFile == "" {
+		return c.token, nil
+	}
+	token, err := ioutil.ReadFile(c.tokenFile)
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimSpace(string(token)), nil
+}
+
+func (c *k8sClient) do(method, path string, body interface{}, target interface{}) (int, error) {
+	var reader *bytes.Reader
+	if body != nil {
+		data, err := json.Marshal(body)
+		if err != nil {
+			return 0, err
+		}
+		reader = bytes.NewReader(data)
+	} else {
+		reader = bytes.NewReader(nil)
+	}
+	req, err := http.NewRequest(method, c.server+path, reader)
+	if err != nil {
+		return 0, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Accept", "application/json")
+	token, err := c.bearerToken()
+	if err != nil {
+		return 0, err
+	}
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+	resp, err := c.http.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+	data, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return resp.StatusCode, err
+	}
+	if resp.StatusCode == http.StatusNotFound {
+		return resp.StatusCode, nil
+	}
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return resp.StatusCode, fmt.Errorf("kubernetes API: %s %s: %s: %s", method, path, resp.Status, strings.TrimSpace(string(data)))
+	}
+	if target != nil {
+		return resp.StatusCode, json.Unmarshal(data, target)
+	}
+	return resp.StatusCode, nil
+}
+
+func secretsPath(namespace string) string {
+	return "/api/v1/namespaces/" + namespace + "/secrets"
+}
+
+// getSecret returns the Secret, or nil if it does not exist.
+func (c *k8sClient) getSecret(namespace, name string) (*k8sSecret, error) {
+	secret := &k8sSecret{}
+	status, err := c.do("GET", secretsPath(namespace)+"/"+name, nil, secret)
+	if err != nil || status == http.StatusNotFound {
+		return nil, err
+	}
+	return secret, nil
+}
+
+// listSecrets returns the Secrets of type secretType.
+func (c *k8sClient) listSecrets(namespace, secretType string) ([]k8sSecret, error) {
+	list := &struct {
+		Items []k8sSecret `json:"items"`
+	}{}
+	query := url.Values{"fieldSelector": {"type=" + secretType}}
+	_, err := c.do("GET", secretsPath(namespace)+"?"+query.Encode(), nil, list)
+	return list.Items, err
+}
+
+// putSecret creates the Secret, or replaces it if it exists.
+func (c *k8sClient) putSecret(secret *k8sSecret) error {
+	secret.APIVersion, secret.Kind = "v1", "Secret"
+	existing, err := c.getSecret(secret.Metadata.Namespace, secret.Metadata.Name)
+	if err != nil {
+		return err
+	}
+	if existing == nil {
+		_, err = c.do("POST", secretsPath(secret.Metadata.Namespace), secret, nil)
+		return err
+	}
+	secret.Metadata.ResourceVersion = existing.Metadata.ResourceVersion
+	status, err := c.do("PUT", secretsPath(secret.Metadata.Namespace)+"/"+secret.Metadata.Name, secret, nil)
+	if err == nil && status == http.StatusNotFound {
+		return fmt.Errorf("secret %s was deleted while being updated", secret.Metadata.Name)
+	}
+	return err
+}
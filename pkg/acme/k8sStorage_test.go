@@ -0,0 +1,264 @@
+package acme
+
+import (
+	"bytes"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/go-acme/lego/certificate"
+)
+
+// fakeSecrets is an in-memory Secrets API of one namespace.
+func fakeSecrets(t *testing.T, secrets map[string]*k8sSecret) http.HandlerFunc {
+	const prefix = "/api/v1/namespaces/certs/secrets"
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer token" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		name := strings.TrimPrefix(strings.TrimPrefix(r.URL.Path, prefix), "/")
+		switch r.Method {
+		case "GET":
+			if name == "" {
+				// List the secrets of the type of the field selector.
+				list := []*k8sSecret{}
+				for _, s := range secrets {
+					if "type="+s.Type == r.URL.Query().Get("fieldSelector") {
+						list = append(list, s)
+					}
+				}
+				json.NewEncoder(w).Encode(map[string]interface{}{"items": list})
+				return
+			}
+			s, ok := secrets[name]
+			if !ok {
+				w.WriteHeader(http.StatusNotFound)
+				return
+			}
+			json.NewEncoder(w).Encode(s)
+		case "POST", "PUT":
+			s := &k8sSecret{}
+			if err := json.NewDecoder(r.Body).Decode(s); err != nil {
+				t.Fatal(err)
+			}
+			if r.Method == "POST" && secrets[s.Metadata.Name] != nil {
+				w.WriteHeader(http.StatusConflict)
+				return
+			}
+			if r.Method == "PUT" && (name != s.Metadata.Name || s.Metadata.ResourceVersion == "") {
+				w.WriteHeader(http.StatusBadRequest)
+				return
+			}
+			s.Metadata.ResourceVersion = "v" + s.Metadata.ResourceVersion
+			secrets[s.Metadata.Name] = s
+		}
+	}
+}
+
+func TestK8sStorage(t *testing.T) {
+	secrets := map[string]*k8sSecret{}
+	srv := httptest.NewServer(fakeSecrets(t, secrets))
+	defer srv.Close()
+	storage := &k8sStorage{
+		namespace:  "certs",
+		secretName: "dnscontrol",
+		client:     &k8sClient{server: srv.URL, token: "token", http: srv.Client()},
+	}
+
+	if cert, err := storage.GetCertificate("main_Cert"); err != nil || cert != nil {
+		t.Fatalf("expected no certificate, got %v %v", cert, err)
+	}
+	leaf := selfSigned(t, "leaf")
+	for i := 0; i < 2; i++ {
+		err := storage.StoreCertificate("main_Cert", &certificate.Resource{
+			Domain:      "example.com",
+			Certificate: leaf,
+			PrivateKey:  []byte("key"),
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	s := secrets["dnscontrol-main-cert"]
+	if s == nil || s.Type != "kubernetes.io/tls" || !bytes.Equal(s.Data["tls.crt"], leaf) || string(s.Data["tls.key"]) != "key" {
+		t.Fatalf("unexpected secret %+v", s)
+	}
+	cert, err := storage.GetCertificate("main_Cert")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cert.Domain != "example.com" || !bytes.Equal(cert.Certificate, leaf) || string(cert.PrivateKey) != "key" {
+		t.Errorf("unexpected certificate %+v", cert)
+	}
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := storage.StoreAccount("acme.example.com", &Account{Email: "a@example.com", key: key}); err != nil {
+		t.Fatal(err)
+	}
+	if secrets["dnscontrol-account-acme.example.com"] == nil {
+		t.Fatal("expected the account in a separate secret")
+	}
+	acct, err := storage.GetAccount("acme.example.com")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if acct.Email != "a@example.com" || acct.key.D.Cmp(key.D) != 0 {
+		t.Errorf("unexpected account %+v", acct)
+	}
+
+	// The account is not a certificate.
+	names, err := storage.ListCertificates()
+	if err != nil || len(names) != 1 || names[0] != "main-cert" {
+		t.Fatalf("expected to list main-cert, got %v, %v", names, err)
+	}
+	if cert, err := storage.GetCertificate(names[0]); err != nil || cert == nil {
+		t.Errorf("expected the listed name to find the certificate, got %v, %v", cert, err)
+	}
+}
+
+func TestKubeconfigClient(t *testing.T) {
+	dir, err := ioutil.TempDir("", "kube")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "config")
+	config := `
+current-context: prod
+clusters:
+- name: prod
+  cluster:
+    server: https://k8s.example.com:6443
+contexts:
+- name: prod
+  context:
+    cluster: prod
+    user: admin
+users:
+- name: admin
+  user:
+    token: secret
+`
+	if err := ioutil.WriteFile(path, []byte(config), 0600); err != nil {
+		t.Fatal(err)
+	}
+	defer os.Setenv("KUBECONFIG", os.Getenv("KUBECONFIG"))
+	os.Setenv("KUBECONFIG", path)
+
+	client, err := kubeconfigClient()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if client.server != "https://k8s.example.com:6443" || client.token != "secret" {
+		t.Errorf("unexpected client %+v", client)
+	}
+
+	config = strings.Replace(config, "token: secret", "exec: {command: kubelogin}", 1)
+	if err := ioutil.WriteFile(path, []byte(config), 0600); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := kubeconfigClient(); err == nil {
+		t.Error("expected an error for an exec plugin")
+	}
+}
+
+func TestK8sTokenFile(t *testing.T) {
+	srv := httptest.NewServer(fakeSecrets(t, map[string]*k8sSecret{}))
+	defer srv.Close()
+	dir, err := ioutil.TempDir("", "kube")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	tokenFile := filepath.Join(dir, "token")
+	storage := &k8sStorage{
+		namespace:  "certs",
+		secretName: "dnscontrol",
+		client:     &k8sClient{server: srv.URL, tokenFile: tokenFile, http: srv.Client()},
+	}
+
+	if err := ioutil.WriteFile(tokenFile, []byte("expired\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := storage.GetCertificate("www"); err == nil {
+		t.Fatal("expected the expired token to be rejected")
+	}
+	// The token was rotated.
+	if err := ioutil.WriteFile(tokenFile, []byte("token\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := storage.GetCertificate("www"); err != nil {
+		t.Errorf("expected the rotated token to be used, got %v", err)
+	}
+}
+
+func TestKubeconfigRelativePaths(t *testing.T) {
+	dir, err := ioutil.TempDir("", "kube")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	config := `
+current-context: prod
+clusters:
+- name: prod
+  cluster:
+    server: https://k8s.example.com:6443
+    certificate-authority: ca.crt
+contexts:
+- name: prod
+  context:
+    cluster: prod
+    user: admin
+users:
+- name: admin
+  user:
+    tokenFile: secrets/token
+`
+	files := map[string][]byte{
+		"config":        []byte(config),
+		"ca.crt":        selfSigned(t, "Cluster CA"),
+		"secrets/token": []byte("secret\n"),
+	}
+	if err := os.Mkdir(filepath.Join(dir, "secrets"), 0700); err != nil {
+		t.Fatal(err)
+	}
+	for name, data := range files {
+		if err := ioutil.WriteFile(filepath.Join(dir, name), data, 0600); err != nil {
+			t.Fatal(err)
+		}
+	}
+	defer os.Setenv("KUBECONFIG", os.Getenv("KUBECONFIG"))
+	os.Setenv("KUBECONFIG", filepath.Join(dir, "config"))
+
+	// The paths are not relative to the working directory.
+	client, err := kubeconfigClient()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if token, err := client.bearerToken(); err != nil || token != "secret" {
+		t.Errorf("expected the token of the token file, got %q, %v", token, err)
+	}
+	if client.http.Transport.(*http.Transport).TLSClientConfig.RootCAs == nil {
+		t.Error("expected the CA of the kubeconfig to be trusted")
+	}
+}
+
+func TestK8sAccountSecretName(t *testing.T) {
+	k := &k8sStorage{secretName: "dnscontrol"}
+	if got := k.accountSecret("acme-v02.api.letsencrypt.org/Me+certs@example.com"); got != "dnscontrol-account-acme-v02.api.letsencrypt.org-me-certs-at-example.com" {
+		t.Errorf("unexpected secret name %q", got)
+	}
+}
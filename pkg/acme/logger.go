@@ -0,0 +1,178 @@
+package acme
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"log"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	acmelog "github.com/go-acme/lego/log"
+)
+
+// Level is the severity of a log message.
+type Level int
+
+// The levels of log messages, from the most to the least verbose. The
+// zero value is LevelInfo.
+const (
+	LevelDebug Level = iota - 1
+	LevelInfo
+	LevelWarn
+	LevelError
+)
+
+var levelNames = []string{"debug", "info", "warn", "error"}
+
+func (l Level) String() string {
+	if l < LevelDebug || l > LevelError {
+		return fmt.Sprintf("Level(%d)", int(l))
+	}
+	return levelNames[l-LevelDebug]
+}
+
+// ParseLevel returns the level named s: debug, info, warn or error.
+func ParseLevel(s string) (Level, error) {
+	for i, name := range levelNames {
+		if strings.EqualFold(s, name) {
+			return Level(i) + LevelDebug, nil
+		}
+	}
+	return 0, fmt.Errorf("unknown log level %q, expected debug, info, warn or error", s)
+}
+
+// Logger receives the log messages of a Client, see WithLogger. The
+// messages are filtered by level before, see WithLogLevel.
+type Logger interface {
+	// Log logs a message of the progress of the issuance.
+	Log(level Level, msg string)
+	// Print writes a message that is part of the output, e.g. the
+	// corrections being run. It is at the info level.
+	Print(msg string)
+}
+
+// NewTextLogger returns the default Logger. It logs with the standard
+// log package, with a prefix for warnings and errors, and prints the
+// output to stdout.
+func NewTextLogger() Logger {
+	return textLogger{}
+}
+
+type textLogger struct{}
+
+func (textLogger) Log(level Level, msg string) {
+	switch level {
+	case LevelWarn:
+		msg = "WARNING: " + msg
+	case LevelError:
+		msg = "ERROR: " + msg
+	}
+	log.Println(msg)
+}
+
+func (textLogger) Print(msg string) {
+	fmt.Fprintln(os.Stdout, msg)
+}
+
+// NewJSONLogger returns a Logger that writes a JSON object per message to
+// w, e.g. {"time":"...","level":"info","msg":"..."}. The output is logged
+// like any other message.
+func NewJSONLogger(w io.Writer) Logger {
+	return &jsonLogger{w: w}
+}
+
+type jsonLogger struct {
+	mu sync.Mutex // concurrent issuances share the logger
+	w  io.Writer
+}
+
+type jsonEntry struct {
+	Time  string `json:"time"`
+	Level string `json:"level"`
+	Msg   string `json:"msg"`
+}
+
+func (l *jsonLogger) Log(level Level, msg string) {
+	b, err := json.Marshal(jsonEntry{
+		Time:  time.Now().UTC().Format(time.RFC3339),
+		Level: level.String(),
+		Msg:   msg,
+	})
+	if err != nil {
+		return
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.w.Write(append(b, '\n'))
+}
+
+func (l *jsonLogger) Print(msg string) {
+	l.Log(LevelInfo, msg)
+}
+
+// logf logs a message at level, if the level of c is not above it.
+func (c *certManager) logf(level Level, format string, args ...interface{}) {
+	if level < c.logLevel {
+		return
+	}
+	c.log().Log(level, fmt.Sprintf(format, args...))
+}
+
+func (c *certManager) debugf(format string, args ...interface{}) {
+	c.logf(LevelDebug, format, args...)
+}
+
+func (c *certManager) infof(format string, args ...interface{}) {
+	c.logf(LevelInfo, format, args...)
+}
+
+func (c *certManager) warnf(format string, args ...interface{}) {
+	c.logf(LevelWarn, format, args...)
+}
+
+func (c *certManager) errorf(format string, args ...interface{}) {
+	c.logf(LevelError, format, args...)
+}
+
+// printf writes a line of output, see Logger.Print.
+func (c *certManager) printf(format string, args ...interface{}) {
+	if LevelInfo < c.logLevel {
+		return
+	}
+	c.log().Print(strings.TrimSuffix(fmt.Sprintf(format, args...), "\n"))
+}
+
+// log returns the logger of c, see WithLogger.
+func (c *certManager) log() Logger {
+	if c.logger == nil {
+		return textLogger{}
+	}
+	return c.logger
+}
+
+// setVerbose enables the debug level, which includes the log of the ACME
+// client library, if verbose is set. Otherwise that log is discarded.
+func (c *certManager) setVerbose(verbose bool) {
+	if !verbose {
+		acmelog.Logger = log.New(ioutil.Discard, "", 0)
+		return
+	}
+	c.logLevel = LevelDebug
+	if _, ok := c.log().(textLogger); !ok {
+		acmelog.Logger = log.New(debugWriter{c}, "", 0)
+	}
+}
+
+// debugWriter logs what is written to it at the debug level.
+type debugWriter struct {
+	c *certManager
+}
+
+func (w debugWriter) Write(p []byte) (int, error) {
+	w.c.debugf("%s", strings.TrimSuffix(string(p), "\n"))
+	return len(p), nil
+}
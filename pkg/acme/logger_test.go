@@ -0,0 +1,63 @@
+package acme
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+func TestParseLevel(t *testing.T) {
+	for _, level := range []Level{LevelDebug, LevelInfo, LevelWarn, LevelError} {
+		got, err := ParseLevel(strings.ToUpper(level.String()))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got != level {
+			t.Errorf("expected %s, got %s", level, got)
+		}
+	}
+	if _, err := ParseLevel("verbose"); err == nil {
+		t.Errorf("expected an error for an unknown level")
+	}
+}
+
+func TestJSONLogger(t *testing.T) {
+	var buf bytes.Buffer
+	c := &certManager{}
+	for _, opt := range []Option{WithLogger(NewJSONLogger(&buf)), WithLogLevel(LevelWarn)} {
+		if err := opt(c); err != nil {
+			t.Fatal(err)
+		}
+	}
+	c.debugf("debug %d", 1)
+	c.infof("info %d", 2)
+	c.printf("%d corrections\n", 3)
+	c.warnf("warn %d", 4)
+	c.errorf("error %d", 5)
+
+	var got []string
+	for _, line := range strings.Split(strings.TrimSpace(buf.String()), "\n") {
+		var e jsonEntry
+		if err := json.Unmarshal([]byte(line), &e); err != nil {
+			t.Fatalf("invalid JSON %q: %s", line, err)
+		}
+		if e.Time == "" {
+			t.Errorf("expected a time in %q", line)
+		}
+		got = append(got, e.Level+" "+e.Msg)
+	}
+	want := []string{"warn warn 4", "error error 5"}
+	if strings.Join(got, "|") != strings.Join(want, "|") {
+		t.Errorf("expected %q, got %q", want, got)
+	}
+
+	// verbose enables the debug level.
+	buf.Reset()
+	c.setVerbose(true)
+	c.debugf("debug %d", 1)
+	c.printf("%d corrections\n", 3)
+	if !strings.Contains(buf.String(), `"level":"debug","msg":"debug 1"`) || !strings.Contains(buf.String(), `"level":"info","msg":"3 corrections"`) {
+		t.Errorf("expected debug and info messages, got %q", buf.String())
+	}
+}
@@ -0,0 +1,140 @@
+package acme
+
+import (
+	"fmt"
+
+	"github.com/go-acme/lego/certificate"
+)
+
+// MultiStorage implements Storage on top of several backends, for example
+// to keep certificates both on disk and in vault. Writes go to all
+// backends, reads are served by the first backend that has the data.
+type MultiStorage struct {
+	backends []Storage
+}
+
+// NewMultiStorage combines backends into a single Storage. The order of
+// backends is the order in which they are read from.
+func NewMultiStorage(backends ...Storage) *MultiStorage {
+	return &MultiStorage{backends: backends}
+}
+
+// GetCertificate returns the certificate from the first backend that has it.
+// Backends that fail are skipped, their error is only returned if no
+// backend has the certificate.
+func (m *MultiStorage) GetCertificate(name string) (*certificate.Resource, error) {
+	var firstErr error
+	for _, b := range m.backends {
+		cert, err := b.GetCertificate(name)
+		if err != nil {
+			if firstErr == nil {
+				firstErr = err
+			}
+			continue
+		}
+		if cert != nil {
+			return cert, nil
+		}
+	}
+	return nil, firstErr
+}
+
+// GetPrivateKey returns the private key of a certificate from the first
+// backend that has it.
+func (m *MultiStorage) GetPrivateKey(name string) ([]byte, error) {
+	var firstErr error
+	for _, b := range m.backends {
+		cert, err := b.GetCertificate(name)
+		if err == nil && cert != nil {
+			var key []byte
+			key, err = getPrivateKey(b, name, cert)
+			if err == nil && len(key) > 0 {
+				return key, nil
+			}
+		}
+		if err != nil && firstErr == nil {
+			firstErr = err
+		}
+	}
+	return nil, firstErr
+}
+
+// StoreCertificate stores the certificate in all backends. It fails if any
+// of the writes fail.
+func (m *MultiStorage) StoreCertificate(name string, cert *certificate.Resource) error {
+	for i, b := range m.backends {
+		if err := b.StoreCertificate(name, cert); err != nil {
+			return fmt.Errorf("storing certificate %s in storage backend %d: %w", name, i+1, err)
+		}
+	}
+	return nil
+}
+
+// DeleteCertificate deletes the certificate from all backends.
+func (m *MultiStorage) DeleteCertificate(name string) error {
+	for i, b := range m.backends {
+		if err := b.DeleteCertificate(name); err != nil {
+			return fmt.Errorf("deleting certificate %s from storage backend %d: %w", name, i+1, err)
+		}
+	}
+	return nil
+}
+
+// ListCertificates returns the names of the certificates of all backends.
+// Backends that fail are skipped, their error is only returned if all
+// backends fail.
+func (m *MultiStorage) ListCertificates() ([]string, error) {
+	var firstErr error
+	failed := 0
+	seen := map[string]bool{}
+	var names []string
+	for _, b := range m.backends {
+		list, err := b.ListCertificates()
+		if err != nil {
+			if firstErr == nil {
+				firstErr = err
+			}
+			failed++
+			continue
+		}
+		for _, name := range list {
+			if !seen[name] {
+				seen[name] = true
+				names = append(names, name)
+			}
+		}
+	}
+	if failed == len(m.backends) {
+		return nil, firstErr
+	}
+	return names, nil
+}
+
+// GetAccount returns the account from the first backend that has it.
+func (m *MultiStorage) GetAccount(acmeHost string) (*Account, error) {
+	var firstErr error
+	for _, b := range m.backends {
+		account, err := b.GetAccount(acmeHost)
+		if err != nil {
+			if firstErr == nil {
+				firstErr = err
+			}
+			continue
+		}
+		if account != nil {
+			return account, nil
+		}
+	}
+	return nil, firstErr
+}
+
+// StoreAccount stores the account in all backends. It fails if any of the
+// writes fail.
+func (m *MultiStorage) StoreAccount(acmeHost string, account *Account) error {
+	for i, b := range m.backends {
+		if err := b.StoreAccount(acmeHost, account); err != nil {
+			return fmt.Errorf("storing account in storage backend %d: %w", i+1, err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,154 @@
+package acme
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/go-acme/lego/certificate"
+)
+
+// memStorage is a Storage in memory. All its methods fail with err if set.
+type memStorage struct {
+	certs    map[string]*certificate.Resource
+	accounts map[string]*Account
+	err      error
+}
+
+func newMemStorage() *memStorage {
+	return &memStorage{certs: map[string]*certificate.Resource{}, accounts: map[string]*Account{}}
+}
+
+func (s *memStorage) GetCertificate(name string) (*certificate.Resource, error) {
+	return s.certs[name], s.err
+}
+
+func (s *memStorage) StoreCertificate(name string, cert *certificate.Resource) error {
+	if s.err != nil {
+		return s.err
+	}
+	s.certs[name] = cert
+	return nil
+}
+
+func (s *memStorage) DeleteCertificate(name string) error {
+	if s.err != nil {
+		return s.err
+	}
+	delete(s.certs, name)
+	return nil
+}
+
+func (s *memStorage) ListCertificates() ([]string, error) {
+	if s.err != nil {
+		return nil, s.err
+	}
+	var names []string
+	for name := range s.certs {
+		names = append(names, name)
+	}
+	return names, nil
+}
+
+func (s *memStorage) GetAccount(acmeHost string) (*Account, error) {
+	return s.accounts[acmeHost], s.err
+}
+
+func (s *memStorage) StoreAccount(acmeHost string, account *Account) error {
+	if s.err != nil {
+		return s.err
+	}
+	s.accounts[acmeHost] = account
+	return nil
+}
+
+func TestMultiStorageReads(t *testing.T) {
+	first, second := newMemStorage(), newMemStorage()
+	m := NewMultiStorage(first, second)
+
+	second.certs["www"] = &certificate.Resource{Domain: "second", PrivateKey: []byte("key2")}
+	second.accounts["acme.example.com"] = &Account{Email: "second@example.com"}
+	if cert, err := m.GetCertificate("www"); err != nil || cert == nil || cert.Domain != "second" {
+		t.Errorf("expected the certificate of the second backend, got %v, %v", cert, err)
+	}
+	if account, err := m.GetAccount("acme.example.com"); err != nil || account == nil || account.Email != "second@example.com" {
+		t.Errorf("expected the account of the second backend, got %v, %v", account, err)
+	}
+
+	// The first backend wins, even if it has the certificate without a key.
+	first.certs["www"] = &certificate.Resource{Domain: "first"}
+	if cert, err := m.GetCertificate("www"); err != nil || cert == nil || cert.Domain != "first" {
+		t.Errorf("expected the certificate of the first backend, got %v, %v", cert, err)
+	}
+	if key, err := m.GetPrivateKey("www"); err != nil || string(key) != "key2" {
+		t.Errorf("expected the key of the second backend, got %q, %v", key, err)
+	}
+
+	// Failing backends are skipped.
+	first.err = errors.New("first is down")
+	if cert, err := m.GetCertificate("www"); err != nil || cert == nil || cert.Domain != "second" {
+		t.Errorf("expected the certificate of the second backend, got %v, %v", cert, err)
+	}
+	if account, err := m.GetAccount("acme.example.com"); err != nil || account == nil {
+		t.Errorf("expected the account of the second backend, got %v, %v", account, err)
+	}
+
+	// Missing everywhere: the error of a failing backend is returned.
+	if cert, err := m.GetCertificate("missing"); cert != nil || err == nil || err.Error() != "first is down" {
+		t.Errorf("expected the error of the first backend, got %v, %v", cert, err)
+	}
+	first.err = nil
+	if cert, err := m.GetCertificate("missing"); cert != nil || err != nil {
+		t.Errorf("expected no certificate and no error, got %v, %v", cert, err)
+	}
+}
+
+func TestMultiStorageWrites(t *testing.T) {
+	first, second := newMemStorage(), newMemStorage()
+	m := NewMultiStorage(first, second)
+
+	cert := &certificate.Resource{Domain: "www.example.com"}
+	if err := m.StoreCertificate("www", cert); err != nil {
+		t.Fatal(err)
+	}
+	account := &Account{Email: "test@example.com"}
+	if err := m.StoreAccount("acme.example.com", account); err != nil {
+		t.Fatal(err)
+	}
+	for i, b := range []*memStorage{first, second} {
+		if b.certs["www"] != cert || b.accounts["acme.example.com"] != account {
+			t.Errorf("expected backend %d to have the certificate and the account", i+1)
+		}
+	}
+
+	second.certs["other"] = cert
+	names, err := m.ListCertificates()
+	sort.Strings(names)
+	if err != nil || fmt.Sprint(names) != "[other www]" {
+		t.Errorf("expected the certificates of all backends, got %v, %v", names, err)
+	}
+
+	if err := m.DeleteCertificate("www"); err != nil {
+		t.Fatal(err)
+	}
+	if first.certs["www"] != nil || second.certs["www"] != nil {
+		t.Error("expected the certificate to be deleted from all backends")
+	}
+
+	second.err = errors.New("second is down")
+	if err := m.StoreCertificate("www", cert); err == nil || !strings.Contains(err.Error(), "storage backend 2") {
+		t.Errorf("expected the write to fail at backend 2, got %v", err)
+	}
+	if err := m.StoreAccount("acme.example.com", account); err == nil || !strings.Contains(err.Error(), "storage backend 2") {
+		t.Errorf("expected the write to fail at backend 2, got %v", err)
+	}
+	if names, err := m.ListCertificates(); err != nil || fmt.Sprint(names) != "[www]" {
+		t.Errorf("expected the certificates of the first backend, got %v, %v", names, err)
+	}
+	first.err = errors.New("first is down")
+	if _, err := m.ListCertificates(); err == nil {
+		t.Error("expected an error if all backends fail")
+	}
+}
@@ -0,0 +1,297 @@
+package acme
+
+import (
+	"context"
+	"crypto/x509"
+	"encoding/pem"
+	"fmt"
+	"strings"
+
+	"github.com/StackExchange/dnscontrol/v3/models"
+	"github.com/gobwas/glob"
+)
+
+// Option configures optional behavior of a Client.
+type Option func(*certManager) error
+
+// WithContext sets the context of the requests to the DNS providers.
+// Cancelling it aborts the computation of corrections at providers that
+// support it, see providers.DNSServiceProviderContext. The challenge
+// records are cleaned up regardless.
+func WithContext(ctx context.Context) Option {
+	return func(c *certManager) error {
+		c.ctx = ctx
+		return nil
+	}
+}
+
+// WithLogger sets the logger of the messages of the client, e.g. the
+// logger of NewJSONLogger. The default is the logger of NewTextLogger.
+func WithLogger(l Logger) Option {
+	return func(c *certManager) error {
+		c.logger = l
+		return nil
+	}
+}
+
+// WithLogLevel sets the lowest level of the messages that are logged. The
+// default is LevelInfo; the verbose argument of IssueOrRenewCert enables
+// LevelDebug.
+func WithLogLevel(level Level) Option {
+	return func(c *certManager) error {
+		if level < LevelDebug || level > LevelError {
+			return fmt.Errorf("invalid log level %s", level)
+		}
+		c.logLevel = level
+		return nil
+	}
+}
+
+// WithPreCheckResolvers sets the DNS resolvers used to verify that
+// the challenge records are visible before asking the ACME server to
+// validate them. Addresses may omit the port; 53 is assumed. This is
+// useful in split-horizon setups, where the local resolver sees a
+// different view than the public authoritative nameservers.
+func WithPreCheckResolvers(resolvers []string) Option {
+	return func(c *certManager) error {
+		for _, r := range resolvers {
+			if r == "" {
+				return fmt.Errorf("empty resolver address")
+			}
+		}
+		c.preCheckResolvers = resolvers
+		return nil
+	}
+}
+
+// WithAuthoritativeNameservers sets the nameservers (host or host:port)
+// that the DNS pre-check queries directly for the challenge records. By
+// default the nameservers of the domain in the config are queried, so
+// that recursive resolvers caching an old value do not delay issuing.
+func WithAuthoritativeNameservers(servers []string) Option {
+	return func(c *certManager) error {
+		for _, s := range servers {
+			if s == "" {
+				return fmt.Errorf("empty nameserver address")
+			}
+		}
+		c.authoritativeNameservers = servers
+		return nil
+	}
+}
+
+// WithoutPreCheck disables checking that challenge records are visible
+// before asking the ACME server to validate them. Use this only for
+// providers whose changes are visible on all nameservers right away.
+func WithoutPreCheck() Option {
+	return func(c *certManager) error {
+		c.skipPreCheck = true
+		return nil
+	}
+}
+
+// WithForcePendingCorrections makes issuing proceed even if the config
+// and the zones differ. The pending corrections are printed as warnings
+// and not run: only the challenge records of such domains are changed, as
+// for ChallengeOnlyDomains. Pending changes of records of the types in
+// except (e.g. TXT) still abort issuing.
+func WithForcePendingCorrections(except []string) Option {
+	return func(c *certManager) error {
+		c.forcePending = true
+		c.forcePendingExcept = map[string]bool{}
+		for _, t := range except {
+			if t == "" {
+				return fmt.Errorf("empty record type")
+			}
+			c.forcePendingExcept[strings.ToUpper(t)] = true
+		}
+		return nil
+	}
+}
+
+// WithMaxFailedChecks makes the DNS pre-check give up after about n
+// failed checks (one per second) instead of polling for five minutes.
+// The error then lists what each authoritative nameserver returned.
+func WithMaxFailedChecks(n int) Option {
+	return func(c *certManager) error {
+		if n < 1 {
+			return fmt.Errorf("max failed checks must be at least 1, got %d", n)
+		}
+		c.maxFailedChecks = n
+		return nil
+	}
+}
+
+// WithRenewalJitter spreads renewals over a window of 2*days+1 days
+// around the renewUnder threshold, so that certificates issued together
+// are not all renewed together. The offset of each certificate is
+// derived from its name and does not change between runs.
+func WithRenewalJitter(days int) Option {
+	return func(c *certManager) error {
+		if days < 0 {
+			return fmt.Errorf("renewal jitter must not be negative, got %d", days)
+		}
+		c.renewalJitter = days
+		return nil
+	}
+}
+
+// WithExpiryWarning sends a notification if an existing certificate
+// expires in less than days, unless it is renewed successfully. This is
+// independent of the renewUnder threshold, and warns when renewing fails
+// for several runs in a row.
+func WithExpiryWarning(days int) Option {
+	return func(c *certManager) error {
+		if days < 0 {
+			return fmt.Errorf("expiry warning must not be negative, got %d", days)
+		}
+		c.expiryWarning = days
+		return nil
+	}
+}
+
+// WithCABundle makes the ACME client trust the CA certificates in
+// pemData (in addition to the system roots) when connecting to the
+// ACME server. Use this for internal ACME CAs with a private root,
+// instead of disabling TLS verification.
+func WithCABundle(pemData []byte) Option {
+	return func(c *certManager) error {
+		pool, err := x509.SystemCertPool()
+		if err != nil || pool == nil {
+			pool = x509.NewCertPool()
+		}
+		n := 0
+		for block, rest := pem.Decode(pemData); block != nil; block, rest = pem.Decode(rest) {
+			if block.Type != "CERTIFICATE" {
+				continue
+			}
+			cert, err := x509.ParseCertificate(block.Bytes)
+			if err != nil {
+				return fmt.Errorf("invalid certificate in CA bundle: %w", err)
+			}
+			pool.AddCert(cert)
+			n++
+		}
+		if n == 0 {
+			return fmt.Errorf("CA bundle contains no PEM encoded certificates")
+		}
+		c.caPool = pool
+		return nil
+	}
+}
+
+// WithForcePurgeLabels makes the final clean up remove all records at
+// labels matching one of the globs (e.g. "_acme-challenge*") that are not
+// in the configuration, even for NO_PURGE domains. The globs match the
+// short name of the record, * also matches dots.
+func WithForcePurgeLabels(labels []string) Option {
+	return func(c *certManager) error {
+		for _, l := range labels {
+			g, err := glob.Compile(l)
+			if err != nil {
+				return fmt.Errorf("invalid label glob %q: %w", l, err)
+			}
+			c.forcePurgeLabels = append(c.forcePurgeLabels, g)
+		}
+		return nil
+	}
+}
+
+// WithSCTCheck makes the client check newly issued certificates for
+// embedded signed certificate timestamps, and log a warning if there are
+// none. Not all CAs embed SCTs, so a missing SCT is not an error.
+func WithSCTCheck() Option {
+	return func(c *certManager) error {
+		c.checkSCT = true
+		return nil
+	}
+}
+
+// WithFailFunc makes corrections fail with the error returned by f,
+// instead of running them, whenever f returns non-nil. It is meant for
+// testing the error handling of the challenge flow.
+func WithFailFunc(f models.FailFunc) Option {
+	return func(c *certManager) error {
+		c.failFunc = f
+		return nil
+	}
+}
+
+// WithCAACheck makes the client check the CAA records of all names of a
+// certificate before ordering it. Issuing fails early if the records do
+// not permit the CA with identifier (i.e. "letsencrypt.org") to issue.
+func WithCAACheck(identifier string) Option {
+	return func(c *certManager) error {
+		if identifier == "" {
+			return fmt.Errorf("empty CA identifier for CAA check")
+		}
+		c.caaIdentifier = identifier
+		return nil
+	}
+}
+
+// WithExternalAccountBinding registers new accounts with an external
+// account binding, as required by CAs like ZeroSSL. kid is the key ID and
+// hmac the base64url encoded HMAC key issued by the CA. Existing accounts
+// are used as they are.
+func WithExternalAccountBinding(kid, hmac string) Option {
+	return func(c *certManager) error {
+		if kid == "" || hmac == "" {
+			return fmt.Errorf("external account binding needs both a key ID and an HMAC key")
+		}
+		c.eabKID = kid
+		c.eabHMAC = hmac
+		return nil
+	}
+}
+
+// WithImportedAccount uses the account serialized with
+// Client.ExportAccount instead of the one in the storage, and saves it
+// there. No account is registered, the ACME server is not contacted. The
+// account must belong to the email and server of the client.
+func WithImportedAccount(data []byte) Option {
+	return func(c *certManager) error {
+		account, err := ImportAccount(data)
+		if err != nil {
+			return err
+		}
+		c.importedAccount = account
+		return nil
+	}
+}
+
+// WithVaultKVVersion sets the version (1 or 2) of the KV secrets engine
+// of the vault storage, instead of detecting it.
+func WithVaultKVVersion(version int) Option {
+	return func(c *certManager) error {
+		if version != 1 && version != 2 {
+			return fmt.Errorf("invalid KV version %d, must be 1 or 2", version)
+		}
+		v := findVaultStorage(c.storage)
+		if v == nil {
+			return fmt.Errorf("a KV version is set, but certificates are not stored in vault")
+		}
+		v.kvVersion = version
+		return nil
+	}
+}
+
+// WithVaultSecretVersions makes the vault storage read the given versions
+// of certificates, by name, e.g. to deploy a previous certificate. It
+// needs KV version 2. Storing a certificate still adds a new version, and
+// is not read back as long as an older version is pinned.
+func WithVaultSecretVersions(versions map[string]int) Option {
+	return func(c *certManager) error {
+		v := findVaultStorage(c.storage)
+		if v == nil {
+			return fmt.Errorf("secret versions are set, but certificates are not stored in vault")
+		}
+		for name, version := range versions {
+			if version < 1 {
+				return fmt.Errorf("invalid version %d of certificate %s", version, name)
+			}
+		}
+		v.versions = versions
+		return nil
+	}
+}
@@ -5,26 +5,55 @@ import (
 	"crypto/ecdsa"
 	"crypto/elliptic"
 	"crypto/rand"
+	"crypto/x509"
+	"encoding/json"
+	"encoding/pem"
+	"fmt"
+	"net/url"
+	"strings"
 
 	"github.com/go-acme/lego/certcrypto"
 	"github.com/go-acme/lego/lego"
 	"github.com/go-acme/lego/registration"
 )
 
+// accountKey is the key of the account in the storage. Accounts are kept
+// per ACME server and email, e.g. "acme-v02.api.letsencrypt.org/me@example.com",
+// so that several accounts can share a storage.
+func (c *certManager) accountKey() string {
+	if c.email == "" {
+		return c.acmeHost
+	}
+	return c.acmeHost + "/" + strings.ToLower(c.email)
+}
+
 func (c *certManager) getOrCreateAccount() (*Account, error) {
-	account, err := c.storage.GetAccount(c.acmeHost)
+	key := c.accountKey()
+	account, err := c.storage.GetAccount(key)
 	if err != nil {
 		return nil, err
 	}
 	if account != nil {
 		return account, nil
 	}
+	if key != c.acmeHost {
+		// Accounts used to be stored per ACME server only. Move such an
+		// account to its key, unless it is for another email.
+		account, err = c.storage.GetAccount(c.acmeHost)
+		if err != nil {
+			return nil, err
+		}
+		if account != nil && strings.EqualFold(account.Email, c.email) {
+			c.infof("Moving the account of %s at %s to %s", account.Email, c.acmeHost, key)
+			return account, c.storage.StoreAccount(key, account)
+		}
+	}
 	// register new
 	account, err = c.createAccount(c.email)
 	if err != nil {
 		return nil, err
 	}
-	err = c.storage.StoreAccount(c.acmeHost, account)
+	err = c.storage.StoreAccount(key, account)
 	return account, err
 }
 
@@ -37,14 +66,24 @@ func (c *certManager) createAccount(email string) (*Account, error) {
 		key:   privateKey,
 		Email: c.email,
 	}
-	config := lego.NewConfig(acct)
-	config.CADirURL = c.acmeDirectory
+	config := c.legoConfig(acct)
 	config.Certificate.KeyType = certcrypto.EC384
 	client, err := lego.NewClient(config)
 	if err != nil {
 		return nil, err
 	}
-	reg, err := client.Registration.Register(registration.RegisterOptions{TermsOfServiceAgreed: true})
+	var reg *registration.Resource
+	if c.eabKID != "" {
+		// The binding is only needed to register, the stored account is
+		// used for renewals without it.
+		reg, err = client.Registration.RegisterWithExternalAccountBinding(registration.RegisterEABOptions{
+			TermsOfServiceAgreed: true,
+			Kid:                  c.eabKID,
+			HmacEncoded:          c.eabHMAC,
+		})
+	} else {
+		reg, err = client.Registration.Register(registration.RegisterOptions{TermsOfServiceAgreed: true})
+	}
 	if err != nil {
 		return nil, err
 	}
@@ -73,3 +112,89 @@ func (a *Account) GetPrivateKey() crypto.PrivateKey {
 func (a *Account) GetRegistration() *registration.Resource {
 	return a.Registration
 }
+
+// portableAccount is the serialized form of an Account, including its
+// private key, used to move an account between storage backends.
+type portableAccount struct {
+	Email        string                 `json:"email"`
+	Registration *registration.Resource `json:"registration"`
+	Key          string                 `json:"key"`
+}
+
+// Export serializes the account, including its private key. The result
+// is sensitive and must be protected like the key itself.
+func (a *Account) Export() ([]byte, error) {
+	if a.key == nil {
+		return nil, fmt.Errorf("account has no private key")
+	}
+	keyBytes, err := x509.MarshalECPrivateKey(a.key)
+	if err != nil {
+		return nil, err
+	}
+	pemKey := &pem.Block{Type: "EC PRIVATE KEY", Bytes: keyBytes}
+	return json.MarshalIndent(portableAccount{
+		Email:        a.Email,
+		Registration: a.Registration,
+		Key:          string(pem.EncodeToMemory(pemKey)),
+	}, "", "  ")
+}
+
+// ImportAccount parses an account serialized with Export.
+func ImportAccount(data []byte) (*Account, error) {
+	p := &portableAccount{}
+	if err := json.Unmarshal(data, p); err != nil {
+		return nil, err
+	}
+	if p.Registration == nil {
+		return nil, fmt.Errorf("account has no registration")
+	}
+	keyBlock, _ := pem.Decode([]byte(p.Key))
+	if keyBlock == nil {
+		return nil, fmt.Errorf("error decoding account private key")
+	}
+	key, err := x509.ParseECPrivateKey(keyBlock.Bytes)
+	if err != nil {
+		return nil, err
+	}
+	return &Account{
+		Email:        p.Email,
+		Registration: p.Registration,
+		key:          key,
+	}, nil
+}
+
+// ExportAccount serializes the account used for the ACME server.
+func (c *certManager) ExportAccount() ([]byte, error) {
+	return c.account.Export()
+}
+
+// ImportAccount replaces the account used for the ACME server with one
+// serialized by ExportAccount, and saves it in the storage. Use
+// WithImportedAccount to import an account into an empty storage.
+func (c *certManager) ImportAccount(data []byte) error {
+	account, err := ImportAccount(data)
+	if err != nil {
+		return err
+	}
+	return c.storeAccount(account)
+}
+
+// storeAccount checks that an imported account belongs to the email and
+// server of c, saves it in the storage and uses it.
+func (c *certManager) storeAccount(account *Account) error {
+	if c.email != "" && !strings.EqualFold(account.Email, c.email) {
+		return fmt.Errorf("the imported account is for %q, not %q", account.Email, c.email)
+	}
+	u, err := url.Parse(account.Registration.URI)
+	if err != nil || u.Host == "" {
+		return fmt.Errorf("the imported account has no valid registration URI: %q", account.Registration.URI)
+	}
+	if !strings.EqualFold(u.Host, c.acmeHost) {
+		return fmt.Errorf("the imported account is registered at %s, not %s", u.Host, c.acmeHost)
+	}
+	if err := c.storage.StoreAccount(c.accountKey(), account); err != nil {
+		return err
+	}
+	c.account = account
+	return nil
+}
@@ -0,0 +1,176 @@
+package acme
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/go-acme/lego/registration"
+)
+
+func TestAccountExportImport(t *testing.T) {
+	key, err := ecdsa.GenerateKey(elliptic.P384(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	dir, err := ioutil.TempDir("", "acme")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	source := &certManager{
+		acmeHost: "acme.example.com",
+		account: &Account{
+			Email:        "test@example.com",
+			Registration: &registration.Resource{URI: "https://acme.example.com/acct/1"},
+			key:          key,
+		},
+	}
+	data, err := source.ExportAccount()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	target := &certManager{
+		acmeHost: "acme.example.com",
+		storage:  directoryStorage(dir),
+	}
+	if err := target.ImportAccount(data); err != nil {
+		t.Fatal(err)
+	}
+	stored, err := target.storage.GetAccount("acme.example.com")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, acct := range []*Account{target.account, stored} {
+		if acct.Email != "test@example.com" {
+			t.Errorf("expected email %q, got %q", "test@example.com", acct.Email)
+		}
+		if !reflect.DeepEqual(acct.Registration, source.account.Registration) {
+			t.Errorf("expected registration %+v, got %+v", source.account.Registration, acct.Registration)
+		}
+		if acct.key == nil || key.D.Cmp(acct.key.D) != 0 {
+			t.Error("private key was not preserved")
+		}
+	}
+
+	if _, err := ImportAccount([]byte(`{"email":"x","registration":{},"key":"garbage"}`)); err == nil {
+		t.Error("expected an error for an invalid key")
+	}
+}
+
+func TestAccountPerServerAndEmail(t *testing.T) {
+	key, err := ecdsa.GenerateKey(elliptic.P384(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	dir, err := ioutil.TempDir("", "acme")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	storage := directoryStorage(dir)
+
+	// An account stored by an older version, per server only.
+	old := &Account{
+		Email:        "Test@example.com",
+		Registration: &registration.Resource{URI: "https://acme.example.com/acct/1"},
+		key:          key,
+	}
+	if err := storage.StoreAccount("acme.example.com", old); err != nil {
+		t.Fatal(err)
+	}
+
+	c := &certManager{acmeHost: "acme.example.com", email: "test@example.com", storage: storage}
+	if got := c.accountKey(); got != "acme.example.com/test@example.com" {
+		t.Errorf("unexpected account key %q", got)
+	}
+	acct, err := c.getOrCreateAccount()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if acct.Registration.URI != old.Registration.URI {
+		t.Errorf("expected the old account to be used, got %+v", acct.Registration)
+	}
+	moved, err := storage.GetAccount("acme.example.com/test@example.com")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if moved == nil || moved.Registration.URI != old.Registration.URI {
+		t.Error("expected the old account to be stored at its new key")
+	}
+
+	// Accounts of other servers and emails are kept apart.
+	other := &certManager{acmeHost: "ca.internal", email: "test@example.com", storage: storage}
+	if acct, err := storage.GetAccount(other.accountKey()); err != nil || acct != nil {
+		t.Errorf("expected no account for another server, got %v, %v", acct, err)
+	}
+}
+
+func TestImportAccountWithoutRegistering(t *testing.T) {
+	requests := 0
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		w.WriteHeader(500)
+	}))
+	defer srv.Close()
+	host := strings.TrimPrefix(srv.URL, "http://")
+
+	key, err := ecdsa.GenerateKey(elliptic.P384(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	export := func(email, uri string) []byte {
+		data, err := (&Account{Email: email, Registration: &registration.Resource{URI: uri}, key: key}).Export()
+		if err != nil {
+			t.Fatal(err)
+		}
+		return data
+	}
+	dir, err := ioutil.TempDir("", "acme")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	storage := directoryStorage(dir)
+
+	for _, tst := range []struct {
+		desc string
+		data []byte
+	}{
+		{"other email", export("other@example.com", srv.URL+"/acct/1")},
+		{"other server", export("test@example.com", "https://acme.example.com/acct/1")},
+		{"no registration URI", export("test@example.com", "")},
+	} {
+		if _, err := commonNew(nil, storage, "test@example.com", srv.URL+"/directory", nil, WithImportedAccount(tst.data)); err == nil {
+			t.Errorf("%s: expected an error", tst.desc)
+		}
+	}
+
+	// The storage is empty, yet no account is registered.
+	client, err := commonNew(nil, storage, "Test@example.com", srv.URL+"/directory", nil, WithImportedAccount(export("test@example.com", srv.URL+"/acct/1")))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if requests != 0 {
+		t.Errorf("expected no request to the ACME server, got %d", requests)
+	}
+	stored, err := storage.GetAccount(host + "/test@example.com")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if stored == nil || stored.Registration.URI != srv.URL+"/acct/1" || key.D.Cmp(stored.key.D) != 0 {
+		t.Errorf("expected the imported account to be stored, got %+v", stored)
+	}
+	if client.(*certManager).account.Registration.URI != srv.URL+"/acct/1" {
+		t.Error("expected the imported account to be used")
+	}
+}
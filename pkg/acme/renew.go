@@ -0,0 +1,76 @@
+package acme
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// RenewUnder is the threshold for renewing a certificate: either a number
+// of days remaining, or a percentage of its total validity remaining.
+// Percentages suit a mix of CAs with different lifetimes, e.g. "33%"
+// renews a 90 day certificate with 30 days left and a one year
+// certificate with about 120 days left.
+type RenewUnder struct {
+	Days    int
+	Percent int // 1 to 99, used instead of Days if set.
+}
+
+// ParseRenewUnder parses a number of days, e.g. "15", or a percentage,
+// e.g. "33%".
+func ParseRenewUnder(s string) (RenewUnder, error) {
+	s = strings.TrimSpace(s)
+	if p := strings.TrimSuffix(s, "%"); p != s {
+		percent, err := strconv.Atoi(strings.TrimSpace(p))
+		if err != nil || percent < 1 || percent > 99 {
+			return RenewUnder{}, fmt.Errorf("invalid renewal threshold %q, a percentage must be between 1%% and 99%%", s)
+		}
+		return RenewUnder{Percent: percent}, nil
+	}
+	days, err := strconv.Atoi(s)
+	if err != nil || days < 0 {
+		return RenewUnder{}, fmt.Errorf("invalid renewal threshold %q, expected days (e.g. 15) or a percentage (e.g. 33%%)", s)
+	}
+	return RenewUnder{Days: days}, nil
+}
+
+// UnmarshalJSON accepts a number of days, e.g. 15, or a string as for
+// ParseRenewUnder, e.g. "33%".
+func (r *RenewUnder) UnmarshalJSON(b []byte) error {
+	var days int
+	if err := json.Unmarshal(b, &days); err == nil {
+		parsed, err := ParseRenewUnder(strconv.Itoa(days))
+		if err != nil {
+			return err
+		}
+		*r = parsed
+		return nil
+	}
+	var s string
+	if err := json.Unmarshal(b, &s); err != nil {
+		return fmt.Errorf("invalid renewal threshold %s, expected days (e.g. 15) or a percentage (e.g. \"33%%\")", b)
+	}
+	parsed, err := ParseRenewUnder(s)
+	if err != nil {
+		return err
+	}
+	*r = parsed
+	return nil
+}
+
+func (r RenewUnder) String() string {
+	if r.Percent > 0 {
+		return fmt.Sprintf("%d%%", r.Percent)
+	}
+	return strconv.Itoa(r.Days)
+}
+
+// days returns the threshold in days for a certificate with a total
+// validity of lifetime days.
+func (r RenewUnder) days(lifetime float64) float64 {
+	if r.Percent > 0 {
+		return lifetime * float64(r.Percent) / 100
+	}
+	return float64(r.Days)
+}
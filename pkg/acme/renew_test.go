@@ -0,0 +1,64 @@
+package acme
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestParseRenewUnder(t *testing.T) {
+	tests := []struct {
+		in      string
+		want    RenewUnder
+		wantErr bool
+	}{
+		{"15", RenewUnder{Days: 15}, false},
+		{"0", RenewUnder{}, false},
+		{"33%", RenewUnder{Percent: 33}, false},
+		{" 1% ", RenewUnder{Percent: 1}, false},
+		{"99%", RenewUnder{Percent: 99}, false},
+		{"0%", RenewUnder{}, true},
+		{"100%", RenewUnder{}, true},
+		{"-1", RenewUnder{}, true},
+		{"a third", RenewUnder{}, true},
+	}
+	for _, tst := range tests {
+		got, err := ParseRenewUnder(tst.in)
+		if (err != nil) != tst.wantErr {
+			t.Errorf("%q: unexpected error %v", tst.in, err)
+			continue
+		}
+		if got != tst.want {
+			t.Errorf("%q: expected %+v, got %+v", tst.in, tst.want, got)
+		}
+	}
+}
+
+func TestRenewUnderJSON(t *testing.T) {
+	var certs []*CertConfig
+	data := `[{"cert_name":"days","renew_under":30},{"cert_name":"percent","renew_under":"33%"},{"cert_name":"global"}]`
+	if err := json.Unmarshal([]byte(data), &certs); err != nil {
+		t.Fatal(err)
+	}
+	if *certs[0].RenewUnder != (RenewUnder{Days: 30}) || *certs[1].RenewUnder != (RenewUnder{Percent: 33}) || certs[2].RenewUnder != nil {
+		t.Errorf("unexpected thresholds %v %v %v", certs[0].RenewUnder, certs[1].RenewUnder, certs[2].RenewUnder)
+	}
+	for _, invalid := range []string{`"150%"`, `true`, `-3`} {
+		var r RenewUnder
+		if err := json.Unmarshal([]byte(invalid), &r); err == nil {
+			t.Errorf("%s: expected an error", invalid)
+		}
+	}
+}
+
+func TestRenewUnderDays(t *testing.T) {
+	third := RenewUnder{Percent: 33}
+	if got := third.days(90); got < 29.69 || got > 29.71 {
+		t.Errorf("expected 33%% of 90 days to be 29.7 days, got %v", got)
+	}
+	if got := third.days(365); got < 120.44 || got > 120.46 {
+		t.Errorf("expected 33%% of 365 days to be 120.45 days, got %v", got)
+	}
+	if got := (RenewUnder{Days: 15}).days(365); got != 15 {
+		t.Errorf("expected 15 days regardless of the validity, got %v", got)
+	}
+}
@@ -0,0 +1,88 @@
+package acme
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"fmt"
+
+	"github.com/go-acme/lego/lego"
+)
+
+// RevocationReasons are the reason codes of RFC 5280, section 5.3.1, by
+// name. Code 7 is not used.
+var RevocationReasons = map[string]int{
+	"unspecified":          0,
+	"keyCompromise":        1,
+	"cACompromise":         2,
+	"affiliationChanged":   3,
+	"superseded":           4,
+	"cessationOfOperation": 5,
+	"certificateHold":      6,
+	"removeFromCRL":        8,
+	"privilegeWithdrawn":   9,
+	"aACompromise":         10,
+}
+
+func validRevocationReason(reason int) bool {
+	for _, r := range RevocationReasons {
+		if r == reason {
+			return true
+		}
+	}
+	return false
+}
+
+// RevokeCert revokes a stored certificate with an RFC 5280 reason code
+// and deletes it from the storage, so that the next run issues a new one.
+func (c *certManager) RevokeCert(certName string, reason int) error {
+	if !validRevocationReason(reason) {
+		return fmt.Errorf("%d is not a valid revocation reason", reason)
+	}
+	cert, err := c.storage.GetCertificate(certName)
+	if err != nil {
+		return err
+	}
+	if cert == nil {
+		return fmt.Errorf("certificate %s not found", certName)
+	}
+	if reason == 0 {
+		var client *lego.Client
+		if client, err = lego.NewClient(c.legoConfig(c.account)); err == nil {
+			err = client.Certificate.Revoke(cert.Certificate)
+		}
+	} else {
+		// The ACME client library can not send a reason.
+		err = c.revokeWithReason(cert.Certificate, reason)
+	}
+	if err != nil {
+		return fmt.Errorf("revoking certificate %s: %w", certName, err)
+	}
+	c.infof("Revoked certificate %s", certName)
+	if err := c.storage.DeleteCertificate(certName); err != nil {
+		return fmt.Errorf("certificate %s was revoked, but deleting it failed: %w", certName, err)
+	}
+	return nil
+}
+
+// revokeWithReason sends a revocation request (RFC 8555, section 7.6) for
+// the first certificate of bundle.
+func (c *certManager) revokeWithReason(bundle []byte, reason int) error {
+	block, _ := pem.Decode(bundle)
+	if block == nil {
+		return fmt.Errorf("invalid certificate PEM data")
+	}
+	payload, err := json.Marshal(struct {
+		Certificate string `json:"certificate"`
+		Reason      int    `json:"reason"`
+	}{base64.RawURLEncoding.EncodeToString(block.Bytes), reason})
+	if err != nil {
+		return err
+	}
+	j := c.jwsClient(c.legoConfig(nil).HTTPClient)
+	if err := j.getDirectory(); err != nil {
+		return err
+	}
+	_, _, err = j.post(j.directory.RevokeCert, payload)
+	return err
+}
@@ -0,0 +1,77 @@
+package acme
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+
+	"github.com/go-acme/lego/certificate"
+	"github.com/go-acme/lego/registration"
+)
+
+func TestRevokeCert(t *testing.T) {
+	var revoked struct {
+		Certificate string `json:"certificate"`
+		Reason      int    `json:"reason"`
+	}
+	var srv *httptest.Server
+	mux := http.NewServeMux()
+	mux.HandleFunc("/directory", func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(map[string]string{"newNonce": srv.URL + "/nonce", "revokeCert": srv.URL + "/revoke"})
+	})
+	mux.HandleFunc("/nonce", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Replay-Nonce", "nonce")
+	})
+	mux.HandleFunc("/revoke", func(w http.ResponseWriter, r *http.Request) {
+		jws := map[string]string{}
+		json.NewDecoder(r.Body).Decode(&jws)
+		payload, _ := base64.RawURLEncoding.DecodeString(jws["payload"])
+		if err := json.Unmarshal(payload, &revoked); err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+		}
+	})
+	srv = httptest.NewServer(mux)
+	defer srv.Close()
+
+	dir, err := ioutil.TempDir("", "acme")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	storage := directoryStorage(dir)
+	if err := storage.StoreCertificate("mainCert", &certificate.Resource{Certificate: selfSigned(t, "example.com")}); err != nil {
+		t.Fatal(err)
+	}
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	c := &certManager{
+		acmeDirectory: srv.URL + "/directory",
+		storage:       storage,
+		account:       &Account{key: key, Registration: &registration.Resource{URI: srv.URL + "/acct"}},
+	}
+
+	if err := c.RevokeCert("mainCert", 7); err == nil {
+		t.Error("expected an error for the unused reason code 7")
+	}
+	if err := c.RevokeCert("missing", 1); err == nil {
+		t.Error("expected an error for a missing certificate")
+	}
+	if err := c.RevokeCert("mainCert", RevocationReasons["keyCompromise"]); err != nil {
+		t.Fatal(err)
+	}
+	if revoked.Reason != 1 || revoked.Certificate == "" {
+		t.Errorf("unexpected revocation request %+v", revoked)
+	}
+	if cert, err := storage.GetCertificate("mainCert"); err != nil || cert != nil {
+		t.Errorf("expected the certificate to be deleted, got %v %v", cert, err)
+	}
+}
@@ -0,0 +1,72 @@
+package acme
+
+import (
+	"crypto/x509"
+	"encoding/asn1"
+	"encoding/pem"
+	"fmt"
+)
+
+// oidEmbeddedSCTList identifies the X.509v3 extension with the signed
+// certificate timestamps of a certificate (RFC 6962, section 3.3).
+var oidEmbeddedSCTList = asn1.ObjectIdentifier{1, 3, 6, 1, 4, 1, 11129, 2, 4, 2}
+
+// embeddedSCTs returns the number of signed certificate timestamps
+// embedded in the first certificate of pemBytes.
+func embeddedSCTs(pemBytes []byte) (int, error) {
+	block, _ := pem.Decode(pemBytes)
+	if block == nil {
+		return 0, fmt.Errorf("invalid certificate PEM data")
+	}
+	cert, err := x509.ParseCertificate(block.Bytes)
+	if err != nil {
+		return 0, err
+	}
+	for _, ext := range cert.Extensions {
+		if !ext.Id.Equal(oidEmbeddedSCTList) {
+			continue
+		}
+		var list []byte
+		if _, err := asn1.Unmarshal(ext.Value, &list); err != nil {
+			return 0, fmt.Errorf("invalid SCT list: %w", err)
+		}
+		return countSCTs(list)
+	}
+	return 0, nil
+}
+
+// countSCTs counts the entries of a TLS encoded SignedCertificateTimestampList:
+// a 2 byte length followed by SCTs that are each prefixed by a 2 byte length.
+func countSCTs(list []byte) (int, error) {
+	if len(list) < 2 || int(list[0])<<8|int(list[1]) != len(list)-2 {
+		return 0, fmt.Errorf("invalid SCT list length")
+	}
+	n := 0
+	for rest := list[2:]; len(rest) > 0; n++ {
+		if len(rest) < 2 {
+			return 0, fmt.Errorf("truncated SCT list")
+		}
+		l := int(rest[0])<<8 | int(rest[1])
+		if l == 0 || len(rest) < 2+l {
+			return 0, fmt.Errorf("truncated SCT list")
+		}
+		rest = rest[2+l:]
+	}
+	return n, nil
+}
+
+// checkSCTs warns if the certificate has no embedded SCTs. Such a
+// certificate may not be trusted by clients that enforce certificate
+// transparency, but some CAs deliver the SCTs by other means.
+func (c *certManager) checkSCTs(certName string, pemBytes []byte) {
+	n, err := embeddedSCTs(pemBytes)
+	if err != nil {
+		c.warnf("can not check the SCTs of cert %s: %s", certName, err)
+		return
+	}
+	if n == 0 {
+		c.warnf("cert %s has no embedded SCTs, it may not be logged in CT logs yet", certName)
+		return
+	}
+	c.infof("Cert %s has %d embedded SCTs", certName, n)
+}
@@ -0,0 +1,71 @@
+package acme
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/asn1"
+	"encoding/pem"
+	"math/big"
+	"testing"
+	"time"
+)
+
+func certWithExtensions(t *testing.T, exts ...pkix.Extension) []byte {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tmpl := &x509.Certificate{
+		SerialNumber:    big.NewInt(1),
+		Subject:         pkix.Name{CommonName: "example.com"},
+		NotBefore:       time.Now(),
+		NotAfter:        time.Now().Add(time.Hour),
+		ExtraExtensions: exts,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, tmpl, &key.PublicKey, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der})
+}
+
+func TestEmbeddedSCTs(t *testing.T) {
+	// Two (fake) SCTs of 3 and 1 bytes, with the list length up front.
+	list := []byte{0, 8, 0, 3, 1, 2, 3, 0, 1, 4}
+	value, err := asn1.Marshal(list)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	n, err := embeddedSCTs(certWithExtensions(t, pkix.Extension{Id: oidEmbeddedSCTList, Value: value}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n != 2 {
+		t.Errorf("expected 2 SCTs, got %d", n)
+	}
+
+	n, err = embeddedSCTs(certWithExtensions(t))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n != 0 {
+		t.Errorf("expected no SCTs, got %d", n)
+	}
+}
+
+func TestCountSCTsInvalid(t *testing.T) {
+	for _, list := range [][]byte{
+		{},
+		{0, 5, 0, 3, 1},    // list length does not match
+		{0, 4, 0, 3, 1, 2}, // truncated SCT
+		{0, 1, 0},          // truncated length of SCT
+	} {
+		if _, err := countSCTs(list); err == nil {
+			t.Errorf("expected an error for %v", list)
+		}
+	}
+}
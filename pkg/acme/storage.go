@@ -7,7 +7,31 @@ type Storage interface {
 	// Get Existing certificate, or return nil if it does not exist
 	GetCertificate(name string) (*certificate.Resource, error)
 	StoreCertificate(name string, cert *certificate.Resource) error
+	// Delete a certificate, e.g. after revoking it. Deleting a certificate that does not exist is not an error.
+	DeleteCertificate(name string) error
+	// List the names of all stored certificates, in no particular order.
+	ListCertificates() ([]string, error)
 
+	// Get the account with a key like "host/email" (or "host" for old
+	// accounts), or return nil if it does not exist. Keys may contain "/",
+	// "@" and other characters of email addresses.
 	GetAccount(acmeHost string) (*Account, error)
 	StoreAccount(acmeHost string, account *Account) error
 }
+
+// privateKeyStorage is implemented by storages that do not return the
+// private key with GetCertificate.
+type privateKeyStorage interface {
+	GetPrivateKey(name string) ([]byte, error)
+}
+
+// getPrivateKey returns the private key of a stored certificate.
+func getPrivateKey(s Storage, name string, cert *certificate.Resource) ([]byte, error) {
+	if len(cert.PrivateKey) > 0 {
+		return cert.PrivateKey, nil
+	}
+	if ks, ok := s.(privateKeyStorage); ok {
+		return ks.GetPrivateKey(name)
+	}
+	return nil, nil
+}
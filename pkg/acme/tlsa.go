@@ -0,0 +1,165 @@
+package acme
+
+import (
+	"crypto/x509"
+	"fmt"
+	"strings"
+
+	"github.com/StackExchange/dnscontrol/v3/models"
+	"github.com/StackExchange/dnscontrol/v3/providers"
+	"github.com/miekg/dns"
+)
+
+// TLSAConfig is a TLSA record that is published for a certificate whenever
+// it is issued or renewed, e.g. {"name": "_25._tcp.mail.example.com",
+// "usage": 3, "selector": 1, "matching_type": 1} for DANE-EE with the
+// SHA-256 of the public key.
+type TLSAConfig struct {
+	Name         string `json:"name"` // FQDN of the owner
+	Usage        uint8  `json:"usage"`
+	Selector     uint8  `json:"selector"`
+	MatchingType uint8  `json:"matching_type"`
+	TTL          uint32 `json:"ttl,omitempty"` // 300 if not set
+}
+
+// Validate checks the parameters of the TLSA record.
+func (t TLSAConfig) Validate() error {
+	if t.Name == "" {
+		return fmt.Errorf("TLSA record without a name")
+	}
+	if t.Usage > 3 {
+		return fmt.Errorf("TLSA record %s has invalid usage %d", t.Name, t.Usage)
+	}
+	if t.Selector > 1 {
+		return fmt.Errorf("TLSA record %s has invalid selector %d", t.Name, t.Selector)
+	}
+	if t.MatchingType > 2 {
+		return fmt.Errorf("TLSA record %s has invalid matching type %d", t.Name, t.MatchingType)
+	}
+	return nil
+}
+
+// tlsaRecord returns the TLSA record of t for the certificate bundle. The
+// usages PKIX-TA (0) and DANE-TA (2) are about the issuer, the others are
+// about the certificate itself.
+func (t TLSAConfig) tlsaRecord(domain string, leaf, issuer *x509.Certificate) (*models.RecordConfig, error) {
+	if err := t.Validate(); err != nil {
+		return nil, err
+	}
+	cert := leaf
+	if t.Usage == 0 || t.Usage == 2 {
+		if issuer == nil {
+			return nil, fmt.Errorf("TLSA record %s with usage %d needs the issuer, the certificate has no chain", t.Name, t.Usage)
+		}
+		cert = issuer
+	}
+	data, err := dns.CertificateToDANE(t.Selector, t.MatchingType, cert)
+	if err != nil {
+		return nil, err
+	}
+	ttl := t.TTL
+	if ttl == 0 {
+		ttl = 300
+	}
+	rc := &models.RecordConfig{Type: "TLSA", TTL: ttl, Metadata: map[string]string{}}
+	rc.SetLabelFromFQDN(strings.TrimSuffix(t.Name, "."), domain)
+	if err := rc.SetTargetTLSA(t.Usage, t.Selector, t.MatchingType, data); err != nil {
+		return nil, err
+	}
+	return rc, nil
+}
+
+// publishTLSA sets the TLSA records of cfg to match the certificate bundle.
+// Only the TLSA records at the names of cfg.TLSA are changed, everything
+// else in the zones is left as it is at the providers.
+func (c *certManager) publishTLSA(cfg *CertConfig, bundle []byte) error {
+	leafPEM, chainPEM, err := splitBundle(bundle)
+	if err != nil {
+		return err
+	}
+	leaf, err := parseLeaf(leafPEM)
+	if err != nil {
+		return err
+	}
+	var issuer *x509.Certificate
+	if len(chainPEM) > 0 {
+		if issuer, err = parseLeaf(chainPEM); err != nil {
+			return err
+		}
+	}
+
+	var domains []*models.DomainConfig
+	records := map[string]models.Records{}
+	for _, t := range cfg.TLSA {
+		d := c.cfg.DomainContainingFQDN(strings.TrimSuffix(t.Name, "."))
+		if d == nil {
+			return fmt.Errorf("no domain in the configuration contains TLSA record %s", t.Name)
+		}
+		rc, err := t.tlsaRecord(d.Name, leaf, issuer)
+		if err != nil {
+			return err
+		}
+		if _, ok := records[d.Name]; !ok {
+			domains = append(domains, d)
+		}
+		records[d.Name] = append(records[d.Name], rc)
+	}
+
+	for _, d := range domains {
+		c.infof("Publishing TLSA records of certificate [%s] in %s", cfg.CertName, d.Name)
+		if err := c.updateTLSA(d, records[d.Name]); err != nil {
+			return fmt.Errorf("%s: %w", d.Name, err)
+		}
+	}
+	return nil
+}
+
+// updateTLSA replaces the TLSA records at the labels of tlsa with tlsa, at
+// all providers of d.
+func (c *certManager) updateTLSA(d *models.DomainConfig, tlsa models.Records) error {
+	owners := map[string]bool{}
+	for _, r := range tlsa {
+		owners[r.GetLabel()] = true
+	}
+	for _, p := range d.DNSProviderInstances {
+		if !c.useProvider(p.Name) {
+			continue
+		}
+		if !providers.ProviderHasCapability(p.ProviderType, providers.CanUseTLSA) {
+			return fmt.Errorf("provider %s does not support TLSA records", p.Name)
+		}
+		existing, err := providers.GetZoneRecordsContext(c.context(), p.Driver, d.Name)
+		if err != nil {
+			return err
+		}
+		recs := models.Records{}
+		for _, r := range existing {
+			if !(r.Type == "TLSA" && owners[r.GetLabel()]) {
+				recs = append(recs, r)
+			}
+		}
+		recs = append(recs, tlsa...)
+
+		dc, err := d.Copy()
+		if err != nil {
+			return err
+		}
+		dc.Records = recs
+		// The TLSA records are usually IGNORE_NAMEd, so that push leaves them alone.
+		dc.KeepUnknown = false
+		dc.IgnoredNames = nil
+		dc.IgnoredTargets = nil
+		corrections, err := providers.GetDomainCorrectionsContext(c.context(), p.Driver, dc)
+		if err != nil {
+			return err
+		}
+		for _, corr := range corrections {
+			corr.Msg = fmt.Sprintf("[%s] %s", p.Name, strings.TrimSpace(corr.Msg))
+		}
+		c.printf("%d corrections\n", models.CountChanges(corrections))
+		if err := c.runCorrections(d, p.Name, corrections); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,140 @@
+package acme
+
+import (
+	"crypto/x509"
+	"encoding/pem"
+	"testing"
+
+	"github.com/StackExchange/dnscontrol/v3/models"
+	"github.com/StackExchange/dnscontrol/v3/providers"
+	"github.com/miekg/dns"
+)
+
+func init() {
+	providers.RegisterDomainServiceProviderType("ACMETEST_TLSA", providers.DspFuncs{}, providers.CanUseTLSA)
+}
+
+// tlsaProvider has the records existing and keeps the records it is asked
+// to converge to.
+type tlsaProvider struct {
+	fakeProvider
+	existing models.Records
+	desired  models.Records
+}
+
+func (p *tlsaProvider) GetZoneRecords(domain string) (models.Records, error) {
+	return p.existing, nil
+}
+
+func (p *tlsaProvider) GetDomainCorrections(dc *models.DomainConfig) ([]*models.Correction, error) {
+	desired := dc.Records
+	return []*models.Correction{{
+		Msg: "update records",
+		F: func() error {
+			p.desired = desired
+			return nil
+		},
+	}}, nil
+}
+
+func tlsaTestRecord(label string, data string) *models.RecordConfig {
+	rc := &models.RecordConfig{Type: "TLSA", TTL: 300}
+	rc.SetLabel(label, "example.com")
+	rc.SetTargetTLSA(3, 1, 1, data)
+	return rc
+}
+
+func TestPublishTLSA(t *testing.T) {
+	a := &models.RecordConfig{Type: "A", TTL: 300}
+	a.SetLabel("mail", "example.com")
+	a.SetTarget("1.2.3.4")
+	provider := &tlsaProvider{existing: models.Records{
+		a,
+		tlsaTestRecord("_25._tcp.mail", "00"),
+		tlsaTestRecord("_443._tcp.www", "11"),
+	}}
+	cfg := &models.DNSConfig{
+		Domains: []*models.DomainConfig{{
+			Name: "example.com",
+			DNSProviderInstances: []*models.DNSProviderInstance{{
+				ProviderBase: models.ProviderBase{Name: "test", ProviderType: "ACMETEST_TLSA"},
+				Driver:       provider,
+			}},
+		}},
+	}
+	c := &certManager{cfg: cfg, notifier: noNotifier(t)}
+
+	leafPEM, issuerPEM := selfSigned(t, "mail.example.com"), selfSigned(t, "Issuer")
+	certCfg := &CertConfig{
+		CertName: "mail",
+		TLSA: []TLSAConfig{
+			{Name: "_25._tcp.mail.example.com", Usage: 3, Selector: 1, MatchingType: 1},
+			{Name: "_25._tcp.mail.example.com.", Usage: 2, Selector: 0, MatchingType: 1, TTL: 600},
+		},
+	}
+	if err := c.publishTLSA(certCfg, append(leafPEM, issuerPEM...)); err != nil {
+		t.Fatal(err)
+	}
+
+	want := map[string]string{}
+	for _, tc := range []struct {
+		pem                           []byte
+		usage, selector, matchingType uint8
+	}{{leafPEM, 3, 1, 1}, {issuerPEM, 2, 0, 1}} {
+		block, _ := pem.Decode(tc.pem)
+		cert, err := x509.ParseCertificate(block.Bytes)
+		if err != nil {
+			t.Fatal(err)
+		}
+		data, err := dns.CertificateToDANE(tc.selector, tc.matchingType, cert)
+		if err != nil {
+			t.Fatal(err)
+		}
+		want[data] = "_25._tcp.mail"
+	}
+
+	var kept []string
+	for _, r := range provider.desired {
+		if r.Type != "TLSA" || want[r.GetTargetField()] == "" {
+			kept = append(kept, r.GetLabel()+" "+r.Type+" "+r.GetTargetField())
+			continue
+		}
+		if r.GetLabel() != want[r.GetTargetField()] {
+			t.Errorf("expected the TLSA record at _25._tcp.mail, got %s", r.GetLabel())
+		}
+		delete(want, r.GetTargetField())
+	}
+	if len(want) != 0 {
+		t.Errorf("expected the TLSA records %v to be published", want)
+	}
+	if len(kept) != 2 || kept[0] != "mail A 1.2.3.4" || kept[1] != "_443._tcp.www TLSA 11" {
+		t.Errorf("expected only the old TLSA record at the owner to be replaced, kept %v", kept)
+	}
+}
+
+func TestPublishTLSAErrors(t *testing.T) {
+	cfg := &models.DNSConfig{
+		Domains: []*models.DomainConfig{{
+			Name: "example.com",
+			DNSProviderInstances: []*models.DNSProviderInstance{{
+				ProviderBase: models.ProviderBase{Name: "test", ProviderType: "ACMETEST_CONCURRENT"},
+				Driver:       &tlsaProvider{},
+			}},
+		}},
+	}
+	c := &certManager{cfg: cfg, notifier: noNotifier(t)}
+	leafPEM := selfSigned(t, "mail.example.com")
+
+	for _, tlsa := range []TLSAConfig{
+		{Name: "_25._tcp.mail.example.com", Usage: 3, Selector: 1, MatchingType: 1}, // provider lacks TLSA support
+		{Name: "_25._tcp.mail.example.org", Usage: 3, Selector: 1, MatchingType: 1}, // no such domain
+		{Name: "_25._tcp.mail.example.com", Usage: 2, Selector: 1, MatchingType: 1}, // no issuer in the bundle
+		{Name: "_25._tcp.mail.example.com", Usage: 4, Selector: 1, MatchingType: 1},
+		{Name: "_25._tcp.mail.example.com", Usage: 3, Selector: 2, MatchingType: 1},
+		{Name: "_25._tcp.mail.example.com", Usage: 3, Selector: 1, MatchingType: 3},
+	} {
+		if err := c.publishTLSA(&CertConfig{CertName: "mail", TLSA: []TLSAConfig{tlsa}}, leafPEM); err == nil {
+			t.Errorf("expected an error for %+v", tlsa)
+		}
+	}
+}
@@ -6,7 +6,9 @@ import (
 	"encoding/json"
 	"encoding/pem"
 	"fmt"
+	"strconv"
 	"strings"
+	"sync"
 
 	"github.com/go-acme/lego/certificate"
 
@@ -16,6 +18,16 @@ import (
 type vaultStorage struct {
 	path   string
 	client *api.Logical
+
+	// kvVersion is the version of the KV secrets engine, 1 or 2. It is
+	// detected on first use if not set.
+	kvVersion int
+	// mount is the mount path of the secrets engine, e.g. "secret/".
+	mount    string
+	detected sync.Once
+	// versions are the versions of certificates to read, by name. KV
+	// version 2 only; the latest version is read for other certificates.
+	versions map[string]int
 }
 
 func makeVaultStorage(vaultPath string) (Storage, error) {
@@ -33,31 +45,129 @@ func makeVaultStorage(vaultPath string) (Storage, error) {
 	return storage, nil
 }
 
+// findVaultStorage returns the vault storage in s, or nil.
+func findVaultStorage(s Storage) *vaultStorage {
+	switch s := s.(type) {
+	case *vaultStorage:
+		return s
+	case *MultiStorage:
+		for _, b := range s.backends {
+			if v := findVaultStorage(b); v != nil {
+				return v
+			}
+		}
+	}
+	return nil
+}
+
+// detect finds the mount of the secrets engine of v.path and, unless it
+// is configured, its KV version. If vault does not tell, e.g. because the
+// token may not read the mounts, the first element of the path is taken
+// as the mount, and version 1 is assumed.
+func (v *vaultStorage) detect() {
+	v.detected.Do(func() {
+		p := strings.TrimPrefix(v.path, "/")
+		v.mount = strings.SplitN(p, "/", 2)[0] + "/"
+		secret, err := v.client.Read("sys/internal/ui/mounts/" + p)
+		if err == nil && secret != nil {
+			if mount, ok := secret.Data["path"].(string); ok && mount != "" {
+				v.mount = mount
+			}
+			if v.kvVersion == 0 {
+				if options, ok := secret.Data["options"].(map[string]interface{}); ok && options["version"] == "2" {
+					v.kvVersion = 2
+				}
+			}
+		}
+		if v.kvVersion == 0 {
+			v.kvVersion = 1
+		}
+	})
+}
+
+// apiPath returns the API path of the secret at path. For KV version 2,
+// the secret data is below "data/" in the mount.
+func (v *vaultStorage) apiPath(path string) string {
+	if v.kvVersion != 2 {
+		return path
+	}
+	p := strings.TrimPrefix(path, "/")
+	return v.mount + "data/" + strings.TrimPrefix(p, v.mount)
+}
+
+// listPath returns the API path to list the secrets below path. For KV
+// version 2, the keys are listed below "metadata/" in the mount.
+func (v *vaultStorage) listPath(path string) string {
+	if v.kvVersion != 2 {
+		return path
+	}
+	p := strings.TrimPrefix(path, "/")
+	return v.mount + "metadata/" + strings.TrimPrefix(p, v.mount)
+}
+
+// read returns the data of the secret at path, or nil if there is none.
+// version 0 is the latest version.
+func (v *vaultStorage) read(path string, version int) (map[string]interface{}, error) {
+	v.detect()
+	if v.kvVersion != 2 {
+		if version != 0 {
+			return nil, fmt.Errorf("reading version %d of %s: secret versions need KV version 2", version, path)
+		}
+		secret, err := v.client.Read(path)
+		if err != nil || secret == nil {
+			return nil, err
+		}
+		return secret.Data, nil
+	}
+	var params map[string][]string
+	if version != 0 {
+		params = map[string][]string{"version": {strconv.Itoa(version)}}
+	}
+	secret, err := v.client.ReadWithData(v.apiPath(path), params)
+	if err != nil || secret == nil {
+		return nil, err
+	}
+	// The data of a deleted version is null.
+	data, _ := secret.Data["data"].(map[string]interface{})
+	return data, nil
+}
+
+// write stores data at path. With KV version 2, this adds a new version of
+// the secret and keeps the previous ones.
+func (v *vaultStorage) write(path string, data map[string]interface{}) error {
+	v.detect()
+	if v.kvVersion == 2 {
+		data = map[string]interface{}{"data": data}
+	}
+	_, err := v.client.Write(v.apiPath(path), data)
+	return err
+}
+
 func (v *vaultStorage) GetCertificate(name string) (*certificate.Resource, error) {
 	var err error
 
 	path := v.certPath(name)
-	secret, err := v.client.Read(path)
+	data, err := v.read(path, v.versions[name])
 	if err != nil {
 		return nil, err
 	}
-	if secret == nil {
+	if data == nil {
 		return nil, nil
 	}
 	cert := &certificate.Resource{}
-	if dat, err := v.getString("meta", secret.Data, path); err != nil {
+	if dat, err := v.getString("meta", data, path); err != nil {
 		return nil, err
 	} else if err = json.Unmarshal(dat, cert); err != nil {
 		return nil, err
 	}
 
 	var dat []byte
-	if dat, err = v.getString("tls.cert", secret.Data, path); err != nil {
+	if dat, err = v.getString("tls.cert", data, path); err != nil {
 		return nil, err
 	}
 	cert.Certificate = dat
 
-	if dat, err = v.getString("tls.key", secret.Data, path); err != nil {
+	if dat, err = v.getString("tls.key", data, path); err != nil {
 		return nil, err
 	}
 	cert.PrivateKey = dat
@@ -90,8 +200,7 @@ func (v *vaultStorage) StoreCertificate(name string, cert *certificate.Resource)
 		"tls.combined": pub + "\n" + key,
 		"meta":         string(jDat),
 	}
-	_, err = v.client.Write(v.certPath(name), data)
-	return err
+	return v.write(v.certPath(name), data)
 }
 
 func (v *vaultStorage) registrationPath(acmeHost string) string {
@@ -102,17 +211,43 @@ func (v *vaultStorage) certPath(name string) string {
 	return v.path + name
 }
 
+// DeleteCertificate deletes the certificate. With KV version 2, only the
+// latest version is deleted, the previous versions are kept.
+func (v *vaultStorage) DeleteCertificate(name string) error {
+	v.detect()
+	_, err := v.client.Delete(v.apiPath(v.certPath(name)))
+	return err
+}
+
+// ListCertificates returns the names of the secrets at the path of the
+// certificates. Folders, e.g. the one of the accounts, are skipped.
+func (v *vaultStorage) ListCertificates() ([]string, error) {
+	v.detect()
+	secret, err := v.client.List(v.listPath(v.path))
+	if err != nil || secret == nil {
+		return nil, err
+	}
+	keys, _ := secret.Data["keys"].([]interface{})
+	var names []string
+	for _, k := range keys {
+		if name, ok := k.(string); ok && !strings.HasSuffix(name, "/") {
+			names = append(names, name)
+		}
+	}
+	return names, nil
+}
+
 func (v *vaultStorage) GetAccount(acmeHost string) (*Account, error) {
 	path := v.registrationPath(acmeHost)
-	secret, err := v.client.Read(path)
+	data, err := v.read(path, 0)
 	if err != nil {
 		return nil, err
 	}
-	if secret == nil {
+	if data == nil {
 		return nil, nil
 	}
 	acct := &Account{}
-	if dat, err := v.getString("registration", secret.Data, path); err != nil {
+	if dat, err := v.getString("registration", data, path); err != nil {
 		return nil, err
 	} else if err = json.Unmarshal(dat, acct); err != nil {
 		return nil, err
@@ -121,7 +256,7 @@ func (v *vaultStorage) GetAccount(acmeHost string) (*Account, error) {
 	var key *ecdsa.PrivateKey
 	var dat []byte
 	var block *pem.Block
-	if dat, err = v.getString("tls.key", secret.Data, path); err != nil {
+	if dat, err = v.getString("tls.key", data, path); err != nil {
 		return nil, err
 	} else if block, _ = pem.Decode(dat); block == nil {
 		return nil, fmt.Errorf("error decoding account private key")
@@ -144,9 +279,8 @@ func (v *vaultStorage) StoreAccount(acmeHost string, account *Account) error {
 	pemKey := &pem.Block{Type: "EC PRIVATE KEY", Bytes: keyBytes}
 	pemBytes := pem.EncodeToMemory(pemKey)
 
-	_, err = v.client.Write(v.registrationPath(acmeHost), map[string]interface{}{
+	return v.write(v.registrationPath(acmeHost), map[string]interface{}{
 		"registration": string(acctBytes),
 		"tls.key":      string(pemBytes),
 	})
-	return err
 }
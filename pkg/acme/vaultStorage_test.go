@@ -0,0 +1,212 @@
+package acme
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/go-acme/lego/certificate"
+	"github.com/hashicorp/vault/api"
+)
+
+// fakeKV serves a KV secrets engine mounted at secret/, of version 1 or
+// 2. With version 2, it keeps all versions of the secrets.
+func fakeKV(t *testing.T, version int) (http.HandlerFunc, map[string][]map[string]interface{}) {
+	secrets := map[string][]map[string]interface{}{}
+	return func(w http.ResponseWriter, r *http.Request) {
+		path := strings.TrimPrefix(r.URL.Path, "/v1/")
+		if strings.HasPrefix(path, "sys/internal/ui/mounts/") {
+			if version == 1 {
+				// Old vault, or a token that may not read the mounts.
+				w.WriteHeader(http.StatusForbidden)
+				return
+			}
+			json.NewEncoder(w).Encode(map[string]interface{}{"data": map[string]interface{}{
+				"path": "secret/", "type": "kv", "options": map[string]interface{}{"version": "2"},
+			}})
+			return
+		}
+		if r.URL.Query().Get("list") == "true" {
+			if version == 2 {
+				path = "secret/" + strings.TrimPrefix(path, "secret/metadata/")
+			}
+			prefix := strings.TrimSuffix(path, "/") + "/"
+			seen := map[string]bool{}
+			var keys []string
+			for p, versions := range secrets {
+				if !strings.HasPrefix(p, prefix) || len(versions) == 0 || versions[len(versions)-1] == nil {
+					continue
+				}
+				key := strings.TrimPrefix(p, prefix)
+				if i := strings.Index(key, "/"); i >= 0 {
+					key = key[:i+1]
+				}
+				if !seen[key] {
+					seen[key] = true
+					keys = append(keys, key)
+				}
+			}
+			if len(keys) == 0 {
+				w.WriteHeader(http.StatusNotFound)
+				return
+			}
+			json.NewEncoder(w).Encode(map[string]interface{}{"data": map[string]interface{}{"keys": keys}})
+			return
+		}
+		if version == 2 {
+			if !strings.HasPrefix(path, "secret/data/") {
+				t.Errorf("unexpected path %s for KV version 2", path)
+				w.WriteHeader(http.StatusNotFound)
+				return
+			}
+			path = "secret/" + strings.TrimPrefix(path, "secret/data/")
+		}
+		versions := secrets[path]
+		switch r.Method {
+		case http.MethodPut, http.MethodPost:
+			var body map[string]interface{}
+			json.NewDecoder(r.Body).Decode(&body)
+			if version == 2 {
+				body, _ = body["data"].(map[string]interface{})
+				secrets[path] = append(versions, body)
+			} else {
+				secrets[path] = []map[string]interface{}{body}
+			}
+		case http.MethodDelete:
+			if len(versions) > 0 {
+				versions[len(versions)-1] = nil
+			}
+		case http.MethodGet:
+			n := len(versions)
+			if v := r.URL.Query().Get("version"); v != "" {
+				n, _ = strconv.Atoi(v)
+			}
+			if n == 0 || n > len(versions) {
+				w.WriteHeader(http.StatusNotFound)
+				return
+			}
+			data := versions[n-1]
+			if version == 2 {
+				if data == nil {
+					w.WriteHeader(http.StatusNotFound)
+				}
+				json.NewEncoder(w).Encode(map[string]interface{}{"data": map[string]interface{}{
+					"data": data, "metadata": map[string]interface{}{"version": n},
+				}})
+				return
+			}
+			json.NewEncoder(w).Encode(map[string]interface{}{"data": data})
+		}
+	}, secrets
+}
+
+func fakeVaultStorage(t *testing.T, kvVersion int) (*vaultStorage, map[string][]map[string]interface{}, func()) {
+	handler, secrets := fakeKV(t, kvVersion)
+	srv := httptest.NewServer(handler)
+	client, err := api.NewClient(&api.Config{Address: srv.URL})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return &vaultStorage{path: "/secret/certs/", client: client.Logical()}, secrets, srv.Close
+}
+
+func TestVaultStorageKV2(t *testing.T) {
+	v, secrets, done := fakeVaultStorage(t, 2)
+	defer done()
+
+	first, second := issuedBy(t, "example.com", "R3"), issuedBy(t, "example.com", "Broken Chain")
+	for _, pem := range [][]byte{first, second} {
+		if err := v.StoreCertificate("mainCert", &certificate.Resource{Domain: "example.com", Certificate: pem}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if v.kvVersion != 2 {
+		t.Fatalf("expected KV version 2 to be detected, got %d", v.kvVersion)
+	}
+	if n := len(secrets["secret/certs/mainCert"]); n != 2 {
+		t.Fatalf("expected storing to add versions, got %d", n)
+	}
+	secrets["secret/certs/.letsencrypt/acme-v02.api.letsencrypt.org"] = []map[string]interface{}{{}}
+	if names, err := v.ListCertificates(); err != nil || len(names) != 1 || names[0] != "mainCert" {
+		t.Errorf("expected to list mainCert only, got %v, %v", names, err)
+	}
+
+	cert, err := v.GetCertificate("mainCert")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(cert.Certificate) != string(second) {
+		t.Error("expected the latest version to be read")
+	}
+
+	// Roll back to the previous certificate.
+	v.versions = map[string]int{"mainCert": 1}
+	if cert, err = v.GetCertificate("mainCert"); err != nil {
+		t.Fatal(err)
+	}
+	if string(cert.Certificate) != string(first) {
+		t.Error("expected the pinned version to be read")
+	}
+	v.versions = nil
+
+	if err := v.DeleteCertificate("mainCert"); err != nil {
+		t.Fatal(err)
+	}
+	if cert, err = v.GetCertificate("mainCert"); err != nil || cert != nil {
+		t.Errorf("expected no certificate after deleting, got %v, %v", cert, err)
+	}
+	if secrets["secret/certs/mainCert"][0] == nil {
+		t.Error("expected the previous version to be kept")
+	}
+}
+
+func TestVaultStorageKV1(t *testing.T) {
+	v, secrets, done := fakeVaultStorage(t, 1)
+	defer done()
+
+	pem := issuedBy(t, "example.com", "R3")
+	if err := v.StoreCertificate("mainCert", &certificate.Resource{Domain: "example.com", Certificate: pem}); err != nil {
+		t.Fatal(err)
+	}
+	if v.kvVersion != 1 {
+		t.Fatalf("expected KV version 1 if vault does not tell, got %d", v.kvVersion)
+	}
+	if _, ok := secrets["secret/certs/mainCert"]; !ok {
+		t.Fatalf("expected the secret at secret/certs/mainCert, got %v", secrets)
+	}
+	if names, err := v.ListCertificates(); err != nil || len(names) != 1 || names[0] != "mainCert" {
+		t.Errorf("expected to list mainCert, got %v, %v", names, err)
+	}
+	cert, err := v.GetCertificate("mainCert")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(cert.Certificate) != string(pem) {
+		t.Error("unexpected certificate")
+	}
+
+	v.versions = map[string]int{"mainCert": 1}
+	if _, err := v.GetCertificate("mainCert"); err == nil {
+		t.Error("expected an error reading a version with KV version 1")
+	}
+}
+
+func TestWithVaultKVVersion(t *testing.T) {
+	v := &vaultStorage{}
+	c := &certManager{storage: NewMultiStorage(directoryStorage("certs"), v)}
+	if err := WithVaultKVVersion(2)(c); err != nil {
+		t.Fatal(err)
+	}
+	if v.kvVersion != 2 {
+		t.Error("expected the KV version of the vault storage to be set")
+	}
+	if err := WithVaultKVVersion(3)(c); err == nil {
+		t.Error("expected an error for KV version 3")
+	}
+	if err := WithVaultKVVersion(2)(&certManager{storage: directoryStorage("certs")}); err == nil {
+		t.Error("expected an error without vault storage")
+	}
+}
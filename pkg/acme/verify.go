@@ -0,0 +1,120 @@
+package acme
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"strings"
+)
+
+// DomainError is the outcome of verifying the challenge records of one
+// domain (zone). Err is nil if they were created and removed again.
+type DomainError struct {
+	Domain string
+	Err    error
+}
+
+// VerifyError lists the domains whose challenge records could not be
+// created or removed.
+type VerifyError struct {
+	CertName string
+	Failed   []DomainError
+}
+
+func (e *VerifyError) Error() string {
+	parts := make([]string, len(e.Failed))
+	for i, f := range e.Failed {
+		parts[i] = fmt.Sprintf("%s: %v", f.Domain, f.Err)
+	}
+	return fmt.Sprintf("cert %s: challenge records failed for %d domain(s): %s", e.CertName, len(e.Failed), strings.Join(parts, "; "))
+}
+
+// VerifyChallengeCapability creates the dns-01 challenge records of all
+// names of cfg and removes them again, without ordering a certificate.
+// This shows whether the DNS providers of every domain are able to fulfill
+// the challenges. Each domain is reported on its own, the returned error is
+// a *VerifyError listing the domains that failed.
+func (c *certManager) VerifyChallengeCapability(cfg *CertConfig) error {
+	c.infof("Verifying challenges of certificate [%s]", cfg.CertName)
+	keyAuth, err := randomKeyAuth()
+	if err != nil {
+		return err
+	}
+
+	w := c.worker(c.notifier)
+	w.ensureCAA = cfg.EnsureCAA
+	w.challengeAliases = cfg.ChallengeAliases
+	if w.challengeProviders, w.ignoredProviders, err = w.certProviders(cfg); err != nil {
+		return err
+	}
+	if err := w.checkChallengeAliases(cfg.ChallengeAliases); err != nil {
+		return fmt.Errorf("cert %s: %w", cfg.CertName, err)
+	}
+	verr := &VerifyError{CertName: cfg.CertName}
+	failed := map[string]bool{}
+	fail := func(domain string, err error) {
+		c.infof("Challenge records of %s: FAILED: %s", domain, err)
+		verr.Failed = append(verr.Failed, DomainError{Domain: domain, Err: err})
+		failed[domain] = true
+	}
+
+	seen := map[string]bool{}
+	for _, name := range cfg.Names {
+		// lego presents the challenge of a wildcard name on the base name.
+		// With the same key authorization, both would be the same record.
+		name = strings.TrimPrefix(name, "*.")
+		if seen[name] {
+			continue
+		}
+		seen[name] = true
+		d := w.cfg.DomainContainingFQDN(cfg.ChallengeFQDN(name))
+		if d == nil {
+			fail(name, fmt.Errorf("no domain in the configuration contains %s", cfg.ChallengeFQDN(name)))
+			continue
+		}
+		if failed[d.Name] {
+			continue
+		}
+		if err := w.Present(name, "", keyAuth); err != nil {
+			fail(d.Name, err)
+		}
+	}
+
+	// Create and remove the records domain by domain, so that a failing
+	// provider can be told apart from the others.
+	for _, original := range w.originalDomains {
+		name := original.Name
+		if failed[name] {
+			continue
+		}
+		err := w.getAndRunCorrections(w.context(), w.domains[name])
+		// The records are removed even if the context was cancelled.
+		if cerr := w.getAndRunCorrections(context.Background(), original); cerr != nil && err == nil {
+			err = fmt.Errorf("failed removing the challenge records: %w", cerr)
+		}
+		if perr := w.forcePurge(context.Background(), original); perr != nil && err == nil {
+			err = fmt.Errorf("failed removing the challenge records: %w", perr)
+		}
+		if err != nil {
+			fail(name, err)
+			continue
+		}
+		c.infof("Challenge records of %s: OK", name)
+	}
+
+	if len(verr.Failed) > 0 {
+		return verr
+	}
+	return nil
+}
+
+// randomKeyAuth returns a key authorization that is not used for any real
+// challenge, so that the challenge records are unique.
+func randomKeyAuth() (string, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return "dnscontrol-verify-" + hex.EncodeToString(b), nil
+}
@@ -0,0 +1,125 @@
+package acme
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/StackExchange/dnscontrol/v3/models"
+)
+
+// zoneProvider keeps the records of a zone. If failCreate is set, records
+// can not be created, only removed.
+type zoneProvider struct {
+	fakeProvider
+	current    models.Records
+	created    int
+	failCreate bool
+}
+
+func (z *zoneProvider) GetDomainCorrections(dc *models.DomainConfig) ([]*models.Correction, error) {
+	if len(dc.Records) == len(z.current) {
+		return nil, nil
+	}
+	desired := dc.Records
+	return []*models.Correction{{
+		Msg: "update records",
+		F: func() error {
+			if len(desired) > len(z.current) {
+				if z.failCreate {
+					return errors.New("permission denied")
+				}
+				z.created++
+			}
+			z.current = desired
+			return nil
+		},
+	}}, nil
+}
+
+func TestVerifyChallengeCapability(t *testing.T) {
+	good := &zoneProvider{}
+	bad := &zoneProvider{failCreate: true}
+	cfg := &models.DNSConfig{
+		Domains: []*models.DomainConfig{
+			{Name: "example.com", DNSProviderInstances: []*models.DNSProviderInstance{{Driver: good}}},
+			{Name: "example.net", DNSProviderInstances: []*models.DNSProviderInstance{{Driver: bad}}},
+		},
+	}
+	c := &certManager{
+		cfg:      cfg,
+		domains:  map[string]*models.DomainConfig{},
+		notifier: noNotifier(t),
+	}
+
+	err := c.VerifyChallengeCapability(&CertConfig{
+		CertName: "test",
+		Names:    []string{"example.com", "*.example.com", "www.example.net", "example.org"},
+	})
+	var verr *VerifyError
+	if !errors.As(err, &verr) {
+		t.Fatalf("expected a *VerifyError, got %v", err)
+	}
+	var failed []string
+	for _, f := range verr.Failed {
+		failed = append(failed, f.Domain)
+	}
+	if len(failed) != 2 || failed[0] != "example.org" || failed[1] != "example.net" {
+		t.Errorf("expected example.org and example.net to fail, got %v", failed)
+	}
+
+	if good.created != 1 || len(good.current) != 0 {
+		t.Errorf("expected the records of example.com to be created once and removed, got %d creations and %d records", good.created, len(good.current))
+	}
+	if len(bad.current) != 0 {
+		t.Errorf("expected no records left in example.net, got %d", len(bad.current))
+	}
+	if len(c.domains) != 0 || len(c.originalDomains) != 0 {
+		t.Error("expected the state of the client to be left untouched")
+	}
+}
+
+func TestVerifyChallengeProviders(t *testing.T) {
+	primary := &zoneProvider{}
+	secondary := &zoneProvider{failCreate: true} // read-only
+	cfg := &models.DNSConfig{
+		DNSProviders: []*models.DNSProviderConfig{{Name: "primary"}, {Name: "secondary"}},
+		Domains: []*models.DomainConfig{{
+			Name: "example.com",
+			DNSProviderInstances: []*models.DNSProviderInstance{
+				{ProviderBase: models.ProviderBase{Name: "primary"}, Driver: primary},
+				{ProviderBase: models.ProviderBase{Name: "secondary"}, Driver: secondary},
+			},
+		}},
+	}
+	// Left over from the certificate issued before.
+	c := &certManager{
+		cfg:                cfg,
+		domains:            map[string]*models.DomainConfig{},
+		notifier:           noNotifier(t),
+		challengeProviders: map[string]bool{"secondary": true},
+	}
+
+	err := c.VerifyChallengeCapability(&CertConfig{
+		CertName:           "test",
+		Names:              []string{"example.com"},
+		ChallengeProviders: []string{"primary"},
+	})
+	if err != nil {
+		t.Fatalf("expected the secondary to be left out, got %v", err)
+	}
+	if primary.created != 1 || len(primary.current) != 0 {
+		t.Errorf("expected the records of the primary to be created once and removed, got %d creations and %d records", primary.created, len(primary.current))
+	}
+	if secondary.created != 0 {
+		t.Errorf("expected no records at the secondary, got %d creations", secondary.created)
+	}
+
+	err = c.VerifyChallengeCapability(&CertConfig{
+		CertName:           "test",
+		Names:              []string{"example.com"},
+		ChallengeProviders: []string{"typo"},
+	})
+	if err == nil {
+		t.Errorf("expected an error for an unknown provider")
+	}
+}
@@ -0,0 +1,191 @@
+// Package apimetrics collects the timing of the API requests of the
+// providers, for finding out why a run is slow.
+//
+// Providers report each HTTP request with Observe. Nothing is collected
+// unless a Hook was set with SetHook, e.g. a Collector.
+package apimetrics
+
+import (
+	"fmt"
+	"io"
+	"math"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// A Request is an API request of a provider.
+type Request struct {
+	Provider string // The type of the provider, e.g. HETZNER.
+	Method   string
+	Endpoint string // The path without IDs and query, e.g. /records/{id}.
+	Duration time.Duration
+	Status   int  // The HTTP status code, 0 if there was no response.
+	Retry    bool // The request repeats a failed or rate-limited one.
+}
+
+// A Hook receives the requests of the providers. It must be safe for
+// concurrent use.
+type Hook interface {
+	Observe(r Request)
+}
+
+var (
+	hookMu sync.RWMutex
+	hook   Hook
+)
+
+// SetHook sets the hook that receives the requests of the providers. nil
+// disables it.
+func SetHook(h Hook) {
+	hookMu.Lock()
+	defer hookMu.Unlock()
+	hook = h
+}
+
+// Observe reports a request to the hook, if there is one.
+func Observe(r Request) {
+	hookMu.RLock()
+	h := hook
+	hookMu.RUnlock()
+	if h != nil {
+		h.Observe(r)
+	}
+}
+
+// Collector is a Hook that keeps all requests, for summarizing them at the
+// end of a run.
+type Collector struct {
+	mu       sync.Mutex
+	requests []Request
+}
+
+// Observe implements Hook.
+func (c *Collector) Observe(r Request) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.requests = append(c.requests, r)
+}
+
+// providerStats are the aggregated requests of one provider.
+type providerStats struct {
+	provider  string
+	durations []time.Duration // sorted
+	total     time.Duration
+	retries   int
+}
+
+// stats returns the requests aggregated by provider, sorted by name.
+func (c *Collector) stats() []*providerStats {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	byProvider := map[string]*providerStats{}
+	var all []*providerStats
+	for _, r := range c.requests {
+		s := byProvider[r.Provider]
+		if s == nil {
+			s = &providerStats{provider: r.Provider}
+			byProvider[r.Provider] = s
+			all = append(all, s)
+		}
+		s.durations = append(s.durations, r.Duration)
+		s.total += r.Duration
+		if r.Retry {
+			s.retries++
+		}
+	}
+	for _, s := range all {
+		sort.Slice(s.durations, func(i, j int) bool { return s.durations[i] < s.durations[j] })
+	}
+	sort.Slice(all, func(i, j int) bool { return all[i].provider < all[j].provider })
+	return all
+}
+
+// quantile returns the q-quantile of the durations (nearest rank).
+func (s *providerStats) quantile(q float64) time.Duration {
+	if len(s.durations) == 0 {
+		return 0
+	}
+	i := int(math.Ceil(q*float64(len(s.durations)))) - 1
+	if i < 0 {
+		i = 0
+	}
+	return s.durations[i]
+}
+
+// Summary returns one line per provider, e.g.
+// "HETZNER: 142 calls, p95 320ms, 3 retries".
+func (c *Collector) Summary() []string {
+	var lines []string
+	for _, s := range c.stats() {
+		lines = append(lines, fmt.Sprintf("%s: %d calls, p95 %s, %d retries",
+			s.provider, len(s.durations), s.quantile(0.95).Round(time.Millisecond), s.retries))
+	}
+	return lines
+}
+
+// WritePrometheus writes the metrics in the Prometheus text format: the
+// number of requests by endpoint and status, the retries, and a summary
+// of the durations by provider.
+func (c *Collector) WritePrometheus(w io.Writer) error {
+	type key struct {
+		provider, method, endpoint string
+		status                     int
+	}
+	c.mu.Lock()
+	counts := map[key]int{}
+	var keys []key
+	for _, r := range c.requests {
+		k := key{r.Provider, r.Method, r.Endpoint, r.Status}
+		if counts[k] == 0 {
+			keys = append(keys, k)
+		}
+		counts[k]++
+	}
+	c.mu.Unlock()
+	sort.Slice(keys, func(i, j int) bool {
+		a, b := keys[i], keys[j]
+		if a.provider != b.provider {
+			return a.provider < b.provider
+		}
+		if a.endpoint != b.endpoint {
+			return a.endpoint < b.endpoint
+		}
+		if a.method != b.method {
+			return a.method < b.method
+		}
+		return a.status < b.status
+	})
+
+	var b strings.Builder
+	b.WriteString("# HELP dnscontrol_provider_requests_total API requests of the providers.\n")
+	b.WriteString("# TYPE dnscontrol_provider_requests_total counter\n")
+	for _, k := range keys {
+		fmt.Fprintf(&b, "dnscontrol_provider_requests_total{provider=%s,method=%s,endpoint=%s,status=\"%d\"} %d\n",
+			label(k.provider), label(k.method), label(k.endpoint), k.status, counts[k])
+	}
+	stats := c.stats()
+	b.WriteString("# HELP dnscontrol_provider_request_retries_total API requests of the providers that were retries.\n")
+	b.WriteString("# TYPE dnscontrol_provider_request_retries_total counter\n")
+	for _, s := range stats {
+		fmt.Fprintf(&b, "dnscontrol_provider_request_retries_total{provider=%s} %d\n", label(s.provider), s.retries)
+	}
+	b.WriteString("# HELP dnscontrol_provider_request_duration_seconds Duration of the API requests of the providers.\n")
+	b.WriteString("# TYPE dnscontrol_provider_request_duration_seconds summary\n")
+	for _, s := range stats {
+		for _, q := range []float64{0.5, 0.95, 0.99} {
+			fmt.Fprintf(&b, "dnscontrol_provider_request_duration_seconds{provider=%s,quantile=\"%g\"} %g\n",
+				label(s.provider), q, s.quantile(q).Seconds())
+		}
+		fmt.Fprintf(&b, "dnscontrol_provider_request_duration_seconds_sum{provider=%s} %g\n", label(s.provider), s.total.Seconds())
+		fmt.Fprintf(&b, "dnscontrol_provider_request_duration_seconds_count{provider=%s} %d\n", label(s.provider), len(s.durations))
+	}
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// label quotes a label value of the Prometheus text format.
+func label(v string) string {
+	return `"` + strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`).Replace(v) + `"`
+}
@@ -0,0 +1,51 @@
+package apimetrics
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestCollector(t *testing.T) {
+	c := &Collector{}
+	SetHook(c)
+	defer SetHook(nil)
+	for i := 1; i <= 20; i++ {
+		Observe(Request{Provider: "HETZNER", Method: "GET", Endpoint: "/records", Duration: time.Duration(i) * 10 * time.Millisecond, Status: 200})
+	}
+	Observe(Request{Provider: "HETZNER", Method: "PUT", Endpoint: "/records/bulk", Duration: time.Second, Status: 429})
+	Observe(Request{Provider: "HETZNER", Method: "PUT", Endpoint: "/records/bulk", Duration: time.Second, Status: 200, Retry: true})
+	Observe(Request{Provider: "BIND", Method: "GET", Endpoint: `/"zone"`, Duration: time.Millisecond})
+
+	want := []string{
+		"BIND: 1 calls, p95 1ms, 0 retries",
+		"HETZNER: 22 calls, p95 1s, 1 retries",
+	}
+	if got := c.Summary(); !reflect.DeepEqual(got, want) {
+		t.Errorf("expected summary %q, got %q", want, got)
+	}
+
+	var b strings.Builder
+	if err := c.WritePrometheus(&b); err != nil {
+		t.Fatal(err)
+	}
+	for _, line := range []string{
+		`dnscontrol_provider_requests_total{provider="BIND",method="GET",endpoint="/\"zone\"",status="0"} 1`,
+		`dnscontrol_provider_requests_total{provider="HETZNER",method="GET",endpoint="/records",status="200"} 20`,
+		`dnscontrol_provider_requests_total{provider="HETZNER",method="PUT",endpoint="/records/bulk",status="429"} 1`,
+		`dnscontrol_provider_request_retries_total{provider="HETZNER"} 1`,
+		`dnscontrol_provider_request_duration_seconds{provider="HETZNER",quantile="0.5"} 0.11`,
+		`dnscontrol_provider_request_duration_seconds_count{provider="HETZNER"} 22`,
+	} {
+		if !strings.Contains(b.String(), line+"\n") {
+			t.Errorf("expected the line %s in\n%s", line, b.String())
+		}
+	}
+
+	SetHook(nil)
+	Observe(Request{Provider: "HETZNER"})
+	if n := len(c.requests); n != 23 {
+		t.Errorf("expected no requests to be collected without a hook, got %d requests", n)
+	}
+}
@@ -2,7 +2,9 @@ package diff
 
 import (
 	"fmt"
+	"regexp"
 	"sort"
+	"strings"
 
 	"github.com/gobwas/glob"
 
@@ -23,6 +25,8 @@ type Changeset []Correlation
 // Differ is an interface for computing the difference between two zones.
 type Differ interface {
 	// IncrementalDiff performs a diff on a record-by-record basis, and returns a sets for which records need to be created, deleted, or modified.
+	// Modifications that only change the TTL are flagged by Correlation.TTLOnly.
+	// Records with FORCE_REAPPLY are always modified, see Correlation.Forced.
 	IncrementalDiff(existing []*models.RecordConfig) (unchanged, create, toDelete, modify Changeset, err error)
 	// ChangedGroups performs a diff more appropriate for providers with a "RecordSet" model, where all records with the same name and type are grouped.
 	// Individual record changes are often not useful in such scenarios. Instead we return a map of record keys to a list of change descriptions within that group.
@@ -37,7 +41,7 @@ func New(dc *models.DomainConfig, extraValues ...func(*models.RecordConfig) map[
 		dc:          dc,
 		extraValues: extraValues,
 
-		// compile IGNORE_NAME glob patterns
+		// compile IGNORE_NAME glob and regex patterns
 		compiledIgnoredNames: compileIgnoredNames(dc.IgnoredNames),
 
 		// compile IGNORE_TARGET glob patterns
@@ -45,6 +49,15 @@ func New(dc *models.DomainConfig, extraValues ...func(*models.RecordConfig) map[
 	}
 }
 
+// CommentValues is an extra value function for New that compares the
+// comment metadata of records, so that a change of only the comment is a
+// modification. Providers that store record comments pass it; for the
+// others the comment is never at the provider and would show as changed
+// on every run.
+func CommentValues(r *models.RecordConfig) map[string]string {
+	return map[string]string{"comment": r.Metadata["comment"]}
+}
+
 type differ struct {
 	dc          *models.DomainConfig
 	extraValues []func(*models.RecordConfig) map[string]string
@@ -59,9 +72,21 @@ func (d *differ) content(r *models.RecordConfig) string {
 	// r.GetTargetDiffable().  In the meanwhile, this function compares
 	// its output with r.GetTargetDiffable() to make sure the same
 	// results are generated.  Once we have confidence, this function will go away.
-	content := fmt.Sprintf("%v ttl=%d", r.GetTargetCombined(), r.TTL)
-	if r.Type == "SOA" {
-		content = fmt.Sprintf("%s %v %d %d %d %d ttl=%d", r.GetTargetField(), r.SoaMbox, r.SoaRefresh, r.SoaRetry, r.SoaExpire, r.SoaMinttl, r.TTL) // SoaSerial is not used in comparison
+	var content string
+	if r.IsSPF() {
+		// Compare the policy, not how it is split into strings. This
+		// includes the routing already.
+		content = r.ToDiffable()
+	} else {
+		content = fmt.Sprintf("%v ttl=%d", r.GetTargetCombined(), r.TTL)
+		if r.Type == "DNAME" {
+			// Like for CNAME, the single target is a name and case-insensitive.
+			content = fmt.Sprintf("%s ttl=%d", strings.ToLower(r.GetTargetField()), r.TTL)
+		}
+		if r.Type == "SOA" {
+			content = fmt.Sprintf("%s %v %d %d %d %d ttl=%d", r.GetTargetField(), r.SoaMbox, r.SoaRefresh, r.SoaRetry, r.SoaExpire, r.SoaMinttl, r.TTL) // SoaSerial is not used in comparison
+		}
+		content += r.GetRoutingDiffable()
 	}
 	var allMaps []map[string]string
 	for _, f := range d.extraValues {
@@ -104,6 +129,36 @@ func ignoreNameException(rec *models.RecordConfig) bool {
 	return ok
 }
 
+// forceReapply returns whether rec is to be modified even if it matches the
+// existing record, see FORCE_REAPPLY.
+func forceReapply(rec *models.RecordConfig) bool {
+	return rec.Metadata["force_reapply"] == "true"
+}
+
+// GenerateMessageCorrections turns messages into informational corrections,
+// which are printed but not counted as changes. They are sorted, so that
+// the output is the same on every run.
+func GenerateMessageCorrections(msgs []string) []*models.Correction {
+	sorted := append([]string(nil), msgs...)
+	sort.Strings(sorted)
+	corrections := make([]*models.Correction, 0, len(msgs))
+	for _, msg := range sorted {
+		corrections = append(corrections, &models.Correction{Msg: msg, Informational: true})
+	}
+	return corrections
+}
+
+// targetIdentity returns what identifies the target of a record when
+// pairing existing and desired records. For CAA records this is the
+// (flag, tag, value) triple, as records with the same value but a
+// different tag are unrelated.
+func targetIdentity(rc *models.RecordConfig) string {
+	if rc.Type == "CAA" {
+		return fmt.Sprintf("%d %s %s", rc.CaaFlag, rc.CaaTag, rc.GetTargetField())
+	}
+	return rc.GetTargetField()
+}
+
 func (d *differ) IncrementalDiff(existing []*models.RecordConfig) (unchanged, create, toDelete, modify Changeset, err error) {
 	unchanged = Changeset{}
 	create = Changeset{}
@@ -174,7 +229,11 @@ func (d *differ) IncrementalDiff(existing []*models.RecordConfig) (unchanged, cr
 				if d.content(de) != d.content(ex) {
 					continue
 				}
-				unchanged = append(unchanged, Correlation{d, ex, de})
+				if forceReapply(de) {
+					modify = append(modify, Correlation{d, ex, de})
+				} else {
+					unchanged = append(unchanged, Correlation{d, ex, de})
+				}
 				existingRecords = existingRecords[:i+copy(existingRecords[i:], existingRecords[i+1:])]
 				desiredRecords = desiredRecords[:j+copy(desiredRecords[j:], desiredRecords[j+1:])]
 				break
@@ -185,7 +244,7 @@ func (d *differ) IncrementalDiff(existing []*models.RecordConfig) (unchanged, cr
 		for i := len(existingRecords) - 1; i >= 0; i-- {
 			ex := existingRecords[i]
 			for j, de := range desiredRecords {
-				if de.GetTargetField() == ex.GetTargetField() {
+				if targetIdentity(de) == targetIdentity(ex) {
 					// two records share a target, but different content (ttl or metadata changes)
 					modify = append(modify, Correlation{d, ex, de})
 					// remove from both slices by index
@@ -216,7 +275,11 @@ func (d *differ) IncrementalDiff(existing []*models.RecordConfig) (unchanged, cr
 		// if a record is in both, it is unchanged
 		for norm, ex := range existingLookup {
 			if de, ok := desiredLookup[norm]; ok {
-				unchanged = append(unchanged, Correlation{d, ex, de})
+				if forceReapply(de) {
+					modify = append(modify, Correlation{d, ex, de})
+				} else {
+					unchanged = append(unchanged, Correlation{d, ex, de})
+				}
 				delete(existingLookup, norm)
 				delete(desiredLookup, norm)
 			}
@@ -253,17 +316,20 @@ func (d *differ) IncrementalDiff(existing []*models.RecordConfig) (unchanged, cr
 		}
 	}
 
-	// Sort the lists. This is purely cosmetic.
+	// Sort the lists. This is cosmetic, but makes the output the same on
+	// every run: the lists are built by iterating over maps.
 	sort.Slice(unchanged, func(i, j int) bool { return ChangesetLess(unchanged, i, j) })
 	sort.Slice(create, func(i, j int) bool { return ChangesetLess(create, i, j) })
 	sort.Slice(toDelete, func(i, j int) bool { return ChangesetLess(toDelete, i, j) })
+	sort.Slice(modify, func(i, j int) bool { return ChangesetLess(modify, i, j) })
 
 	return
 }
 
-// ChangesetLess returns true if c[i] < c[j].
+// ChangesetLess returns true if c[i] < c[j], ordered by label, type and
+// target (including the TTL and other fields that are compared).
 func ChangesetLess(c Changeset, i, j int) bool {
-	var a, b string
+	var a, b *models.RecordConfig
 	// Which fields are we comparing?
 	// Usually only Desired OR Existing content exists (we're either
 	// adding or deleting records).  In those cases, just use whichever
@@ -273,18 +339,24 @@ func ChangesetLess(c Changeset, i, j int) bool {
 	// coin and picked to use Desired in that case.
 
 	if c[i].Desired != nil {
-		a = c[i].Desired.NameFQDN
+		a = c[i].Desired
 	} else {
-		a = c[i].Existing.NameFQDN
+		a = c[i].Existing
 	}
 
 	if c[j].Desired != nil {
-		b = c[j].Desired.NameFQDN
+		b = c[j].Desired
 	} else {
-		b = c[j].Existing.NameFQDN
+		b = c[j].Existing
 	}
 
-	return a < b
+	if a.NameFQDN != b.NameFQDN {
+		return a.NameFQDN < b.NameFQDN
+	}
+	if a.Type != b.Type {
+		return a.Type < b.Type
+	}
+	return a.ToDiffable() < b.ToDiffable()
 
 	// TODO(tlim): This won't correctly sort:
 	// []string{"example.com", "foo.example.com", "bar.example.com"}
@@ -366,9 +438,32 @@ func (c Correlation) String() string {
 	if c.Desired == nil {
 		return fmt.Sprintf("DELETE %s %s %s", c.Existing.Type, c.Existing.GetLabelFQDN(), c.d.content(c.Existing))
 	}
+	if c.Forced() {
+		return fmt.Sprintf("MODIFY-FORCED %s %s: (%s)", c.Desired.Type, c.Desired.GetLabelFQDN(), c.d.content(c.Desired))
+	}
+	if c.TTLOnly() {
+		return fmt.Sprintf("MODIFY-TTL %s %s: (%s) -> (%s)", c.Existing.Type, c.Existing.GetLabelFQDN(), c.d.content(c.Existing), c.d.content(c.Desired))
+	}
 	return fmt.Sprintf("MODIFY %s %s: (%s) -> (%s)", c.Existing.Type, c.Existing.GetLabelFQDN(), c.d.content(c.Existing), c.d.content(c.Desired))
 }
 
+// Forced returns true if c is a modification of a record that matches the
+// existing record, and is only rewritten because of FORCE_REAPPLY.
+func (c Correlation) Forced() bool {
+	return c.Existing != nil && c.Desired != nil && forceReapply(c.Desired) && c.d.content(c.Existing) == c.d.content(c.Desired)
+}
+
+// TTLOnly returns true if c is a modification that only changes the TTL.
+// Providers with an API to change just the TTL may use it for these.
+func (c Correlation) TTLOnly() bool {
+	if c.Existing == nil || c.Desired == nil || c.Existing.TTL == c.Desired.TTL {
+		return false
+	}
+	e := *c.Existing
+	e.TTL = c.Desired.TTL
+	return c.d.content(&e) == c.d.content(c.Desired)
+}
+
 func sortedKeys(m map[string]*models.RecordConfig) []string {
 	s := []string{}
 	for v := range m {
@@ -378,10 +473,31 @@ func sortedKeys(m map[string]*models.RecordConfig) []string {
 	return s
 }
 
+// regexpGlob matches IGNORE_NAME patterns that are regular expressions.
+type regexpGlob struct {
+	*regexp.Regexp
+}
+
+func (r regexpGlob) Match(s string) bool {
+	return r.MatchString(s)
+}
+
+// compileIgnoredNames compiles IGNORE_NAME patterns. A pattern enclosed in
+// slashes, e.g. "/^_acme-challenge\..*/", is a regular expression, anything
+// else is a glob pattern.
 func compileIgnoredNames(ignoredNames []string) []glob.Glob {
 	result := make([]glob.Glob, 0, len(ignoredNames))
 
 	for _, tst := range ignoredNames {
+		if len(tst) > 1 && strings.HasPrefix(tst, "/") && strings.HasSuffix(tst, "/") {
+			re, err := regexp.Compile(tst[1 : len(tst)-1])
+			if err != nil {
+				panic(fmt.Sprintf("Failed to compile IGNORE_NAME regex %q: %v", tst, err))
+			}
+			result = append(result, regexpGlob{re})
+			continue
+		}
+
 		g, err := glob.Compile(tst, '.')
 		if err != nil {
 			panic(fmt.Sprintf("Failed to compile IGNORE_NAME pattern %q: %v", tst, err))
@@ -115,6 +115,69 @@ func TestTTLChange(t *testing.T) {
 	checkLengths(t, existing, desired, 0, 0, 0, 1)
 }
 
+func TestTTLOnly(t *testing.T) {
+	existing := []*models.RecordConfig{
+		myRecord("www A 1 1.1.1.1"),
+		myRecord("api A 1 2.2.2.2"),
+		myRecord("mail MX 1 3.3.3.3"),
+	}
+	desired := []*models.RecordConfig{
+		myRecord("www A 300 1.1.1.1"),
+		myRecord("api A 300 2.2.2.3"),
+		myRecord("mail MX 1 3.3.3.3"),
+	}
+	existing[2].MxPreference = 10
+	desired[2].MxPreference = 20
+	_, _, _, mods := checkLengths(t, existing, desired, 0, 0, 0, 3)
+	ttlOnly := map[string]bool{}
+	for _, m := range mods {
+		ttlOnly[m.Desired.GetLabel()] = m.TTLOnly()
+	}
+	expected := map[string]bool{"www": true, "api": false, "mail": false}
+	for label, want := range expected {
+		if ttlOnly[label] != want {
+			t.Errorf("TTLOnly of %s: expected %v, got %v", label, want, ttlOnly[label])
+		}
+	}
+	for _, m := range mods {
+		if got := strings.HasPrefix(m.String(), "MODIFY-TTL "); got != m.TTLOnly() {
+			t.Errorf("unexpected description %q", m.String())
+		}
+	}
+}
+
+func TestForceReapply(t *testing.T) {
+	existing := []*models.RecordConfig{
+		myRecord("www A 300 1.1.1.1"),
+		myRecord("api A 300 2.2.2.2"),
+		myRecord("mail A 300 3.3.3.3"),
+	}
+	desired := []*models.RecordConfig{
+		myRecord("www A 300 1.1.1.1"),
+		myRecord("api A 300 2.2.2.2"),
+		myRecord("mail A 600 3.3.3.3"),
+	}
+	desired[0].Metadata["force_reapply"] = "true"
+	desired[2].Metadata["force_reapply"] = "true"
+	un, _, _, mods := checkLengths(t, existing, desired, 1, 0, 0, 2)
+	if un[0].Desired.GetLabel() != "api" {
+		t.Errorf("expected api to be unchanged, got %s", un[0].Desired.GetLabel())
+	}
+	forced := map[string]bool{}
+	for _, m := range mods {
+		forced[m.Desired.GetLabel()] = m.Forced()
+	}
+	// A forced record that changes anyway is a regular modification.
+	if !forced["www"] || forced["mail"] {
+		t.Errorf("expected only www to be forced, got %v", forced)
+	}
+	for _, m := range mods {
+		if got := strings.HasPrefix(m.String(), "MODIFY-FORCED "); got != m.Forced() {
+			t.Errorf("unexpected description %q", m.String())
+		}
+	}
+}
+
 func TestMetaChange(t *testing.T) {
 	existing := []*models.RecordConfig{
 		myRecord("www MX 1 1.1.1.1"),
@@ -133,6 +196,31 @@ func TestMetaChange(t *testing.T) {
 	checkLengths(t, existing, desired, 0, 0, 0, 1, getMeta)
 }
 
+func TestCommentChange(t *testing.T) {
+	existing := []*models.RecordConfig{
+		myRecord("www A 1 1.1.1.1"),
+		myRecord("mail A 1 2.2.2.2"),
+	}
+	desired := []*models.RecordConfig{
+		myRecord("www A 1 1.1.1.1"),
+		myRecord("mail A 1 2.2.2.2"),
+	}
+	existing[0].Metadata["comment"] = "team-a"
+	desired[0].Metadata["comment"] = "team-b"
+	desired[1].Metadata["comment"] = "team-b"
+	checkLengths(t, existing, desired, 2, 0, 0, 0)
+	_, _, _, mod := checkLengths(t, existing, desired, 0, 0, 0, 2, CommentValues)
+	for _, m := range mod {
+		if m.Desired.Metadata["comment"] != "team-b" {
+			t.Errorf("expected the desired comment, got %q", m.Desired.Metadata["comment"])
+		}
+	}
+
+	desired[0].Metadata["comment"] = "team-a"
+	delete(desired[1].Metadata, "comment")
+	checkLengths(t, existing, desired, 2, 0, 0, 0, CommentValues)
+}
+
 func TestMetaOrdering(t *testing.T) {
 	existing := []*models.RecordConfig{
 		myRecord("www MX 1 1.1.1.1"),
@@ -267,6 +355,47 @@ func TestInvalidGlobIgnoredName(t *testing.T) {
 	checkLengthsFull(t, existing, desired, 0, 1, 0, 0, false, []string{"www1", "www2", "[.www3"}, nil)
 }
 
+func TestRegexIgnoredName(t *testing.T) {
+	existing := []*models.RecordConfig{
+		myRecord("_acme-challenge.www TXT 1 token1"),
+		myRecord("_acme-challenge.api A 1 1.1.1.1"),
+		myRecord("_acme-challenge TXT 1 token2"),
+		myRecord("www4 MX 1 1.1.1.1"),
+	}
+	desired := []*models.RecordConfig{
+		myRecord("www4 MX 1 2.2.2.2"),
+	}
+	// "_acme-challenge" itself does not match and is deleted.
+	checkLengthsFull(t, existing, desired, 0, 0, 1, 1, false, []string{`/^_acme-challenge\..*/`}, nil)
+}
+
+func TestModifyingRegexIgnoredName(t *testing.T) {
+	existing := []*models.RecordConfig{
+		myRecord("dyn1 A 1 1.1.1.1"),
+	}
+	desired := []*models.RecordConfig{
+		myRecord("dyn2 A 1 2.2.2.2"),
+	}
+	dc := &models.DomainConfig{
+		Name:         "example.com",
+		Records:      desired,
+		IgnoredNames: []string{"/^dyn[0-9]+$/"},
+	}
+	if _, _, _, _, err := New(dc).IncrementalDiff(existing); err == nil {
+		t.Errorf("expected an error when adding a record that matches an IGNORE_NAME regex")
+	}
+}
+
+func TestInvalidRegexIgnoredName(t *testing.T) {
+	defer func() {
+		if r := recover(); r == nil {
+			t.Errorf("should panic: invalid regex for IGNORE_NAME")
+		}
+	}()
+
+	checkLengthsFull(t, nil, nil, 0, 0, 0, 0, false, []string{"/^(www/"}, nil)
+}
+
 func TestGlobIgnoredTarget(t *testing.T) {
 	existing := []*models.RecordConfig{
 		myRecord("www1 CNAME 1 ignoreme.com"),
@@ -350,3 +479,210 @@ func TestCaas(t *testing.T) {
 
 	checkLengthsFull(t, existing, desired, 3, 0, 0, 0, false, nil, nil)
 }
+
+func TestSPFChunking(t *testing.T) {
+	policy := "v=spf1 include:_spf.example.com ip4:192.0.2.0/24 -all"
+	existing := myRecord("@ TXT 300 placeholder")
+	existing.SetTargetTXTs([]string{policy[:20], policy[20:]})
+	desired := myRecord("@ TXT 300 placeholder")
+	if err := desired.SetTargetSPF(policy); err != nil {
+		t.Fatal(err)
+	}
+	checkLengths(t, []*models.RecordConfig{existing}, []*models.RecordConfig{desired}, 1, 0, 0, 0)
+
+	changed := myRecord("@ TXT 300 placeholder")
+	changed.SetTargetSPF("v=spf1 include:_spf.example.com -all")
+	checkLengths(t, []*models.RecordConfig{existing}, []*models.RecordConfig{changed}, 0, 0, 0, 1)
+}
+
+func TestCaaSingleValueChange(t *testing.T) {
+	existing := []*models.RecordConfig{
+		myRecord("@ CAA 300 placeholder"),
+		myRecord("@ CAA 300 placeholder"),
+		myRecord("@ CAA 300 placeholder"),
+	}
+	existing[0].SetTargetCAA(0, "issue", "letsencrypt.org")
+	existing[1].SetTargetCAA(0, "issue", "amazon.com")
+	existing[2].SetTargetCAA(0, "iodef", "mailto:security@example.com")
+
+	desired := []*models.RecordConfig{
+		myRecord("@ CAA 300 placeholder"),
+		myRecord("@ CAA 300 placeholder"),
+		myRecord("@ CAA 300 placeholder"),
+	}
+	desired[0].SetTargetCAA(0, "issue", "letsencrypt.org")
+	desired[1].SetTargetCAA(0, "issue", "digicert.com")
+	desired[2].SetTargetCAA(0, "iodef", "mailto:security@example.com")
+
+	_, _, _, mod := checkLengths(t, existing, desired, 2, 0, 0, 1)
+	if mod[0].Existing != existing[1] || mod[0].Desired != desired[1] {
+		t.Errorf("expected the changed issue value to be modified, got %s", mod[0])
+	}
+}
+
+func TestSvcbParamOrder(t *testing.T) {
+	existing := []*models.RecordConfig{
+		myRecord("@ HTTPS 300 placeholder"),
+		myRecord("@ HTTPS 300 placeholder"),
+	}
+	existing[0].SetTargetSVCB(1, ".", `alpn="h2,h3" ipv4hint=192.0.2.1`)
+	existing[1].SetTargetSVCB(2, "backup.example.com.", `port=8443`)
+
+	desired := []*models.RecordConfig{
+		myRecord("@ HTTPS 300 placeholder"),
+		myRecord("@ HTTPS 300 placeholder"),
+	}
+	desired[0].SetTargetSVCB(1, ".", `ipv4hint=192.0.2.1 alpn=h2,h3`)
+	desired[1].SetTargetSVCB(2, "backup.example.com.", `port=8444`)
+
+	_, _, _, mod := checkLengths(t, existing, desired, 1, 0, 0, 1)
+	if mod[0].Existing != existing[1] || mod[0].Desired != desired[1] {
+		t.Errorf("expected only the changed port to be modified, got %s", mod[0])
+	}
+}
+
+func TestCaaMatchedByTriple(t *testing.T) {
+	existing := []*models.RecordConfig{
+		myRecord("@ CAA 300 placeholder"),
+		myRecord("@ CAA 300 placeholder"),
+	}
+	existing[0].SetTargetCAA(0, "issue", "letsencrypt.org")
+	existing[1].SetTargetCAA(0, "issuewild", "letsencrypt.org")
+
+	// Same values, new TTL: each record must be paired with the one with the same tag.
+	desired := []*models.RecordConfig{
+		myRecord("@ CAA 600 placeholder"),
+		myRecord("@ CAA 600 placeholder"),
+	}
+	desired[0].SetTargetCAA(0, "issue", "letsencrypt.org")
+	desired[1].SetTargetCAA(0, "issuewild", "letsencrypt.org")
+
+	_, _, _, mod := checkLengths(t, existing, desired, 0, 0, 0, 2)
+	for _, m := range mod {
+		if m.Existing.CaaTag != m.Desired.CaaTag {
+			t.Errorf("expected records with the same tag to be paired, got %s", m)
+		}
+	}
+}
+
+func TestFilterOwned(t *testing.T) {
+	existing := []*models.RecordConfig{
+		myRecord("mine A 1 1.2.3.4"),
+		myRecord("theirs A 1 1.2.3.5"),
+	}
+	dc := &models.DomainConfig{Name: "example.com"}
+	_, _, toDelete, _, err := New(dc).IncrementalDiff(existing)
+	if err != nil {
+		t.Fatal(err)
+	}
+	kept, msgs := FilterOwned(toDelete, func(rc *models.RecordConfig) bool { return rc.GetLabel() == "mine" })
+	if len(kept) != 1 || kept[0].Existing.GetLabel() != "mine" {
+		t.Errorf("expected only the owned record to be deleted, got %v", kept)
+	}
+	if len(msgs) != 1 || !strings.Contains(msgs[0], "theirs.example.com") {
+		t.Errorf("expected a message about the unowned record, got %v", msgs)
+	}
+}
+
+func TestDnameCaseInsensitive(t *testing.T) {
+	existing := []*models.RecordConfig{
+		myRecord("old DNAME 300 new.example.com."),
+		myRecord("other DNAME 300 a.example.com."),
+	}
+	desired := []*models.RecordConfig{
+		myRecord("old DNAME 300 NEW.example.com."),
+		myRecord("other DNAME 300 b.example.com."),
+	}
+	checkLengths(t, existing, desired, 1, 0, 0, 1)
+}
+
+func TestRoutingIsCompared(t *testing.T) {
+	existing := []*models.RecordConfig{
+		myRecord("www A 300 1.2.3.4"),
+		myRecord("api A 300 1.2.3.5"),
+		myRecord("mail A 300 1.2.3.6"),
+	}
+	existing[0].Routing = map[string]string{"policy": "weighted", "weight": "10"}
+	existing[1].Routing = map[string]string{"policy": "weighted", "weight": "10"}
+	desired := []*models.RecordConfig{
+		myRecord("www A 300 1.2.3.4"),
+		myRecord("api A 300 1.2.3.5"),
+		myRecord("mail A 300 1.2.3.6"),
+	}
+	desired[0].Routing = map[string]string{"weight": "10", "policy": "weighted"}
+	desired[1].Routing = map[string]string{"policy": "weighted", "weight": "20"}
+	desired[2].Routing = map[string]string{"region": "eu-central"}
+	_, _, _, mod := checkLengths(t, existing, desired, 1, 0, 0, 2)
+	if len(mod) == 2 && !strings.Contains(mod[0].String()+mod[1].String(), "routing.weight=20") {
+		t.Errorf("expected the modification to show the routing, got %v", mod)
+	}
+}
+
+func TestRoutingOfSPF(t *testing.T) {
+	spf := func(policy, weight string) *models.RecordConfig {
+		r := myRecord("@ TXT 300 placeholder")
+		if err := r.SetTargetSPF(policy); err != nil {
+			t.Fatal(err)
+		}
+		r.Routing = map[string]string{"weight": weight}
+		return r
+	}
+	existing := []*models.RecordConfig{spf("v=spf1 -all", "10")}
+	checkLengths(t, existing, []*models.RecordConfig{spf("v=spf1 -all", "10")}, 1, 0, 0, 0)
+	_, _, _, mod := checkLengths(t, existing, []*models.RecordConfig{spf("v=spf1 -all", "20")}, 0, 0, 0, 1)
+	if len(mod) == 1 && strings.Count(mod[0].String(), "routing.weight=20") != 1 {
+		t.Errorf("expected the routing once, got %v", mod[0])
+	}
+}
+
+func TestDeterministicOrder(t *testing.T) {
+	existing := []*models.RecordConfig{
+		myRecord("www A 300 1.1.1.1"),
+		myRecord("www A 300 1.1.1.2"),
+		myRecord("api A 300 2.2.2.2"),
+		myRecord("api TXT 300 old"),
+		myRecord("mail MX 300 mx.example.com."),
+		myRecord("old A 300 3.3.3.3"),
+		myRecord("old AAAA 300 ::1"),
+	}
+	desired := []*models.RecordConfig{
+		myRecord("www A 600 1.1.1.1"),
+		myRecord("www A 600 1.1.1.2"),
+		myRecord("api A 600 2.2.2.2"),
+		myRecord("mail MX 600 mx.example.com."),
+		myRecord("new AAAA 300 ::2"),
+		myRecord("new A 300 4.4.4.6"),
+		myRecord("new A 300 4.4.4.5"),
+	}
+	render := func(cs Changeset) string {
+		var lines []string
+		for _, c := range cs {
+			lines = append(lines, c.String())
+		}
+		return strings.Join(lines, "\n")
+	}
+	var first [3]string
+	for i := 0; i < 20; i++ {
+		_, cre, del, mod := checkLengths(t, existing, desired, 0, 3, 3, 4)
+		got := [3]string{render(cre), render(del), render(mod)}
+		if i == 0 {
+			first = got
+			continue
+		}
+		if got != first {
+			t.Fatalf("run %d: expected the same order as in the first run, got\n%v\ninstead of\n%v", i, got, first)
+		}
+	}
+	want := "CREATE A new.example.com 4.4.4.5 ttl=300\nCREATE A new.example.com 4.4.4.6 ttl=300\nCREATE AAAA new.example.com ::2 ttl=300"
+	if first[0] != want {
+		t.Errorf("expected the creations sorted by type and target, got\n%s", first[0])
+	}
+	if i, j := strings.Index(first[2], "api"), strings.Index(first[2], "www"); i > j {
+		t.Errorf("expected the modifications sorted by label, got\n%s", first[2])
+	}
+
+	msgs := GenerateMessageCorrections([]string{"b", "a"})
+	if msgs[0].Msg != "a" || msgs[1].Msg != "b" {
+		t.Errorf("expected the messages sorted, got %q %q", msgs[0].Msg, msgs[1].Msg)
+	}
+}
@@ -0,0 +1,24 @@
+package diff
+
+import (
+	"fmt"
+
+	"github.com/StackExchange/dnscontrol/v3/models"
+)
+
+// FilterOwned removes the deletions of records that owned reports as not
+// owned, so that records managed by other tools in a shared zone are left
+// alone. It returns the remaining deletions and a message for each record
+// that is kept, suitable for GenerateMessageCorrections.
+func FilterOwned(toDelete Changeset, owned func(*models.RecordConfig) bool) (Changeset, []string) {
+	kept := Changeset{}
+	var msgs []string
+	for _, c := range toDelete {
+		if owned(c.Existing) {
+			kept = append(kept, c)
+			continue
+		}
+		msgs = append(msgs, fmt.Sprintf("Not deleting unowned record %s %s %s", c.Existing.Type, c.Existing.GetLabelFQDN(), c.Existing.GetTargetCombined()))
+	}
+	return kept, msgs
+}
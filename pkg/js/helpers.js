@@ -188,6 +188,35 @@ function DefaultTTL(v) {
     };
 }
 
+// MIN_TTL(v): Raise the TTL of all records of the domain to at least v.
+function MIN_TTL(v) {
+    if (_.isString(v)) {
+        v = stringToDuration(v);
+    }
+    return { min_ttl: v.toString() };
+}
+
+// MAX_TTL(v): Lower the TTL of all records of the domain to at most v.
+function MAX_TTL(v) {
+    if (_.isString(v)) {
+        v = stringToDuration(v);
+    }
+    return { max_ttl: v.toString() };
+}
+
+// ALIAS_FLATTENING(v): Publish the ALIAS records of the domain as the A and
+// AAAA records their targets resolve to, with a TTL of at most v, if a
+// provider of the domain can not use ALIAS records.
+function ALIAS_FLATTENING(v) {
+    if (v === undefined) {
+        v = 300;
+    }
+    if (_.isString(v)) {
+        v = stringToDuration(v);
+    }
+    return { alias_flattening: v.toString() };
+}
+
 function makeCAAFlag(value) {
     return function(record) {
         record.caaflag |= value;
@@ -218,6 +247,10 @@ var AAAA = recordBuilder('AAAA');
 // ALIAS(name,target, recordModifiers...)
 var ALIAS = recordBuilder('ALIAS');
 
+// APL(name,target, recordModifiers...)
+// target is in presentation format, e.g. '1:192.168.0.0/16 !1:192.168.38.0/28'.
+var APL = recordBuilder('APL');
+
 // AZURE_ALIAS(name, type, target, recordModifiers...)
 var AZURE_ALIAS = recordBuilder('AZURE_ALIAS', {
     args: [
@@ -261,6 +294,20 @@ var R53_ALIAS = recordBuilder('R53_ALIAS', {
     },
 });
 
+// ROUTING(params) sets the routing policy of a record, e.g.
+// A('www', '192.0.2.1', ROUTING({policy: 'weighted', weight: 10})).
+// The keys and values are specific to the provider.
+function ROUTING(params) {
+    return function(r) {
+        if (!_.isObject(r.routing)) {
+            r.routing = {};
+        }
+        for (var key in params) {
+            r.routing[key] = String(params[key]);
+        }
+    };
+}
+
 // R53_ZONE(zone_id)
 function R53_ZONE(zone_id) {
     return function(r) {
@@ -313,6 +360,46 @@ var CAA = recordBuilder('CAA', {
 // CNAME(name,target, recordModifiers...)
 var CNAME = recordBuilder('CNAME');
 
+// CSYNC(name,serial,flags,types, recordModifiers...)
+// types is an array of type names, e.g. ['A', 'AAAA', 'NS'].
+var CSYNC = recordBuilder('CSYNC', {
+    args: [
+        ['name', _.isString],
+        ['serial', _.isNumber],
+        ['flags', _.isNumber],
+        ['types', _.isArray],
+    ],
+    transform: function(record, args, modifiers) {
+        record.name = args.name;
+        record.csyncserial = args.serial;
+        record.csyncflags = args.flags;
+        record.csynctypes = args.types;
+        record.target = args.types.join(' ');
+    },
+});
+
+// DNAME(name,target, recordModifiers...)
+var DNAME = recordBuilder('DNAME');
+
+// HINFO(name,cpu,os, recordModifiers...)
+var HINFO = recordBuilder('HINFO', {
+    args: [
+        ['name', _.isString],
+        ['cpu', _.isString],
+        ['os', _.isString],
+    ],
+    transform: function(record, args, modifiers) {
+        record.name = args.name;
+        record.hinfocpu = args.cpu;
+        record.hinfoos = args.os;
+        record.target = args.cpu;
+    },
+});
+
+// LOC(name,target, recordModifiers...)
+// target is in presentation format, e.g. '52 22 23 N 4 53 32 E -2m'.
+var LOC = recordBuilder('LOC');
+
 // DS(name, keytag, algorithm, digestype, digest)
 var DS = recordBuilder("DS", {
     args: [
@@ -431,6 +518,31 @@ var TLSA = recordBuilder('TLSA', {
     },
 });
 
+// svcbRecord returns the options of the SVCB and the HTTPS record builders.
+// params is in presentation format, e.g. 'alpn="h2,h3" ipv4hint=192.0.2.1'.
+function svcbRecord() {
+    return {
+        args: [
+            ['name', _.isString],
+            ['priority', _.isNumber],
+            ['target', _.isString],
+            ['params', _.isString],
+        ],
+        transform: function(record, args, modifiers) {
+            record.name = args.name;
+            record.svcpriority = args.priority;
+            record.target = args.target;
+            record.svcparams = args.params;
+        },
+    };
+}
+
+// SVCB(name, priority, target, params, recordModifiers...)
+var SVCB = recordBuilder('SVCB', svcbRecord());
+
+// HTTPS(name, priority, target, params, recordModifiers...)
+var HTTPS = recordBuilder('HTTPS', svcbRecord());
+
 function isStringOrArray(x) {
     return _.isString(x) || _.isArray(x);
 }
@@ -547,6 +659,12 @@ var IGNORE_NAME_DISABLE_SAFETY_CHECK = {
   // See https://github.com/StackExchange/dnscontrol/issues/1106
 };
 
+// FORCE_REAPPLY marks a record to be written to the providers on every run,
+// even if it matches the existing record.
+var FORCE_REAPPLY = {
+  force_reapply: "true"
+};
+
 // IGNORE_TARGET(target)
 function IGNORE_TARGET(target, rType) {
     return function(d) {
@@ -0,0 +1,4 @@
+D("foo.com","none",
+    HTTPS("@",1,".",'alpn="h2,h3" ipv4hint=192.0.2.1'),
+    SVCB("_8443._foo.api",0,"svc.example.net.","")
+);
@@ -0,0 +1,3 @@
+D("foo.com","none",
+    DNAME("old","new.foo.com.")
+);
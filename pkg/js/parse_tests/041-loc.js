@@ -0,0 +1,3 @@
+D("foo.com","none",
+    LOC("@","52 22 23 N 4 53 32 E -2m")
+);
@@ -0,0 +1,4 @@
+D("foo.com","none",
+    A("www","1.2.3.4",ROUTING({policy: "weighted", weight: 10})),
+    A("www","1.2.3.5",ROUTING({policy: "weighted"}),ROUTING({weight: 20}))
+);
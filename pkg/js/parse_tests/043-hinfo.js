@@ -0,0 +1,4 @@
+D("foo.com","none",
+    HINFO("@","INTEL 386","Linux"),
+    HINFO("www","ARM","FreeBSD 13")
+);
@@ -0,0 +1,4 @@
+D("foo.com","none",
+    APL("@","1:192.168.0.0/16 !1:192.168.38.0/28"),
+    APL("net","2:2001:db8::/32")
+);
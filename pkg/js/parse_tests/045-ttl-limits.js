@@ -0,0 +1,3 @@
+D("foo.com", "none", MAX_TTL("1h"), MIN_TTL(60),
+    A("@", "1.2.3.4", TTL("1d"))
+);
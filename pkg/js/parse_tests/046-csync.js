@@ -0,0 +1,3 @@
+D("foo.com","none",
+    CSYNC("@", 0, 3, ["A", "NS", "AAAA"])
+);
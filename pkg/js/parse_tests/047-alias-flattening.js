@@ -0,0 +1,2 @@
+D("foo.com", "none", ALIAS_FLATTENING());
+D("bar.com", "none", ALIAS_FLATTENING("1m"));
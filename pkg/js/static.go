@@ -212,151 +212,163 @@ var _escData = map[string]*_escFile{
 	"/helpers.js": {
 		name:    "helpers.js",
 		local:   "pkg/js/helpers.js",
-		size:    33082,
+		size:    36605,
 		modtime: 0,
 		compressed: `
-H4sIAAAAAAAC/+x9/XMbN7Lg7/or2qp7GTKmKcle+72ilu+W0UdWtfoqks56n07HB3EwJOwhZhbAiGJi
-5W+/wtcMMIOhZN0mqbq6/OBogEaj0Wg0uhsNMCo4Bi4YmYvocGdnbw/OEthkBeCYCBBLwiEhKe6pslXB
-BbCCwn8vMlhgihkS+L9BZIBXdzhW4BKFbAGEglhi4FnB5hjmWYz7Ln7EMCwxuifpBmJ8VywWhC50hxK2
-pxrvvonx/S4kKVrAmqSpbM8wiivCICYMz0W6AUK5kFVZAgXXuDBkhcgLAVkiW3pU9+EfWRGlKXBB0hQo
-lvRngdHd4SRjWLaXZM+z1UoxBsN8iegC8/7Ozj1iMM9oAkP4ZQcAgOEF4YIhxgdwc9tTZTHls5xl9yTG
-XnG2QoQ2CmYUrbApfTzUXcQ4QUUqRmzBYQg3t4c7O0lB54JkFAglgqCU/Iw7XUOER1EbVVsoC1L3eKiJ
-bJDyqCZ3jEXBKAdEATGGNnI2DA5YL8l8CWvMsKEEMxwDzyCRYyuYnDNWUEFWittXawrl8JJMcniVI0Hu
-SErERooBzyiHjAFJgGcrDDHaAM/xnKAUcpbNMVdysM6KNIY72es/C8Jw3K/YtsDiKKMJWRQMx8ea0JKB
-TA1G8bHvzooabIniEq/HlrEdWd8DsclxD1ZYIIuKJNCRpV1nOuQ3DIcQXYwuP47OI83ZR/WvnG6GF3L6
-QOIcQIV54OAfqH/trChKq1nu5wVfdhhedA/d8UhMjSEcU35tRODJQWSJ7nUoic/uPuO5iOC77yAi+Wye
-0XvMOMkoj6QKcNvL/+R334eDoZzeFRIzITqB+m6dMTHPX8IYT8w1b2KeP8UbitdaLgxbSvbWpKQaokNW
-WcaLOy1BA4iiXnNFDqo/ex6vBvDLows/z1jcXL7X1ep1wc0qnU7PB7Df8wjkmN03VjtZ0Izh2NU99SqB
-2AILXyG47DLr7hixBe+sembxW17JvSFjgNF8CassJgnBrCfligggHFC/3y/hDMYBzFGaSoA1EUuDzwIp
-HTOwnUr2FIyTe5xuLIQWTykNbIFVN1RkirMxEqgU61mf8FPTY2fV9SS2Y8ZgxBBwynHZaCQpqLWQQ+xI
-Qf2sVoBbJf/zWXTz+bbk0mEJ9xjq60qNpdbZrI8fBKaxobIvh9aDlU+to3SWLFtD9PfR+PLs8seB6bmc
-DK2UCsqLPM+YwPEAInjtkW81QK04gmMr4LUaQ5heWnpwerM41kuqWlEDOGIYCQwIji8nBmEfPnKsNtwc
-MbTCAjMOiNu1AIjGknzuaPXjtrWqtIce8XDLytZkltNIYAj7h0Dgz+6+108xXYjlIZDXr90J8abXgb8h
-9Yl+bHbzVneD2KJYYSpaO5HwKxhWgDfk9jBMwirYq5SpxsbWJzTGD1eJYkgXXg2H8Oag25AeWQuvIZJL
-NsbzFMl9fJUxOUuIQkbn2NvMnH6s3nUJapKhYBQN1q44np18mp5c6ontDuBjHtflBFAqTcMNoDjGsdYW
-x51uT1oIpfqVcsRwljiy4mEOyclsgYXuwixAQ5llowUcAi3SdAu71ogDzUTFsw0WSnwVUdLKhDmiEuIO
-Q6FGGGvpP+50jR3a9zhrllZ297lfDXGoepQFXLDOfk9/akF647RwiuENHISk/uA3FEdJQ7dNTG4MDIlv
-Yeg0OJQ6PcUi4pDdY7ZmRGjdoPV834hLeMoGMJVuA1nlKVZUqpZWAyIxXxK6kM1RusgYEcsVFBzHcLep
-pKTbhyNEY6LET7XBXPkyiAJ+QHOhCyWWLHHwR9wYKtpeVTIhdzzJnBy7EqqbSQReyz5MlxjSTLocphOJ
-QFsfnk0bHnxQAxZpelgrPsdUqbtWFeit5i3yIF20SznMoT+z5PZmV1K060iI9m64NM4nRZKQBxjCbn8X
-XpdYfNgkK2gF6Yr7Gw+Noc/ZWLUDqtxHwmuTJudGuawasZlda5PY5a6mTpq+5QC/fvUJGg79wdQNAIeG
-ch6RnlpmSrQiLRjMC8YwlRrBzrpLT2mVG1Lscv7PajLrnVdqQ890relhC7AyuEk8ANKTa21Qn1NrafsG
-jGPKuLayblbq9pPT0cfz6QSMcS6ZwbFQrqPePiu9Il10lOfpRv2RppAUomB2kfG+xHcirUtlNIqsQr6W
-Xv48xYgBohvIGb4nWcHhHqUF5rJD14AwrUpXsOnvti2PJ3Wla0Kojc5Vml3fQppOzzv33QFMsA45TKfn
-qlO972kLyCFbgzvemrQaJ0J61p17z2q8h6GK+tDFNDsuGFJ2772njs1cWeQd5rZnfSFSGML9YcgJCGB2
-1I/VmkO476u/O3v/u/O/4tfdzg1fLeM13dz+z+7/2HN22LJF2xZ7b80RuXkiOackhtj0bsjxNs6CEgFD
-iHjU6OXm7a3bgYGsKj1vFIbSKuX4jIqy/YGdRTnYQi0cPoCDHqwG8GG/B8sBvPuwv29XTHETxZHc5Yr+
-Er6Ht38qi9emOIbv4d/LUuqUvtsvizdu8Yf3hgL4fgjFjRzDrefn3peLr3QRPUGzC88KXLWRuavEbfsb
-SV3sLZ1+5dG2Ct8KfcFHo9FpihYdtbhrjnol0Gr5eFKtF9QcIRVx/DrU2sHtZm8Pjkaj2dH4bHp2NDqX
-HgsRZI5SWawClSpU58Io6aloOoA//xn+vauDrW7YZdcGJ6Q63u3BfldCUH6UFVRpw31YYUQ5xBmNhDRN
-5IZlQ2lKqzmefd9tLJeFxW6QyOYoTd3pbISATPNA/MciViGggsY4IRTHkcvMEgTeHHzLDDvRjBtJhhRr
-g6s2ESNNJsl7ZuYujBcr9+yumocRDE3dDwVJ5ciiUWR4PxqNnoNhNAohGY0qPOdno4lGpKMjW5BJ0AA2
-WVyi+6+P45OZg9REtZ7EXbUL9FBVRj3Db2mOD+Cm5P1NJLuLelCtXycAdBNJMqKeVq5I4NHPBcOjlCA+
-3eTYh1SkhjCZ/wmGKE8ythrUl2NPkdUrAxKB5akNMAXnBBUcAN29BdFfh54N50RTTBskRzNDcjjdusnU
-BDHMuC372OQOGY2gSxiJ2hl03LJE4ppRxnDq7Tx23Uh/mP++qpNjfOWqYVXp81KvQpRyHFidN9Eo6oEW
-8x5ER5eji5PotowPmM50gKCM/b9/54utEVgtvm1iW7ZqCm1Z9a8S2fH7d7+5wPLfS2LZ+3fb5bUEeLm0
-lii+TVaNMPzX1eVJ5+eM4hmJu5UAN6ra9md3XHUebBu+O3LThxq8+fupoddGbVoN7B+BYfsGSEja/sXL
-s1PJrh+EHTmHC7pArWC/TK/memET7uJTvWT6aVovup6O60WT69NG0finetHlyG/aol1UfdexvexOu+gp
-uHbNchTauNUwq9OI6dXxVUekZNUdwJkAvrRnhYgCZkwHa1Q/1rvYl0bXwdv/6L9MIaFFe6Xq549TQnOE
-BFpUSmjxhJpybWNNoO3+sljdYRag0lsFTYub103uSp8omX2ekaVAAzOvpN7a3XaT+oI3UpSqkF8PYrLA
-XG9a+k+N9ri5Q+0eT3ZfujXpjk29ZphXXxLUDqKpM3vcVhifjN9RpmKux2mB9FcArAq5GsiyIABcDdxC
-VyWt4D7oN2zBjhReT8fPk8Hr6bgpgVLfGURK+WlUGYsx6+UMJ5hhOsc9tRJ60o0jc3U6hh/yJztUCJtd
-GiX7QhlVpLXLVkVzO4waTHsPZpTtAHr42xTqH2u5UZQLpvhkwdRHGK5imAWuSsIttFY0wOojDGf4aCHN
-ZxhWs9SC6q+XLYfJldmNKe+t7rIHKbQEpT2GE4b5ssewYJsefsgJw70VoWRVrNpFeHIV2K8nV3a/doW3
-FFx1AF6beEcoQpWS0NaWhvKQPMtKwTaqaaBSj9L2WatcESpEGqhU/7xARLeK55NTaAB4hiQzLIT8u15v
-+FEJi/psQgm2AaigBNvUYTR/Shj92SBH8akkSH1JGFfmxj9pmcsZkRvEprfGZLEUvTxj4kk1ORn/FJAx
-aZy+UEVaKto1oCZvixbN2JbaP1q/cXZvh1jpLP0dgtWDtZD6K4gzYyWU/PuF+mfy19NrLQ2V/aYstydc
-A9UwIAiy+MWi8AyLLSF0gVnOCN0y5X+wG8D5Msm/wRxT8M7Ayt2qKvomR8JOrrbPC44WuAccp3guMtYr
-z+m1gT7HTJCEzJHAamKn55PAJiJLXzytioL22bKUtUO4FH/jQgeV7+yMReUpc0Cwq+F3y/PG3zNalXKk
-uGKh1EcQzHKnMkz0dxDYZVS5BzhlL1ASVX604ekV0xl7D7WokxONeejC169QJfc96OiDis1/nF5Nrs/P
-pjr1KWd4rpN0zoSOD6wBAc3eZHlfx+RL+CH8Ao9asKefps9zIqafpgFZ/jR9eTjUyliNG7+PfpEKW+gs
-MWyOATkkLFupgoJjBveY3SFBVv1G3M/MjTPRbWFP8SAs8iHcOA1uD4PgIRmStF6Z/CKBKdxtFI0/Zupu
-wrNCpx4ZwbDuE0T0P2eEdnZ3u8+mpq5BLz7VLKWnBO7iU1PeLj79hrbRH23drB5CLlmLefMsk+TymSeB
-l4HzjstJFR64OJmcjH868cINTgy9BuAGlusJKPBqCIEkzqhCARlNN4Dmc5wLDhnF5aaizv5VelX0DUe4
-7im0ynBxU/XhsVs7xq0ImbXluzi0mrTffogXs98iFeEXoHwmRDqA+77IDLJuPehf3WAoRXYm0F2KndT3
-qTpZu0mztUoHWZLFcgBve0Dx+gfE8QDe3fZAV//JVr9X1WfXA/hwe2sRqRz23QP4Fd7Cr/AOfj2EP8Gv
-8B5+BfgVPuyW2ScpofiphKUavdtS+kgOwzq8l+kpgRS5MASS99Wf/jmWKqprbj+ZXoOE8tYs6ll/hXIN
-16ukkISauHc7itXbOBMd0m0muT12tbqNelGtNqjjXWIsWk329iw4h0dyxksuyY8Gn2Thk5xSQC28Ml2U
-3JLffyi/DEEOxxT5z+OZVFpDuCmpyvtptu72wCmQS6ZbriezchzxVMvB3IrK1mYE8CtE3dDC19AG6BCi
-8hDq7MfLq7E+jHBUsltarfnKSOyplBsNNZM6y+3LKfYT3xsV9Q6dqpZz1Jp29i75eKn2lVaW/HbQz47P
-JqMfzk9mk9HpyfQfs6O/nhz9zVwt1OgUtllMuFQJM44SLDaz+RLPvwxgV7ACS50kVeBSGs4aTOV5KkhQ
-kFKtYRrre5g5w/eYioFudtCH6TqDbE0x4yCyxSIldAHI7AZwh8UaYwpinQHHQkizq6+bvtWJ05lYYqYR
-wJrkqnWaVpdIzF3XFN3htGevKmY0EhrLHQaaCTLHMRRUkFTtThQ/CBBkhSGmfJ5RwbJU5WQX1HQ+wRiW
-QuR8sLe3IGJZ3PXn2WpvItD8y8mDvkC6VzXeI5wXmO8dHOx/2DHegpmG6Wj848m00zAEQtU9YFPnruEz
-5cHc7DI7do6EwIwOvCyOgUbs7+CKyIvrq/F0Nh2PLienV+MLvQmmalfV20R5CUXLVg2+aQvVIepG6E3U
-6CKSu2dkkubV3zrm6tie/0qrMvpL9ISJaNOc60YnFsiQX22jKkGhMiK0iVkfYbfZYRUtNaFS/7zq4/jH
-k44jLrqglIC4/zeM84/0C83WVBKg8w2MXXY1a7Qvy1pRyMVeJuh9nF4dX04mJ0eKGMxW0oGJbc41Yngg
-K3Z3AY4zdbqu+K7dG7OOoePko6qMyN2M7gLACZUscfowiapSwehLggo2SSR2rXG2AZdDrGBmV5d2nHEf
-FSKbxZRzPIehokGOMtjq9LS9WZK0tbNt5hnlmbTDsoVO89gtL+s55D8ZfwC4TrHcb+Wu440JMlYjV6tI
-m/NLVJb9Cn2ROs+shLmSQt7XCnyFuQr/qJx6qc3zHEvzkAKyCfkMq977Uu+bzez773fge/hLRfYOfL/n
-XcUu3aSOXoVcICa81PEsbjVnFXCZg9+afq+uCtq8ey/l3tGVEsgleqy3GRWYutMqSo1F3UiEX7Qj8ajr
-HdgQTJYL3ldd397s38LIelpSq7jwli9Dv8nBLVzlshylNtEoY9valXoG7P3W6g6Fd63C3iaA7y2rplIE
-WvMyEXfuOsCIbiqlqQXjDju4ZIcEx+YWm3m/wRDUd1JvVoVA5krXgtxj6pLVyho5GCs7gWFWdIlMYdY4
-ffHz9x8dXZbYrezIv5UxbZYJ7/zyqCF6jnSVu1MgMlLFO+Q+VLnjL9uMjH1pDCDF8CW6x85gy/uQmvX1
-lhK3nShA1NygU2vKuWhrMrtDQav26Irrqeidd2vgLrSBWqvebfdMR+NZOZU1T8OZD0+aAnPSOhsh57oE
-blNH3g3KLIZh1UR51g3A5m31LO62eXKrLLbXHAI+XPh2+RZ0e3ug32UQldSqRWUincFG6mpNFjuK6Lvv
-nOi6V9XasxmMg8R7NMLDcRjE8BgsLW/PO7aZmuJ2foUJNEG1k/H4ajwAaw551+qjAMp2edRethGAuglf
-D8yoO0ixuZ32y6MfkKk0gnk0xp2ZRrTwz9V2Y29P1oYscZbNzonKrCrbNIaogg9VzEHg1RNhBwlys38b
-ijk0kZsgBNSjEHo61H78utEqslrTPAjDG08WWIXvsiGIqNpBOyEcPpsCCLp9uKLpBrY23kaAek6HF1rF
-R/XDBclQN1t5x1vJaSoVftnNzjZFVudGUJEZyTiWewZRu6ojGV6g0ELr1Nq2i+OOkFY4qzuuByFJknti
-QSvbSL0OVAS2wDIR28N+c3AbSMd+tmg1RCzaAuR3vH+7FV8ZkjcjU0FnRNLGrG/TK+o2fqkrbuoESB/U
-OYxvl5lSpYRlJiAsz7kZ66YQt9+NrVG1NbpRPaWkJmMYmFLn4aBGXfMBnrKVSAfedUQf5LG2cTfN1IA5
-cdhsUm5qJXg1e37TunX3V0TjFDvvFugHMcpnBnjzEnnsvCHx3XetZpUU/FdDiI5OZ+OT47PxydE0eib8
-9OTiumoUWmDJP2OpNG4cWnrmROnWHIj2d7s7bZ25j2A4X4fBhe+ZsSqe074zfRv2ppG8FdwxxNT4Xw29
-1t991+ClyiT+jYh9PYSoH8HrJ2iuaRj/xZ++PaUzL5AFLFCzbnWds7K9w8EnQgYojrW33YntNTP/6pn0
-451gPElMjQqWKMekB4jzYoWB5BIdw5z3SyOXiP5OwJcJuDENv8VzWdw33eaeFgppn9D7YRpdGY3deYYe
-sufY3tNfvkYzzA6/yhXjOYkx3CGOY5DutCTVwr8p3Wz7PhfXCqZyrwHp91W8BCXV9Cr4JpeE9d7lUrD2
-KsnZKVx8qjDrKVPzaMe54zgbPPgcl++XPWnJrLQzFjZJtjwYVj0cxvA87LRufdHrxd6WGnyrn/UML2vV
-5l9t9a6anpXrVdUeJPtGsFafqxElbVhMZdT0ovVts6gXtvDMC2fh2qgz+ULynNDFq27UgOg+5xmUpn70
-XyFkeG5D6CSH6inE0soxeVNLIfLB3h4XaP4lu8csSbO1OmBCe/9xsP/+3/+0v3fw9uDDh32J6Z4g2+Az
-ukd8zkgu+uguK4Rqk5I7hthm7y4luZG7/lKsnKOm606ceeHYWL3NJPo8T4noRH3rhe3tQc6wEASzN/p4
-ybu8qP57Hd/s33bhe3j7/kMXXoMsOLjt1kreNkre3XZrDzTa0+Ri5WZ+0GKlXicoHycIXK+MovqTaE6+
-iMQXaEOLVeM9Sq334d8knYHI9Dupc/5TqZ43b7wnEiSNcIHEsp+kWcYU0XtqtJUYSeydEr1kg9meA3Hr
-uLwnmWZFnKTqYaqUII75QKeEYYHKE1JFJaExuSdxgdIqtUbdojudXY+vPv1jdnV6qrIX5yXKWc6yh80A
-oixJInhU+WnXssie7MZ1FJetGKiPANNQ+9OP5+dtGJIiTT0cr8eIpIuCVrj02dMb+9CXywJ1/mRoN8cf
-WZLo7ZAKUr4s5J9CDXzyzGtBrZyamXYVxwK90manbd1cPtkLtZ18pETqDpROJufhkZWdfLw8++lkPBmd
-TybnoaEUFhXnqT8SvxP67D4un+pCD0PJ88fJ9OqiB9fjq5/Ojk/GMLk+OTo7PTuC8cnR1fgYpv+4Ppk4
-WmFmb2FXK2GM9VvR/+K72KpBeXc56kVdpXfMuwhm4NbpCVxLddyo9kRL/Yp21Ns2Lv/eJ+aCUBUmeFar
-3/dk3DwK/hqinlRl+rS8otg/xzYs9JzHIB999/L/M7ONmR/H503+fRyfy+3b1L/bPwiCvNs/sFCn4+A1
-a1VsYS4nB7OP4/PTvx+Hsl1tnc16nVyfzn74eHYu17dAXzCvjqWUns4RE3ygzqrVn/aFxcn1qfUMOiKD
-OwyfM7nja48kgqir9gCV26ObH19O9Gf5uFXOyAqxjYOrD51Ko/4lUqkHDK0H8PclZhg6OkdIYelqqzzT
-z0AWFKX6bXNrtjl0VtlJe3vae5P0qCQiSYr04FQe1AIz9XCpUkouKfoBUWXR9MxD99U7XIpIZY0ZvHiV
-p0ho3CiOiTk5tmlPmltz9ehu7I53xvPk32I96CRFQmA6gBGkhAv3SXfd3gCYrVYaokuM4oMBjFaZenwf
-du+KJMEMWJatdvVhs0oQVn7lEkNCGBcq8l/+bECewHyp3huTjHoQF+hhQn7Gelwr9EBWxQo4+RlXvuv0
-07Rk2E86xUQSA2/fv9cHnQxzleBAYVWkguRpdQ/BGfvb9++jrrOVOGIZ2Dq0+tfy+PUrOJ/VicrbQPq1
-K+zlOQQSkGLEBbwFbN4obZiopkcjeO45UFnsqo1GQ4bW0jOsPl4NhxBFTVSybgjRjKE1z5MSnd779FmS
-TunDpVw4cqV3Rx0/yfWplIWWFphzxCwy/dyjnnhlbcmZLA/+ZXeKBBudNuw1mZlRt0RcrTx/qe1Ur24a
-WZXLRr2e+s8Cc5WcaX/wAZDTuxPTQOsaUstWTZLBW3HWFFSnFfveI9Flg2ENPpBWu7enD4lQHJe0SHYY
-Gu3z6TQS6tmSVS42Rq69o75tM66YnNcOD/2GQqTBg3vtw04/TasOembCesDynn6VskTRffYx/hOIu0+6
-2s60W+9YTrr6iYiEyEnXLoLWmHJa67Nqm/lTp8DLibMw3vrwUSh16OMoiz08qqQFUaUDfUxVeYmqKjqs
-seLH7VLur8w6N2oS0Jggkzxrp6h16htT/iSmKj/bC5O4LzJuMxy27vxHo9GWHZ9kMU5003lGhX4rmKRV
-rLiTmXSsCnw2N29CDuCHLEsxouoQEtNY/cQJVpefjfYhDMd7Fr4vRVVu8GWIyrvh6jxPxHBScBw3uue8
-wAM4N+r4aGR/dUUHAtJsrbOrFZyLmtde+YSONgr0dSAjJnaj1eaUwrEmaTyAkcFc9TeXY1adSIg5YnGo
-tzL7sr+9P2czdqa6dTN+/tZYE3BNcanC9afUlTSjOOr6xXATHUa3hyEUcsw1NKoojEpXWXQlvpJ6O6yS
-ule1xl34+rWC9oFrUe2yyu5LwyHsbwEzI9lW7WLSGRoBa8ddoU1rR845poJtZJGmPGOVgL3U9KhPjVyb
-9TflnKpy2TYflFPq6Wg08tVTpJpFPXCQ9LynX909quWxueej7jZ/HyQowN2Wk48epI694UqBPhNJMdVn
-Ic+kUCKoKJRfN+S22z3caVsS30CYI1gvJ07JTq+O1iWyvpEcX4zGRy/fSlTz0hWdxSvE5pAznJAH9cZ7
-HOP4EBp7TJ6lZL4xSBUKXQKdfNjt6V9du8NqlWSJ0SA9iP5ZIIaoIPqLYfU7TMoBtMe2122IE/c3MTh0
-+Ld3VNt5UEoWVDosk+vTAUTmF+X2Ih7JpRwxnKIHHEd7EYsqWEWHtKo7iOfJsOewhkU+2uO/nV18G17Z
-Ajoo/kJWIcw5ZnNMxcCcMJZXiPbVL3Ic7O/3LAhaaB9T72yKg8T+6IPJau7kc+F2crCvH29mBRrAyP4Q
-GlosGF7IjdzYAObKTY2VrEicRgkiqU6o39LEAOnMcD4wJ6xVAOHQligbhij3506bJlxNgByzZFjPNFAH
-q4hzHCtvo5NkHg/3I7fbU3VQOAD9f+lea1b5pGuOlR4XdJg/4yhhiYmyKPgzKjC7l0aU/avC3IaRDLtl
-XOWM5oWwQRVp2S6z2Hns2l3pbZZEw4ZwHKTH/0urQ13PtXVaVUR1Y0LXv2omp+iKMlPDgW5YNjZAoRZ+
-kzyjEKxhsr25fu5bAcLXrxCoc1RFC4RRHwEb4Yzqd/tdTdXkWZnKdRPdDxXoQXTrvSOltoQoH1acMYM/
-LN2gSZkco6vqw61p0PC4g0BhBgRBn8sJXiO2EdgJ9hGIKGvGcJcz9UZ1f3FUqlSpHWo9esrZ7Y+viZgv
-nwRT5/iI40qNDwJ58Q0UUlhZIOnxjmH05TCA3Wwaz0bOvwU5q+E1XQZ6C6Cw2m+nJZ3cFwRFX7XJedLg
-R0C8CddbYDXn/ny0z/jk+rRtwifXp8+Y7xrUC6Zbbk2/1Wwb3P+vTbY0pAJzLeeiPtXXpX1Tm2dj+FQu
-rC14NVSmTZtqkVaQo3V1o6aE1cwgXuudFcgJUBeoJYTq9cwK5PQsG5Vx1Eb/p75Z0ug9cXtPntd74vWe
-PLt3aWppS24rHb59V7/9kWRSkPej1hfjg0hCT0uEAPshta2dONltPf388XlIA7qhwslfhlMtmRaebe/w
-oLXDoNeuGoV6CWaoq7zeTJtx+1HLq1VakJJMyVGStbn6DQEyWXrPEB5jnbcUK+q0Cd4q5a6Qe60bQj42
-MU5juNep8+z70Cv/bi5GHbyZqtkECj7uE0LWcqXAHTVxl3aodTNSX4uT6yDD9NO0Y+PaugOtJA4h6jYi
-5YE8k23ty+dCpvaH7cpb4OcZXTixfu0zLdXtgBgoEurXcoFma/eXgP52dtFBrP4Tx4iVgZLyku2aoTzH
-TPlZizS763TVn84v8UKaIRX4TkiK9bn3iFdHfWWnHULhx6wrqSfmt+/MkxqIbtZo01MPdsh25vkAdQyv
-A9v6oitHlIjNG/WYiDmMvswEHljCCDcvXVEtmRSlUNA4m6v8ZBzDEqdqLOW95Emmrs8TdTq5kTRlawqM
-8C999+awimfOTC9l1om5uPL2Foaw+5nvHppE6zkGkWlKCJ2nRYyh/5lb9pRKXX7CUNGur450aJGmvQqz
-+4OdTmqzxtOS22xo7Siglsvvqs7M8wSLMkXNsF32d3R+Jokk6hkXJzh/fjYrfzrP3pO23Zcxvy9YDhzq
-9bVfmBJLwm++4M2tcpZ2yzTO3fr6dwBLnOq7oUHdrNHTk+nRX+u/9J5gMV+2MLs/Vz9Vdz26PDtSy+3/
-BAAA//+k69kyOoEAAA==
+H4sIAAAAAAAC/+y9bXfjNrIg/N2/otrnuaGUZssvne6ZRx7dHcUvic+4bR9JnUmu16sLi5CENEVqANCy
+JnF++57CCwmSoKT2dpJz9qw/dItgoVCoKhQKBaAYZIKCkJxNZHCyt3dwAJdTWKcZ0IhJkHMmYMpiGqqy
+RSYk8CyB/56lMKMJ5UTS/waZAl080EiBIwqsASwBOacg0oxPKEzSiHZc/IRTmFPyyOI1RPQhm81YMtMN
+ImyoKu+/iejjPkxjMoMVi2OszymJCsIgYpxOZLwGlgiJr9IpZELjopBmcplJSKdYs0R1B35KsyCOQUgW
+x5BQpD/19O6BTlNOsT6SPUkXC8UYCpM5SWZUdPb2HgmHSZpMoQe/7AEAcDpjQnLCRRfu7kNVFiVivOTp
+I4toqThdEJbUCsYJWVBT+nyim4jolGSx7POZgB7c3Z/s7U2zZCJZmgBLmGQkZv+mrbYhokRRE1UbKPNS
+93yi/quT8qyEO6Ay44kAkgDhnKxRGgYHrOZsMocV5dRQQjmNQKQwxb5lHGXGs0SyheL2zSqBvHvTFDm8
+WBLJHljM5Bo4JSJNBKQc2BREuqAQkTWIJZ0wEsOSpxMqlB6s0iyO4AFb/VfGOI06BdtmVJ6myZTNMk6j
+M01ozkCuOqP42HGlojqbo7imq4FlbAvfhyDXSxrCgkpiUbEptLC07YgDn6HXg+BD//pj/yrQnH1W/6K4
+OZ2h+ABxdqHA3HXwd9W/ViqK0kLKnWUm5i1OZ+0Ttz+IqdaFs0TcGhXY2ol0qoqhh8SnDz/TiQzgq68g
+YMvxJE0eKRcsTUQALCnVxz987pThoIfiXRA5lrLled+uMiYSy5cwpqTmmjeRWG7jTUJXWi8MW3L2VrSk
+6KJDVl4msgetQV0IgrA+IrvFz7DEqy788uzCT1Ie1YfvbTF6XXAzSkejqy4chiUCBeWPtdHOZknKaeTa
+nuorSfiMyrJBcNllxt0Z4TPRWoRm8Fte4dyQcqBkModFGrEpozwENgUmgQkgnU4nhzMYuzAhcYwAKybn
+Bp8FUjamaxtF9mRcsEcary2EVk/UBj6jqplEpoqzEZEkV+txh4kL02Jr0S5pbMv0wagh0FjQvFIfKajU
+wC62UFF/ViPAfYV/ZRbd/XwfQqmFQtkrbd2ovlQaG3fok6RJZKjsYNdCWJSpLcDlnKcrCP7ZH1xfXn/X
+NS3nwtBGKUtEtlymXNKoCwG8LpFvLUClOIAzq+CVN4YwPbR05/RkcaaHVDGiunDKKZEUCJxdDw3CDnwU
+VE24S8LJgkrKBRBhxwKQJELyhWPVz5rGqrIeuse9DSP7ZK8kRgY9ODwBBn9z571OTJOZnJ8Ae/3aFUhJ
+vA78HasK+rnezLFuhvBZtqCJbGwE4RfQKwDv2P2Jn4SFt1XUqdrE1mFJRJ9upoohbXjV68Gbo3ZNe/At
+vIYAmICITmLCKYqAo5RIAmkyoaXJzGnH2l2XoDoZCkbRYP2Ks/H5j6Pzay3Ydhc+LqOqngCJ0TVcA4ki
+GmlrcdZqh5DywvyiHnGaTh1dKWH26cl4RqVuwgxAQ5llowXsQZLF8QZ2rYiAJJUFz9ZUKvVVRKGXCROS
+IMQDhUz1MNLaf9ZqGz+0U+KsGVrpw8+doos91SIWCMlbh6F+1Ir0xqnhFMMbOPJp/dHvqI5IQ7tJTe4M
+DIvuoedUOIGDA4ipDASkj5SvOJPaNmg73zHq4hdZF0a4bGCLZUwVlaqmtYBETuYsmWF1Es9SzuR8AZmg
+ETysCy1pd+CUJBFT6qfqUAGEUyAJ0CcykboQsaRTB38gjKOi/VX8rWY8ZM6SuhqqqyGCUs0OjOYU4hSX
+HKYRRKC9j5JP6++81wJmcXxSKb6iiTJ3jSawNJo36AMu0a6xm72yZNn93T5StH9/UoKPqEDnfJhNp+wJ
+erDf2YfXOZYy7DTNkgLSVfc3JTSGPmdi1QtQqfRAVIQGKddLVo3YSNf6JHa4J6pPvV7RwV9/LRPU65U7
+U3UAHBpyORItWm5KtCHNOEwyzmmCFsFK3aUn98oNKaa/8J+FMKuNF2ZDS7pS9aQBWDncLOoCC3Gsdasy
+tZ522YEpfj27vrKultv284v+x6vREIxzLoCAoFItHfX0WdgVkCmQ5TJeqx9xDNNMZtwOMtFBfOfoXSqn
+UaYFcgwfwCSmhANJ1rDk9JGlmYBHEmdUYIOuA2Fq5UvB+nq3aXhstZWuC6EmOtdotsse0mh01Xpsd2FI
+dchhNLpSjep5T3tADtka3Fmtodc4lLiybj2WvMZH6KmoTzIbpWcZJ1i99dg+qcvKIm9xtz7vSBlDDx5P
+fIsAD2bH/Fir2YPHjvrdOvhfrf8ZvW637sRiHq2S9f3/aP9/B84Mm9dommIfrTuCkydBmbIIItO6Iac0
+cWYJk9CDQAS1Vu6O790GDGTxsrQahR56pYJeJjKvf2SliJ3N1MARXTgKYdGF94chzLvw9v3hoR0x2V0Q
+BTjLZZ05fA3H3+TFK1Mcwdfwl7w0cUrfHubFa7f4/TtDAXzdg+wO+3BfWuc+5oMvXyKWFM0OPKtwxUTm
+jhK37u+kdVFp6HSKFW1V+Q4O4MPl9dh2ZECYWTUgbDpVxsIuGUozs7IkEmJKhIRHp3sFui/bt19gwZKx
+lHEXHjsyNdjaTj/6P+b9uEpXlH9OPxZptRs5ti/eDfK0qRv9q8v+cHxx1R+NznGxqfpzmz3ETMwV3Qqg
+oTNEO2d9IEmkkPX7/RxUzinjYEIRwKlI40cKMg21109yXll2qBgDQTw2AFRpbkISZTsyUSHLYaSnPw5H
+H9W8nyURnbKERlWevj08rC6/vogMSMyIGE9jIiVNWDLzCiPvwoJ8oqf9/kVMZi018VWCWIWxV70vWXxV
+0pkQoqLxv/b0zFkZgqf9/vh0cDm6PO1f4WqeSTYhMRarIL4KY7sw0CvRdAR/+xv8pa03ItyQ5L6VG7oq
++yEcthEiEadplihP4RAWlCQCojQJtBhTnoeZ1YzvRL06bmUUu8VukGB1EseuqauFR011T2zUvNHh0Vwh
+ApeZOQi8Ofoc61dQIe6QDDT5BldFEH1NJluGRnIfTIQH/dm2kkMfeubdtxmLsWdBPzC8x+G2A4Z+34ek
+3y/w4JjRiPRw3YAMQT3YsDhHd3u1HdnBgTENwASwBJacCppIYjcSFkSGQDuzDgRH3aP//7hz9P6vncPO
+4cHRe3hVlLz9a+fw4PivQUdTd3vloe32Kqfsvz4OzsdOd00semuvi3oe/MXLIDSagIvoLtzlWnEXYHNB
+CIU5ccK2dwGSEYTaJSKS9v+dcdpHozFaL2kZUpHqw2T+k5wkAhnYrRqKUJEV5mFEj+HQyyYF54QCHQDd
+vAXRTyellZcTAzV1CPZmrGxgu7rQqYMYZtznbayXDhm1UKkfifLn9G5DjsRd/JjlTrj33Hb35/z8Lxth
+7OMrd1ZQL8u81PaBxIJ67MZd0A9C0AMwhOD0uv/hPLjPo3qmMR3Wy3fs3r0tq61RWK2+TWqb16orbf7q
+S6ns4N3b311hxR+lsfzd2836mgO8XFtzFJ+nq6gMNx9H6NmoSLtog6AmSMbTTLJkBss0ZhO1q0vAchJN
+qZ5ygtVqhYqHJvSwc9w5CsIc5S+6aheCFWWzuaRREIL+2YWjw+d2WyHBINcnutZhfbM2J5zqfd0pm4BM
+9V6AmQedObpK/C/bl7H5iLNC6piO1qVj3yBPaywsBQM+0bWadkpk1PDcfaJrlK0Z6xpaFda3gdyx+l83
+1+etf6cJHbOo7XS++mrX7pd736ydrmKaNpRumt/bNLOilKZW1/6AZ3+na/bTNQZf2Hq2CtNS3tnqB2Gl
+oN+vlWljWy2sw334sVoy+nFULbodDapFw9uLWtHgh2rRdb9ctcH4q/dtx2m3XtUsVHDNhv/U5/GpbhZb
+vKObs5uWjNmi3YVLCWJuD2CQBCjnOgKu2rEhm0NIORwd/7XzsvmCzJpfqnb+vDliQogks2KOmG2ZRdxF
+lSbQNn+dLR4o91BZGgX1pZqortUKc690djfvXIF6JK+03qIb/nR9qtEJyhmJQ9V+iNOOaPbV8a0Jxudn
+hrBQr9iMq15xba6Hwb12zFWjHsKw+MUeiCbfvNeML71X/Wp+rbpkXqsDAn+syol1MtE9sHD6yQ+p+mIB
+1YMfTsvJcUbENocIYTo/pyxpBRC0a9p3trv2nfm178zVvu8vry9uNLrJMgtT0YxOgdbRqeIX68xkmTW/
+TMWfZ4LmLJmmk2VmgSbLzA+T5uJNt8k2x+HI8+rm9Isuzt8dw/ExHL+Fa/gG3r2Ft8dwDm+OF2ZFfnXj
+GfZXN6dWH87siuYTXePEVuzqhhAx3EVVKxz906hZfTmzfzbcf6lG6IabzUROUDOIps4siDbClMn4A9Ur
+ErqfFkg/ecDy7lrIvMADXHTcQhcljeBl0M9YrzlafDsa7GaTbkeDuv6h92UQXfdzVCmPKA+XnE4pp8mE
+mnkRo5Fsog5A0afl1gav+94mjcv3Qh1VpDXrVkHzbpOhfy7FXjYD6O5vcu/+3GV+QpaSKz5ZMPXghysY
+ZoGLEn+NHeZfBWf46Ezp+OiH1Sy1oPrpZcNheGPWBokIFw/pU8jplFMxDzmVfB3SpyXjNFywhC2yRbPu
+Dm88y4bhjV02uFqbayxAXeKONvheIoWNNQ3lPkXGl5KvVVXPS93LIPS+XLBEytjzUv3zAt3cqJdbZWcA
+REqQGRYCf1ffG34UWqIe61CSrwEKKMnXVRjNnxxGP9bIUXzKCVJPJ3tlZRv8oJVtyRnODOtQB4hCPIu6
+1T4OBz94dAzXyC+0jZaKZtOnydtgPlO+4e2fbdgEf7RdLIyVfvbB6s5aSP3kxZnyHAp/v9DwDL+/uNXa
+UDhuymXbEqFQFT2KgMUvVoUdXLUpS2aULzlLNoj8T45GCDGfLj/DD1PwTsfyaaoo+qx4hhWuEitkgsxo
+CILGdCJTHuZnMJWYYUK5xJAvkVQJdnQ19EwiWPpisSoKmqVlKWuGcCn+zIEOBwflvqg7aAII7Gv4/fws
+2R+5pxELorhiodSDF8xyp/BI9LMX2GVUPgc4ZS8zEuJx8jBQ8CaIq3cr0qVUF4vMGY/hD6ff2nPW8P1o
+dGtPd8CD5rw+EKBD8NvXpSReJr39+XE4f7sPbPn4zZwlslfseThbEgV9rcbrQlWd3a63u81O2+YYB5Pq
+eBOM8/OFureT/jlA4nGyfV7aeR+uglgL2aJVTwXsc1jecEHN0XMQ5C5JvkOqK2+YiFDt6vPQD6ffBmFJ
+M2wACzXzxa2p2p6YFhZ72isujhqJ33B9lempoqjOjspTG486F7eenvQOgtr7+zi6Gd5eXY70nZAlpxN9
+e+FS6hj/Cggk6Zt0aY5T5PA9+AX5rfb+fhzttvQe/TjyTAS4jfLSHWc7Sirc+GMmZ1Q0qa/PUHPwS8CU
+pwtVkAnK4ZHyByLZolPbuzOycVS/aWdZPkmLvAd3ToX73UcV0npjLl5ImuDVCKTxu1Rd2t5pd7pExrYR
+6yNCh5T399s7U1OdNj78WFlmbFO4Dz/W9Q038n63hcWfvTRYPPkCGQ1rg52m6usdj4Fde4zY9bAIqn04
+H54PfjgvBemcffAKgLs5XD2ZD6964LndFhQoIE3iNZDJhC6lgDShuUemdvuxgfJ9rC3n99wjiOrov3uH
+GZ7blTN8BSHjposABYjnwGqt/pc+AJyIxvO/7s19c7U7V9mxJA8xde4Ej9Thpbs4Xalz8nM2m3fhOISE
+rr4lgnbh7X0I+vU39vU79frytgvv7+8tInW5d/8IfoNj+A3ewm8n8A38Bu/gN4Df4P1+fiw/ZgnddpOj
+Qu+mu05sCb0qfOkKHAIpcqEHbNlRP8tHhVRR1XKXbxlrkCoM/lnU486CLDVcWGgh81VxBJlki+MolS3W
+rt/+eW6bHbwwqLz12niXGItWk735epDDI5R4ziV8qPEJC7dySgE18Mo0kXMLn/9UfhmCHI4p8nfjGRqt
+HtzlVC07cbpqh+AU4JBp5+PJjBxHPdVw0CaJpyvTA/gNgrZv4GtoA3Sitna1wbr87vpmoLd0HZPslhZj
+vnASMRBADdQYbZbbllNcvhFce1Ft0HkFv+xinUvZD0p3kAurjPx20I/PLof9b6/Ox8P+xfnop/Hp9+en
+/zA5VzQ6hW0cMYEmYSzIlMr1eDKnk09d2Jc8o/t72gTOmQADJoCAhgQFiWaNJpFOUIMX12giu7raUQdG
+qxTSVUK5AJnOZjFLZvnhPHigckVpAnKVgqASD56Jjq56rG+Upnj5WCOAFVuq2s4VE2KSAMXkgcahzeGC
+h+w1lgcKSSrZhEaAqVtiNTsl9EmCZAsKUSImaSJ5GgMTwLPEND6kFOZSLkX34GDG5Dx76EzSxcFQksmn
+8yedWeegqHzAhMioODg6Ony/Z1YLFzeD0/Px4Lx/e3v1EywI/ySKfssUKbN+YeXMIE7lQB8pXyNFISJD
+npo8FPayGdagT0wgz/Krdij/csNa2NOUT+iYU3U3MZesodQozKg/+O581Kq5LL7XIfDRevm5mqvrWt9i
+SaSkPOmWjvR2NeKyr6GI/HB7MxiNR4P+9fDiZvBBT9exmv/1hJbnEdCjoAJf99qqEFV3+S6oNRHgPB/o
+ZvRvvbXieMlf0v8N/h5scWbtTdUK0IJKchfkNFjiS5lsVP1aD9v1BotNEbMjUt6P/jj47rzlqIsuyDUg
+6vyD0uXH5FOSrhLo2dONxoO8Gdfq52WNKFB5LQZcqJ9dD4fnp4oYyhc4pNyjuV18sb8PcJaiKdB81wsx
+Y3Gg5VwpVEdA9tNkHwDOE2SJ04a5a4imUDFew06niJ2JbcDFTa0cZnxzbfsZdUgm03GUCEEneL88Tfax
+l95aFxfN1abTpnq2ziRNRIoeYzpr7QEA7Of5Vgrg7ZESgFu8lUj1rTS3T5DyCrnamBseIyKZqptVkKRm
+JEyUFoqOnmoWVKgor7oWjfPOckkJB5YAsXeqOVWtd3CGMtPu11/vwdfw94LsPfj6oJRNK1/QtfQoFJJw
+Wbr9m0aNjrcCzq9RN96gRhT51enSrWnHViKQS7QJE6MNtGFf1RcVV4Nf9JLnWb93YH0w6VKKjmr6/u7w
+Hvp2TYhWxYW3fOmVqxzdw40KUJPYHmtO+aZ6uZ0Bm6KouAZfuhlvZyn42rJqhCrQeEmHiKJ+B/rJOn8n
+tGI8UAcXNshoZBKRmBR8hqCOc9B3kUlisnLMGM6rDlmNrMHOWN3xdLOgy8zlGmdZ/crzj95EQuxWd/C3
+cvvNMBGtX541RFiPx3tjOEVkBuehLxEXzzeBNMPn5JEWwEVKG836ak3EbQUFJDFJUNSYcnIlmQuInx+O
+t2sqPfNuDDH6JlC7/nDr7bgk2jmw/+yG7vdcTc21ySOTRmn4wgA5cJM5ctdiizSCXlFFxQBqgPWEY2nU
+blpzLtLI0O1bbfoThG1Ad3AAOrWeLLRWDSoTk/VWQvyLNHIM0VdfOfsApVeNLZvOFJDlvH8lHCdeDM/e
+0jwBmuObKRE388tPoAn/nQ8GN4MuWHeolBkt8KBs1kf1X9soQNWFr4aQVBqJyCQYcW8YGbUyFsHk/XQl
+U4tr/q2YbkxRVSaIM692xdTJybxOrYsqTJITziRdbAmQIMjd4b0vOlJHbsIlUI2XaHEg1yv55PAvsFbT
+5PQUEHigqmzwIsr5AC0fjjKbPAjaHbjBMPHGypsIUBlRRaZNfHCyV2eoezdqrzSSY9xyL5rZ22TIqtzw
+GjKjGWc4ZzCUt6sZpZCmhVYrgcbcX46SFjiLNEVHPk3COTFLCt8IEVj+eI3pqxL2u6N7z+WvnVWrpmLB
+BqByw4f3G/FZDtmeqfA4YXFN6pvsCv4VtuKuSgCuQZ0zN806k5sUv854lGWX5EbgXFhqTm9UoWpjdCOP
+cmph9DwidXK/1t7Vc6jmtXDnws0oUwZ5rkzcdTfV406c1Kvkk1oOXkivXLXq3X1PkiimTuo5nd0kzxQn
+6nnAIicN4FdfNbpVqPivehCcXowH52eXg/PTUbAj/Oj8w21RyTfApv+KEpymHFpCs/d1b7ZuO/vtvabG
+3DyGztOJd+CX3FgVz2memT4Pe91J3gjuOGKq/696pdpffVXjpbop8DsR+7oHQSeA11torliY0mPUsfuJ
+Jom0xwM141a/c0Z2aRtzS8iARJE5lhLZnAPlPAS4jne2DdjUuT+UqIVJCESIbEGBLREdp0J0cieXyc6e
+Zy3jWcbU1i2lJYublntSskI+6+NLAa3R5dHYvR3skN1xL2VvLlu055M8YXI9sXJEJyyi8EAEjSBNNKkW
+/g1cVFIsC21giuW1zcJUOoeoqt540yojbCm1soK1F1cvL/AcRY5Zi0zJ0fZzz1lsCG9G5fK6bKsns9CL
+Mb9LsiHns/1TRtu/aN2YlPnFqy3V+cZ11g6rrEXT+mrj6up5b9OqqpJT+jPBGtdctShp9a/IUv2hMT11
+EHqr2iTV/rdBa/iJLXGv61U7qEG0d8lkWbeP5UTynE5sCJ0tochmn3s55oQXboJ1Dw4Ebnylj5RP43Sl
+tsLIwV+PDt/95ZvDg6Pjo/fvDxHTIyO2ws/kkYgJZ0vZIQ9pJlWdmD1wwtcHDzFbGr3rzOXC2Wq6bUVp
+KRwbqfS6siOWMZOtoGNXYSpVGobvKX+jt5fc3rXU3+vo7vC+jTkL371vw2vAgqP7dqXkuFby9r5dybFv
+972zhXtGJckWzUnVDCVBsCGtGuLz1EmyRe2TAtruw38gnZ7I9NsTYPCfyvS8eeOiVDTCByLnnWmcplwR
+faB6W6gRYm/l6JENZnr2xK2jPCtDnGbRNCac6iRvVHRV+QcqSb6Xq6hkScQeWZSRuDgEpK7GX4xvBzc/
+/oT7AzhlwSRHiR9CeMJkLOl0GsCzOkl3i0V2DzqqorhuxJCUEdDEV//i49VVE4ZpFsclHK8HhMWzLClw
+Hai9pzc2V7PLgu6erZZvf6TTqZ4OE8ny5LDlXahumTyT8LWRU2NTr+CYp9Wk3mhTM9dbW0lsIx8ThraD
+xMPhlb9neSMfry9/OB8M+1fD4ZWvK5lFJURc7km5kWTnNq63NaG7ofT543B08yGE28HND5dn5wMY3p6f
+Xl5cnsLg/PRmcAajn27Ph45VGNucL8VIGFD9uZ8vnPlFVcgzpeDRHegVSbJMx+2ix5Nroni54Uio/hBS
+EG7qV/leN8WzBypMsFOtP3ZnXHcHTVmIpkyVORSX97ENC0uLRy8fSxD/j5mNzPw48KQj/DhQ6QjN+7eH
+R16Qt4dHFupi4E2roYotzPXwaPxxcHXxzzPfuVz7zp7PHd5ejL/9eHmF41uST1QU21Jdc8dGiq7aq1Y/
+8ys6txcGObT02R2MFNjPOAQYZcXq6hSSro4JsNVjnp94ydmC8LWDqwOtwqL+PVBHDzhZdeGfc8optPRp
+JoWlrb3yVGfyzxIS689TWbfNobM4R3VwoFdvSI867oSk4ApOndiaUQ4pN66+S4r+BoTyaELzrbIilbIi
+UnljBi9dLGMiNW4SRczsHOe5cBW3Juq7KZHb37FYTv8j0p02+WG70IeYCel+lUvXNwBmqkVHdE5JdNSF
+/iJV30+D/YdsOqUceJou9vVmszrKrNaVcwpTxoVUkf/8y2/LKUzmKmU0MupJfiBPQ/Zvqvu1IE94ZRwE
++zct1q54s8My7Ad9xASJgeN37/RGJ6dCHXBIYJHFki3j4saE0/fjd++CtjOVOGrpmTpUSUfr46+/gvNY
+7Kgcew6KO1iLfYg8lfQxUPOZiZqLalo0iufuA+XFrtmoVeRkhSvD4gGzegVBHRW+60Ew5mQlltMcnfqP
+670kMEn3rF44eqVnRx0/WepdKQuNHpizxSxTnbFfCx4VS0ky3/gHAE0C9ErsLZIDWcTFyCsPNbsouZxC
+kexYfwDjXxkV6hip/WYfEKd1J6ZBVhWklq2aJIO34KwpKHYrDkvf+ckr9CrwngPABwd6k4hEUU4LssPQ
+aL+AlQQSSAJ0sZRro9elrb5NEsc/vqxsHpYrShl7N+71GhavX+UNhEZgIfBlqD8skKNo77yNvwVxe+tS
+2xG7XR0DEzYbJApdLxG0xUSxVqVqq5VFp8BzwVmY0vgoo1DmsIwjLy7hUSUNiAobWMZUlOeoiqKTCiu+
+26zl5ZFZ5UZFA2oCMsd8rYgaRV8T+VZM7XapIzZM4iYO3+Q4bJz5T/Pc8L4Zn6URneqqeJxYf+6FxUWs
+uJWa41gF+HhiUpd34ds0jSlRp4MFTSJ9GhijT9b6ME6jAwvfQVXFCT4PUZUusjvJEDmdZoJGtebxpHMX
+row5Pu3bD2fqQECcrvQ5cAXnohaVDzVASzsF+uKSURM70Wp3SuFYsTjqQt9gLtqbkEQD4MQbTQiPfK3l
+py87m9tzJmNH1I2T8e5TY0XBNcW5CdePaCuTNKFBu1wMd8FJcH/iQ4F9rqBRRX5U+pVFl+PLqW+9coAR
+7atKZbxZW0CXgStR7fyVnZd6PTjcAGZ6sum1i6mtAD3ejjtC694Oypwmkq+xSFOe8kLBXup6VEWDY7Oa
+wdZ5lQ/bevpaZZ4w02nJPAWqWhCCgyQsfaHAnaMaUtvujrpdz+3rVeB2w85HCLHjb7haoPdEYprovZAd
+KUQEBYX4hJv07ZO9piHxGYQ5ivVy4pTuhFW0LpHVieTsQ39w+vKpRFXPl6LjaEH4BPAuLHsCJvS3BU+g
+NsfY5NajHIUugday1w71h7MfqBol6dRYkBCCf2WEk0Qy/cQp0hggvnzb9rYJ8dT9rKGAlvj8hiozD4nZ
+LMEFy/D2oguB+Sj4QSACSDlWiskTjYKDgAcFrKIDveoWEctpL3RYw4My2rN/XH74PLxYA1ok+sQWPsxL
+yid4FcrsMOaXnQ6BJBEcHR6GFoTM9BpTz2yKg8x+t8+cam4tJ9Jt5OhQf2OEZ6QLfZuXlsxmnM6IpNYH
+sB+hKbOSZ1OnEp7xyfiWKgZInwwXXbPDWgQQTmyJ8mGYWv48aNdEKAFgn5FhoamgNlaJEDRSq43WNC3x
+8DBwm71QG4Vd0P8DSwyryqRrjuUrLmjxssTJlE81Wg1/mUjKH9GJsr8KzE0YWa+dx1Uuk2UmbVAFFlTO
+08j5Jos70ps8iZoP4SyQnv8PvQ51kdi+06YiqDoT+v2r+uEU/SI/qeFA1zwbG6BQA79Oni4H65hsrq6/
+SqMAMdbheeeYigYIYz48PsJlogKrJUtV51l+lOsueOwp0KPgvpQuTk0JwbJXcMZ0/iRfBg2t7TPNVLtb
+saD+fnuB/Azwgu7KCVEhthbY8bbhiShrxgiXM9VK1fViPzepaB0qLZaMs9ueWDE5mW8Fw78JEbQw413P
+ufgaClRW7jn0+MAp+XTiwW4mjZ2Ri89BzoOup1QE3V1QWOtXg/UqgqIvp7asDeUISEngegosZF6WR7PE
+h7cXTQIf3l7sIO8K1AvEjVPT7yVtg/v/NmGjI+WRNcqiKurb3L+pyNk4PsUS1hZg0pPDw0bTgl6QY3V1
+pbqGVdwgUWmdZ6RomWekIYRaaplnxGkZK+Vx1Fr7F2W3pNb61G19ulvr01Lr051bR1fLJJzbREfZv6ve
+/pimqMiHQePng7xIfEkwfIAdn9nWizhstnr8/Hk3pB7bUOAUL8OJhDbxbHODR40NelftqpKvFe8JdaR3
+mmo37jBoyK+lFWmaKj2apk1L/ZoCmVN6OyiP8c4bihV12gVv1HJXyUu1a0o+0LQx47hXqSv5975vCrln
+Marg9aOadSBvGiIfsoYrBW6vmTu0fbWf97bEyXWQAaPbNq6tG9BG4gSCdi1S7jlnsql+nthkZL9Nnt8C
+v0qTmRPr12umubodEAGeEHik8RpvjrsfrPzH5YcW4bySQYLwPFCSX7Jdcbz8jTaIwyxOH1pt9ZPTScaF
+xh2nRAW+pyymet+7L4qtvrzRFkvgu7RtUmiqz5eb5B8kWa/IOgT1Ve45tekD1Da8Dmzri66CJEyu36i0
+J2Yz+jqVtGsJY8Lk5Eq0ZiYkhiyJ0ok6n0wjmNNY9SW/lzxMIRMUmNqdXCNNeKuPM/Gp494cVvHMsWkl
+P3ViLq4c3+PF/5/F/ok5aD2hIFNNCUsmcRZR6PwsLHtyo46P0FO066sjLfx8dVhgbjtHDZ2jzRpPw9lm
+Q2tLATVcflfvbA5LKq3fYtmO7Z1eXSKRTCWccYLzV5fj/Ovnplo+W1U/cVZ9D+UPoeLugPnMWa8H+/kx
+zv3q+HcAc5zquWZB3VOjF+ej0++reVWnFD+R72d2Z6K+Nn7bv748VcPtfw8AM0kezP2OAAA=
 `,
 	},
 }
@@ -13,6 +13,8 @@ import (
 // DetermineNameservers will find all nameservers we should use for a domain. It follows the following rules:
 // 1. All explicitly defined NAMESERVER records will be used.
 // 2. Each DSP declares how many nameservers to use. Default is all. 0 indicates to use none.
+// Nameservers that appear more than once (e.g. shared by two providers) are
+// only returned the first time, compared case-insensitively.
 func DetermineNameservers(dc *models.DomainConfig) ([]*models.Nameserver, error) {
 	// always take explicit
 	ns := dc.Nameservers
@@ -44,20 +46,38 @@ func DetermineNameservers(dc *models.DomainConfig) ([]*models.Nameserver, error)
 			ns = append(ns, nss[i])
 		}
 	}
-	return ns, nil
+	return dedupe(ns), nil
+}
+
+// dedupe returns nss without the nameservers already listed before.
+func dedupe(nss []*models.Nameserver) []*models.Nameserver {
+	seen := map[string]bool{}
+	result := make([]*models.Nameserver, 0, len(nss))
+	for _, ns := range nss {
+		key := strings.ToLower(strings.TrimSuffix(ns.Name, "."))
+		if seen[key] {
+			continue
+		}
+		seen[key] = true
+		result = append(result, ns)
+	}
+	return result
 }
 
 // AddNSRecords creates NS records on a domain corresponding to the nameservers specified.
+// Their TTL is set by the "ns_ttl" metadata (see NAMESERVER_TTL), 300 by default,
+// within the limits of MIN_TTL and MAX_TTL.
 func AddNSRecords(dc *models.DomainConfig) {
 	ttl := uint32(300)
 	if ttls, ok := dc.Metadata["ns_ttl"]; ok {
 		t, err := strconv.ParseUint(ttls, 10, 32)
 		if err != nil {
-			fmt.Printf("WARNING: ns_ttl for %s (%s) is not a valid int", dc.Name, ttls)
+			fmt.Printf("WARNING: ns_ttl for %s (%s) is not a valid int\n", dc.Name, ttls)
 		} else {
 			ttl = uint32(t)
 		}
 	}
+	ttl = dc.ClampTTL(ttl)
 	for _, ns := range dc.Nameservers {
 		rc := &models.RecordConfig{
 			Type:     "NS",
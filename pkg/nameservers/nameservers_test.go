@@ -0,0 +1,92 @@
+package nameservers
+
+import (
+	"testing"
+
+	"github.com/StackExchange/dnscontrol/v3/models"
+)
+
+type nsProvider []string
+
+func (p nsProvider) GetNameservers(domain string) ([]*models.Nameserver, error) {
+	var nss []*models.Nameserver
+	for _, name := range p {
+		nss = append(nss, &models.Nameserver{Name: name})
+	}
+	return nss, nil
+}
+
+func (p nsProvider) GetZoneRecords(domain string) (models.Records, error) {
+	return nil, nil
+}
+
+func (p nsProvider) GetDomainCorrections(dc *models.DomainConfig) ([]*models.Correction, error) {
+	return nil, nil
+}
+
+func TestDetermineNameserversDedupe(t *testing.T) {
+	dc := &models.DomainConfig{
+		Name: "example.com",
+		DNSProviderInstances: []*models.DNSProviderInstance{
+			{
+				ProviderBase:        models.ProviderBase{Name: "a"},
+				Driver:              nsProvider{"ns1.shared.net", "ns2.a.net"},
+				NumberOfNameservers: -1,
+			},
+			{
+				ProviderBase:        models.ProviderBase{Name: "b"},
+				Driver:              nsProvider{"NS1.Shared.net.", "ns2.b.net"},
+				NumberOfNameservers: -1,
+			},
+		},
+	}
+	nss, err := DetermineNameservers(dc)
+	if err != nil {
+		t.Fatal(err)
+	}
+	dc.Nameservers = nss
+	AddNSRecords(dc)
+
+	var got []string
+	for _, r := range dc.Records {
+		got = append(got, r.GetTargetField())
+	}
+	want := []string{"ns1.shared.net.", "ns2.a.net.", "ns2.b.net."}
+	if len(got) != len(want) {
+		t.Fatalf("expected NS records %v, got %v", want, got)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("expected NS records %v, got %v", want, got)
+			break
+		}
+	}
+}
+
+func TestAddNSRecordsTTL(t *testing.T) {
+	for _, tc := range []struct {
+		meta map[string]string
+		want uint32
+	}{
+		{nil, 300},
+		{map[string]string{"ns_ttl": "86400"}, 86400},
+		{map[string]string{"ns_ttl": "invalid"}, 300},
+		{map[string]string{"ns_ttl": "86400", "max_ttl": "3600"}, 3600},
+		{map[string]string{"min_ttl": "600"}, 600},
+	} {
+		dc := &models.DomainConfig{
+			Name:        "example.com",
+			Metadata:    tc.meta,
+			Nameservers: []*models.Nameserver{{Name: "ns1.example.net"}, {Name: "ns2.example.net"}},
+		}
+		AddNSRecords(dc)
+		if len(dc.Records) != 2 {
+			t.Fatalf("expected 2 NS records, got %d", len(dc.Records))
+		}
+		for _, r := range dc.Records {
+			if r.TTL != tc.want {
+				t.Errorf("%v: expected TTL %d, got %d", tc.meta, tc.want, r.TTL)
+			}
+		}
+	}
+}
@@ -0,0 +1,140 @@
+package normalize
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"net"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/StackExchange/dnscontrol/v3/models"
+	"github.com/StackExchange/dnscontrol/v3/providers"
+	"github.com/miekg/dns/dnsutil"
+)
+
+// aliasFlatteningMetadata is the metadata of a domain that enables the
+// flattening of ALIAS records, see ALIAS_FLATTENING. Its value is the
+// highest TTL of the flattened records.
+const aliasFlatteningMetadata = "alias_flattening"
+
+// lookupIPAddr resolves the target of an ALIAS record. Tests replace it.
+var lookupIPAddr = net.DefaultResolver.LookupIPAddr
+
+// aliasLookupTimeout limits the time resolving a single target may take.
+const aliasLookupTimeout = 10 * time.Second
+
+// FlattenAliases replaces the ALIAS records of the domains that use
+// ALIAS_FLATTENING with A and AAAA records of the addresses their targets
+// resolve to, if a provider of the domain can not use ALIAS records. The
+// targets are resolved on every run, so the records follow the target.
+//
+// As it needs the network, it is not part of ValidateAndNormalizeConfig
+// and only preview and push call it, after the validation.
+func FlattenAliases(cfg *models.DNSConfig) []error {
+	var errs []error
+	for _, domain := range cfg.Domains {
+		if !flattensAliases(domain) {
+			continue
+		}
+		maxTTL, err := aliasFlatteningTTL(domain)
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		recs := make(models.Records, 0, len(domain.Records))
+		for _, rec := range domain.Records {
+			if rec.Type != "ALIAS" {
+				recs = append(recs, rec)
+				continue
+			}
+			flat, err := flattenAlias(rec, domain.Name, maxTTL)
+			if err != nil {
+				errs = append(errs, err)
+				continue
+			}
+			recs = append(recs, flat...)
+		}
+		domain.Records = recs
+	}
+	return errs
+}
+
+// flattensAliases returns whether FlattenAliases replaces the ALIAS
+// records of the domain. If any provider of the domain can not use
+// them, they are replaced for all its providers.
+func flattensAliases(domain *models.DomainConfig) bool {
+	_, ok := domain.Metadata[aliasFlatteningMetadata]
+	return ok && needsAliasFlattening(domain)
+}
+
+// aliasFlatteningTTL returns the highest TTL of the flattened records of
+// the domain.
+func aliasFlatteningTTL(domain *models.DomainConfig) (uint32, error) {
+	v := domain.Metadata[aliasFlatteningMetadata]
+	maxTTL, err := strconv.ParseUint(v, 10, 32)
+	if err != nil || maxTTL == 0 {
+		return 0, fmt.Errorf("ALIAS_FLATTENING of %s (%s) is not a valid TTL", domain.Name, v)
+	}
+	return uint32(maxTTL), nil
+}
+
+// needsAliasFlattening returns whether a provider of the domain can not
+// use ALIAS records.
+func needsAliasFlattening(domain *models.DomainConfig) bool {
+	for _, p := range domain.DNSProviderInstances {
+		if !providers.ProviderHasCapability(p.ProviderType, providers.CanUseAlias) {
+			return true
+		}
+	}
+	return false
+}
+
+// flattenAlias returns the A and AAAA records of the addresses the target
+// of the ALIAS record rec resolves to, in a stable order.
+func flattenAlias(rec *models.RecordConfig, domain string, maxTTL uint32) (models.Records, error) {
+	target := dnsutil.AddOrigin(rec.GetTargetField(), domain+".")
+	ctx, cancel := context.WithTimeout(context.Background(), aliasLookupTimeout)
+	defer cancel()
+	addrs, err := lookupIPAddr(ctx, target)
+	if err != nil {
+		return nil, fmt.Errorf("can not flatten ALIAS %s: %w", rec.GetLabelFQDN(), err)
+	}
+	ips := make([]net.IP, 0, len(addrs))
+	seen := map[string]bool{}
+	for _, a := range addrs {
+		if !seen[a.IP.String()] {
+			seen[a.IP.String()] = true
+			ips = append(ips, a.IP)
+		}
+	}
+	if len(ips) == 0 {
+		return nil, fmt.Errorf("can not flatten ALIAS %s: %s has no addresses", rec.GetLabelFQDN(), target)
+	}
+	sort.Slice(ips, func(i, j int) bool {
+		a, b := ips[i].To4(), ips[j].To4()
+		if (a == nil) != (b == nil) {
+			return a != nil // IPv4 first
+		}
+		return bytes.Compare(ips[i].To16(), ips[j].To16()) < 0
+	})
+
+	var flat models.Records
+	for _, ip := range ips {
+		rc, err := rec.Copy()
+		if err != nil {
+			return nil, err
+		}
+		rc.Type = "AAAA"
+		if ip.To4() != nil {
+			rc.Type = "A"
+		}
+		rc.SetTarget(ip.String())
+		if rc.TTL > maxTTL {
+			rc.TTL = maxTTL
+		}
+		flat = append(flat, rc)
+	}
+	return flat, nil
+}
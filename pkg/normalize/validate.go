@@ -6,6 +6,7 @@ import (
 	"strings"
 
 	"github.com/StackExchange/dnscontrol/v3/models"
+	"github.com/StackExchange/dnscontrol/v3/pkg/printer"
 	"github.com/StackExchange/dnscontrol/v3/pkg/transform"
 	"github.com/StackExchange/dnscontrol/v3/providers"
 	"github.com/miekg/dns"
@@ -51,15 +52,22 @@ func validateRecordTypes(rec *models.RecordConfig, domain string, pTypes []strin
 	var validTypes = map[string]bool{
 		"A":                true,
 		"AAAA":             true,
+		"APL":              true,
 		"CNAME":            true,
+		"DNAME":            true,
 		"CAA":              true,
+		"CSYNC":            true,
 		"DS":               true,
+		"HINFO":            true,
 		"TLSA":             true,
+		"LOC":              true,
 		"IMPORT_TRANSFORM": false,
 		"MX":               true,
 		"SOA":              true,
 		"SRV":              true,
 		"SSHFP":            true,
+		"SVCB":             true,
+		"HTTPS":            true,
 		"TXT":              true,
 		"NS":               true,
 		"PTR":              true,
@@ -170,6 +178,8 @@ func checkTargets(rec *models.RecordConfig, domain string) (errs []error) {
 		if label == "@" {
 			check(fmt.Errorf("cannot create CNAME record for bare domain"))
 		}
+	case "DNAME":
+		check(checkTarget(target))
 	case "MX":
 		check(checkTarget(target))
 	case "NS":
@@ -191,7 +201,10 @@ func checkTargets(rec *models.RecordConfig, domain string) (errs []error) {
 		}
 	case "SRV":
 		check(checkTarget(target))
-	case "TXT", "IMPORT_TRANSFORM", "CAA", "SSHFP", "TLSA", "DS":
+	case "SVCB", "HTTPS":
+		// The target "." means the owner name (ServiceMode) or no service (AliasMode).
+		check(checkTarget(target))
+	case "TXT", "IMPORT_TRANSFORM", "APL", "CAA", "CSYNC", "HINFO", "LOC", "SSHFP", "TLSA", "DS":
 	default:
 		if rec.Metadata["orig_custom_type"] != "" {
 			// it is a valid custom type. We perform no validation on target
@@ -248,7 +261,7 @@ func importTransform(srcDomain, dstDomain *models.DomainConfig, transforms []tra
 			r := newRec()
 			r.SetTarget(transformCNAME(r.GetTargetField(), srcDomain.Name, dstDomain.Name))
 			dstDomain.Records = append(dstDomain.Records, r)
-		case "MX", "NAPTR", "NS", "SOA", "SRV", "TXT", "CAA", "TLSA":
+		case "APL", "CSYNC", "DNAME", "HINFO", "LOC", "MX", "NAPTR", "NS", "SOA", "SRV", "SVCB", "HTTPS", "TXT", "CAA", "TLSA":
 			// Not imported.
 			continue
 		default:
@@ -345,7 +358,7 @@ func ValidateAndNormalizeConfig(config *models.DNSConfig) (errs []error) {
 			}
 
 			// Canonicalize Targets.
-			if rec.Type == "CNAME" || rec.Type == "MX" || rec.Type == "NAPTR" || rec.Type == "NS" || rec.Type == "SRV" {
+			if rec.Type == "CNAME" || rec.Type == "DNAME" || rec.Type == "MX" || rec.Type == "NAPTR" || rec.Type == "NS" || rec.Type == "SRV" || rec.Type == "SVCB" || rec.Type == "HTTPS" {
 				// #rtype_variations
 				// These record types have a target that is a hostname.
 				// We normalize them to a FQDN so there is less variation to handle.  If a
@@ -382,6 +395,36 @@ func ValidateAndNormalizeConfig(config *models.DNSConfig) (errs []error) {
 						rec.TlsaMatchingType, rec.GetLabel(), domain.Name))
 				}
 			}
+			if rec.Type == "LOC" {
+				// Parse the presentation format into the LOC fields.
+				if err := rec.SetTargetLOCString(rec.GetTargetField()); err != nil {
+					errs = append(errs, fmt.Errorf("%s record %s (domain %s): %w", rec.Type, rec.GetLabel(), domain.Name, err))
+				}
+			}
+			if rec.Type == "APL" {
+				// Parse the presentation format into the APL prefixes.
+				if err := rec.SetTargetAPLString(rec.GetTargetField()); err != nil {
+					errs = append(errs, fmt.Errorf("%s record %s (domain %s): %w", rec.Type, rec.GetLabel(), domain.Name, err))
+				}
+			}
+			if rec.Type == "CSYNC" {
+				// Canonicalize the type bitmap.
+				if err := rec.SetTargetCSYNC(rec.CsyncSerial, rec.CsyncFlags, rec.CsyncTypes); err != nil {
+					errs = append(errs, fmt.Errorf("%s record %s (domain %s): %w", rec.Type, rec.GetLabel(), domain.Name, err))
+				}
+			}
+			if rec.Type == "HINFO" {
+				// Quote the fields into the target.
+				if err := rec.SetTargetHINFO(rec.HinfoCpu, rec.HinfoOs); err != nil {
+					errs = append(errs, fmt.Errorf("%s record %s (domain %s): %w", rec.Type, rec.GetLabel(), domain.Name, err))
+				}
+			}
+			if rec.Type == "SVCB" || rec.Type == "HTTPS" {
+				// Validate the params and put them in canonical order.
+				if err := rec.SetTargetSVCB(rec.SvcPriority, rec.GetTargetField(), rec.SvcParams); err != nil {
+					errs = append(errs, fmt.Errorf("%s record %s (domain %s): %w", rec.Type, rec.GetLabel(), domain.Name, err))
+				}
+			}
 
 			// Populate FQDN:
 			rec.SetLabel(rec.GetLabel(), domain.Name)
@@ -424,10 +467,25 @@ func ValidateAndNormalizeConfig(config *models.DNSConfig) (errs []error) {
 			errs = append(errs, err)
 		}
 	}
+	// Apply MIN_TTL and MAX_TTL, after all records were generated.
+	for _, domain := range config.Domains {
+		clamped, err := domain.ClampTTLs()
+		if err != nil {
+			errs = append(errs, err)
+		} else if clamped > 0 {
+			printer.Printf("%s: the TTL of %d records was limited by MIN_TTL/MAX_TTL\n", domain.Name, clamped)
+		}
+	}
 
 	for _, d := range config.Domains {
 		// Check that CNAMES don't have to co-exist with any other records
 		errs = append(errs, checkCNAMEs(d)...)
+		// The ALIAS records are flattened by preview and push, check the TTL now
+		if flattensAliases(d) {
+			if _, err := aliasFlatteningTTL(d); err != nil {
+				errs = append(errs, err)
+			}
+		}
 		// Check that if any advanced record types are used in a domain, every provider for that domain supports them
 		err := checkProviderCapabilities(d)
 		if err != nil {
@@ -449,11 +507,7 @@ func ValidateAndNormalizeConfig(config *models.DNSConfig) (errs []error) {
 	// validated anything that can be globally validated.
 	// Let's ask // the provider if there are any records they can't handle.
 	for _, domain := range config.Domains { // For each domain..
-		for _, provider := range domain.DNSProviderInstances { // For each provider...
-			if err := providers.AuditRecords(provider.ProviderBase.ProviderType, domain.Records); err != nil {
-				errs = append(errs, err)
-			}
-		}
+		errs = append(errs, providers.AuditConfig(domain)...)
 	}
 
 	return errs
@@ -545,14 +599,22 @@ var providerCapabilityChecks = []pairTypeCapability{
 	// If a zone uses rType X, the provider must support capability Y.
 	//{"X", providers.Y},
 	capabilityCheck("ALIAS", providers.CanUseAlias),
+	capabilityCheck("APL", providers.CanUseAPL),
 	capabilityCheck("AUTODNSSEC", providers.CanAutoDNSSEC),
 	capabilityCheck("CAA", providers.CanUseCAA),
+	capabilityCheck("CSYNC", providers.CanUseCSYNC),
+	capabilityCheck("DNAME", providers.CanUseDNAME),
+	capabilityCheck("HINFO", providers.CanUseHINFO),
+	capabilityCheck("HTTPS", providers.CanUseHTTPS),
+	capabilityCheck("LOC", providers.CanUseLOC),
 	capabilityCheck("NAPTR", providers.CanUseNAPTR),
 	capabilityCheck("PTR", providers.CanUsePTR),
 	capabilityCheck("R53_ALIAS", providers.CanUseRoute53Alias),
+	capabilityCheck("ROUTING", providers.CanUseRouting),
 	capabilityCheck("SSHFP", providers.CanUseSSHFP),
 	capabilityCheck("SOA", providers.CanUseSOA),
 	capabilityCheck("SRV", providers.CanUseSRV),
+	capabilityCheck("SVCB", providers.CanUseSVCB),
 	capabilityCheck("TLSA", providers.CanUseTLSA),
 	capabilityCheck("AZURE_ALIAS", providers.CanUseAzureAlias),
 
@@ -619,12 +681,23 @@ func checkProviderCapabilities(dc *models.DomainConfig) error {
 	// Check if the zone uses a capability that the provider doesn't
 	// support.
 	for _, ty := range providerCapabilityChecks {
+		if ty.rType == "ALIAS" && flattensAliases(dc) {
+			// FlattenAliases replaces them before the corrections are computed.
+			continue
+		}
 		hasAny := false
 		switch ty.rType {
 		case "AUTODNSSEC":
 			if dc.AutoDNSSEC != "" {
 				hasAny = true
 			}
+		case "ROUTING":
+			for _, r := range dc.Records {
+				if len(r.Routing) != 0 {
+					hasAny = true
+					break
+				}
+			}
 		default:
 			for _, r := range dc.Records {
 				if r.Type == ty.rType {
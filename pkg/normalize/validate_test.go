@@ -1,6 +1,8 @@
 package normalize
 
 import (
+	"context"
+	"net"
 	"testing"
 
 	"fmt"
@@ -432,3 +434,135 @@ func Test_DSChecks(t *testing.T) {
 		})
 	})
 }
+
+func TestRoutingCapability(t *testing.T) {
+	providers.RegisterDomainServiceProviderType("ROUTING_SUPPORT", providers.DspFuncs{}, providers.DocumentationNotes{
+		providers.CanUseRouting: providers.Can(),
+	})
+	a := &models.RecordConfig{Type: "A", Routing: map[string]string{"weight": "10"}}
+	a.SetLabel("www", "example.com")
+	dc := &models.DomainConfig{Name: "example.com", Records: models.Records{a}}
+
+	for pType, wantErr := range map[string]bool{"ROUTING_SUPPORT": false, ProviderNoDS: true} {
+		dc.DNSProviderInstances = []*models.DNSProviderInstance{{ProviderBase: models.ProviderBase{ProviderType: pType}}}
+		if err := checkProviderCapabilities(dc); (err != nil) != wantErr {
+			t.Errorf("%s: expected an error %v, got %v", pType, wantErr, err)
+		}
+	}
+}
+
+func TestTTLLimits(t *testing.T) {
+	rc := func(label string, ttl uint32) *models.RecordConfig {
+		return makeRC(label, "example.com", "192.0.2.1", models.RecordConfig{Type: "A", TTL: ttl})
+	}
+	config := &models.DNSConfig{
+		Domains: []*models.DomainConfig{
+			{
+				Name:          "example.com",
+				RegistrarName: "BIND",
+				Metadata:      map[string]string{"min_ttl": "60", "max_ttl": "3600"},
+				Records: []*models.RecordConfig{
+					rc("short", 30),
+					rc("ok", 300),
+					rc("long", 86400),
+					rc("default", 0),
+				},
+			},
+		},
+	}
+	if errs := ValidateAndNormalizeConfig(config); len(errs) != 0 {
+		t.Fatalf("unexpected errors %v", errs)
+	}
+	want := map[string]uint32{"short": 60, "ok": 300, "long": 3600, "default": models.DefaultTTL}
+	for _, r := range config.Domains[0].Records {
+		if r.TTL != want[r.GetLabel()] {
+			t.Errorf("%s: expected TTL %d, got %d", r.GetLabel(), want[r.GetLabel()], r.TTL)
+		}
+	}
+
+	for _, meta := range []map[string]string{
+		{"min_ttl": "3600", "max_ttl": "60"},
+		{"max_ttl": "invalid"},
+	} {
+		config.Domains[0].Metadata = meta
+		if errs := ValidateAndNormalizeConfig(config); len(errs) != 1 {
+			t.Errorf("%v: expected an error, got %v", meta, errs)
+		}
+	}
+}
+
+func TestFlattenAliases(t *testing.T) {
+	providers.RegisterDomainServiceProviderType("ALIAS_SUPPORT", providers.DspFuncs{}, providers.DocumentationNotes{
+		providers.CanUseAlias: providers.Can(),
+	})
+	defer func(f func(context.Context, string) ([]net.IPAddr, error)) { lookupIPAddr = f }(lookupIPAddr)
+	lookupIPAddr = func(ctx context.Context, host string) ([]net.IPAddr, error) {
+		if host != "cdn.example.net." {
+			return nil, fmt.Errorf("no such host %s", host)
+		}
+		var addrs []net.IPAddr
+		for _, ip := range []string{"2001:db8::1", "192.0.2.2", "192.0.2.1", "192.0.2.1"} {
+			addrs = append(addrs, net.IPAddr{IP: net.ParseIP(ip)})
+		}
+		return addrs, nil
+	}
+	newConfig := func(pType, target string) *models.DNSConfig {
+		return &models.DNSConfig{Domains: []*models.DomainConfig{{
+			Name:                 "example.com",
+			Metadata:             map[string]string{"alias_flattening": "300"},
+			Records:              models.Records{makeRC("@", "example.com", target, models.RecordConfig{Type: "ALIAS", TTL: 3600})},
+			DNSProviderInstances: []*models.DNSProviderInstance{{ProviderBase: models.ProviderBase{ProviderType: pType}}},
+		}}}
+	}
+
+	config := newConfig(ProviderNoDS, "cdn.example.net.")
+	if errs := FlattenAliases(config); len(errs) != 0 {
+		t.Fatal(errs)
+	}
+	var got []string
+	for _, r := range config.Domains[0].Records {
+		got = append(got, fmt.Sprintf("%s %s %s %d", r.GetLabel(), r.Type, r.GetTargetField(), r.TTL))
+	}
+	want := []string{"@ A 192.0.2.1 300", "@ A 192.0.2.2 300", "@ AAAA 2001:db8::1 300"}
+	if fmt.Sprint(got) != fmt.Sprint(want) {
+		t.Errorf("expected %v, got %v", want, got)
+	}
+
+	// Providers that can use ALIAS records get them as they are.
+	config = newConfig("ALIAS_SUPPORT", "cdn.example.net.")
+	if errs := FlattenAliases(config); len(errs) != 0 || config.Domains[0].Records[0].Type != "ALIAS" {
+		t.Errorf("expected the ALIAS record to be kept, got %v", errs)
+	}
+
+	// A target that does not resolve is an error, not an empty record set.
+	config = newConfig(ProviderNoDS, "missing.example.net.")
+	if errs := FlattenAliases(config); len(errs) != 1 {
+		t.Errorf("expected an error, got %v", errs)
+	}
+
+	// A single provider that can not use ALIAS records flattens them for all.
+	config = newConfig("ALIAS_SUPPORT", "cdn.example.net.")
+	config.Domains[0].DNSProviderInstances = append(config.Domains[0].DNSProviderInstances,
+		&models.DNSProviderInstance{ProviderBase: models.ProviderBase{ProviderType: ProviderNoDS}})
+	if errs := FlattenAliases(config); len(errs) != 0 || config.Domains[0].Records[0].Type != "A" {
+		t.Errorf("expected the ALIAS record to be flattened, got %v", errs)
+	}
+
+	// The validation accepts the ALIAS records without resolving them, so
+	// check and print-ir work offline.
+	lookupIPAddr = func(ctx context.Context, host string) ([]net.IPAddr, error) {
+		t.Errorf("unexpected lookup of %s", host)
+		return nil, fmt.Errorf("offline")
+	}
+	providers.RegisterDomainServiceProviderType("ALIAS_NO_SUPPORT", providers.DspFuncs{
+		RecordAuditor: func([]*models.RecordConfig) error { return nil },
+	}, providers.DocumentationNotes{})
+	config = newConfig("ALIAS_NO_SUPPORT", "cdn.example.net.")
+	if errs := ValidateAndNormalizeConfig(config); len(errs) != 0 || config.Domains[0].Records[0].Type != "ALIAS" {
+		t.Errorf("expected the ALIAS record to be kept, got %v", errs)
+	}
+	config.Domains[0].Metadata["alias_flattening"] = "0"
+	if errs := ValidateAndNormalizeConfig(config); len(errs) != 1 {
+		t.Errorf("expected an error for the TTL, got %v", errs)
+	}
+}
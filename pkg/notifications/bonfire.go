@@ -19,13 +19,21 @@ func init() {
 type bonfireNotifier string
 
 func (b bonfireNotifier) Notify(domain, provider, msg string, err error, preview bool) {
+	b.NotifyEvent(Event{Domain: domain, Provider: provider, Message: msg, Err: err, Preview: preview})
+}
+
+func (b bonfireNotifier) NotifyEvent(e Event) {
+	where := fmt.Sprintf("%s[%s]", e.Domain, e.Provider)
+	if summary := eventSummary(e); summary != "" {
+		where += " (" + summary + ")"
+	}
 	var payload string
-	if preview {
-		payload = fmt.Sprintf(`**Preview: %s[%s] -** %s`, domain, provider, msg)
-	} else if err != nil {
-		payload = fmt.Sprintf(`**ERROR running correction on %s[%s] -** (%s) Error: %s`, domain, provider, msg, err)
+	if e.Preview {
+		payload = fmt.Sprintf(`**Preview: %s -** %s`, where, e.Message)
+	} else if e.Err != nil {
+		payload = fmt.Sprintf(`**ERROR running correction on %s -** (%s) Error: %s`, where, e.Message, e.Err)
 	} else {
-		payload = fmt.Sprintf(`Successfully ran correction for **%s[%s]** - %s`, domain, provider, msg)
+		payload = fmt.Sprintf(`Successfully ran correction for **%s** - %s`, where, e.Message)
 	}
 	// chat doesn't markdownify multiline messages. Split in two so the first line can have markdown
 	parts := strings.SplitN(payload, "\n", 2)
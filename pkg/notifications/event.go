@@ -0,0 +1,88 @@
+package notifications
+
+import (
+	"strings"
+	"time"
+)
+
+// Event is the structured result of a correction.
+type Event struct {
+	Domain   string
+	Provider string
+	Message  string
+	// Kind is the kind of change, see ChangeKind. Empty if unknown.
+	Kind     string
+	Err      error
+	Preview  bool
+	Start    time.Time
+	Duration time.Duration // zero for previews
+}
+
+// EventNotifier is a Notifier that accepts structured events. Notifiers
+// that do not implement it are sent the events through Notify.
+type EventNotifier interface {
+	Notifier
+	NotifyEvent(e Event)
+}
+
+// NotifyEvent sends e to n, as a structured event if n supports them.
+func NotifyEvent(n Notifier, e Event) {
+	if en, ok := n.(EventNotifier); ok {
+		en.NotifyEvent(e)
+		return
+	}
+	n.Notify(e.Domain, e.Provider, e.Message, e.Err, e.Preview)
+}
+
+// NewEvent returns the event of the correction with message msg. The kind
+// of change is derived from the message.
+func NewEvent(domain, provider, msg string, err error, preview bool, start time.Time) Event {
+	e := Event{
+		Domain:   domain,
+		Provider: provider,
+		Message:  msg,
+		Kind:     ChangeKind(msg),
+		Err:      err,
+		Preview:  preview,
+		Start:    start,
+	}
+	if !preview {
+		e.Duration = time.Since(start)
+	}
+	return e
+}
+
+var changeKinds = map[string]string{
+	"CREATE":     "create",
+	"DELETE":     "delete",
+	"MODIFY":     "modify",
+	"MODIFY-TTL": "modify-ttl",
+}
+
+// ChangeKind returns the kind of change ("create", "delete", "modify" or
+// "modify-ttl") of a correction message in the format of the diff package,
+// e.g. "CREATE A www.example.com 1.2.3.4 ttl=300". It returns "" for other
+// messages.
+func ChangeKind(msg string) string {
+	fields := strings.Fields(msg)
+	for _, f := range fields {
+		// Skip decorations like "+" or "[provider]".
+		if strings.HasPrefix(f, "[") || strings.Trim(f, "+-±*") == "" {
+			continue
+		}
+		return changeKinds[strings.TrimSuffix(f, ":")]
+	}
+	return ""
+}
+
+// eventSummary describes the kind and duration of e, e.g. "create, 1.2s".
+func eventSummary(e Event) string {
+	var parts []string
+	if e.Kind != "" {
+		parts = append(parts, e.Kind)
+	}
+	if e.Duration > 0 {
+		parts = append(parts, e.Duration.Round(time.Millisecond).String())
+	}
+	return strings.Join(parts, ", ")
+}
@@ -0,0 +1,101 @@
+package notifications
+
+import (
+	"encoding/json"
+	"errors"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestChangeKind(t *testing.T) {
+	for msg, want := range map[string]string{
+		"CREATE A www.example.com 1.2.3.4 ttl=300":                         "create",
+		"DELETE TXT example.com \"x\" ttl=300":                             "delete",
+		"MODIFY A www.example.com: (1.2.3.4 ttl=300) -> (1.2.3.5 ttl=300)": "modify",
+		"MODIFY-TTL A www.example.com: (1.2.3.4 ttl=1) -> (1.2.3.4 ttl=2)": "modify-ttl",
+		"[hetzner] CREATE A www.example.com 1.2.3.4 ttl=300":               "create",
+		"+ CREATE A www.example.com 1.2.3.4 ttl=300":                       "create",
+		"Update nameservers a -> b":                                        "",
+		"":                                                                 "",
+	} {
+		if got := ChangeKind(msg); got != want {
+			t.Errorf("ChangeKind(%q) = %q, want %q", msg, got, want)
+		}
+	}
+}
+
+// eventRecorder records the events it is sent.
+type eventRecorder struct {
+	recordingNotifier
+	events []Event
+}
+
+func (r *eventRecorder) NotifyEvent(e Event) {
+	r.events = append(r.events, e)
+}
+
+func TestNotifyEvent(t *testing.T) {
+	plain, structured := &recordingNotifier{}, &eventRecorder{}
+	r := NewRoutingNotifier(multiNotifier{plain, structured})
+
+	e := NewEvent("example.com", "BIND", "CREATE A www.example.com 1.2.3.4 ttl=300", nil, false, time.Now())
+	NotifyEvent(r, e)
+
+	if len(plain.domains) != 1 || plain.domains[0] != "example.com" {
+		t.Errorf("expected the plain notifier to be notified, got %v", plain.domains)
+	}
+	if len(structured.events) != 1 || structured.events[0].Kind != "create" || structured.events[0].Provider != "BIND" {
+		t.Errorf("expected the structured event to be forwarded, got %+v", structured.events)
+	}
+	if len(structured.domains) != 0 {
+		t.Error("expected Notify not to be called on an EventNotifier")
+	}
+}
+
+func TestSlackEvent(t *testing.T) {
+	var got map[string]interface{}
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := ioutil.ReadAll(r.Body)
+		json.Unmarshal(body, &got)
+	}))
+	defer srv.Close()
+	s := &slackNotifier{URL: srv.URL}
+
+	s.NotifyEvent(Event{
+		Domain:   "example.com",
+		Provider: "BIND",
+		Message:  "DELETE A www.example.com 1.2.3.4 ttl=300",
+		Kind:     "delete",
+		Err:      errors.New("boom"),
+		Duration: 1500 * time.Millisecond,
+	})
+	attachments, _ := got["attachments"].([]interface{})
+	if len(attachments) != 1 {
+		t.Fatalf("expected one attachment, got %v", got)
+	}
+	a := attachments[0].(map[string]interface{})
+	if a["color"] != "danger" {
+		t.Errorf("expected the color of an error, got %v", a["color"])
+	}
+	fields := map[string]interface{}{}
+	for _, f := range a["fields"].([]interface{}) {
+		f := f.(map[string]interface{})
+		fields[f["title"].(string)] = f["value"]
+	}
+	want := map[string]string{"Domain": "example.com", "Provider": "BIND", "Change": "delete", "Duration": "1.5s"}
+	for k, v := range want {
+		if fields[k] != v {
+			t.Errorf("field %s: expected %q, got %v", k, v, fields[k])
+		}
+	}
+
+	// Plain notifications have no attachment.
+	got = nil
+	s.Notify("example.com", "BIND", "msg", nil, true)
+	if _, ok := got["attachments"]; ok {
+		t.Errorf("expected no attachments, got %v", got)
+	}
+}
@@ -16,7 +16,22 @@ var initers = []func(map[string]string) Notifier{}
 
 // Init will take the given config map (from creds.json notifications key) and create a single Notifier with
 // all notifications it has full config for.
-func Init(config map[string]string) Notifier {
+// Settings named "route:<pattern>:<key>" configure notifiers for the domains matching pattern only,
+// all other domains are notified with the remaining settings.
+// It returns an error if a route setting is invalid.
+func Init(config map[string]string) (Notifier, error) {
+	notifiers := initNotifiers(config)
+	r, err := initRoutes(config, notifiers)
+	if err != nil {
+		return nil, err
+	}
+	if r != nil {
+		return r, nil
+	}
+	return notifiers, nil
+}
+
+func initNotifiers(config map[string]string) multiNotifier {
 	notifiers := multiNotifier{}
 	for _, i := range initers {
 		n := i(config)
@@ -34,6 +49,11 @@ func (m multiNotifier) Notify(domain, provider string, message string, err error
 		n.Notify(domain, provider, message, err, preview)
 	}
 }
+func (m multiNotifier) NotifyEvent(e Event) {
+	for _, n := range m {
+		NotifyEvent(n, e)
+	}
+}
 func (m multiNotifier) Done() {
 	for _, n := range m {
 		n.Done()
@@ -0,0 +1,124 @@
+package notifications
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/gobwas/glob"
+)
+
+// routePrefix marks notification settings that only apply to some domains,
+// e.g. "route:*.team-a.example.com:slack_url".
+const routePrefix = "route:"
+
+type route struct {
+	pattern  string
+	glob     glob.Glob
+	notifier Notifier
+}
+
+// RoutingNotifier sends the notifications of a domain to the notifier of the
+// first route whose pattern matches the domain, or to the fallback if none
+// matches.
+type RoutingNotifier struct {
+	routes   []route
+	fallback Notifier
+}
+
+// NewRoutingNotifier returns a RoutingNotifier without routes. fallback may be nil.
+func NewRoutingNotifier(fallback Notifier) *RoutingNotifier {
+	return &RoutingNotifier{fallback: fallback}
+}
+
+// AddRoute sends notifications for domains matching pattern to n. Patterns
+// are globs where * does not match dots, e.g. "*.example.com". Routes are
+// matched in the order they were added.
+func (r *RoutingNotifier) AddRoute(pattern string, n Notifier) error {
+	g, err := glob.Compile(pattern, '.')
+	if err != nil {
+		return fmt.Errorf("invalid notification route %q: %w", pattern, err)
+	}
+	r.routes = append(r.routes, route{pattern: pattern, glob: g, notifier: n})
+	return nil
+}
+
+func (r *RoutingNotifier) notifierFor(domain string) Notifier {
+	for _, rt := range r.routes {
+		if rt.glob.Match(domain) {
+			return rt.notifier
+		}
+	}
+	return r.fallback
+}
+
+// Notify dispatches the notification to the notifier routed for domain.
+func (r *RoutingNotifier) Notify(domain, provider string, message string, err error, preview bool) {
+	if n := r.notifierFor(domain); n != nil {
+		n.Notify(domain, provider, message, err, preview)
+	}
+}
+
+// NotifyEvent dispatches the event to the notifier routed for its domain.
+func (r *RoutingNotifier) NotifyEvent(e Event) {
+	if n := r.notifierFor(e.Domain); n != nil {
+		NotifyEvent(n, e)
+	}
+}
+
+// Done calls Done on all routed notifiers and the fallback.
+func (r *RoutingNotifier) Done() {
+	for _, rt := range r.routes {
+		rt.notifier.Done()
+	}
+	if r.fallback != nil {
+		r.fallback.Done()
+	}
+}
+
+// initRoutes builds a RoutingNotifier from the "route:<pattern>:<key>"
+// settings in config. It returns nil if there are none. More specific
+// (longer) patterns are matched first.
+func initRoutes(config map[string]string, fallback Notifier) (*RoutingNotifier, error) {
+	perPattern := map[string]map[string]string{}
+	for k, v := range config {
+		if !strings.HasPrefix(k, routePrefix) {
+			continue
+		}
+		i := strings.LastIndex(k, ":")
+		if i < len(routePrefix) {
+			// The only colon is the one of the prefix.
+			return nil, fmt.Errorf("invalid notification setting %q, expected %s<pattern>:<key>", k, routePrefix)
+		}
+		pattern, key := k[len(routePrefix):i], k[i+1:]
+		if pattern == "" || key == "" {
+			return nil, fmt.Errorf("invalid notification setting %q, expected %s<pattern>:<key>", k, routePrefix)
+		}
+		if perPattern[pattern] == nil {
+			perPattern[pattern] = map[string]string{}
+		}
+		perPattern[pattern][key] = v
+	}
+	if len(perPattern) == 0 {
+		return nil, nil
+	}
+
+	var patterns []string
+	for p := range perPattern {
+		patterns = append(patterns, p)
+	}
+	sort.Slice(patterns, func(i, j int) bool {
+		if len(patterns[i]) != len(patterns[j]) {
+			return len(patterns[i]) > len(patterns[j])
+		}
+		return patterns[i] < patterns[j]
+	})
+
+	r := NewRoutingNotifier(fallback)
+	for _, p := range patterns {
+		if err := r.AddRoute(p, initNotifiers(perPattern[p])); err != nil {
+			return nil, err
+		}
+	}
+	return r, nil
+}
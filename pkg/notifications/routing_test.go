@@ -0,0 +1,84 @@
+package notifications
+
+import (
+	"reflect"
+	"testing"
+)
+
+type recordingNotifier struct {
+	domains []string
+	done    bool
+}
+
+func (r *recordingNotifier) Notify(domain, provider string, message string, err error, preview bool) {
+	r.domains = append(r.domains, domain)
+}
+
+func (r *recordingNotifier) Done() {
+	r.done = true
+}
+
+func TestRoutingNotifier(t *testing.T) {
+	teamA, teamB, fallback := &recordingNotifier{}, &recordingNotifier{}, &recordingNotifier{}
+	r := NewRoutingNotifier(fallback)
+	if err := r.AddRoute("team-a.example.com", teamA); err != nil {
+		t.Fatal(err)
+	}
+	if err := r.AddRoute("*.team-b.example.com", teamB); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, d := range []string{"team-a.example.com", "www.team-b.example.com", "example.com", "a.b.team-b.example.com"} {
+		r.Notify(d, "BIND", "msg", nil, false)
+	}
+	r.Done()
+
+	if !reflect.DeepEqual(teamA.domains, []string{"team-a.example.com"}) {
+		t.Errorf("team A got %v", teamA.domains)
+	}
+	if !reflect.DeepEqual(teamB.domains, []string{"www.team-b.example.com"}) {
+		t.Errorf("team B got %v", teamB.domains)
+	}
+	if !reflect.DeepEqual(fallback.domains, []string{"example.com", "a.b.team-b.example.com"}) {
+		t.Errorf("fallback got %v", fallback.domains)
+	}
+	if !teamA.done || !teamB.done || !fallback.done {
+		t.Error("expected Done to be called on all notifiers")
+	}
+}
+
+func TestInitRoutes(t *testing.T) {
+	n, err := Init(map[string]string{
+		"slack_url":                          "https://example.com/default",
+		"route:team-a.example.com:slack_url": "https://example.com/team-a",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	r, ok := n.(*RoutingNotifier)
+	if !ok {
+		t.Fatalf("expected a RoutingNotifier, got %T", n)
+	}
+	routed := r.notifierFor("team-a.example.com").(multiNotifier)
+	if len(routed) != 1 || routed[0].(*slackNotifier).URL != "https://example.com/team-a" {
+		t.Errorf("unexpected notifier for team A: %+v", routed)
+	}
+	fallback := r.notifierFor("example.com").(multiNotifier)
+	if len(fallback) != 1 || fallback[0].(*slackNotifier).URL != "https://example.com/default" {
+		t.Errorf("unexpected fallback notifier: %+v", fallback)
+	}
+}
+
+func TestInitInvalidRoutes(t *testing.T) {
+	for _, k := range []string{
+		"route:x",
+		"route:",
+		"route::slack_url",
+		"route:example.com:",
+		"route:[example.com:slack_url",
+	} {
+		if _, err := Init(map[string]string{k: "https://example.com/hook"}); err == nil {
+			t.Errorf("%s: expected an error", k)
+		}
+	}
+}
@@ -5,6 +5,7 @@ import (
 	"encoding/json"
 	"fmt"
 	"net/http"
+	"time"
 )
 
 func init() {
@@ -25,18 +26,53 @@ type slackNotifier struct {
 }
 
 func (s *slackNotifier) Notify(domain, provider, msg string, err error, preview bool) {
+	s.NotifyEvent(Event{Domain: domain, Provider: provider, Message: msg, Err: err, Preview: preview})
+}
+
+type slackField struct {
+	Title string `json:"title"`
+	Value string `json:"value"`
+	Short bool   `json:"short"`
+}
+
+type slackAttachment struct {
+	Color  string       `json:"color"`
+	Fields []slackField `json:"fields"`
+}
+
+func (s *slackNotifier) NotifyEvent(e Event) {
 	var payload struct {
-		Username string `json:"username"`
-		Text     string `json:"text"`
+		Username    string            `json:"username"`
+		Text        string            `json:"text"`
+		Attachments []slackAttachment `json:"attachments,omitempty"`
 	}
 	payload.Username = "DNSControl"
 
-	if preview {
-		payload.Text = fmt.Sprintf(`**Preview: %s[%s] -** %s`, domain, provider, msg)
-	} else if err != nil {
-		payload.Text = fmt.Sprintf(`**ERROR running correction on %s[%s] -** (%s) Error: %s`, domain, provider, msg, err)
+	color := "good"
+	if e.Preview {
+		payload.Text = fmt.Sprintf(`**Preview: %s[%s] -** %s`, e.Domain, e.Provider, e.Message)
+		color = "#439FE0"
+	} else if e.Err != nil {
+		payload.Text = fmt.Sprintf(`**ERROR running correction on %s[%s] -** (%s) Error: %s`, e.Domain, e.Provider, e.Message, e.Err)
+		color = "danger"
 	} else {
-		payload.Text = fmt.Sprintf(`Successfully ran correction for **%s[%s]** - %s`, domain, provider, msg)
+		payload.Text = fmt.Sprintf(`Successfully ran correction for **%s[%s]** - %s`, e.Domain, e.Provider, e.Message)
+	}
+
+	// Only structured events get the fields, plain notifications are
+	// rendered as before.
+	if e.Kind != "" || e.Duration > 0 {
+		a := slackAttachment{Color: color, Fields: []slackField{
+			{Title: "Domain", Value: e.Domain, Short: true},
+			{Title: "Provider", Value: e.Provider, Short: true},
+		}}
+		if e.Kind != "" {
+			a.Fields = append(a.Fields, slackField{Title: "Change", Value: e.Kind, Short: true})
+		}
+		if e.Duration > 0 {
+			a.Fields = append(a.Fields, slackField{Title: "Duration", Value: e.Duration.Round(time.Millisecond).String(), Short: true})
+		}
+		payload.Attachments = []slackAttachment{a}
 	}
 
 	json, _ := json.Marshal(payload)
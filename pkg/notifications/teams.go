@@ -28,6 +28,10 @@ type teamsNotifier struct {
 }
 
 func (s *teamsNotifier) Notify(domain, provider, msg string, err error, preview bool) {
+	s.NotifyEvent(Event{Domain: domain, Provider: provider, Message: msg, Err: err, Preview: preview})
+}
+
+func (s *teamsNotifier) NotifyEvent(e Event) {
 	var payload struct {
 		Username string `json:"username"`
 		Text     string `json:"text"`
@@ -35,14 +39,17 @@ func (s *teamsNotifier) Notify(domain, provider, msg string, err error, preview
 	payload.Username = "DnsControl"
 
 	// Format changes as 'preformated' text
-	msg = strings.ReplaceAll(msg, "\n", "\n    ")
+	msg := strings.ReplaceAll(e.Message, "\n", "\n    ")
+	if summary := eventSummary(e); summary != "" {
+		msg += fmt.Sprintf("\n\n%s (%s)", e.Provider, summary)
+	}
 
-	if preview {
-		payload.Text = fmt.Sprintf("**DnsControl Preview %s**\n%s", domain, msg)
-	} else if err != nil {
-		payload.Text = fmt.Sprintf("**DnsControl Error Making Changes %s**\n%s\nError: %s", domain, msg, err)
+	if e.Preview {
+		payload.Text = fmt.Sprintf("**DnsControl Preview %s**\n%s", e.Domain, msg)
+	} else if e.Err != nil {
+		payload.Text = fmt.Sprintf("**DnsControl Error Making Changes %s**\n%s\nError: %s", e.Domain, msg, e.Err)
 	} else {
-		payload.Text = fmt.Sprintf("**DnsControl Successfully Changed %s**\n%s", domain, msg)
+		payload.Text = fmt.Sprintf("**DnsControl Successfully Changed %s**\n%s", e.Domain, msg)
 	}
 
 	json, _ := json.Marshal(payload)
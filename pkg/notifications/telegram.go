@@ -0,0 +1,90 @@
+package notifications
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"unicode/utf8"
+)
+
+func init() {
+	initers = append(initers, func(cfg map[string]string) Notifier {
+		token, ok := cfg["telegram_bot_token"]
+		if !ok {
+			return nil
+		}
+		return &telegramNotifier{
+			APIURL: "https://api.telegram.org",
+			Token:  token,
+			ChatID: cfg["telegram_chat_id"],
+		}
+	})
+}
+
+// telegramMaxLength is the maximum length of the text of a Telegram message.
+const telegramMaxLength = 4096
+
+// telegramNotifier sends notifications to a Telegram chat via a bot.
+type telegramNotifier struct {
+	APIURL string
+	Token  string
+	ChatID string
+}
+
+func (t *telegramNotifier) Notify(domain, provider, msg string, err error, preview bool) {
+	t.NotifyEvent(Event{Domain: domain, Provider: provider, Message: msg, Err: err, Preview: preview})
+}
+
+func (t *telegramNotifier) NotifyEvent(e Event) {
+	where := fmt.Sprintf("%s[%s]", e.Domain, e.Provider)
+	if summary := eventSummary(e); summary != "" {
+		where += " (" + summary + ")"
+	}
+	var text string
+	if e.Preview {
+		text = fmt.Sprintf("Preview: %s\n%s", where, e.Message)
+	} else if e.Err != nil {
+		text = fmt.Sprintf("ERROR running correction on %s\n%s\nError: %s", where, e.Message, e.Err)
+	} else {
+		text = fmt.Sprintf("Successfully ran correction for %s\n%s", where, e.Message)
+	}
+
+	for _, part := range splitMessage(text, telegramMaxLength) {
+		t.send(part)
+	}
+}
+
+func (t *telegramNotifier) send(text string) {
+	payload := struct {
+		ChatID string `json:"chat_id"`
+		Text   string `json:"text"`
+	}{ChatID: t.ChatID, Text: text}
+
+	json, _ := json.Marshal(payload)
+	http.Post(t.APIURL+"/bot"+t.Token+"/sendMessage", "application/json", bytes.NewReader(json))
+}
+
+func (t *telegramNotifier) Done() {}
+
+// splitMessage splits text into parts of at most max characters, at line
+// breaks where possible.
+func splitMessage(text string, max int) []string {
+	var parts []string
+	for utf8.RuneCountInString(text) > max {
+		// The byte offset of the first character that does not fit.
+		cut := 0
+		for i := 0; i < max; i++ {
+			_, size := utf8.DecodeRuneInString(text[cut:])
+			cut += size
+		}
+		end := cut
+		if i := strings.LastIndex(text[:cut], "\n"); i > 0 {
+			end = i
+		}
+		parts = append(parts, text[:end])
+		text = strings.TrimPrefix(text[end:], "\n")
+	}
+	return append(parts, text)
+}
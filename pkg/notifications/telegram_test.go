@@ -0,0 +1,77 @@
+package notifications
+
+import (
+	"encoding/json"
+	"errors"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"unicode/utf8"
+)
+
+func TestSplitMessage(t *testing.T) {
+	long := strings.Repeat("CREATE A www.example.com 1.2.3.4 ttl=300\n", 200)
+	parts := splitMessage(long, telegramMaxLength)
+	if len(parts) < 2 {
+		t.Fatalf("expected several parts, got %d", len(parts))
+	}
+	for _, p := range parts {
+		if utf8.RuneCountInString(p) > telegramMaxLength {
+			t.Errorf("part too long: %d", utf8.RuneCountInString(p))
+		}
+		if strings.HasPrefix(p, "\n") || !strings.HasPrefix(p, "CREATE") {
+			t.Errorf("expected parts to be split at line breaks, got %q...", p[:20])
+		}
+	}
+	if strings.Join(parts, "\n") != long {
+		t.Error("expected no text to be lost")
+	}
+
+	// A single line without breaks is split anywhere, not within a character.
+	line := strings.Repeat("ä", 10)
+	parts = splitMessage(line, 4)
+	if len(parts) != 3 || parts[0] != "ääää" || parts[2] != "ää" {
+		t.Errorf("unexpected parts %q", parts)
+	}
+}
+
+func TestTelegramNotifier(t *testing.T) {
+	var paths []string
+	var texts []string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var payload struct {
+			ChatID string `json:"chat_id"`
+			Text   string `json:"text"`
+		}
+		body, _ := ioutil.ReadAll(r.Body)
+		json.Unmarshal(body, &payload)
+		if payload.ChatID != "-100123" {
+			t.Errorf("unexpected chat id %q", payload.ChatID)
+		}
+		paths = append(paths, r.URL.Path)
+		texts = append(texts, payload.Text)
+	}))
+	defer srv.Close()
+
+	notifier, err := Init(map[string]string{"telegram_bot_token": "123:abc", "telegram_chat_id": "-100123"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	tg := notifier.(multiNotifier)[0].(*telegramNotifier)
+	tg.APIURL = srv.URL
+
+	tg.Notify("example.com", "BIND", "CREATE A www.example.com 1.2.3.4 ttl=300", errors.New("boom"), false)
+	if len(paths) != 1 || paths[0] != "/bot123:abc/sendMessage" {
+		t.Fatalf("unexpected requests %v", paths)
+	}
+	if !strings.Contains(texts[0], "ERROR") || !strings.Contains(texts[0], "example.com[BIND]") || !strings.Contains(texts[0], "boom") {
+		t.Errorf("unexpected text %q", texts[0])
+	}
+
+	tg.Notify("example.com", "BIND", strings.Repeat("x\n", 3000), nil, true)
+	if len(texts) != 3 || !strings.HasPrefix(texts[1], "Preview: ") {
+		t.Errorf("expected the long preview to be sent in 2 messages, got %d", len(texts)-1)
+	}
+}
@@ -0,0 +1,19 @@
+package recordaudit
+
+import (
+	"fmt"
+
+	"github.com/StackExchange/dnscontrol/v3/models"
+)
+
+// CsyncAtApex audits CSYNC records for being at the apex of the zone. The
+// parent only looks for them there (RFC 7477, section 2.1.1), elsewhere
+// they have no effect.
+func CsyncAtApex(records []*models.RecordConfig) error {
+	for _, rc := range records {
+		if rc.Type == "CSYNC" && rc.GetLabel() != "@" {
+			return fmt.Errorf("CSYNC %s is not at the apex of the zone, it would have no effect", rc.GetLabelFQDN())
+		}
+	}
+	return nil
+}
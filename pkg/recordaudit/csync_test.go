@@ -0,0 +1,16 @@
+package recordaudit
+
+import (
+	"testing"
+
+	"github.com/StackExchange/dnscontrol/v3/models"
+)
+
+func TestCsyncAtApex(t *testing.T) {
+	if err := CsyncAtApex([]*models.RecordConfig{rec("@", "CSYNC", "0 3 A NS AAAA"), rec("www", "A", "192.0.2.1")}); err != nil {
+		t.Errorf("expected no error at the apex, got %v", err)
+	}
+	if err := CsyncAtApex([]*models.RecordConfig{rec("sub", "CSYNC", "0 3 A NS AAAA")}); err == nil {
+		t.Errorf("expected an error below the apex")
+	}
+}
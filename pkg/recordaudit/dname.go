@@ -0,0 +1,38 @@
+package recordaudit
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/StackExchange/dnscontrol/v3/models"
+)
+
+// DnameNoChildren audits DNAME records for records beneath their owner
+// name. A DNAME redirects the whole subtree, hence such records would never
+// be served (RFC 6672, section 2.4). A CNAME at the owner name is flagged,
+// too.
+func DnameNoChildren(records []*models.RecordConfig) error {
+	dnames := map[string]bool{}
+	for _, rc := range records {
+		if rc.Type == "DNAME" {
+			dnames[strings.ToLower(rc.GetLabelFQDN())] = true
+		}
+	}
+	if len(dnames) == 0 {
+		return nil
+	}
+
+	for _, rc := range records {
+		name := strings.ToLower(rc.GetLabelFQDN())
+		if rc.Type == "CNAME" && dnames[name] {
+			return fmt.Errorf("%s has both a DNAME and a CNAME record", name)
+		}
+		for parent := name; strings.Contains(parent, "."); {
+			parent = parent[strings.Index(parent, ".")+1:]
+			if dnames[parent] {
+				return fmt.Errorf("%s %s is beneath the DNAME at %s and would never be served", rc.Type, name, parent)
+			}
+		}
+	}
+	return nil
+}
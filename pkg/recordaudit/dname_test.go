@@ -0,0 +1,59 @@
+package recordaudit
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/StackExchange/dnscontrol/v3/models"
+)
+
+func rec(label, rtype, target string) *models.RecordConfig {
+	rc := &models.RecordConfig{Type: rtype}
+	rc.SetLabel(label, "example.com")
+	rc.SetTarget(target)
+	return rc
+}
+
+func TestDnameNoChildren(t *testing.T) {
+	tests := []struct {
+		name    string
+		records []*models.RecordConfig
+		wantErr string
+	}{
+		{"no DNAME", []*models.RecordConfig{rec("www.old", "A", "192.0.2.1")}, ""},
+		{"DNAME alone", []*models.RecordConfig{
+			rec("old", "DNAME", "new.example.com."),
+			rec("new", "A", "192.0.2.1"),
+		}, ""},
+		{"other types at the owner", []*models.RecordConfig{
+			rec("old", "DNAME", "new.example.com."),
+			rec("old", "A", "192.0.2.1"),
+		}, ""},
+		{"record beneath", []*models.RecordConfig{
+			rec("old", "DNAME", "new.example.com."),
+			rec("www.old", "A", "192.0.2.1"),
+		}, "A www.old.example.com is beneath the DNAME at old.example.com"},
+		{"record deep beneath", []*models.RecordConfig{
+			rec("a.b.OLD", "TXT", "x"),
+			rec("old", "DNAME", "new.example.com."),
+		}, "beneath the DNAME at old.example.com"},
+		{"CNAME at the owner", []*models.RecordConfig{
+			rec("old", "DNAME", "new.example.com."),
+			rec("old", "CNAME", "new.example.com."),
+		}, "has both a DNAME and a CNAME record"},
+	}
+	for _, tst := range tests {
+		t.Run(tst.name, func(t *testing.T) {
+			err := DnameNoChildren(tst.records)
+			if tst.wantErr == "" {
+				if err != nil {
+					t.Errorf("expected no error, got %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tst.wantErr) {
+				t.Errorf("expected error containing %q, got %v", tst.wantErr, err)
+			}
+		})
+	}
+}
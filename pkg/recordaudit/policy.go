@@ -0,0 +1,158 @@
+package recordaudit
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/StackExchange/dnscontrol/v3/models"
+)
+
+// Auditor checks the records of a domain.
+type Auditor func(records []*models.RecordConfig) error
+
+// A Policy is an org-wide rule for the records of a domain, checked before
+// any corrections are computed. Unlike the auditors of the providers, the
+// policies are chosen by the user (see ParsePolicies).
+type Policy struct {
+	Name  string
+	Warn  bool // Only warn about violations, do not stop the push.
+	Audit Auditor
+}
+
+// policyFuncs returns the Auditor of a policy for the argument after "=",
+// which is empty if there is none.
+var policyFuncs = map[string]func(arg string) (Auditor, error){
+	"no-wildcard-cname": func(arg string) (Auditor, error) { return NoWildcardCNAME, noArg("no-wildcard-cname", arg) },
+	"require-caa":       func(arg string) (Auditor, error) { return RequireCAA, noArg("require-caa", arg) },
+	"txt-max-length":    txtMaxLengthPolicy,
+}
+
+// RegisterPolicy adds a policy that can be enabled by name. fn returns the
+// Auditor for the argument after "=", or an error if the argument is
+// invalid.
+func RegisterPolicy(name string, fn func(arg string) (Auditor, error)) {
+	if _, ok := policyFuncs[name]; ok {
+		panic(fmt.Sprintf("policy %s is already registered", name))
+	}
+	policyFuncs[name] = fn
+}
+
+// PolicyNames returns the names of the registered policies.
+func PolicyNames() []string {
+	var names []string
+	for name := range policyFuncs {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// ParsePolicies parses a comma separated list of policies, e.g.
+// "no-wildcard-cname,txt-max-length=512,warn:require-caa". Policies with
+// the prefix "warn:" only warn about violations.
+func ParsePolicies(s string) ([]*Policy, error) {
+	var policies []*Policy
+	for _, item := range strings.Split(s, ",") {
+		item = strings.TrimSpace(item)
+		if item == "" {
+			continue
+		}
+		p := &Policy{}
+		if strings.HasPrefix(item, "warn:") {
+			p.Warn = true
+			item = strings.TrimPrefix(item, "warn:")
+		}
+		parts := strings.SplitN(item, "=", 2)
+		p.Name = parts[0]
+		fn, ok := policyFuncs[p.Name]
+		if !ok {
+			return nil, fmt.Errorf("unknown policy %q, expected one of %s", p.Name, strings.Join(PolicyNames(), ", "))
+		}
+		var arg string
+		if len(parts) == 2 {
+			arg = parts[1]
+		}
+		audit, err := fn(arg)
+		if err != nil {
+			return nil, err
+		}
+		p.Audit = audit
+		policies = append(policies, p)
+	}
+	return policies, nil
+}
+
+// CheckPolicies checks the records of a domain against the policies. The
+// violations of policies that only warn are returned as warnings.
+func CheckPolicies(policies []*Policy, records []*models.RecordConfig) (warnings []error, errs []error) {
+	for _, p := range policies {
+		err := p.Audit(records)
+		if err == nil {
+			continue
+		}
+		err = fmt.Errorf("policy %s: %w", p.Name, err)
+		if p.Warn {
+			warnings = append(warnings, err)
+		} else {
+			errs = append(errs, err)
+		}
+	}
+	return warnings, errs
+}
+
+func noArg(name, arg string) error {
+	if arg != "" {
+		return fmt.Errorf("policy %s takes no argument, got %q", name, arg)
+	}
+	return nil
+}
+
+func txtMaxLengthPolicy(arg string) (Auditor, error) {
+	max, err := strconv.Atoi(arg)
+	if err != nil || max <= 0 {
+		return nil, fmt.Errorf("policy txt-max-length needs a length, e.g. txt-max-length=512, got %q", arg)
+	}
+	return TxtMaxLength(max), nil
+}
+
+// NoWildcardCNAME audits for CNAME records at wildcard names.
+func NoWildcardCNAME(records []*models.RecordConfig) error {
+	for _, rc := range records {
+		if rc.Type == "CNAME" && (rc.GetLabel() == "*" || strings.HasPrefix(rc.GetLabel(), "*.")) {
+			return fmt.Errorf("CNAME %s is a wildcard", rc.GetLabelFQDN())
+		}
+	}
+	return nil
+}
+
+// RequireCAA audits for a CAA record at the apex.
+func RequireCAA(records []*models.RecordConfig) error {
+	for _, rc := range records {
+		if rc.Type == "CAA" && rc.GetLabel() == "@" {
+			return nil
+		}
+	}
+	return fmt.Errorf("no CAA record at the apex")
+}
+
+// TxtMaxLength returns an Auditor for TXT records that are longer than
+// max octets, all strings together.
+func TxtMaxLength(max int) Auditor {
+	return func(records []*models.RecordConfig) error {
+		for _, rc := range records {
+			if !rc.HasFormatIdenticalToTXT() {
+				continue
+			}
+			length := 0
+			for _, txt := range rc.TxtStrings {
+				length += len(txt)
+			}
+			if length > max {
+				return fmt.Errorf("TXT %s is %d octets long, more than %d", rc.GetLabelFQDN(), length, max)
+			}
+		}
+		return nil
+	}
+}
@@ -0,0 +1,88 @@
+package recordaudit
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/StackExchange/dnscontrol/v3/models"
+)
+
+func TestParsePolicies(t *testing.T) {
+	policies, err := ParsePolicies("no-wildcard-cname, txt-max-length=10,warn:require-caa")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(policies) != 3 || policies[0].Warn || policies[1].Name != "txt-max-length" || !policies[2].Warn {
+		t.Errorf("unexpected policies %+v", policies)
+	}
+
+	for spec, wantErr := range map[string]string{
+		"no-such-policy":         "unknown policy",
+		"txt-max-length":         "needs a length",
+		"txt-max-length=0":       "needs a length",
+		"require-caa=yes":        "takes no argument",
+		"warn:no-wildcard-cname": "",
+		"":                       "",
+	} {
+		_, err := ParsePolicies(spec)
+		if wantErr == "" {
+			if err != nil {
+				t.Errorf("%q: unexpected error %v", spec, err)
+			}
+		} else if err == nil || !strings.Contains(err.Error(), wantErr) {
+			t.Errorf("%q: expected error containing %q, got %v", spec, wantErr, err)
+		}
+	}
+}
+
+func TestCheckPolicies(t *testing.T) {
+	policies, err := ParsePolicies("no-wildcard-cname,txt-max-length=10,warn:require-caa")
+	if err != nil {
+		t.Fatal(err)
+	}
+	txt := &models.RecordConfig{Type: "TXT"}
+	txt.SetLabel("@", "example.com")
+	txt.SetTargetTXTs([]string{"123456", "78901"})
+
+	warnings, errs := CheckPolicies(policies, []*models.RecordConfig{
+		rec("*.dev", "CNAME", "dev.example.com."),
+		rec("www", "CNAME", "example.com."),
+		txt,
+	})
+	if len(warnings) != 1 || !strings.Contains(warnings[0].Error(), "policy require-caa: no CAA record at the apex") {
+		t.Errorf("expected a warning about the missing CAA record, got %v", warnings)
+	}
+	if len(errs) != 2 ||
+		!strings.Contains(errs[0].Error(), "policy no-wildcard-cname: CNAME *.dev.example.com is a wildcard") ||
+		!strings.Contains(errs[1].Error(), "TXT example.com is 11 octets long, more than 10") {
+		t.Errorf("expected errors about the wildcard CNAME and the long TXT, got %v", errs)
+	}
+
+	warnings, errs = CheckPolicies(policies, []*models.RecordConfig{rec("@", "CAA", "letsencrypt.org")})
+	if len(warnings) != 0 || len(errs) != 0 {
+		t.Errorf("expected no violations, got %v %v", warnings, errs)
+	}
+}
+
+var errNoA = errors.New("no A records allowed")
+
+func TestRegisterPolicy(t *testing.T) {
+	RegisterPolicy("test-no-a", func(arg string) (Auditor, error) {
+		return func(records []*models.RecordConfig) error {
+			for _, rc := range records {
+				if rc.Type == "A" {
+					return errNoA
+				}
+			}
+			return nil
+		}, nil
+	})
+	policies, err := ParsePolicies("test-no-a")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, errs := CheckPolicies(policies, []*models.RecordConfig{rec("www", "A", "192.0.2.1")}); len(errs) != 1 {
+		t.Errorf("expected the registered policy to be checked, got %v", errs)
+	}
+}
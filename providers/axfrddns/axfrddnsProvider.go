@@ -152,6 +152,7 @@ func init() {
 		RecordAuditor: AuditRecords,
 	}
 	providers.RegisterDomainServiceProviderType("AXFRDDNS", fns, features)
+	providers.RegisterMaintainer("AXFRDDNS", "@hnrgrgr")
 }
 
 // Param is used to decode extra parameters sent to provider.
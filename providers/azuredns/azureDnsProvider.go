@@ -73,6 +73,7 @@ func init() {
 		RecordAuditor: AuditRecords,
 	}
 	providers.RegisterDomainServiceProviderType("AZURE_DNS", fns, features)
+	providers.RegisterMaintainer("AZURE_DNS", "@vatsalyagoel")
 	providers.RegisterCustomRecordType("AZURE_ALIAS", "AZURE_DNS", "")
 }
 
@@ -2,10 +2,14 @@ package bind
 
 import (
 	"github.com/StackExchange/dnscontrol/v3/models"
+	"github.com/StackExchange/dnscontrol/v3/pkg/recordaudit"
 )
 
 // AuditRecords returns an error if any records are not
 // supportable by this provider.
 func AuditRecords(records []*models.RecordConfig) error {
-	return nil
+	if err := recordaudit.DnameNoChildren(records); err != nil {
+		return err
+	}
+	return recordaudit.CsyncAtApex(records)
 }
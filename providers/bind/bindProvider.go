@@ -35,8 +35,12 @@ import (
 var features = providers.DocumentationNotes{
 	providers.CanAutoDNSSEC:          providers.Can("Just writes out a comment indicating DNSSEC was requested"),
 	providers.CanGetZones:            providers.Can(),
+	providers.CanUseAPL:              providers.Can(),
 	providers.CanUseCAA:              providers.Can(),
+	providers.CanUseCSYNC:            providers.Can(),
 	providers.CanUseDS:               providers.Can(),
+	providers.CanUseDNAME:            providers.Can(),
+	providers.CanUseHINFO:            providers.Can(),
 	providers.CanUseNAPTR:            providers.Can(),
 	providers.CanUsePTR:              providers.Can(),
 	providers.CanUseSOA:              providers.Can(),
@@ -83,6 +87,7 @@ func init() {
 		RecordAuditor: AuditRecords,
 	}
 	providers.RegisterDomainServiceProviderType("BIND", fns, features)
+	providers.RegisterMaintainer("BIND", "@tlimoncelli")
 }
 
 // SoaDefaults contains the parts of the default SOA settings.
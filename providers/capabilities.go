@@ -4,6 +4,7 @@ package providers
 
 import (
 	"log"
+	"sort"
 )
 
 // Capability is a bitmasked set of "features" that a provider supports. Only use constants from this package.
@@ -69,6 +70,39 @@ const (
 
 	// CanUseSOA indicates the provider supports full management of a zone's SOA record
 	CanUseSOA
+
+	// CanReplaceZone indicates the provider implements ZoneReplacer and can
+	// replace all records of a zone at once.
+	CanReplaceZone
+
+	// CanRunConcurrently indicates the corrections of different zones can be
+	// applied concurrently, i.e. the provider is safe for concurrent use.
+	CanRunConcurrently
+
+	// CanUseHTTPS indicates the provider can handle HTTPS records
+	CanUseHTTPS
+
+	// CanUseSVCB indicates the provider can handle SVCB records
+	CanUseSVCB
+
+	// CanUseDNAME indicates the provider can handle DNAME records
+	CanUseDNAME
+
+	// CanUseLOC indicates the provider can handle LOC records
+	CanUseLOC
+
+	// CanUseRouting indicates the provider can handle the routing policies
+	// of records, see ROUTING.
+	CanUseRouting
+
+	// CanUseHINFO indicates the provider can handle HINFO records
+	CanUseHINFO
+
+	// CanUseAPL indicates the provider can handle APL records
+	CanUseAPL
+
+	// CanUseCSYNC indicates the provider can handle CSYNC records
+	CanUseCSYNC
 )
 
 var providerCapabilities = map[string]map[Capability]bool{}
@@ -160,3 +194,58 @@ func (n *DocumentationNote) addStrings(comments []string) {
 		n.Link = comments[1]
 	}
 }
+
+// CapabilityMatrix returns the capabilities of every registered
+// provider (DNS providers and registrars), keyed by provider type.
+// The result is a copy and may be modified freely.
+func CapabilityMatrix() map[string]map[Capability]bool {
+	matrix := map[string]map[Capability]bool{}
+	for pName, caps := range providerCapabilities {
+		m := map[Capability]bool{}
+		for k, v := range caps {
+			m[k] = v
+		}
+		matrix[pName] = m
+	}
+	return matrix
+}
+
+// ProviderReport is a machine-readable summary of a single provider,
+// suitable for generating compatibility reports.
+type ProviderReport struct {
+	Name         string          `json:"name"`
+	Maintainer   string          `json:"maintainer,omitempty"`
+	DNSProvider  bool            `json:"dnsProvider"`
+	Registrar    bool            `json:"registrar"`
+	Capabilities map[string]bool `json:"capabilities"`
+}
+
+// CapabilityReport returns a ProviderReport for every registered
+// provider, sorted by provider type. Capabilities are keyed by their
+// name (i.e. "CanUseCAA") so the report can be marshalled to JSON.
+func CapabilityReport() []ProviderReport {
+	matrix := CapabilityMatrix()
+	names := make([]string, 0, len(matrix))
+	for pName := range matrix {
+		names = append(names, pName)
+	}
+	sort.Strings(names)
+
+	reports := make([]ProviderReport, 0, len(names))
+	for _, pName := range names {
+		caps := map[string]bool{}
+		for k, v := range matrix[pName] {
+			caps[k.String()] = v
+		}
+		_, isDSP := DNSProviderTypes[pName]
+		_, isRegistrar := RegistrarTypes[pName]
+		reports = append(reports, ProviderReport{
+			Name:         pName,
+			Maintainer:   ProviderMaintainers[pName],
+			DNSProvider:  isDSP,
+			Registrar:    isRegistrar,
+			Capabilities: caps,
+		})
+	}
+	return reports
+}
@@ -0,0 +1,72 @@
+package providers
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/StackExchange/dnscontrol/v3/models"
+)
+
+func TestCapabilityReport(t *testing.T) {
+	const dsp, registrar = "TEST_MATRIX_DSP", "TEST_MATRIX_REG"
+	defer func() {
+		for _, name := range []string{dsp, registrar} {
+			delete(DNSProviderTypes, name)
+			delete(RegistrarTypes, name)
+			delete(providerCapabilities, name)
+			delete(Notes, name)
+			delete(ProviderMaintainers, name)
+		}
+	}()
+
+	RegisterDomainServiceProviderType(dsp, DspFuncs{
+		Initializer: func(map[string]string, json.RawMessage) (DNSServiceProvider, error) {
+			return nil, nil
+		},
+		RecordAuditor: func([]*models.RecordConfig) error { return nil },
+	}, CanUsePTR, DocumentationNotes{
+		CanUseSRV: Can(),
+		CanUseCAA: Cannot(),
+	})
+	RegisterMaintainer(dsp, "@someone")
+	RegisterRegistrarType(registrar, func(map[string]string) (Registrar, error) {
+		return nil, nil
+	}, DocCreateDomains)
+
+	matrix := CapabilityMatrix()
+	for cap, want := range map[Capability]bool{CanUsePTR: true, CanUseSRV: true, CanUseCAA: false} {
+		if got, ok := matrix[dsp][cap]; !ok || got != want {
+			t.Errorf("%s: expected %v, got %v (present: %v)", cap, want, got, ok)
+		}
+	}
+	if _, ok := matrix[dsp][CanUseLOC]; ok {
+		t.Errorf("expected undeclared capabilities to be missing")
+	}
+	// The matrix is a copy.
+	matrix[dsp][CanUseLOC] = true
+	if ProviderHasCapability(dsp, CanUseLOC) {
+		t.Errorf("expected changes of the matrix to not affect the registry")
+	}
+
+	reports := map[string]ProviderReport{}
+	previous := ""
+	for _, r := range CapabilityReport() {
+		if r.Name < previous {
+			t.Errorf("expected the report to be sorted, got %s after %s", r.Name, previous)
+		}
+		previous = r.Name
+		reports[r.Name] = r
+	}
+
+	r := reports[dsp]
+	if !r.DNSProvider || r.Registrar || r.Maintainer != "@someone" {
+		t.Errorf("unexpected report of %s: %+v", dsp, r)
+	}
+	if !r.Capabilities["CanUsePTR"] || !r.Capabilities["CanUseSRV"] || r.Capabilities["CanUseCAA"] {
+		t.Errorf("unexpected capabilities of %s: %v", dsp, r.Capabilities)
+	}
+	r = reports[registrar]
+	if r.DNSProvider || !r.Registrar || r.Maintainer != "" || !r.Capabilities["DocCreateDomains"] {
+		t.Errorf("unexpected report of %s: %+v", registrar, r)
+	}
+}
@@ -26,11 +26,21 @@ func _() {
 	_ = x[CanGetZones-15]
 	_ = x[CanUseAzureAlias-16]
 	_ = x[CanUseSOA-17]
+	_ = x[CanReplaceZone-18]
+	_ = x[CanRunConcurrently-19]
+	_ = x[CanUseHTTPS-20]
+	_ = x[CanUseSVCB-21]
+	_ = x[CanUseDNAME-22]
+	_ = x[CanUseLOC-23]
+	_ = x[CanUseRouting-24]
+	_ = x[CanUseHINFO-25]
+	_ = x[CanUseAPL-26]
+	_ = x[CanUseCSYNC-27]
 }
 
-const _Capability_name = "CanUseAliasCanUseCAACanUseDSCanUseDSForChildrenCanUsePTRCanUseNAPTRCanUseSRVCanUseSSHFPCanUseTLSACanAutoDNSSECCantUseNOPURGEDocOfficiallySupportedDocDualHostDocCreateDomainsCanUseRoute53AliasCanGetZonesCanUseAzureAliasCanUseSOA"
+const _Capability_name = "CanUseAliasCanUseCAACanUseDSCanUseDSForChildrenCanUsePTRCanUseNAPTRCanUseSRVCanUseSSHFPCanUseTLSACanAutoDNSSECCantUseNOPURGEDocOfficiallySupportedDocDualHostDocCreateDomainsCanUseRoute53AliasCanGetZonesCanUseAzureAliasCanUseSOACanReplaceZoneCanRunConcurrentlyCanUseHTTPSCanUseSVCBCanUseDNAMECanUseLOCCanUseRoutingCanUseHINFOCanUseAPLCanUseCSYNC"
 
-var _Capability_index = [...]uint8{0, 11, 20, 28, 47, 56, 67, 76, 87, 97, 110, 124, 146, 157, 173, 191, 202, 218, 227}
+var _Capability_index = [...]uint16{0, 11, 20, 28, 47, 56, 67, 76, 87, 97, 110, 124, 146, 157, 173, 191, 202, 218, 227, 241, 259, 270, 280, 291, 300, 313, 324, 333, 344}
 
 func (i Capability) String() string {
 	if i >= Capability(len(_Capability_index)-1) {
@@ -58,6 +58,7 @@ func init() {
 		RecordAuditor: AuditRecords,
 	}
 	providers.RegisterDomainServiceProviderType("CLOUDNS", fns, features)
+	providers.RegisterMaintainer("CLOUDNS", "@pragmaton")
 }
 
 // GetNameservers returns the nameservers for a domain.
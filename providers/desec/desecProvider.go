@@ -61,6 +61,7 @@ func init() {
 		RecordAuditor: AuditRecords,
 	}
 	providers.RegisterDomainServiceProviderType("DESEC", fns, features)
+	providers.RegisterMaintainer("DESEC", "@D3luxee")
 }
 
 // GetNameservers returns the nameservers for a domain.
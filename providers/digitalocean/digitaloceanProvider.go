@@ -85,6 +85,7 @@ func init() {
 		RecordAuditor: AuditRecords,
 	}
 	providers.RegisterDomainServiceProviderType("DIGITALOCEAN", fns, features)
+	providers.RegisterMaintainer("DIGITALOCEAN", "@Deraen")
 }
 
 // EnsureDomainExists returns an error if domain doesn't exist.
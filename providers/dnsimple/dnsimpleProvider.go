@@ -40,6 +40,7 @@ func init() {
 		RecordAuditor: AuditRecords,
 	}
 	providers.RegisterDomainServiceProviderType("DNSIMPLE", fns, features)
+	providers.RegisterMaintainer("DNSIMPLE", "@aeden")
 }
 
 const stateRegistered = "registered"
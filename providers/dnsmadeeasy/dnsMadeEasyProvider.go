@@ -34,6 +34,7 @@ func init() {
 	}
 
 	providers.RegisterDomainServiceProviderType("DNSMADEEASY", fns, features)
+	providers.RegisterMaintainer("DNSMADEEASY", "@vojtad")
 }
 
 // New creates a new API handle.
@@ -19,6 +19,7 @@ Info required in `creds.json`:
 
 func init() {
 	providers.RegisterRegistrarType("DNSOVERHTTPS", newDNSOverHTTPS)
+	providers.RegisterMaintainer("DNSOVERHTTPS", "@mikenz")
 }
 
 func newDNSOverHTTPS(m map[string]string) (providers.Registrar, error) {
@@ -41,6 +41,7 @@ func init() {
 	}
 	providers.RegisterDomainServiceProviderType("GANDI_V5", fns, features)
 	providers.RegisterRegistrarType("GANDI_V5", newReg)
+	providers.RegisterMaintainer("GANDI_V5", "@TomOnTime")
 }
 
 // features declares which features and options are available.
@@ -65,6 +65,7 @@ func init() {
 		RecordAuditor: AuditRecords,
 	}
 	providers.RegisterDomainServiceProviderType("HEDNS", fns, features)
+	providers.RegisterMaintainer("HEDNS", "@rblenkinsopp")
 }
 
 var defaultNameservers = []string{
@@ -2,26 +2,86 @@ package hetzner
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"io/ioutil"
+	"math/rand"
 	"net/http"
+	"net/url"
 	"strconv"
 	"strings"
+	"sync"
 	"time"
+
+	"github.com/StackExchange/dnscontrol/v3/pkg/apimetrics"
 )
 
 const (
 	baseURL = "https://dns.hetzner.com/api/v1"
+
+	// createZoneAttempts bounds the number of tries for creating a zone.
+	createZoneAttempts = 3
+
+	// defaultRateLimitRetries bounds the retries of a rate-limited request.
+	defaultRateLimitRetries = 10
+)
+
+// createZoneRetryDelay is the base delay between createZone attempts.
+// It is multiplied by the attempt number.
+var createZoneRetryDelay = 2 * time.Second
+
+// rateLimitBackoff is the delay before the first retry of a rate-limited
+// request without a Retry-After header. It doubles with every retry, up to
+// rateLimitBackoffMax.
+var (
+	rateLimitBackoff    = time.Second
+	rateLimitBackoffMax = time.Minute
 )
 
 type hetznerProvider struct {
 	apiKey             string
+	baseURL            string
+	auditLog           *auditLog
+	transactional      bool
+	dumpPayloads       bool
+	scriptPath         string
+	scriptMu           sync.Mutex // guards scriptStarted and the script
+	scriptStarted      bool
+	ownerID            string
+	rateLimitRetries   int        // 0 means defaultRateLimitRetries
+	zonesMu            sync.Mutex // guards zones
 	zones              map[string]zone
+	recordsMu          sync.Mutex // guards records and recordsGen
+	records            map[string][]record
+	recordsGen         int // incremented whenever records is invalidated
 	requestRateLimiter requestRateLimiter
 }
 
+// apiError is returned for any non-200 response from the HETZNER API.
+type apiError struct {
+	StatusCode int
+	Message    string
+	Code       int // the code in the error of the response body
+}
+
+func (e *apiError) Error() string {
+	return fmt.Sprintf("bad status code from HETZNER: %d not 200", e.StatusCode)
+}
+
+// isRetryable returns true for errors that are likely transient.
+func (e *apiError) isRetryable() bool {
+	return e.StatusCode >= 500
+}
+
+// isUnprocessable returns true if the API rejected the request as
+// unprocessable, which it does e.g. for an object that exists already.
+func (e *apiError) isUnprocessable() bool {
+	return e.StatusCode == http.StatusUnprocessableEntity && e.Code == http.StatusUnprocessableEntity
+}
+
 func checkIsLockedSystemRecord(record record) error {
 	if record.Type == "SOA" {
 		// The upload of a BIND zone file can change the SOA record.
@@ -31,6 +91,16 @@ func checkIsLockedSystemRecord(record record) error {
 	return nil
 }
 
+// isDNSSECRecord returns true for records that are maintained by the
+// DNSSEC signer. DNSControl must never touch them.
+func isDNSSECRecord(record record) bool {
+	switch record.Type {
+	case "RRSIG", "NSEC", "NSEC3", "NSEC3PARAM", "DNSKEY":
+		return true
+	}
+	return false
+}
+
 func getHomogenousDelay(headers http.Header, quotaName string) (time.Duration, error) {
 	quota, err := parseHeaderAsInt(headers, "X-Ratelimit-Limit-"+strings.Title(quotaName))
 	if err != nil {
@@ -68,65 +138,216 @@ func parseHeaderAsInt(headers http.Header, headerName string) (int64, error) {
 	return strconv.ParseInt(value[0], 10, 0)
 }
 
-func (api *hetznerProvider) bulkCreateRecords(records []record) error {
+// maxBulkRecords is the number of records HETZNER accepts per bulk request.
+const maxBulkRecords = 100
+
+// bulkBatches splits records into batches for the bulk endpoints.
+func bulkBatches(records []record) [][]record {
+	var batches [][]record
+	for len(records) > maxBulkRecords {
+		batches = append(batches, records[:maxBulkRecords])
+		records = records[maxBulkRecords:]
+	}
+	return append(batches, records)
+}
+
+// bulkBatchError describes the failure of one batch of a bulk request.
+func bulkBatchError(i, n int, batch []record, err error) error {
+	if n == 1 {
+		return err
+	}
+	summaries := make([]string, len(batch))
+	for j, r := range batch {
+		summaries[j] = summarizeRecord(r)
+	}
+	return fmt.Errorf("batch %d of %d (%s): %w", i+1, n, strings.Join(summaries, ", "), err)
+}
+
+// joinBatchErrors adds err to the errors of earlier batches, if any.
+func joinBatchErrors(errs error, err error) error {
+	if errs == nil {
+		return err
+	}
+	return fmt.Errorf("%w; %v", errs, err)
+}
+
+// bulkCreateRecords creates records, in batches of maxBulkRecords. All
+// batches are sent even if one fails. The records that were created are
+// returned in any case, the error lists the records of the failed batches.
+func (api *hetznerProvider) bulkCreateRecords(ctx context.Context, records []record) ([]record, error) {
 	for _, record := range records {
 		if err := checkIsLockedSystemRecord(record); err != nil {
-			return err
+			return nil, err
 		}
 	}
 
-	request := bulkCreateRecordsRequest{
-		Records: records,
+	var created []record
+	var errs error
+	batches := bulkBatches(records)
+	for i, batch := range batches {
+		request := bulkCreateRecordsRequest{
+			Records: batch,
+		}
+		response := &bulkCreateRecordsResponse{}
+		if err := api.auditedRequest(ctx, "/records/bulk", "POST", request, response, batch...); err != nil {
+			errs = joinBatchErrors(errs, bulkBatchError(i, len(batches), batch, err))
+			continue
+		}
+		created = append(created, response.Records...)
 	}
-	return api.request("/records/bulk", "POST", request, nil)
+	return created, errs
 }
 
-func (api *hetznerProvider) bulkUpdateRecords(records []record) error {
+// bulkUpdateRecords updates records, in batches of maxBulkRecords. All
+// batches are sent even if one fails. The records that were updated are
+// returned in any case, the error lists the records of the failed batches.
+func (api *hetznerProvider) bulkUpdateRecords(ctx context.Context, records []record) ([]record, error) {
 	for _, record := range records {
 		if err := checkIsLockedSystemRecord(record); err != nil {
-			return err
+			return nil, err
 		}
 	}
 
-	request := bulkUpdateRecordsRequest{
-		Records: records,
+	var updated []record
+	var errs error
+	batches := bulkBatches(records)
+	for i, batch := range batches {
+		request := bulkUpdateRecordsRequest{
+			Records: batch,
+		}
+		if err := api.auditedRequest(ctx, "/records/bulk", "PUT", request, nil, batch...); err != nil {
+			errs = joinBatchErrors(errs, bulkBatchError(i, len(batches), batch, err))
+			continue
+		}
+		updated = append(updated, batch...)
 	}
-	return api.request("/records/bulk", "PUT", request, nil)
+	return updated, errs
 }
 
-func (api *hetznerProvider) createRecord(record record) error {
+func (api *hetznerProvider) createRecord(ctx context.Context, record record) error {
 	if err := checkIsLockedSystemRecord(record); err != nil {
 		return err
 	}
 
 	request := createRecordRequest{
 		Name:   record.Name,
-		TTL:    *record.TTL,
+		TTL:    record.TTL,
 		Type:   record.Type,
 		Value:  record.Value,
 		ZoneID: record.ZoneID,
 	}
-	return api.request("/records", "POST", request, nil)
+	return api.request(ctx, "/records", "POST", request, nil)
 }
 
-func (api *hetznerProvider) createZone(name string) error {
+// createZone creates a zone. The created zone is returned, it is nil if
+// the zone existed already.
+func (api *hetznerProvider) createZone(ctx context.Context, name string) (*zone, error) {
 	request := createZoneRequest{
 		Name: name,
 	}
-	return api.request("/zones", "POST", request, nil)
+	var created *zone
+	var err error
+	for attempt := 1; attempt <= createZoneAttempts; attempt++ {
+		response := &createZoneResponse{}
+		err = api.request(ctx, "/zones", "POST", request, response)
+		if err == nil {
+			created = &response.Zone
+		}
+		var aErr *apiError
+		if err == nil || !errors.As(err, &aErr) {
+			break
+		}
+		if aErr.isUnprocessable() {
+			// Someone else (or an earlier, timed out attempt) may have created
+			// the zone. An invalid name gets the same answer, so the zone must
+			// exist now.
+			if _, zErr := api.getCurrentZone(ctx, name); zErr == nil {
+				err = nil
+			}
+			break
+		}
+		if !aErr.isRetryable() || attempt == createZoneAttempts {
+			break
+		}
+		fmt.Printf("Creating zone %q failed (%s), retrying...\n", name, err)
+		if err = sleep(ctx, createZoneRetryDelay*time.Duration(attempt)); err != nil {
+			break
+		}
+	}
+	// The cached list of zones is outdated now, refresh it on next use.
+	api.zonesMu.Lock()
+	api.zones = nil
+	api.zonesMu.Unlock()
+	if err != nil {
+		return nil, fmt.Errorf("failed creating zone %q: %w", name, err)
+	}
+	return created, nil
 }
 
-func (api *hetznerProvider) deleteRecord(record record) error {
+func (api *hetznerProvider) deleteRecord(ctx context.Context, record record) error {
 	if err := checkIsLockedSystemRecord(record); err != nil {
 		return err
 	}
 
 	url := fmt.Sprintf("/records/%s", record.ID)
-	return api.request(url, "DELETE", nil, nil)
+	return api.auditedRequest(ctx, url, "DELETE", nil, nil, record)
+}
+
+// getAllRecords returns the records of a zone that are available for updating.
+func (api *hetznerProvider) getAllRecords(ctx context.Context, domain string) ([]record, error) {
+	all, err := api.getCachedRecords(ctx, domain)
+	if err != nil {
+		return nil, err
+	}
+	records := make([]record, 0, len(all))
+	for _, record := range all {
+		if checkIsLockedSystemRecord(record) != nil {
+			// Some records are not available for updating, hide them.
+			continue
+		}
+		records = append(records, record)
+	}
+	return records, nil
 }
 
-func (api *hetznerProvider) getAllRecords(domain string) ([]record, error) {
-	zone, err := api.getZone(domain)
+// getCachedRecords returns all records of a zone. The records are cached
+// until the next mutating request, repeated calls for the same zone during
+// a run do not hit the API.
+func (api *hetznerProvider) getCachedRecords(ctx context.Context, domain string) ([]record, error) {
+	api.recordsMu.Lock()
+	cached, ok := api.records[domain]
+	gen := api.recordsGen
+	api.recordsMu.Unlock()
+	if ok {
+		return append([]record(nil), cached...), nil
+	}
+
+	records, err := api.fetchAllRecords(ctx, domain)
+	if err != nil {
+		return nil, err
+	}
+	api.recordsMu.Lock()
+	// Do not cache records that may have been fetched before a concurrent change.
+	if gen == api.recordsGen {
+		if api.records == nil {
+			api.records = map[string][]record{}
+		}
+		api.records[domain] = append([]record(nil), records...)
+	}
+	api.recordsMu.Unlock()
+	return records, nil
+}
+
+// invalidateRecords drops the cached records of all zones.
+func (api *hetznerProvider) invalidateRecords() {
+	api.recordsMu.Lock()
+	defer api.recordsMu.Unlock()
+	api.records = nil
+	api.recordsGen++
+}
+
+func (api *hetznerProvider) fetchAllRecords(ctx context.Context, domain string) ([]record, error) {
+	zone, err := api.getZone(ctx, domain)
 	if err != nil {
 		return nil, err
 	}
@@ -135,16 +356,18 @@ func (api *hetznerProvider) getAllRecords(domain string) ([]record, error) {
 	for {
 		response := &getAllRecordsResponse{}
 		url := fmt.Sprintf("/records?zone_id=%s&per_page=100&page=%d", zone.ID, page)
-		if err := api.request(url, "GET", nil, response); err != nil {
+		if err := api.request(ctx, url, "GET", nil, response); err != nil {
 			return nil, fmt.Errorf("failed fetching zone records for %q: %w", domain, err)
 		}
 		for _, record := range response.Records {
 			if record.TTL == nil {
+				// "ttl": null means the record uses the default TTL of the zone.
 				record.TTL = &zone.TTL
+				record.defaultTTL = true
 			}
 
-			if checkIsLockedSystemRecord(record) != nil {
-				// Some records are not available for updating, hide them.
+			if isDNSSECRecord(record) {
+				// Signed zones contain records maintained by HETZNER, hide them.
 				continue
 			}
 
@@ -159,7 +382,9 @@ func (api *hetznerProvider) getAllRecords(domain string) ([]record, error) {
 	return records, nil
 }
 
-func (api *hetznerProvider) getAllZones() error {
+// getAllZones fills the cache of zones, unless it is filled already.
+// The caller must hold zonesMu.
+func (api *hetznerProvider) getAllZones(ctx context.Context) error {
 	if api.zones != nil {
 		return nil
 	}
@@ -168,7 +393,7 @@ func (api *hetznerProvider) getAllZones() error {
 	for {
 		response := &getAllZonesResponse{}
 		url := fmt.Sprintf("/zones?per_page=100&page=%d", page)
-		if err := api.request(url, "GET", nil, response); err != nil {
+		if err := api.request(ctx, url, "GET", nil, response); err != nil {
 			return fmt.Errorf("failed fetching zones: %w", err)
 		}
 		for _, zone := range response.Zones {
@@ -184,8 +409,10 @@ func (api *hetznerProvider) getAllZones() error {
 	return nil
 }
 
-func (api *hetznerProvider) getZone(name string) (*zone, error) {
-	if err := api.getAllZones(); err != nil {
+func (api *hetznerProvider) getZone(ctx context.Context, name string) (*zone, error) {
+	api.zonesMu.Lock()
+	defer api.zonesMu.Unlock()
+	if err := api.getAllZones(ctx); err != nil {
 		return nil, err
 	}
 	zone, ok := api.zones[name]
@@ -195,28 +422,80 @@ func (api *hetznerProvider) getZone(name string) (*zone, error) {
 	return &zone, nil
 }
 
-func (api *hetznerProvider) request(endpoint string, method string, request interface{}, target interface{}) error {
-	for {
+// getCurrentZone fetches a single zone from the API, bypassing the cache.
+// The cache is updated with the result.
+func (api *hetznerProvider) getCurrentZone(ctx context.Context, name string) (*zone, error) {
+	response := &getAllZonesResponse{}
+	url := fmt.Sprintf("/zones?name=%s", url.QueryEscape(name))
+	if err := api.request(ctx, url, "GET", nil, response); err != nil {
+		return nil, fmt.Errorf("failed fetching zone %q: %w", name, err)
+	}
+	for _, zone := range response.Zones {
+		if zone.Name != name {
+			continue
+		}
+		api.zonesMu.Lock()
+		if api.zones != nil {
+			api.zones[name] = zone
+		}
+		api.zonesMu.Unlock()
+		return &zone, nil
+	}
+	return nil, fmt.Errorf("%q is not a zone in this HETZNER account", name)
+}
+
+func (api *hetznerProvider) request(ctx context.Context, endpoint string, method string, request interface{}, target interface{}) error {
+	if method != "GET" {
+		// Even a failed request may have changed records, e.g. a partial bulk update.
+		defer api.invalidateRecords()
+	}
+	retries := api.rateLimitRetries
+	if retries == 0 {
+		retries = defaultRateLimitRetries
+	}
+	for attempt := 1; ; attempt++ {
 		var requestBody io.Reader
-		if request != nil {
+		contentType := ""
+		switch body := request.(type) {
+		case nil:
+		case zoneFile:
+			requestBody = strings.NewReader(string(body))
+			contentType = "text/plain"
+		default:
 			requestBodySerialised, err := json.Marshal(request)
 			if err != nil {
 				return err
 			}
 			requestBody = bytes.NewBuffer(requestBodySerialised)
 		}
-		req, err := http.NewRequest(method, baseURL+endpoint, requestBody)
+		req, err := http.NewRequestWithContext(ctx, method, api.baseURL+endpoint, requestBody)
 		if err != nil {
 			return err
 		}
 		req.Header.Add("Auth-API-Token", api.apiKey)
+		if contentType != "" {
+			req.Header.Set("Content-Type", contentType)
+		}
 
-		api.requestRateLimiter.beforeRequest()
+		if err := api.requestRateLimiter.beforeRequest(ctx); err != nil {
+			return err
+		}
+		start := time.Now()
 		resp, err := http.DefaultClient.Do(req)
 		api.requestRateLimiter.afterRequest()
+		observed := apimetrics.Request{
+			Provider: "HETZNER",
+			Method:   method,
+			Endpoint: metricsEndpoint(endpoint),
+			Duration: time.Since(start),
+			Retry:    attempt > 1,
+		}
 		if err != nil {
+			apimetrics.Observe(observed)
 			return err
 		}
+		observed.Status = resp.StatusCode
+		apimetrics.Observe(observed)
 		cleanupResponseBody := func() {
 			err := resp.Body.Close()
 			if err != nil {
@@ -227,8 +506,12 @@ func (api *hetznerProvider) request(endpoint string, method string, request inte
 		api.requestRateLimiter.handleResponse(*resp)
 		// retry the request when rate-limited
 		if resp.StatusCode == 429 {
-			api.requestRateLimiter.handleRateLimitedRequest()
 			cleanupResponseBody()
+			if attempt > retries {
+				return fmt.Errorf("%s %s is still rate-limited after %d retries: %w", method, endpoint, retries, &apiError{StatusCode: resp.StatusCode})
+			}
+			api.requestRateLimiter.handleRateLimitedRequest()
+			api.requestRateLimiter.pause(rateLimitWait(resp.Header, attempt))
 			continue
 		}
 
@@ -236,16 +519,43 @@ func (api *hetznerProvider) request(endpoint string, method string, request inte
 		if resp.StatusCode != 200 {
 			data, _ := ioutil.ReadAll(resp.Body)
 			fmt.Println(string(data))
-			return fmt.Errorf("bad status code from HETZNER: %d not 200", resp.StatusCode)
+			response := &errorResponse{}
+			_ = json.Unmarshal(data, response)
+			return &apiError{
+				StatusCode: resp.StatusCode,
+				Message:    response.Error.Message,
+				Code:       response.Error.Code,
+			}
 		}
 		if target == nil {
 			return nil
 		}
+		if file, ok := target.(*zoneFile); ok {
+			data, err := ioutil.ReadAll(resp.Body)
+			*file = zoneFile(data)
+			return err
+		}
 		decoder := json.NewDecoder(resp.Body)
 		return decoder.Decode(target)
 	}
 }
 
+// metricsEndpoint returns endpoint without the query and with the IDs
+// replaced by {id}, e.g. /zones/{id}/dnssec, so that requests for
+// different records and zones add up.
+func metricsEndpoint(endpoint string) string {
+	if i := strings.IndexByte(endpoint, '?'); i >= 0 {
+		endpoint = endpoint[:i]
+	}
+	parts := strings.Split(endpoint, "/")
+	for i := 1; i < len(parts); i++ {
+		if (parts[i-1] == "records" || parts[i-1] == "zones") && parts[i] != "bulk" {
+			parts[i] = "{id}"
+		}
+	}
+	return strings.Join(parts, "/")
+}
+
 func (api *hetznerProvider) startRateLimited() {
 	// _Now_ is the best reference we can get for the last request.
 	// Head-On-Head invocations of DNSControl benefit from fewer initial
@@ -255,30 +565,87 @@ func (api *hetznerProvider) startRateLimited() {
 	api.requestRateLimiter.setDefaultDelay()
 }
 
-func (api *hetznerProvider) updateRecord(record record) error {
+func (api *hetznerProvider) updateRecord(ctx context.Context, record record) error {
 	if err := checkIsLockedSystemRecord(record); err != nil {
 		return err
 	}
 
 	url := fmt.Sprintf("/records/%s", record.ID)
-	return api.request(url, "PUT", record, nil)
+	return api.request(ctx, url, "PUT", record, nil)
 }
 
+// requestRateLimiter spaces requests by delay. It is safe for concurrent
+// use, concurrent requests are sent one after another.
 type requestRateLimiter struct {
+	mu                        sync.Mutex
 	delay                     time.Duration
 	lastRequest               time.Time
+	notBefore                 time.Time // no request is sent before, after a 429
 	optimizeForRateLimitQuota string
 }
 
 func (requestRateLimiter *requestRateLimiter) afterRequest() {
+	requestRateLimiter.mu.Lock()
+	defer requestRateLimiter.mu.Unlock()
 	requestRateLimiter.lastRequest = time.Now()
 }
 
-func (requestRateLimiter *requestRateLimiter) beforeRequest() {
-	if requestRateLimiter.delay == 0 {
-		return
+// beforeRequest waits for the next slot for a request. It returns early
+// with the error of ctx if ctx is done.
+func (requestRateLimiter *requestRateLimiter) beforeRequest(ctx context.Context) error {
+	requestRateLimiter.mu.Lock()
+	defer requestRateLimiter.mu.Unlock()
+	next := requestRateLimiter.notBefore
+	if requestRateLimiter.delay != 0 {
+		if slot := requestRateLimiter.lastRequest.Add(requestRateLimiter.delay); slot.After(next) {
+			next = slot
+		}
+	}
+	if err := sleep(ctx, time.Until(next)); err != nil {
+		return err
+	}
+	// Reserve this slot, concurrent requests wait for the next one.
+	requestRateLimiter.lastRequest = time.Now()
+	return nil
+}
+
+// sleep pauses for d, or until ctx is done. In the latter case the error
+// of ctx is returned.
+func sleep(ctx context.Context, d time.Duration) error {
+	if d <= 0 {
+		return ctx.Err()
+	}
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
 	}
-	time.Sleep(time.Until(requestRateLimiter.lastRequest.Add(requestRateLimiter.delay)))
+}
+
+// pause delays all requests by d from now on.
+func (requestRateLimiter *requestRateLimiter) pause(d time.Duration) {
+	requestRateLimiter.mu.Lock()
+	defer requestRateLimiter.mu.Unlock()
+	if until := time.Now().Add(d); until.After(requestRateLimiter.notBefore) {
+		requestRateLimiter.notBefore = until
+	}
+}
+
+// rateLimitWait returns how long to wait before retrying a rate-limited
+// request: the Retry-After of the response if there is one, otherwise an
+// exponential backoff with jitter.
+func rateLimitWait(header http.Header, attempt int) time.Duration {
+	if d, err := getRetryAfterDelay(header); err == nil {
+		return d
+	}
+	d := rateLimitBackoffMax
+	if attempt < 32 && rateLimitBackoff<<uint(attempt-1) < rateLimitBackoffMax {
+		d = rateLimitBackoff << uint(attempt-1)
+	}
+	return d/2 + time.Duration(rand.Int63n(int64(d/2)+1))
 }
 
 func (requestRateLimiter *requestRateLimiter) setDefaultDelay() {
@@ -313,18 +680,13 @@ func (requestRateLimiter *requestRateLimiter) handleRateLimitedRequest() {
 }
 
 func (requestRateLimiter *requestRateLimiter) handleResponse(resp http.Response) {
+	requestRateLimiter.mu.Lock()
+	defer requestRateLimiter.mu.Unlock()
 	homogenousDelay, err := getHomogenousDelay(resp.Header, requestRateLimiter.optimizeForRateLimitQuota)
 	if err != nil {
 		requestRateLimiter.setDefaultDelay()
 		return
 	}
-
-	delay := homogenousDelay
-	if resp.StatusCode == 429 {
-		retryAfterDelay, err := getRetryAfterDelay(resp.Header)
-		if err == nil {
-			delay = retryAfterDelay
-		}
-	}
-	requestRateLimiter.delay = delay
+	// A Retry-After of a 429 is handled by pause.
+	requestRateLimiter.delay = homogenousDelay
 }
@@ -0,0 +1,390 @@
+package hetzner
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/StackExchange/dnscontrol/v3/pkg/apimetrics"
+)
+
+// newTestProvider returns a provider that talks to a fake API served by handler.
+func newTestProvider(t *testing.T, handler http.HandlerFunc) *hetznerProvider {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// Avoid the default delay of 1 req/s.
+		w.Header().Set("X-Ratelimit-Limit-Second", "1000")
+		handler(w, r)
+	}))
+	t.Cleanup(server.Close)
+
+	api := &hetznerProvider{
+		apiKey:  "test-token",
+		baseURL: server.URL,
+	}
+	if err := api.requestRateLimiter.setOptimizeForRateLimitQuota("Second"); err != nil {
+		t.Fatal(err)
+	}
+	return api
+}
+
+func TestEnsureDomainExistsAlreadyExists(t *testing.T) {
+	created := false
+	api := newTestProvider(t, func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.Method == "GET" && r.URL.Path == "/zones":
+			if created {
+				w.Write([]byte(`{"zones":[{"id":"zone1","name":"example.com","ttl":3600}]}`))
+			} else {
+				w.Write([]byte(`{"zones":[]}`))
+			}
+		case r.Method == "POST" && r.URL.Path == "/zones":
+			// The zone was created concurrently, e.g. by a timed out request.
+			// Only the status and the code tell, not the message.
+			created = true
+			w.WriteHeader(422)
+			w.Write([]byte(`{"error":{"message":"422 Unprocessable Entity: name is taken","code":422}}`))
+		default:
+			t.Errorf("unexpected request: %s %s", r.Method, r.URL)
+			w.WriteHeader(500)
+		}
+	})
+
+	if err := api.EnsureDomainExists("example.com"); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	z, err := api.getZone(context.Background(), "example.com")
+	if err != nil {
+		t.Fatalf("expected the zone cache to be refreshed, got %v", err)
+	}
+	if z.ID != "zone1" {
+		t.Errorf("expected zone id %q, got %q", "zone1", z.ID)
+	}
+}
+
+func TestGetZonePagination(t *testing.T) {
+	var pages []string
+	api := newTestProvider(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != "GET" || r.URL.Path != "/zones" || r.URL.Query().Get("per_page") != "100" {
+			t.Errorf("unexpected request: %s %s", r.Method, r.URL)
+			w.WriteHeader(500)
+			return
+		}
+		page := r.URL.Query().Get("page")
+		pages = append(pages, page)
+		var zones []string
+		for i := 0; i < 100; i++ {
+			zones = append(zones, fmt.Sprintf(`{"id":"zone%s-%d","name":"zone%s-%d.example","ttl":3600}`, page, i, page, i))
+		}
+		if page == "3" {
+			zones = zones[:1]
+		}
+		fmt.Fprintf(w, `{"zones":[%s],"meta":{"pagination":{"page":%s,"per_page":100,"last_page":3}}}`, strings.Join(zones, ","), page)
+	})
+
+	for _, name := range []string{"zone1-0.example", "zone2-99.example", "zone3-0.example"} {
+		if _, err := api.getZone(context.Background(), name); err != nil {
+			t.Errorf("expected zone %q to be found, got %v", name, err)
+		}
+	}
+	if _, err := api.getZone(context.Background(), "zone3-1.example"); err == nil {
+		t.Error("expected an error for a zone that does not exist")
+	}
+	if strings.Join(pages, ",") != "1,2,3" {
+		t.Errorf("expected pages 1,2,3 to be fetched once, got %v", pages)
+	}
+	if len(api.zones) != 201 {
+		t.Errorf("expected 201 cached zones, got %d", len(api.zones))
+	}
+}
+
+// noCreateZoneRetryDelay disables the delay between createZone attempts
+// for the duration of the test t.
+func noCreateZoneRetryDelay(t *testing.T) {
+	delay := createZoneRetryDelay
+	t.Cleanup(func() { createZoneRetryDelay = delay })
+	createZoneRetryDelay = 0
+}
+
+func TestCreateZoneRetry(t *testing.T) {
+	noCreateZoneRetryDelay(t)
+	attempts := 0
+	api := newTestProvider(t, func(w http.ResponseWriter, r *http.Request) {
+		attempts++
+		if attempts == 1 {
+			w.WriteHeader(503)
+			return
+		}
+		w.Write([]byte(`{"zone":{"id":"zone1","name":"example.com"}}`))
+	})
+
+	if _, err := api.createZone(context.Background(), "example.com"); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if attempts != 2 {
+		t.Errorf("expected 2 attempts, got %d", attempts)
+	}
+}
+
+func TestCreateZoneNotRetryable(t *testing.T) {
+	noCreateZoneRetryDelay(t)
+	attempts := 0
+	api := newTestProvider(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == "GET" && r.URL.Path == "/zones" {
+			// The zone does not exist, the name is invalid.
+			w.Write([]byte(`{"zones":[]}`))
+			return
+		}
+		attempts++
+		w.WriteHeader(422)
+		w.Write([]byte(`{"error":{"message":"422 Unprocessable Entity: zone already exists","code":422}}`))
+	})
+
+	if _, err := api.createZone(context.Background(), "example"); err == nil {
+		t.Fatal("expected an error")
+	}
+	if attempts != 1 {
+		t.Errorf("expected 1 attempt, got %d", attempts)
+	}
+}
+
+func TestAuditLog(t *testing.T) {
+	api := newTestProvider(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == "DELETE" {
+			w.WriteHeader(404)
+			return
+		}
+		w.Write([]byte(`{}`))
+	})
+	api.auditLog = &auditLog{}
+
+	ttl := 300
+	records := []record{{Name: "www", Type: "A", Value: "1.2.3.4", TTL: &ttl, ZoneID: "zone1"}}
+	if _, err := api.bulkCreateRecords(context.Background(), records); err != nil {
+		t.Fatal(err)
+	}
+	if err := api.deleteRecord(context.Background(), record{ID: "rec1", Name: "old", Type: "A", Value: "1.1.1.1", TTL: &ttl}); err == nil {
+		t.Fatal("expected an error")
+	}
+
+	entries := api.AuditLog()
+	if len(entries) != 2 {
+		t.Fatalf("expected 2 entries, got %d", len(entries))
+	}
+	if e := entries[0]; e.Method != "POST" || e.Path != "/records/bulk" || e.StatusCode != 200 || e.Records[0] != `A www "1.2.3.4" ttl=300` {
+		t.Errorf("unexpected entry: %+v", e)
+	}
+	if e := entries[1]; e.Method != "DELETE" || e.Path != "/records/rec1" || e.StatusCode != 404 || e.Error == "" {
+		t.Errorf("unexpected entry: %+v", e)
+	}
+}
+
+func TestCreateZoneVerification(t *testing.T) {
+	api := newTestProvider(t, func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"zone":{"id":"zone1","name":"example.com","txt_verification":{"name":"_hetzner","token":"abc123"}}}`))
+	})
+
+	created, err := api.createZone(context.Background(), "example.com")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if v := created.verification(); v != `_hetzner TXT "abc123"` {
+		t.Errorf("unexpected verification: %q", v)
+	}
+}
+
+func TestPlanZoneCreation(t *testing.T) {
+	api := newTestProvider(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != "GET" || r.URL.Path != "/zones" {
+			t.Errorf("unexpected request: %s %s", r.Method, r.URL)
+			w.WriteHeader(500)
+			return
+		}
+		w.Write([]byte(`{"zones":[{"id":"zone1","name":"example.com","ttl":3600}]}`))
+	})
+
+	toCreate, err := api.PlanZoneCreation([]string{"example.com", "example.net", "example.org"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(toCreate) != 2 || toCreate[0] != "example.net" || toCreate[1] != "example.org" {
+		t.Errorf("expected [example.net example.org], got %v", toCreate)
+	}
+}
+
+func TestRateLimitRetries(t *testing.T) {
+	defer func(base, max time.Duration) { rateLimitBackoff, rateLimitBackoffMax = base, max }(rateLimitBackoff, rateLimitBackoffMax)
+	rateLimitBackoff, rateLimitBackoffMax = time.Millisecond, 4*time.Millisecond
+
+	limited := 2
+	requests := 0
+	api := newTestProvider(t, func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		if requests <= limited {
+			if requests == 1 {
+				w.Header().Set("Retry-After", "0")
+			}
+			w.WriteHeader(http.StatusTooManyRequests)
+			return
+		}
+		w.Write([]byte(`{"zones":[{"id":"zone1","name":"example.com","ttl":3600}]}`))
+	})
+	api.rateLimitRetries = 3
+
+	if _, err := api.ListZones(); err != nil {
+		t.Fatal(err)
+	}
+	if requests != 3 {
+		t.Errorf("expected 3 requests, got %d", requests)
+	}
+
+	api.zones = nil
+	requests, limited = 0, 100
+	_, err := api.ListZones()
+	if err == nil || !strings.Contains(err.Error(), "GET /zones?per_page=100&page=1 is still rate-limited after 3 retries") {
+		t.Errorf("expected an error naming the endpoint, got %v", err)
+	}
+	if requests != 4 {
+		t.Errorf("expected 4 requests, got %d", requests)
+	}
+}
+
+func TestCancel(t *testing.T) {
+	release := make(chan struct{})
+	defer close(release)
+	api := newTestProvider(t, func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/zones":
+			w.Write([]byte(`{"zones":[{"id":"zone1","name":"example.com","ttl":3600}]}`))
+		case "/records":
+			// Hang until the client gives up.
+			select {
+			case <-r.Context().Done():
+			case <-release:
+			}
+		case "/zones/zone1/dnssec":
+			// Rate-limited for a minute.
+			w.Header().Set("Retry-After", "60")
+			w.WriteHeader(http.StatusTooManyRequests)
+		}
+	})
+
+	// Cancelled while the request is in flight.
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	if _, err := api.GetZoneRecordsContext(ctx, "example.com"); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("expected the deadline to abort fetching the records, got %v", err)
+	}
+
+	// Cancelled while waiting for the rate limit.
+	ctx, cancel = context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	start := time.Now()
+	if _, err := api.getDNSSEC(ctx, "example.com"); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("expected the deadline to abort waiting for the rate limit, got %v", err)
+	}
+	if d := time.Since(start); d > 10*time.Second {
+		t.Errorf("expected to stop waiting when the context is done, waited %s", d)
+	}
+}
+
+func TestMetrics(t *testing.T) {
+	for _, tst := range []struct{ in, want string }{
+		{"/records?zone_id=abc&per_page=100&page=2", "/records"},
+		{"/records/abc123", "/records/{id}"},
+		{"/records/bulk", "/records/bulk"},
+		{"/zones/zone1/dnssec/enable", "/zones/{id}/dnssec/enable"},
+		{"/zones?name=example.com", "/zones"},
+	} {
+		if got := metricsEndpoint(tst.in); got != tst.want {
+			t.Errorf("%s: expected %s, got %s", tst.in, tst.want, got)
+		}
+	}
+
+	defer func(base, max time.Duration) { rateLimitBackoff, rateLimitBackoffMax = base, max }(rateLimitBackoff, rateLimitBackoffMax)
+	rateLimitBackoff, rateLimitBackoffMax = time.Millisecond, time.Millisecond
+	limited := true
+	api := newTestProvider(t, func(w http.ResponseWriter, r *http.Request) {
+		if limited {
+			limited = false
+			w.WriteHeader(http.StatusTooManyRequests)
+			return
+		}
+		w.Write([]byte(`{"zones":[{"id":"zone1","name":"example.com","ttl":3600}]}`))
+	})
+	metrics := &apimetrics.Collector{}
+	apimetrics.SetHook(metrics)
+	defer apimetrics.SetHook(nil)
+	if _, err := api.ListZones(); err != nil {
+		t.Fatal(err)
+	}
+	want := []string{"HETZNER: 2 calls, p95 0s, 1 retries"}
+	if got := metrics.Summary(); len(got) != 1 || !strings.HasPrefix(got[0], "HETZNER: 2 calls, p95 ") || !strings.HasSuffix(got[0], ", 1 retries") {
+		t.Errorf("expected a summary like %q, got %q", want, got)
+	}
+}
+
+func TestRateLimitWait(t *testing.T) {
+	defer func(base, max time.Duration) { rateLimitBackoff, rateLimitBackoffMax = base, max }(rateLimitBackoff, rateLimitBackoffMax)
+	rateLimitBackoff, rateLimitBackoffMax = time.Second, 8*time.Second
+
+	header := http.Header{}
+	for attempt, max := range map[int]time.Duration{1: time.Second, 2: 2 * time.Second, 4: 8 * time.Second, 40: 8 * time.Second} {
+		if d := rateLimitWait(header, attempt); d < max/2 || d > max {
+			t.Errorf("attempt %d: wait %s not in [%s, %s]", attempt, d, max/2, max)
+		}
+	}
+	header.Set("Retry-After", "30")
+	if d := rateLimitWait(header, 1); d != 30*time.Second {
+		t.Errorf("expected the Retry-After to be honored, got %s", d)
+	}
+}
+
+func TestBulkBatches(t *testing.T) {
+	var sizes []int
+	api := newTestProvider(t, func(w http.ResponseWriter, r *http.Request) {
+		request := &bulkCreateRecordsRequest{}
+		if err := json.NewDecoder(r.Body).Decode(request); err != nil {
+			t.Fatal(err)
+		}
+		sizes = append(sizes, len(request.Records))
+		if len(sizes) == 2 {
+			w.WriteHeader(422)
+			w.Write([]byte(`{"error":{"message":"422 Unprocessable Entity: invalid value","code":422}}`))
+			return
+		}
+		body, _ := json.Marshal(bulkCreateRecordsResponse{Records: request.Records})
+		w.Write(body)
+	})
+
+	ttl := 300
+	var records []record
+	for i := 0; i < 250; i++ {
+		records = append(records, record{Name: fmt.Sprintf("host%d", i), Type: "A", Value: "1.2.3.4", TTL: &ttl, ZoneID: "zone1"})
+	}
+	created, err := api.bulkCreateRecords(context.Background(), records)
+	if fmt.Sprint(sizes) != "[100 100 50]" {
+		t.Errorf("expected batches of [100 100 50], got %v", sizes)
+	}
+	if len(created) != 150 {
+		t.Errorf("expected the records of the other batches to be created, got %d", len(created))
+	}
+	if err == nil {
+		t.Fatal("expected an error")
+	}
+	if msg := err.Error(); !strings.Contains(msg, "batch 2 of 3") || !strings.Contains(msg, "host100") || strings.Contains(msg, "host99 ") {
+		t.Errorf("expected the error to list the records of batch 2, got %q", msg)
+	}
+
+	if got := describeBatches("Batch creation of records:", 3); got != "Batch creation of records (in 3 requests of up to 100 records):" {
+		t.Errorf("unexpected description %q", got)
+	}
+	if got := describeBatches("Batch creation of records:", 1); got != "Batch creation of records:" {
+		t.Errorf("unexpected description %q", got)
+	}
+}
@@ -0,0 +1,96 @@
+package hetzner
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"sync"
+	"time"
+)
+
+// AuditLogEntry describes a single mutating request sent to the HETZNER API.
+type AuditLogEntry struct {
+	Time       time.Time `json:"time"`
+	Method     string    `json:"method"`
+	Path       string    `json:"path"`
+	Records    []string  `json:"records"`
+	StatusCode int       `json:"status_code"`
+	Error      string    `json:"error,omitempty"`
+}
+
+// auditLog collects AuditLogEntry items and optionally appends them
+// to a file as JSON lines.
+type auditLog struct {
+	mu      sync.Mutex
+	path    string
+	entries []AuditLogEntry
+}
+
+func (l *auditLog) add(entry AuditLogEntry) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.entries = append(l.entries, entry)
+	if l.path == "" {
+		return nil
+	}
+	f, err := os.OpenFile(l.path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0600)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	return json.NewEncoder(f).Encode(entry)
+}
+
+// AuditLog returns the mutating requests executed so far. It is empty
+// unless the provider was configured with "audit_log".
+func (api *hetznerProvider) AuditLog() []AuditLogEntry {
+	if api.auditLog == nil {
+		return nil
+	}
+	api.auditLog.mu.Lock()
+	defer api.auditLog.mu.Unlock()
+	return append([]AuditLogEntry(nil), api.auditLog.entries...)
+}
+
+// auditedRequest performs a request and records it in the audit log, if enabled.
+func (api *hetznerProvider) auditedRequest(ctx context.Context, endpoint string, method string, request interface{}, target interface{}, records ...record) error {
+	err := api.request(ctx, endpoint, method, request, target)
+	if api.auditLog == nil {
+		return err
+	}
+
+	entry := AuditLogEntry{
+		Time:       time.Now().UTC(),
+		Method:     method,
+		Path:       endpoint,
+		StatusCode: 200,
+	}
+	for _, r := range records {
+		entry.Records = append(entry.Records, summarizeRecord(r))
+	}
+	if err != nil {
+		entry.Error = err.Error()
+		entry.StatusCode = 0
+		var aErr *apiError
+		if errors.As(err, &aErr) {
+			entry.StatusCode = aErr.StatusCode
+		}
+	}
+	if logErr := api.auditLog.add(entry); logErr != nil {
+		fmt.Printf("failed writing HETZNER audit log: %v\n", logErr)
+	}
+	return err
+}
+
+func summarizeRecord(r record) string {
+	s := fmt.Sprintf("%s %s %q", r.Type, r.Name, r.Value)
+	if r.TTL != nil {
+		s += fmt.Sprintf(" ttl=%d", *r.TTL)
+	}
+	if r.ID != "" {
+		s += fmt.Sprintf(" id=%s", r.ID)
+	}
+	return s
+}
@@ -1,11 +1,142 @@
 package hetzner
 
 import (
+	"encoding/hex"
+	"fmt"
+	"regexp"
+	"strings"
+
 	"github.com/StackExchange/dnscontrol/v3/models"
 )
 
 // AuditRecords returns an error if any records are not
 // supportable by this provider.
+// HETZNER rejects invalid values with a generic "422 Unprocessable Entity",
+// hence the values are checked before sending them.
 func AuditRecords(records []*models.RecordConfig) error {
+	for _, rc := range records {
+		var err error
+		switch rc.Type {
+		case "CAA":
+			err = auditCAA(rc)
+		case "DS":
+			err = auditDS(rc)
+		case "PTR":
+			err = auditPTR(rc)
+		case "SRV":
+			err = auditSRV(rc)
+		case "TLSA":
+			err = auditTLSA(rc)
+		}
+		if err != nil {
+			return fmt.Errorf("%s %s: %w", rc.Type, rc.GetLabelFQDN(), err)
+		}
+	}
+	return nil
+}
+
+var hostnameRegexp = regexp.MustCompile(`^([a-zA-Z0-9_]([a-zA-Z0-9_-]{0,61}[a-zA-Z0-9_])?\.)+$`)
+
+// maxValueLength is the longest single character-string in a record value.
+const maxValueLength = 255
+
+func auditCAA(rc *models.RecordConfig) error {
+	if rc.CaaFlag != 0 && rc.CaaFlag != 128 {
+		return fmt.Errorf("flag must be 0 or 128, got %d", rc.CaaFlag)
+	}
+	value := rc.GetTargetField()
+	switch rc.CaaTag {
+	case "issue", "issuewild":
+	case "iodef":
+		if !strings.HasPrefix(value, "mailto:") && !strings.HasPrefix(value, "https://") && !strings.HasPrefix(value, "http://") {
+			return fmt.Errorf("iodef value must be a mailto: or http(s):// URL, got %q", value)
+		}
+	default:
+		return fmt.Errorf("tag must be issue, issuewild or iodef, got %q", rc.CaaTag)
+	}
+	if len(value) > maxValueLength {
+		return fmt.Errorf("value must be at most %d characters, got %d", maxValueLength, len(value))
+	}
+	return nil
+}
+
+// dsDigestLengths maps the DS digest types to the length of their hex encoded digest.
+var dsDigestLengths = map[uint8]int{
+	1: 40, // SHA-1
+	2: 64, // SHA-256
+	4: 96, // SHA-384
+}
+
+func auditDS(rc *models.RecordConfig) error {
+	if rc.GetLabel() == "@" {
+		// HETZNER generates the DS records of a signed zone, see AUTODNSSEC_ON.
+		return fmt.Errorf("DS records at the apex belong into the parent zone, add them at the registrar")
+	}
+	switch rc.DsAlgorithm {
+	case 5, 7, 8, 10, 13, 14, 15, 16:
+	default:
+		return fmt.Errorf("algorithm %d is not supported", rc.DsAlgorithm)
+	}
+	length, ok := dsDigestLengths[rc.DsDigestType]
+	if !ok {
+		return fmt.Errorf("digest type must be 1, 2 or 4, got %d", rc.DsDigestType)
+	}
+	return auditHex("digest", rc.DsDigest, length)
+}
+
+func auditPTR(rc *models.RecordConfig) error {
+	target := rc.GetTargetField()
+	if len(target) > 254 || !hostnameRegexp.MatchString(target) {
+		return fmt.Errorf("target must be a fully qualified hostname, got %q", target)
+	}
+	return nil
+}
+
+func auditSRV(rc *models.RecordConfig) error {
+	target := rc.GetTargetField()
+	if target == "." {
+		// "Service not available", the other fields do not matter.
+		return nil
+	}
+	if rc.SrvPort == 0 {
+		return fmt.Errorf("port must be between 1 and 65535, got 0")
+	}
+	if len(target) > 254 || !hostnameRegexp.MatchString(target) {
+		return fmt.Errorf("target must be a fully qualified hostname or \".\", got %q", target)
+	}
+	return nil
+}
+
+// tlsaDigestLengths maps the TLSA matching types to the length of their hex encoded data.
+var tlsaDigestLengths = map[uint8]int{
+	1: 64,  // SHA-256
+	2: 128, // SHA-512
+}
+
+func auditTLSA(rc *models.RecordConfig) error {
+	if rc.TlsaUsage > 3 {
+		return fmt.Errorf("usage must be between 0 and 3, got %d", rc.TlsaUsage)
+	}
+	if rc.TlsaSelector > 1 {
+		return fmt.Errorf("selector must be 0 or 1, got %d", rc.TlsaSelector)
+	}
+	if rc.TlsaMatchingType > 2 {
+		return fmt.Errorf("matching type must be between 0 and 2, got %d", rc.TlsaMatchingType)
+	}
+	// Matching type 0 is the full certificate or key, any length.
+	return auditHex("certificate data", rc.GetTargetField(), tlsaDigestLengths[rc.TlsaMatchingType])
+}
+
+// auditHex checks that value is hex encoded and, unless length is 0, has length characters.
+func auditHex(field, value string, length int) error {
+	if value == "" {
+		return fmt.Errorf("%s must not be empty", field)
+	}
+	if _, err := hex.DecodeString(value); err != nil {
+		return fmt.Errorf("%s must be hex encoded: %q", field, value)
+	}
+	if length != 0 && len(value) != length {
+		return fmt.Errorf("%s must be %d hex characters, got %d", field, length, len(value))
+	}
 	return nil
 }
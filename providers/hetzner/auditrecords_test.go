@@ -0,0 +1,122 @@
+package hetzner
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/StackExchange/dnscontrol/v3/models"
+)
+
+func TestAuditRecords(t *testing.T) {
+	sha256 := strings.Repeat("ab", 32)
+	tests := []struct {
+		name    string
+		rc      func() *models.RecordConfig
+		wantErr string
+	}{
+		{"SRV ok", srv(10, 20, 5060, "sip.example.com."), ""},
+		{"SRV max values", srv(65535, 65535, 65535, "sip.example.com."), ""},
+		{"SRV null target", srv(0, 0, 0, "."), ""},
+		{"SRV port 0", srv(10, 20, 0, "sip.example.com."), "port must be between 1 and 65535"},
+		{"SRV bad target", srv(10, 20, 5060, "sip example.com."), "target must be a fully qualified hostname"},
+		{"SRV relative target", srv(10, 20, 5060, "sip"), "target must be a fully qualified hostname"},
+		{"SRV label too long", srv(10, 20, 5060, strings.Repeat("a", 64)+".example.com."), "target must be a fully qualified hostname"},
+
+		{"TLSA ok", tlsa(3, 1, 1, sha256), ""},
+		{"TLSA full data", tlsa(3, 0, 0, "3082"), ""},
+		{"TLSA usage", tlsa(4, 1, 1, sha256), "usage must be between 0 and 3"},
+		{"TLSA selector", tlsa(3, 2, 1, sha256), "selector must be 0 or 1"},
+		{"TLSA matching type", tlsa(3, 1, 3, sha256), "matching type must be between 0 and 2"},
+		{"TLSA short digest", tlsa(3, 1, 1, sha256[2:]), "must be 64 hex characters, got 62"},
+		{"TLSA not hex", tlsa(3, 1, 2, strings.Repeat("zz", 64)), "must be hex encoded"},
+
+		{"DS ok", ds(13, 2, sha256), ""},
+		{"DS SHA-1", ds(8, 1, strings.Repeat("ab", 20)), ""},
+		{"DS algorithm", ds(3, 2, sha256), "algorithm 3 is not supported"},
+		{"DS digest type", ds(13, 3, sha256), "digest type must be 1, 2 or 4"},
+		{"DS digest length", ds(13, 4, sha256), "must be 96 hex characters, got 64"},
+		{"DS at the apex", func() *models.RecordConfig {
+			rc := ds(13, 2, sha256)()
+			rc.SetLabel("@", "example.com")
+			return rc
+		}, "belong into the parent zone"},
+
+		{"PTR ok", ptr("host.example.com."), ""},
+		{"PTR relative target", ptr("host"), "target must be a fully qualified hostname"},
+		{"PTR bad target", ptr("host example.com."), "target must be a fully qualified hostname"},
+
+		{"CAA ok", caa(0, "issue", "letsencrypt.org"), ""},
+		{"CAA critical", caa(128, "issuewild", ";"), ""},
+		{"CAA iodef", caa(0, "iodef", "mailto:security@example.com"), ""},
+		{"CAA flag", caa(1, "issue", "letsencrypt.org"), "flag must be 0 or 128"},
+		{"CAA tag", caa(0, "issuemail", "letsencrypt.org"), "tag must be issue, issuewild or iodef"},
+		{"CAA iodef not a URL", caa(0, "iodef", "security@example.com"), "iodef value must be a mailto: or http(s):// URL"},
+		{"CAA value length", caa(0, "issue", strings.Repeat("a", 256)), "value must be at most 255 characters, got 256"},
+
+		{"comment ignored", func() *models.RecordConfig {
+			rc := ptr("host.example.com.")()
+			rc.Metadata = map[string]string{"comment": "owned by team-a"}
+			return rc
+		}, ""},
+	}
+	for _, tst := range tests {
+		t.Run(tst.name, func(t *testing.T) {
+			err := AuditRecords([]*models.RecordConfig{tst.rc()})
+			if tst.wantErr == "" {
+				if err != nil {
+					t.Errorf("expected no error, got %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tst.wantErr) {
+				t.Errorf("expected error containing %q, got %v", tst.wantErr, err)
+			}
+		})
+	}
+}
+
+func newAuditRC(rtype string) *models.RecordConfig {
+	rc := &models.RecordConfig{Type: rtype}
+	rc.SetLabel("test", "example.com")
+	return rc
+}
+
+func srv(priority, weight, port uint16, target string) func() *models.RecordConfig {
+	return func() *models.RecordConfig {
+		rc := newAuditRC("SRV")
+		rc.SetTargetSRV(priority, weight, port, target)
+		return rc
+	}
+}
+
+func tlsa(usage, selector, matchingType uint8, data string) func() *models.RecordConfig {
+	return func() *models.RecordConfig {
+		rc := newAuditRC("TLSA")
+		rc.SetTargetTLSA(usage, selector, matchingType, data)
+		return rc
+	}
+}
+
+func ds(algorithm, digestType uint8, digest string) func() *models.RecordConfig {
+	return func() *models.RecordConfig {
+		rc := newAuditRC("DS")
+		rc.SetTargetDS(12345, algorithm, digestType, digest)
+		return rc
+	}
+}
+
+func caa(flag uint8, tag, value string) func() *models.RecordConfig {
+	return func() *models.RecordConfig {
+		rc := newAuditRC("CAA")
+		rc.SetTargetCAA(flag, tag, value)
+		return rc
+	}
+}
+
+func ptr(target string) func() *models.RecordConfig {
+	return func() *models.RecordConfig {
+		rc := newAuditRC("PTR")
+		rc.SetTarget(target)
+		return rc
+	}
+}
@@ -0,0 +1,100 @@
+package hetzner
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/StackExchange/dnscontrol/v3/models"
+)
+
+func (api *hetznerProvider) getDNSSEC(ctx context.Context, domain string) (*dnssecStatus, error) {
+	zone, err := api.getZone(ctx, domain)
+	if err != nil {
+		return nil, err
+	}
+	response := &dnssecResponse{}
+	if err := api.request(ctx, "/zones/"+zone.ID+"/dnssec", "GET", nil, response); err != nil {
+		return nil, fmt.Errorf("failed fetching DNSSEC state of %q: %w", domain, err)
+	}
+	return &response.DNSSEC, nil
+}
+
+// setDNSSEC enables or disables DNSSEC for the zone.
+func (api *hetznerProvider) setDNSSEC(ctx context.Context, domain string, enable bool) error {
+	zone, err := api.getCurrentZone(ctx, domain)
+	if err != nil {
+		return err
+	}
+	return api.auditedRequest(ctx, dnssecEndpoint(zone, enable), "POST", nil, nil)
+}
+
+func dnssecEndpoint(zone *zone, enable bool) string {
+	if enable {
+		return "/zones/" + zone.ID + "/dnssec/enable"
+	}
+	return "/zones/" + zone.ID + "/dnssec/disable"
+}
+
+// getDNSSECCorrections returns the correction that updates the DNSSEC
+// state of the zone, if it differs from dc.AutoDNSSEC, and its API call.
+func (api *hetznerProvider) getDNSSECCorrections(ctx context.Context, dc *models.DomainConfig) ([]*models.Correction, []apiCall, error) {
+	if dc.AutoDNSSEC == "" {
+		return nil, nil, nil
+	}
+	status, err := api.getDNSSEC(ctx, dc.Name)
+	if err != nil {
+		return nil, nil, err
+	}
+	enable := dc.AutoDNSSEC == "on"
+	if status.Enabled == enable {
+		return nil, nil, nil
+	}
+	zone, err := api.getZone(ctx, dc.Name)
+	if err != nil {
+		return nil, nil, err
+	}
+	call := apiCall{method: "POST", endpoint: dnssecEndpoint(zone, enable)}
+	msg := "Disable DNSSEC"
+	if enable {
+		msg = "Enable DNSSEC (add the DS records at the registrar once they are generated)"
+	}
+	domain := dc.Name
+	return []*models.Correction{{
+		Msg: msg + api.describePayload(call),
+		F:   func() error { return api.setDNSSEC(ctx, domain, enable) },
+	}}, []apiCall{call}, nil
+}
+
+// dsRecords returns the DS records of a signed zone, for showing them with
+// the records of the zone. They belong into the parent zone.
+// The zone is taken as unsigned if the DNSSEC state is not available to
+// the token, so that zones without AUTODNSSEC do not depend on it.
+func (api *hetznerProvider) dsRecords(ctx context.Context, domain string) (models.Records, error) {
+	status, err := api.getDNSSEC(ctx, domain)
+	var aerr *apiError
+	if errors.As(err, &aerr) && (aerr.StatusCode == http.StatusNotFound || aerr.StatusCode == http.StatusForbidden) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if !status.Enabled {
+		return nil, nil
+	}
+	zone, err := api.getZone(ctx, domain)
+	if err != nil {
+		return nil, err
+	}
+	var records models.Records
+	for _, ds := range status.DSRecords {
+		rc := &models.RecordConfig{Type: "DS", TTL: uint32(zone.TTL)}
+		rc.SetLabel("@", domain)
+		if err := rc.SetTargetDS(ds.KeyTag, ds.Algorithm, ds.DigestType, ds.Digest); err != nil {
+			return nil, err
+		}
+		records = append(records, rc)
+	}
+	return records, nil
+}
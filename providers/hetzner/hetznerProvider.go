@@ -1,9 +1,12 @@
 package hetzner
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
+	"strconv"
 	"strings"
+	"sync"
 
 	"github.com/StackExchange/dnscontrol/v3/models"
 	"github.com/StackExchange/dnscontrol/v3/pkg/diff"
@@ -15,11 +18,21 @@ var features = providers.DocumentationNotes{
 	providers.DocCreateDomains:       providers.Can(),
 	providers.DocDualHost:            providers.Can(),
 	providers.DocOfficiallySupported: providers.Cannot(),
+	providers.CanAutoDNSSEC:          providers.Can(),
 	providers.CanGetZones:            providers.Can(),
+	providers.CanReplaceZone:         providers.Can("Not atomic, uses the bulk endpoints"),
+	providers.CanRunConcurrently:     providers.Can(),
 	providers.CanUseAlias:            providers.Cannot(),
+	providers.CanUseAPL:              providers.Cannot(),
 	providers.CanUseCAA:              providers.Can(),
+	providers.CanUseCSYNC:            providers.Cannot(),
 	providers.CanUseDS:               providers.Cannot(),
-	providers.CanUsePTR:              providers.Cannot(),
+	providers.CanUseDSForChildren:    providers.Can(),
+	providers.CanUseHINFO:            providers.Can(),
+	providers.CanUseLOC:              providers.Can(),
+	providers.CanUseNAPTR:            providers.Can(),
+	providers.CanUsePTR:              providers.Can(),
+	providers.CanUseSOA:              providers.Can("The serial is maintained by HETZNER"),
 	providers.CanUseSRV:              providers.Can(),
 	providers.CanUseSSHFP:            providers.Cannot(),
 	providers.CanUseTLSA:             providers.Cannot(),
@@ -31,6 +44,7 @@ func init() {
 		RecordAuditor: AuditRecords,
 	}
 	providers.RegisterDomainServiceProviderType("HETZNER", fns, features)
+	providers.RegisterMaintainer("HETZNER", "@das7pad")
 }
 
 // New creates a new API handle.
@@ -39,7 +53,9 @@ func New(settings map[string]string, _ json.RawMessage) (providers.DNSServicePro
 		return nil, fmt.Errorf("missing HETZNER api_key")
 	}
 
-	api := &hetznerProvider{}
+	api := &hetznerProvider{
+		baseURL: baseURL,
+	}
 
 	api.apiKey = settings["api_key"]
 
@@ -51,6 +67,26 @@ func New(settings map[string]string, _ json.RawMessage) (providers.DNSServicePro
 		api.startRateLimited()
 	}
 
+	if path := settings["audit_log"]; path != "" {
+		api.auditLog = &auditLog{path: path}
+	}
+
+	api.transactional = settings["transactional"] == "true"
+	api.dumpPayloads = settings["dump_payloads"] == "true"
+	api.scriptPath = settings["export_script"]
+	api.ownerID = settings["owner_id"]
+	if strings.ContainsAny(api.ownerID, " \t\"") {
+		return nil, fmt.Errorf("HETZNER owner_id must not contain spaces or quotes")
+	}
+
+	if retries := settings["rate_limit_retries"]; retries != "" {
+		n, err := strconv.Atoi(retries)
+		if err != nil || n < 1 {
+			return nil, fmt.Errorf("HETZNER rate_limit_retries must be a positive number, got %q", retries)
+		}
+		api.rateLimitRetries = n
+	}
+
 	quota := settings["optimize_for_rate_limit_quota"]
 	err := api.requestRateLimiter.setOptimizeForRateLimitQuota(quota)
 	if err != nil {
@@ -62,7 +98,8 @@ func New(settings map[string]string, _ json.RawMessage) (providers.DNSServicePro
 
 // EnsureDomainExists creates the domain if it does not exist.
 func (api *hetznerProvider) EnsureDomainExists(domain string) error {
-	domains, err := api.ListZones()
+	ctx := context.Background()
+	domains, err := api.ListZonesContext(ctx)
 	if err != nil {
 		return err
 	}
@@ -73,11 +110,51 @@ func (api *hetznerProvider) EnsureDomainExists(domain string) error {
 		}
 	}
 
-	return api.createZone(domain)
+	created, err := api.createZone(ctx, domain)
+	if err != nil {
+		return err
+	}
+	if v := created.verification(); v != "" {
+		fmt.Printf("Zone %q created, HETZNER requires a TXT record to verify it: %s\n", domain, v)
+	}
+	return nil
+}
+
+// PlanZoneCreation returns the domains that EnsureDomainExists would
+// create, without creating them.
+func (api *hetznerProvider) PlanZoneCreation(domains []string) ([]string, error) {
+	api.zonesMu.Lock()
+	defer api.zonesMu.Unlock()
+	if err := api.getAllZones(context.Background()); err != nil {
+		return nil, err
+	}
+	var toCreate []string
+	for _, domain := range domains {
+		if _, ok := api.zones[domain]; !ok {
+			toCreate = append(toCreate, domain)
+		}
+	}
+	return toCreate, nil
 }
 
 // GetDomainCorrections returns the corrections for a domain.
 func (api *hetznerProvider) GetDomainCorrections(dc *models.DomainConfig) ([]*models.Correction, error) {
+	return api.GetDomainCorrectionsContext(context.Background(), dc)
+}
+
+// zonePlan is the diff of a zone that GetDomainCorrections applies.
+type zonePlan struct {
+	dc                  *models.DomainConfig // normalized, w/o the records HETZNER manages
+	desiredSOA          *models.RecordConfig // if any, it is applied by zone import
+	existing            models.Records
+	owner               *ownership
+	ownerMsgs           []string // about records of other owners, which are kept
+	create, del, modify diff.Changeset
+}
+
+// plan diffs the records of dc with the existing records of the zone, like
+// GetDomainCorrections does.
+func (api *hetznerProvider) plan(ctx context.Context, dc *models.DomainConfig) (*zonePlan, error) {
 	dc, err := dc.Copy()
 	if err != nil {
 		return nil, err
@@ -87,10 +164,25 @@ func (api *hetznerProvider) GetDomainCorrections(dc *models.DomainConfig) ([]*mo
 	if err != nil {
 		return nil, err
 	}
-	domain := dc.Name
+	p := &zonePlan{dc: dc}
 
 	// Get existing records
-	existingRecords, err := api.GetZoneRecords(domain)
+	existingRecords, err := api.getZoneRecords(ctx, dc.Name)
+	if err != nil {
+		return nil, err
+	}
+	// The DS records at the apex are only shown by GetZoneRecords, they are
+	// not part of the zone. DS records of child zones are managed as usual.
+	// The SOA record is not available for updating like the other records.
+	dc.Filter(func(r *models.RecordConfig) bool {
+		if r.Type == "SOA" {
+			p.desiredSOA = r
+			return false
+		}
+		return !(r.Type == "DS" && r.GetLabel() == "@")
+	})
+
+	existingRecords, err = api.reconcileDefaultNS(ctx, dc, existingRecords)
 	if err != nil {
 		return nil, err
 	}
@@ -98,26 +190,114 @@ func (api *hetznerProvider) GetDomainCorrections(dc *models.DomainConfig) ([]*mo
 	// Normalize
 	models.PostProcessRecords(existingRecords)
 	txtutil.SplitSingleLongTxt(dc.Records) // Autosplit long TXT records
+	p.existing = existingRecords
+
+	if api.ownerID != "" {
+		p.owner = readOwnership(api.ownerID, existingRecords)
+		dc.Records = append(dc.Records, p.owner.manifestRecord(dc.Name, dc.Records))
+	}
 
 	differ := diff.New(dc)
-	_, create, del, modify, err := differ.IncrementalDiff(existingRecords)
+	_, p.create, p.del, p.modify, err = differ.IncrementalDiff(existingRecords)
+	if err != nil {
+		return nil, err
+	}
+	if p.owner != nil {
+		p.del, p.ownerMsgs = diff.FilterOwned(p.del, p.owner.owns)
+	}
+	return p, nil
+}
+
+// ListChangesContext returns the changes of the records of dc that
+// GetDomainCorrections would make. Unlike a diff of GetZoneRecords, they
+// leave out the SOA and DS records, which are not changed like the others,
+// the default NS records and the records of other owners.
+func (api *hetznerProvider) ListChangesContext(ctx context.Context, dc *models.DomainConfig) (create, del, modify diff.Changeset, existingCount int, err error) {
+	p, err := api.plan(ctx, dc)
+	if err != nil {
+		return nil, nil, nil, 0, err
+	}
+	return p.create, p.del, p.modify, len(p.existing), nil
+}
+
+// GetDomainCorrectionsContext is like GetDomainCorrections, cancelling ctx
+// aborts the requests. The corrections run with ctx as well.
+func (api *hetznerProvider) GetDomainCorrectionsContext(ctx context.Context, dc *models.DomainConfig) ([]*models.Correction, error) {
+	p, err := api.plan(ctx, dc)
+	if err != nil {
+		return nil, err
+	}
+	dc, owner, create, del, modify := p.dc, p.owner, p.create, p.del, p.modify
+	domain := dc.Name
+
+	soaCorrections, err := api.getSOACorrections(ctx, domain, p.desiredSOA)
+	if err != nil {
+		return nil, err
+	}
+	dnssecCorrections, dnssecCalls, err := api.getDNSSECCorrections(ctx, dc)
 	if err != nil {
 		return nil, err
 	}
 
 	var corrections []*models.Correction
 
-	zone, err := api.getZone(domain)
+	if owner != nil {
+		corrections = append(corrections, diff.GenerateMessageCorrections(p.ownerMsgs)...)
+	}
+
+	// A zone replacement is computed when it runs, it can not be exported.
+	// It would also remove the records of other owners, and it can not be
+	// rolled back.
+	changes := len(create) + len(del) + len(modify)
+	if api.scriptPath == "" && owner == nil && !api.transactional && providers.ShouldReplaceZone(dc, changes) {
+		desc := []string{fmt.Sprintf("Replace all records of %s (%d changes):", domain, changes)}
+		for _, group := range [][]diff.Correlation{del, create, modify} {
+			for _, m := range group {
+				desc = append(desc, m.String())
+			}
+		}
+		corr := &models.Correction{
+			Msg: strings.Join(desc, "\n\t"),
+			F: func() error {
+				return api.replaceZoneRecords(ctx, dc)
+			},
+		}
+		corrections = append(corrections, corr)
+		corrections = append(corrections, soaCorrections...)
+		return append(corrections, dnssecCorrections...), nil
+	}
+
+	zone, err := api.getZone(ctx, domain)
 	if err != nil {
 		return nil, err
 	}
 
+	var tx *transaction
+	if api.transactional {
+		tx = &transaction{}
+	}
+	refresher := newZoneRefresher(api, domain, zone, p.existing)
+
+	var calls []apiCall
+
 	for _, m := range del {
 		record := m.Existing.Original.(*record)
+		call := apiCall{method: "DELETE", endpoint: "/records/" + record.ID}
+		calls = append(calls, call)
 		corr := &models.Correction{
-			Msg: m.String(),
+			Msg: m.String() + api.describePayload(call),
 			F: func() error {
-				return api.deleteRecord(*record)
+				return tx.apply(func() (func() error, error) {
+					current, err := refresher.record(ctx, *record)
+					if err != nil || current == nil {
+						// A record missing from a re-created zone is deleted already.
+						return nil, err
+					}
+					if err := api.deleteRecord(ctx, *current); err != nil {
+						return nil, err
+					}
+					return api.undoDelete(ctx, *current), nil
+				})
 			},
 		}
 		corrections = append(corrections, corr)
@@ -131,40 +311,281 @@ func (api *hetznerProvider) GetDomainCorrections(dc *models.DomainConfig) ([]*mo
 		createDescription = append(createDescription, m.String())
 	}
 	if len(createRecords) > 0 {
+		var createCalls []apiCall
+		for _, batch := range bulkBatches(createRecords) {
+			createCalls = append(createCalls, apiCall{method: "POST", endpoint: "/records/bulk", request: bulkCreateRecordsRequest{Records: batch}})
+		}
+		calls = append(calls, createCalls...)
+		createDescription[0] = describeBatches(createDescription[0], len(createCalls))
 		corr := &models.Correction{
-			Msg: strings.Join(createDescription, "\n\t"),
+			Msg: strings.Join(createDescription, "\n\t") + api.describePayload(createCalls...),
 			F: func() error {
-				return api.bulkCreateRecords(createRecords)
+				return tx.apply(func() (func() error, error) {
+					records, err := refresher.records(ctx, createRecords)
+					if err != nil {
+						return nil, err
+					}
+					// Undo the batches that succeeded, even if one failed.
+					created, err := api.bulkCreateRecords(ctx, records)
+					return api.undoCreate(ctx, created), err
+				})
 			},
 		}
 		corrections = append(corrections, corr)
 	}
 
-	var modifyRecords []record
+	var modifyRecords, previousRecords []record
 	modifyDescription := []string{"Batch modification of records:"}
 	for _, m := range modify {
-		id := m.Existing.Original.(*record).ID
+		previous := m.Existing.Original.(*record)
 		record := fromRecordConfig(m.Desired, zone)
-		record.ID = id
+		record.ID = previous.ID
 		modifyRecords = append(modifyRecords, *record)
+		previousRecords = append(previousRecords, *previous)
 		modifyDescription = append(modifyDescription, m.String())
 	}
 	if len(modifyRecords) > 0 {
+		var modifyCalls []apiCall
+		for _, batch := range bulkBatches(modifyRecords) {
+			modifyCalls = append(modifyCalls, apiCall{method: "PUT", endpoint: "/records/bulk", request: bulkUpdateRecordsRequest{Records: batch}})
+		}
+		calls = append(calls, modifyCalls...)
+		modifyDescription[0] = describeBatches(modifyDescription[0], len(modifyCalls))
 		corr := &models.Correction{
-			Msg: strings.Join(modifyDescription, "\n\t"),
+			Msg: strings.Join(modifyDescription, "\n\t") + api.describePayload(modifyCalls...),
 			F: func() error {
-				return api.bulkUpdateRecords(modifyRecords)
+				return tx.apply(func() (func() error, error) {
+					records, err := refresher.records(ctx, modifyRecords)
+					if err != nil {
+						return nil, err
+					}
+					previous, err := refresher.records(ctx, previousRecords)
+					if err != nil {
+						return nil, err
+					}
+					// Some batches may have been applied even if one failed.
+					updated, err := api.bulkUpdateRecords(ctx, records)
+					return api.undoModify(ctx, updated, previous), err
+				})
 			},
 		}
 		corrections = append(corrections, corr)
 	}
 
+	// The SOA record is updated last, the imported zone contains the changes above.
+	corrections = append(corrections, soaCorrections...)
+	corrections = append(corrections, dnssecCorrections...)
+	calls = append(calls, dnssecCalls...)
+
+	if api.scriptPath != "" && len(calls) > 0 && providers.IsPlan(ctx) {
+		if err := api.exportScript(domain, calls); err != nil {
+			return nil, fmt.Errorf("failed exporting script: %w", err)
+		}
+	}
+
 	return corrections, nil
 }
 
+// ReplaceZoneRecords replaces all records of a zone with records.
+// Existing records are updated in place where possible, with bulk
+// updates and bulk creations. The remaining existing records are
+// deleted one by one. Records that HETZNER does not allow to change and
+// its default NS records (unless records has NS records at the apex) are
+// left alone.
+func (api *hetznerProvider) ReplaceZoneRecords(domain string, records models.Records) error {
+	dc := &models.DomainConfig{Name: domain, Records: records}
+	return api.replaceZoneRecords(context.Background(), dc)
+}
+
+func (api *hetznerProvider) replaceZoneRecords(ctx context.Context, dc *models.DomainConfig) error {
+	zone, err := api.getCurrentZone(ctx, dc.Name)
+	if err != nil {
+		return err
+	}
+	existingRecords, err := api.getZoneRecords(ctx, dc.Name)
+	if err != nil {
+		return err
+	}
+	// The default NS records are kept like with incremental corrections.
+	existingRecords, err = api.reconcileDefaultNS(ctx, dc, existingRecords)
+	if err != nil {
+		return err
+	}
+	existing := make([]record, len(existingRecords))
+	for i, rc := range existingRecords {
+		existing[i] = *rc.Original.(*record)
+	}
+
+	// Reuse the IDs of existing records with the same name and type.
+	available := map[string][]record{}
+	for _, r := range existing {
+		key := r.Name + "/" + r.Type
+		available[key] = append(available[key], r)
+	}
+	reused := map[string]bool{}
+	var createRecords, modifyRecords []record
+	for _, rc := range dc.Records {
+		r := fromRecordConfig(rc, zone)
+		if checkIsLockedSystemRecord(*r) != nil {
+			continue
+		}
+		key := r.Name + "/" + r.Type
+		if candidates := available[key]; len(candidates) > 0 {
+			r.ID = candidates[0].ID
+			reused[r.ID] = true
+			available[key] = candidates[1:]
+			modifyRecords = append(modifyRecords, *r)
+		} else {
+			createRecords = append(createRecords, *r)
+		}
+	}
+
+	if len(modifyRecords) > 0 {
+		if _, err := api.bulkUpdateRecords(ctx, modifyRecords); err != nil {
+			return err
+		}
+	}
+	if len(createRecords) > 0 {
+		if _, err := api.bulkCreateRecords(ctx, createRecords); err != nil {
+			return err
+		}
+	}
+	for _, r := range existing {
+		if reused[r.ID] {
+			continue
+		}
+		if err := api.deleteRecord(ctx, r); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// describePayload returns the request that a correction sends, for
+// appending to the message of the correction. It is empty unless the
+// provider was configured with "dump_payloads".
+// The zone ID is refreshed when the correction runs and may differ.
+func (api *hetznerProvider) describePayload(calls ...apiCall) string {
+	if !api.dumpPayloads {
+		return ""
+	}
+	var payloads string
+	for _, call := range calls {
+		payload := fmt.Sprintf("\n\tpayload: %s %s", call.method, call.endpoint)
+		if call.request != nil {
+			body, err := json.Marshal(call.request)
+			if err != nil {
+				payload += fmt.Sprintf(" (failed serializing: %s)", err)
+			} else {
+				payload += " " + string(body)
+			}
+		}
+		payloads += payload
+	}
+	return payloads
+}
+
+// describeBatches adds the number of bulk requests to the description of
+// a correction, if there is more than one.
+func describeBatches(desc string, n int) string {
+	if n < 2 {
+		return desc
+	}
+	return fmt.Sprintf("%s (in %d requests of up to %d records)", strings.TrimSuffix(desc, ":"), n, maxBulkRecords) + ":"
+}
+
+// zoneRefresher looks up the current zone once per run, when the first
+// correction is applied. The zone may have been re-created since the
+// corrections were computed, hence the zone ID they were computed with can
+// not be trusted. The records of a re-created zone have new IDs, they are
+// looked up again by name, type and value.
+type zoneRefresher struct {
+	api      *hetznerProvider
+	domain   string
+	zoneID   string
+	existing []record
+
+	mu   sync.Mutex
+	zone *zone
+	ids  map[string]string
+}
+
+func newZoneRefresher(api *hetznerProvider, domain string, z *zone, existing models.Records) *zoneRefresher {
+	r := &zoneRefresher{api: api, domain: domain, zoneID: z.ID}
+	for _, rc := range existing {
+		r.existing = append(r.existing, *rc.Original.(*record))
+	}
+	return r
+}
+
+// refresh looks up the current zone, unless that was done already.
+func (r *zoneRefresher) refresh(ctx context.Context) (*zone, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.zone != nil {
+		return r.zone, nil
+	}
+	current, err := r.api.getCurrentZone(ctx, r.domain)
+	if err != nil {
+		return nil, err
+	}
+	if current.ID != r.zoneID {
+		// The cached records belong to the old zone.
+		r.api.invalidateRecords()
+		records, err := r.api.getAllRecords(ctx, r.domain)
+		if err != nil {
+			return nil, err
+		}
+		key := func(rec record) string { return rec.Name + "/" + rec.Type + "/" + rec.Value }
+		byKey := map[string]string{}
+		for _, rec := range records {
+			byKey[key(rec)] = rec.ID
+		}
+		r.ids = map[string]string{}
+		for _, rec := range r.existing {
+			r.ids[rec.ID] = byKey[key(rec)]
+		}
+	}
+	r.zone = current
+	return current, nil
+}
+
+// record returns a copy of rec with the current zone ID and record ID, or
+// nil if the record does not exist in a re-created zone.
+func (r *zoneRefresher) record(ctx context.Context, rec record) (*record, error) {
+	zone, err := r.refresh(ctx)
+	if err != nil {
+		return nil, err
+	}
+	rec.ZoneID = zone.ID
+	if rec.ID != "" && r.ids != nil {
+		if rec.ID = r.ids[rec.ID]; rec.ID == "" {
+			return nil, nil
+		}
+	}
+	return &rec, nil
+}
+
+// records is like record for several records. It fails if one of them
+// does not exist in a re-created zone.
+func (r *zoneRefresher) records(ctx context.Context, records []record) ([]record, error) {
+	refreshed := make([]record, len(records))
+	for i, rec := range records {
+		current, err := r.record(ctx, rec)
+		if err != nil {
+			return nil, err
+		}
+		if current == nil {
+			return nil, fmt.Errorf("record %s %s of %s does not exist in the re-created zone", rec.Name, rec.Type, r.domain)
+		}
+		refreshed[i] = *current
+	}
+	return refreshed, nil
+}
+
 // GetNameservers returns the nameservers for a domain.
 func (api *hetznerProvider) GetNameservers(domain string) ([]*models.Nameserver, error) {
-	zone, err := api.getZone(domain)
+	zone, err := api.getZone(context.Background(), domain)
 	if err != nil {
 		return nil, err
 	}
@@ -176,8 +597,34 @@ func (api *hetznerProvider) GetNameservers(domain string) ([]*models.Nameserver,
 }
 
 // GetZoneRecords gets the records of a zone and returns them in RecordConfig format.
+// The SOA record and the DS records of a signed zone are included.
 func (api *hetznerProvider) GetZoneRecords(domain string) (models.Records, error) {
-	records, err := api.getAllRecords(domain)
+	return api.GetZoneRecordsContext(context.Background(), domain)
+}
+
+// GetZoneRecordsContext is like GetZoneRecords, cancelling ctx aborts the
+// requests.
+func (api *hetznerProvider) GetZoneRecordsContext(ctx context.Context, domain string) (models.Records, error) {
+	records, err := api.getZoneRecords(ctx, domain)
+	if err != nil {
+		return nil, err
+	}
+	soa, err := api.getSOA(ctx, domain)
+	if err != nil {
+		return nil, err
+	}
+	if soa != nil {
+		records = append(records, soa)
+	}
+	ds, err := api.dsRecords(ctx, domain)
+	if err != nil {
+		return nil, err
+	}
+	return append(records, ds...), nil
+}
+
+func (api *hetznerProvider) getZoneRecords(ctx context.Context, domain string) (models.Records, error) {
+	records, err := api.getAllRecords(ctx, domain)
 	if err != nil {
 		return nil, err
 	}
@@ -188,9 +635,56 @@ func (api *hetznerProvider) GetZoneRecords(domain string) (models.Records, error
 	return existingRecords, nil
 }
 
+// reconcileDefaultNS keeps the NS records that HETZNER creates for its
+// nameservers with a new zone from showing up as changes. They have no TTL
+// of their own. If dc has no NS records at the apex, they are left alone.
+// Otherwise those that are in dc are taken to have the TTL of dc, the
+// others are removed as usual.
+func (api *hetznerProvider) reconcileDefaultNS(ctx context.Context, dc *models.DomainConfig, existing models.Records) (models.Records, error) {
+	zone, err := api.getZone(ctx, dc.Name)
+	if err != nil {
+		return nil, err
+	}
+	isHetznerNS := map[string]bool{}
+	for _, ns := range zone.NameServers {
+		isHetznerNS[strings.ToLower(strings.TrimSuffix(ns, "."))] = true
+	}
+	desiredTTL := map[string]uint32{}
+	for _, r := range dc.Records {
+		if r.Type == "NS" && r.GetLabel() == "@" {
+			desiredTTL[strings.ToLower(strings.TrimSuffix(r.GetTargetField(), "."))] = r.TTL
+		}
+	}
+
+	reconciled := make(models.Records, 0, len(existing))
+	for _, r := range existing {
+		target := strings.ToLower(strings.TrimSuffix(r.GetTargetField(), "."))
+		original, _ := r.Original.(*record)
+		if r.Type != "NS" || r.GetLabel() != "@" || original == nil || !original.defaultTTL || !isHetznerNS[target] {
+			reconciled = append(reconciled, r)
+			continue
+		}
+		if len(desiredTTL) == 0 {
+			continue
+		}
+		if ttl, ok := desiredTTL[target]; ok {
+			r.TTL = ttl
+		}
+		reconciled = append(reconciled, r)
+	}
+	return reconciled, nil
+}
+
 // ListZones lists the zones on this account.
 func (api *hetznerProvider) ListZones() ([]string, error) {
-	if err := api.getAllZones(); err != nil {
+	return api.ListZonesContext(context.Background())
+}
+
+// ListZonesContext is like ListZones, cancelling ctx aborts the requests.
+func (api *hetznerProvider) ListZonesContext(ctx context.Context) ([]string, error) {
+	api.zonesMu.Lock()
+	defer api.zonesMu.Unlock()
+	if err := api.getAllZones(ctx); err != nil {
 		return nil, err
 	}
 	var zones []string
@@ -0,0 +1,1323 @@
+package hetzner
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/StackExchange/dnscontrol/v3/models"
+	"github.com/StackExchange/dnscontrol/v3/providers"
+)
+
+func makeRC(label, rtype, target string, ttl uint32) *models.RecordConfig {
+	rc := &models.RecordConfig{
+		Type:     rtype,
+		TTL:      ttl,
+		Metadata: map[string]string{},
+	}
+	rc.SetLabel(label, "example.com")
+	if err := rc.PopulateFromString(rtype, target, "example.com"); err != nil {
+		panic(err)
+	}
+	return rc
+}
+
+func TestCorrectionsUseCurrentZoneID(t *testing.T) {
+	zoneID := "zone1"
+	var createdZoneIDs []string
+	api := newTestProvider(t, func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.Method == "GET" && r.URL.Path == "/zones":
+			w.Write([]byte(`{"zones":[{"id":"` + zoneID + `","name":"example.com","ttl":3600}]}`))
+		case r.Method == "GET" && r.URL.Path == "/records":
+			w.Write([]byte(`{"records":[]}`))
+		case r.Method == "POST" && r.URL.Path == "/records/bulk":
+			request := &bulkCreateRecordsRequest{}
+			if err := json.NewDecoder(r.Body).Decode(request); err != nil {
+				t.Error(err)
+			}
+			for _, rec := range request.Records {
+				createdZoneIDs = append(createdZoneIDs, rec.ZoneID)
+			}
+			w.Write([]byte(`{}`))
+		default:
+			t.Errorf("unexpected request: %s %s", r.Method, r.URL)
+			w.WriteHeader(500)
+		}
+	})
+
+	dc := &models.DomainConfig{
+		Name:    "example.com",
+		Records: models.Records{makeRC("www", "A", "1.2.3.4", 300)},
+	}
+	corrections, err := api.GetDomainCorrections(dc)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// The zone is re-created between computing and applying the corrections.
+	zoneID = "zone2"
+
+	for _, c := range corrections {
+		if err := c.F(); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if len(createdZoneIDs) != 1 || createdZoneIDs[0] != "zone2" {
+		t.Errorf("expected records to be created in zone2, got %v", createdZoneIDs)
+	}
+}
+
+func TestCorrectionsInRecreatedZone(t *testing.T) {
+	zoneID := "zone1"
+	zoneLookups := 0
+	var mutations []string
+	api := newTestProvider(t, func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.Method == "GET" && r.URL.Path == "/zones":
+			zoneLookups++
+			w.Write([]byte(`{"zones":[{"id":"` + zoneID + `","name":"example.com","ttl":3600}]}`))
+		case r.Method == "GET" && r.URL.Path == "/records":
+			// The records of the re-created zone have new IDs.
+			id := r.URL.Query().Get("zone_id")
+			w.Write([]byte(`{"records":[
+				{"id":"old-` + id + `","name":"old","type":"A","value":"1.1.1.1","ttl":300,"zone_id":"` + id + `"},
+				{"id":"mod-` + id + `","name":"mod","type":"A","value":"2.2.2.2","ttl":300,"zone_id":"` + id + `"}
+			]}`))
+		case r.Method == "DELETE":
+			mutations = append(mutations, "DELETE "+r.URL.Path)
+			w.Write([]byte(`{}`))
+		case r.Method == "POST" && r.URL.Path == "/records/bulk":
+			request := &bulkCreateRecordsRequest{}
+			if err := json.NewDecoder(r.Body).Decode(request); err != nil {
+				t.Error(err)
+			}
+			for _, rec := range request.Records {
+				mutations = append(mutations, "POST "+rec.Name+" "+rec.ZoneID)
+			}
+			w.Write([]byte(`{}`))
+		case r.Method == "PUT" && r.URL.Path == "/records/bulk":
+			request := &bulkUpdateRecordsRequest{}
+			if err := json.NewDecoder(r.Body).Decode(request); err != nil {
+				t.Error(err)
+			}
+			for _, rec := range request.Records {
+				mutations = append(mutations, "PUT "+rec.ID+" "+rec.ZoneID)
+			}
+			w.Write([]byte(`{}`))
+		default:
+			t.Errorf("unexpected request: %s %s", r.Method, r.URL)
+			w.WriteHeader(500)
+		}
+	})
+
+	dc := &models.DomainConfig{
+		Name: "example.com",
+		Records: models.Records{
+			makeRC("www", "A", "1.2.3.4", 300),
+			makeRC("mod", "A", "3.3.3.3", 300),
+		},
+	}
+	corrections, err := api.GetDomainCorrections(dc)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(corrections) != 3 {
+		t.Fatalf("expected 3 corrections, got %d", len(corrections))
+	}
+
+	// The zone is re-created between computing and applying the corrections.
+	zoneID = "zone2"
+	zoneLookups = 0
+
+	for _, c := range corrections {
+		if err := c.F(); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if zoneLookups != 1 {
+		t.Errorf("expected the zone to be looked up once, got %d", zoneLookups)
+	}
+	expected := []string{
+		"DELETE /records/old-zone2",
+		"POST www zone2",
+		"PUT mod-zone2 zone2",
+	}
+	if strings.Join(mutations, "\n") != strings.Join(expected, "\n") {
+		t.Errorf("expected requests\n%v\ngot\n%v", expected, mutations)
+	}
+}
+
+func TestTransactionalRollback(t *testing.T) {
+	var requests []string
+	api := newTestProvider(t, func(w http.ResponseWriter, r *http.Request) {
+		requests = append(requests, r.Method+" "+r.URL.Path)
+		switch {
+		case r.Method == "GET" && r.URL.Path == "/zones":
+			w.Write([]byte(`{"zones":[{"id":"zone1","name":"example.com","ttl":3600}]}`))
+		case r.Method == "GET" && r.URL.Path == "/records":
+			w.Write([]byte(`{"records":[
+				{"id":"old","name":"old","type":"A","value":"1.1.1.1","ttl":300,"zone_id":"zone1"},
+				{"id":"mod","name":"mod","type":"A","value":"2.2.2.2","ttl":300,"zone_id":"zone1"}
+			]}`))
+		case r.Method == "DELETE":
+			w.Write([]byte(`{}`))
+		case r.Method == "POST" && r.URL.Path == "/records/bulk":
+			w.Write([]byte(`{"records":[{"id":"new","name":"www","type":"A","value":"1.2.3.4","ttl":300,"zone_id":"zone1"}]}`))
+		case r.Method == "PUT" && r.URL.Path == "/records/bulk":
+			w.WriteHeader(500)
+		default:
+			t.Errorf("unexpected request: %s %s", r.Method, r.URL)
+			w.WriteHeader(500)
+		}
+	})
+	api.transactional = true
+
+	dc := &models.DomainConfig{
+		Name: "example.com",
+		Records: models.Records{
+			makeRC("www", "A", "1.2.3.4", 300),
+			makeRC("mod", "A", "3.3.3.3", 300),
+		},
+	}
+	corrections, err := api.GetDomainCorrections(dc)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(corrections) != 3 {
+		t.Fatalf("expected 3 corrections, got %d", len(corrections))
+	}
+	for i, c := range corrections {
+		err := c.F()
+		if i < 2 && err != nil {
+			t.Fatalf("correction %d: unexpected error %v", i, err)
+		}
+		if i == 2 && err == nil {
+			t.Fatal("expected the modification to fail")
+		}
+	}
+
+	var mutations []string
+	for _, r := range requests {
+		if r[:3] != "GET" {
+			mutations = append(mutations, r)
+		}
+	}
+	expected := []string{
+		"DELETE /records/old",
+		"POST /records/bulk",
+		"PUT /records/bulk",
+		// rollback, in reverse order
+		"DELETE /records/new",
+		"POST /records/bulk",
+	}
+	if strings.Join(mutations, "\n") != strings.Join(expected, "\n") {
+		t.Errorf("expected requests\n%v\ngot\n%v", expected, mutations)
+	}
+}
+
+func TestRollbackKeepsDefaultTTL(t *testing.T) {
+	var bodies []string
+	api := newTestProvider(t, func(w http.ResponseWriter, r *http.Request) {
+		body, _ := ioutil.ReadAll(r.Body)
+		bodies = append(bodies, r.Method+" "+string(body))
+		w.Write([]byte(`{}`))
+	})
+
+	zoneTTL, ownTTL := 3600, 300
+	previous := []record{
+		{ID: "default", Name: "a", Type: "A", Value: "192.0.2.1", TTL: &zoneTTL, ZoneID: "zone1", defaultTTL: true},
+		{ID: "own", Name: "b", Type: "A", Value: "192.0.2.2", TTL: &ownTTL, ZoneID: "zone1"},
+	}
+	if err := api.undoDelete(context.Background(), previous[0])(); err != nil {
+		t.Fatal(err)
+	}
+	if err := api.undoModify(context.Background(), previous, previous)(); err != nil {
+		t.Fatal(err)
+	}
+
+	if len(bodies) != 2 {
+		t.Fatalf("expected 2 requests, got %v", bodies)
+	}
+	if !strings.HasPrefix(bodies[0], "POST ") || !strings.Contains(bodies[0], `"ttl":null`) {
+		t.Errorf("expected the deleted record to be re-created without a TTL, got %s", bodies[0])
+	}
+	if !strings.HasPrefix(bodies[1], "PUT ") || !strings.Contains(bodies[1], `"id":"default","name":"a","ttl":null`) ||
+		!strings.Contains(bodies[1], `"id":"own","name":"b","ttl":300`) {
+		t.Errorf("expected the modified records to be restored with their own TTLs, got %s", bodies[1])
+	}
+}
+
+func TestDNSSECRecordsIgnored(t *testing.T) {
+	api := newTestProvider(t, func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.Method == "GET" && r.URL.Path == "/zones":
+			w.Write([]byte(`{"zones":[{"id":"zone1","name":"example.com","ttl":3600}]}`))
+		case r.Method == "GET" && r.URL.Path == "/records":
+			w.Write([]byte(`{"records":[
+				{"id":"1","name":"www","type":"A","value":"1.2.3.4","ttl":300,"zone_id":"zone1"},
+				{"id":"2","name":"@","type":"DNSKEY","value":"257 3 13 mdsswUyr3DPW132mOi8V9xESWE8jTo0dxCjjnopKl+GqJxpVXckHAeF+KkxLbxILfDLUT0rAK9iUzy1L53eKGQ==","ttl":3600,"zone_id":"zone1"},
+				{"id":"3","name":"www","type":"RRSIG","value":"A 13 3 300 20230101000000 20221201000000 12345 example.com. c2lnbmF0dXJl","ttl":300,"zone_id":"zone1"},
+				{"id":"4","name":"www","type":"NSEC","value":"example.com. A RRSIG NSEC","ttl":300,"zone_id":"zone1"},
+				{"id":"5","name":"abc","type":"NSEC3","value":"1 0 10 AABBCCDD 2T7B4G4VSA5SMI47K61MV5BV1A22BOJR A RRSIG","ttl":300,"zone_id":"zone1"},
+				{"id":"6","name":"@","type":"NSEC3PARAM","value":"1 0 10 AABBCCDD","ttl":0,"zone_id":"zone1"}
+			]}`))
+		case r.Method == "GET" && r.URL.Path == "/zones/zone1/dnssec":
+			w.Write([]byte(`{"dnssec":{"enabled":true,"ds_records":[{"key_tag":12345,"algorithm":13,"digest_type":2,"digest":"ABCDEF0123456789"}]}}`))
+		default:
+			t.Errorf("unexpected request: %s %s", r.Method, r.URL)
+			w.WriteHeader(500)
+		}
+	})
+
+	existing, err := api.GetZoneRecords("example.com")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(existing) != 2 || existing[0].Type != "A" || existing[1].Type != "DS" {
+		t.Errorf("expected only the A record and the DS record, got %v", existing)
+	}
+
+	dc := &models.DomainConfig{
+		Name:    "example.com",
+		Records: models.Records{makeRC("www", "A", "1.2.3.4", 300)},
+	}
+	corrections, err := api.GetDomainCorrections(dc)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(corrections) != 0 {
+		t.Errorf("expected no corrections, got %d", len(corrections))
+	}
+}
+
+func TestDNSSECStateUnavailable(t *testing.T) {
+	for _, tst := range []struct {
+		status  int
+		wantErr bool
+	}{
+		{http.StatusNotFound, false},
+		{http.StatusForbidden, false},
+		{http.StatusBadRequest, true},
+	} {
+		api := newTestProvider(t, func(w http.ResponseWriter, r *http.Request) {
+			switch {
+			case r.Method == "GET" && r.URL.Path == "/zones":
+				w.Write([]byte(`{"zones":[{"id":"zone1","name":"example.com","ttl":3600}]}`))
+			case r.Method == "GET" && r.URL.Path == "/records":
+				w.Write([]byte(`{"records":[{"id":"1","name":"www","type":"A","value":"1.2.3.4","ttl":300,"zone_id":"zone1"}]}`))
+			case r.Method == "GET" && r.URL.Path == "/zones/zone1/dnssec":
+				w.WriteHeader(tst.status)
+				w.Write([]byte(`{"error":{"message":"not available","code":0}}`))
+			default:
+				t.Errorf("unexpected request: %s %s", r.Method, r.URL)
+				w.WriteHeader(500)
+			}
+		})
+
+		existing, err := api.GetZoneRecords("example.com")
+		if tst.wantErr {
+			if err == nil {
+				t.Errorf("%d: expected an error", tst.status)
+			}
+			continue
+		}
+		if err != nil || len(existing) != 1 || existing[0].Type != "A" {
+			t.Errorf("%d: expected the records without DS records, got %v, %v", tst.status, existing, err)
+		}
+	}
+}
+
+func TestDumpPayloads(t *testing.T) {
+	api := newTestProvider(t, func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.Method == "GET" && r.URL.Path == "/zones":
+			w.Write([]byte(`{"zones":[{"id":"zone1","name":"example.com","ttl":3600}]}`))
+		case r.Method == "GET" && r.URL.Path == "/records":
+			w.Write([]byte(`{"records":[{"id":"old","name":"old","type":"A","value":"1.1.1.1","ttl":300,"zone_id":"zone1"}]}`))
+		default:
+			t.Errorf("unexpected request: %s %s", r.Method, r.URL)
+			w.WriteHeader(500)
+		}
+	})
+	api.dumpPayloads = true
+
+	dc := &models.DomainConfig{
+		Name:    "example.com",
+		Records: models.Records{makeRC("www", "A", "1.2.3.4", 300)},
+	}
+	corrections, err := api.GetDomainCorrections(dc)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(corrections) != 2 {
+		t.Fatalf("expected 2 corrections, got %d", len(corrections))
+	}
+	expected := []string{
+		"\n\tpayload: DELETE /records/old",
+		"\n\tpayload: POST /records/bulk " + `{"records":[{"id":"","name":"www","ttl":300,"type":"A","value":"1.2.3.4","zone_id":"zone1"}]}`,
+	}
+	for i, c := range corrections {
+		if !strings.HasSuffix(c.Msg, expected[i]) {
+			t.Errorf("correction %d: expected message to end with %q, got %q", i, expected[i], c.Msg)
+		}
+	}
+}
+
+func TestNullTTL(t *testing.T) {
+	api := newTestProvider(t, func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.Method == "GET" && r.URL.Path == "/zones":
+			w.Write([]byte(`{"zones":[{"id":"zone1","name":"example.com","ttl":3600}]}`))
+		case r.Method == "GET" && r.URL.Path == "/records":
+			w.Write([]byte(`{"records":[{"id":"1","name":"www","type":"A","value":"1.2.3.4","ttl":null,"zone_id":"zone1"}]}`))
+		default:
+			t.Errorf("unexpected request: %s %s", r.Method, r.URL)
+			w.WriteHeader(500)
+		}
+	})
+
+	dc := &models.DomainConfig{
+		Name:    "example.com",
+		Records: models.Records{makeRC("www", "A", "1.2.3.4", 3600)},
+	}
+	corrections, err := api.GetDomainCorrections(dc)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(corrections) != 0 {
+		t.Errorf("expected no corrections, got %d", len(corrections))
+	}
+
+	r := fromRecordConfig(makeRC("www", "A", "1.2.3.4", 0), &zone{ID: "zone1"})
+	body, _ := json.Marshal(r)
+	if !strings.Contains(string(body), `"ttl":null`) {
+		t.Errorf("expected a TTL of 0 to be sent as null, got %s", body)
+	}
+}
+
+func TestReplaceZone(t *testing.T) {
+	defer func(threshold int) { providers.ReplaceZoneThreshold = threshold }(providers.ReplaceZoneThreshold)
+	providers.ReplaceZoneThreshold = 2
+
+	var mutations []string
+	api := newTestProvider(t, func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.Method == "GET" && r.URL.Path == "/zones":
+			w.Write([]byte(`{"zones":[{"id":"zone1","name":"example.com","ttl":3600,"ns":["hydrogen.ns.hetzner.com"]}]}`))
+		case r.Method == "GET" && r.URL.Path == "/records":
+			// The default NS record has to survive the replacement.
+			w.Write([]byte(`{"records":[
+				{"id":"ns","name":"@","type":"NS","value":"hydrogen.ns.hetzner.com.","zone_id":"zone1"},
+				{"id":"old","name":"old","type":"A","value":"1.1.1.1","ttl":300,"zone_id":"zone1"},
+				{"id":"mod","name":"mod","type":"A","value":"2.2.2.2","ttl":300,"zone_id":"zone1"}
+			]}`))
+		case r.Method == "PUT" && r.URL.Path == "/records/bulk":
+			request := &bulkUpdateRecordsRequest{}
+			if err := json.NewDecoder(r.Body).Decode(request); err != nil {
+				t.Error(err)
+			}
+			for _, rec := range request.Records {
+				mutations = append(mutations, "PUT "+rec.ID+" "+rec.Value)
+			}
+			w.Write([]byte(`{}`))
+		case r.Method == "POST" && r.URL.Path == "/records/bulk":
+			request := &bulkCreateRecordsRequest{}
+			if err := json.NewDecoder(r.Body).Decode(request); err != nil {
+				t.Error(err)
+			}
+			for _, rec := range request.Records {
+				mutations = append(mutations, "POST "+rec.Name+" "+rec.Value)
+			}
+			w.Write([]byte(`{}`))
+		case r.Method == "DELETE":
+			mutations = append(mutations, "DELETE "+r.URL.Path)
+			w.Write([]byte(`{}`))
+		default:
+			t.Errorf("unexpected request: %s %s", r.Method, r.URL)
+			w.WriteHeader(500)
+		}
+	})
+
+	newDC := func() *models.DomainConfig {
+		return &models.DomainConfig{
+			Name: "example.com",
+			Records: models.Records{
+				makeRC("www", "A", "1.2.3.4", 300),
+				makeRC("mod", "A", "3.3.3.3", 300),
+			},
+		}
+	}
+
+	dc := newDC()
+	dc.KeepUnknown = true
+	corrections, err := api.GetDomainCorrections(dc)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(corrections) != 2 {
+		t.Fatalf("expected incremental corrections with NO_PURGE, got %d", len(corrections))
+	}
+
+	corrections, err = api.GetDomainCorrections(newDC())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(corrections) != 1 {
+		t.Fatalf("expected a single replace correction, got %d", len(corrections))
+	}
+	if err := corrections[0].F(); err != nil {
+		t.Fatal(err)
+	}
+	expected := []string{
+		"PUT mod 3.3.3.3",
+		"POST www 1.2.3.4",
+		"DELETE /records/old",
+	}
+	if strings.Join(mutations, "\n") != strings.Join(expected, "\n") {
+		t.Errorf("expected requests\n%v\ngot\n%v", expected, mutations)
+	}
+}
+
+func TestInBailiwickDelegation(t *testing.T) {
+	var created []string
+	api := newTestProvider(t, func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.Method == "GET" && r.URL.Path == "/zones":
+			w.Write([]byte(`{"zones":[{"id":"zone1","name":"example.com","ttl":3600}]}`))
+		case r.Method == "GET" && r.URL.Path == "/records":
+			w.Write([]byte(`{"records":[]}`))
+		case r.Method == "POST" && r.URL.Path == "/records/bulk":
+			request := &bulkCreateRecordsRequest{}
+			if err := json.NewDecoder(r.Body).Decode(request); err != nil {
+				t.Error(err)
+			}
+			for _, rec := range request.Records {
+				created = append(created, rec.Name+" "+rec.Type+" "+rec.Value)
+			}
+			w.Write([]byte(`{}`))
+		default:
+			t.Errorf("unexpected request: %s %s", r.Method, r.URL)
+			w.WriteHeader(500)
+		}
+	})
+
+	dc := &models.DomainConfig{Name: "example.com"}
+	err := dc.AddDelegation(&models.Delegation{
+		Label: "sub",
+		Nameservers: []models.DelegationNameserver{
+			{Name: "ns1.sub.example.com", IPs: []net.IP{net.ParseIP("192.0.2.1")}},
+			{Name: "ns2.sub.example.com", IPs: []net.IP{net.ParseIP("2001:db8::2")}},
+		},
+	}, 300)
+	if err != nil {
+		t.Fatal(err)
+	}
+	corrections, err := api.GetDomainCorrections(dc)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, c := range corrections {
+		if err := c.F(); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	sort.Strings(created)
+	expected := []string{
+		"ns1.sub A 192.0.2.1",
+		"ns2.sub AAAA 2001:db8::2",
+		"sub NS ns1.sub.example.com.",
+		"sub NS ns2.sub.example.com.",
+	}
+	if strings.Join(created, "\n") != strings.Join(expected, "\n") {
+		t.Errorf("expected records\n%v\ngot\n%v", expected, created)
+	}
+}
+
+func TestExportScript(t *testing.T) {
+	api := newTestProvider(t, func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.Method == "GET" && r.URL.Path == "/zones":
+			w.Write([]byte(`{"zones":[{"id":"zone1","name":"example.com","ttl":3600}]}`))
+		case r.Method == "GET" && r.URL.Path == "/records":
+			w.Write([]byte(`{"records":[
+				{"id":"old","name":"old","type":"TXT","value":"\"it's\"","ttl":300,"zone_id":"zone1"}
+			]}`))
+		default:
+			t.Errorf("unexpected request: %s %s", r.Method, r.URL)
+			w.WriteHeader(500)
+		}
+	})
+	dir, err := ioutil.TempDir("", "hetzner")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	api.scriptPath = filepath.Join(dir, "changes.sh")
+
+	dc := &models.DomainConfig{
+		Name:    "example.com",
+		Records: models.Records{makeRC("www", "TXT", "it's new", 300)},
+	}
+	// Corrections computed for anything else than a plan, e.g. the
+	// challenge records of get-certs, are not exported.
+	if _, err := api.GetDomainCorrections(dc); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(api.scriptPath); !os.IsNotExist(err) {
+		t.Fatalf("expected no script outside of a plan, got %v", err)
+	}
+	// Concurrent plans do not mix up their steps.
+	var wg sync.WaitGroup
+	errs := make(chan error, 2)
+	for i := 0; i < 2; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_, err := api.GetDomainCorrectionsContext(providers.WithPlan(context.Background()), dc)
+			errs <- err
+		}()
+	}
+	wg.Wait()
+	close(errs)
+	for err := range errs {
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	data, err := ioutil.ReadFile(api.scriptPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	script := string(data)
+	if strings.Count(script, "#!/bin/sh") != 1 {
+		t.Error("expected a single script header")
+	}
+	if !strings.Contains(script, "step 'example.com-1-") {
+		t.Error("expected steps to be named after the domain")
+	}
+	if strings.Contains(script, "test-token") {
+		t.Error("the API key must not be written to the script")
+	}
+	for _, expected := range []string{
+		`curl --fail --silent --show-error -X DELETE -H "Auth-API-Token: $HETZNER_API_KEY" '` + api.baseURL + `/records/old'`,
+		`curl --fail --silent --show-error -X POST -H "Auth-API-Token: $HETZNER_API_KEY" -H 'Content-Type: application/json' --data '{"records":[{"id":"","name":"www","ttl":300,"type":"TXT","value":"\"it'\''s new\"","zone_id":"zone1"}]}' '` + api.baseURL + `/records/bulk'`,
+	} {
+		if strings.Count(script, "' "+expected+"\n") != 2 {
+			t.Errorf("expected the script to contain twice:\n%s\ngot:\n%s", expected, script)
+		}
+	}
+}
+
+func TestOwnership(t *testing.T) {
+	oldKey := ownershipKey(makeRC("old", "A", "1.1.1.1", 300))
+	var mutations []string
+	api := newTestProvider(t, func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.Method == "GET" && r.URL.Path == "/zones":
+			w.Write([]byte(`{"zones":[{"id":"zone1","name":"example.com","ttl":3600}]}`))
+		case r.Method == "GET" && r.URL.Path == "/records":
+			w.Write([]byte(`{"records":[
+				{"id":"old","name":"old","type":"A","value":"1.1.1.1","ttl":300,"zone_id":"zone1"},
+				{"id":"foreign","name":"foreign","type":"A","value":"2.2.2.2","ttl":300,"zone_id":"zone1"},
+				{"id":"other-manifest","name":"_dnscontrol-owner","type":"TXT","value":"\"owner=team-b 0123456789ab\"","ttl":300,"zone_id":"zone1"},
+				{"id":"manifest","name":"_dnscontrol-owner","type":"TXT","value":"\"owner=team-a ` + oldKey + `\"","ttl":300,"zone_id":"zone1"}
+			]}`))
+		case r.Method == "DELETE":
+			mutations = append(mutations, "DELETE "+r.URL.Path)
+			w.Write([]byte(`{}`))
+		case r.URL.Path == "/records/bulk":
+			request := &bulkCreateRecordsRequest{}
+			if err := json.NewDecoder(r.Body).Decode(request); err != nil {
+				t.Error(err)
+			}
+			for _, rec := range request.Records {
+				mutations = append(mutations, r.Method+" "+rec.ID+" "+rec.Name+" "+rec.Value)
+			}
+			w.Write([]byte(`{}`))
+		default:
+			t.Errorf("unexpected request: %s %s", r.Method, r.URL)
+			w.WriteHeader(500)
+		}
+	})
+	api.ownerID = "team-a"
+
+	www := makeRC("www", "A", "3.3.3.3", 300)
+	dc := &models.DomainConfig{Name: "example.com", Records: models.Records{www}}
+	corrections, err := api.GetDomainCorrections(dc)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var notes []string
+	for _, c := range corrections {
+		if c.Informational {
+			notes = append(notes, c.Msg)
+			continue
+		}
+		if err := c.F(); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if len(notes) != 2 || !strings.Contains(notes[0], "foreign.example.com") || !strings.Contains(notes[1], "owner=team-b") {
+		t.Errorf("expected notes about the records of others, got %v", notes)
+	}
+	expected := []string{
+		"DELETE /records/old",
+		"POST  www 3.3.3.3",
+		`PUT manifest _dnscontrol-owner "owner=team-a ` + ownershipKey(www) + `"`,
+	}
+	if strings.Join(mutations, "\n") != strings.Join(expected, "\n") {
+		t.Errorf("expected requests\n%v\ngot\n%v", expected, mutations)
+	}
+}
+
+func TestAutoDNSSEC(t *testing.T) {
+	enabled := false
+	var mutations []string
+	api := newTestProvider(t, func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.Method == "GET" && r.URL.Path == "/zones":
+			w.Write([]byte(`{"zones":[{"id":"zone1","name":"example.com","ttl":3600}]}`))
+		case r.Method == "GET" && r.URL.Path == "/records":
+			w.Write([]byte(`{"records":[]}`))
+		case r.Method == "GET" && r.URL.Path == "/zones/zone1/dnssec":
+			fmt.Fprintf(w, `{"dnssec":{"enabled":%v}}`, enabled)
+		case r.Method == "POST" && r.URL.Path == "/zones/zone1/dnssec/enable":
+			mutations = append(mutations, r.URL.Path)
+			enabled = true
+		case r.Method == "POST" && r.URL.Path == "/zones/zone1/dnssec/disable":
+			mutations = append(mutations, r.URL.Path)
+			enabled = false
+		default:
+			t.Errorf("unexpected request: %s %s", r.Method, r.URL)
+			w.WriteHeader(500)
+		}
+	})
+
+	for _, tst := range []struct {
+		autoDNSSEC string
+		expected   string
+	}{
+		{"", ""},
+		{"off", ""},
+		{"on", "/zones/zone1/dnssec/enable"},
+		{"on", ""},
+		{"off", "/zones/zone1/dnssec/disable"},
+	} {
+		mutations = nil
+		dc := &models.DomainConfig{Name: "example.com", AutoDNSSEC: tst.autoDNSSEC}
+		corrections, err := api.GetDomainCorrections(dc)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, c := range corrections {
+			if err := c.F(); err != nil {
+				t.Fatal(err)
+			}
+		}
+		if got := strings.Join(mutations, ","); got != tst.expected {
+			t.Errorf("AutoDNSSEC %q: expected %q, got %q", tst.autoDNSSEC, tst.expected, got)
+		}
+	}
+}
+
+func TestReverseZonePTR(t *testing.T) {
+	const reverse = "2.0.192.in-addr.arpa"
+	var created []string
+	api := newTestProvider(t, func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.Method == "GET" && r.URL.Path == "/zones":
+			w.Write([]byte(`{"zones":[{"id":"zone1","name":"` + reverse + `","ttl":3600}]}`))
+		case r.Method == "GET" && r.URL.Path == "/records":
+			w.Write([]byte(`{"records":[` +
+				`{"id":"1","name":"1","type":"PTR","value":"host.example.com.","ttl":3600,"zone_id":"zone1"},` +
+				`{"id":"2","name":"2","type":"PTR","value":"mail","ttl":3600,"zone_id":"zone1"}]}`))
+		case r.Method == "POST" && r.URL.Path == "/records/bulk":
+			request := &bulkCreateRecordsRequest{}
+			if err := json.NewDecoder(r.Body).Decode(request); err != nil {
+				t.Error(err)
+			}
+			for _, rec := range request.Records {
+				created = append(created, rec.Name+" "+rec.Type+" "+rec.Value)
+			}
+			w.Write([]byte(`{}`))
+		default:
+			t.Errorf("unexpected request: %s %s", r.Method, r.URL)
+			w.WriteHeader(500)
+		}
+	})
+
+	ptr := func(label, target string) *models.RecordConfig {
+		rc := &models.RecordConfig{Type: "PTR", TTL: 3600, Metadata: map[string]string{}}
+		rc.SetLabel(label, reverse)
+		rc.SetTarget(target)
+		return rc
+	}
+	dc := &models.DomainConfig{
+		Name: reverse,
+		Records: models.Records{
+			ptr("1", "host.example.com."),
+			// A relative value is stored relative to the reverse zone.
+			ptr("2", "mail."+reverse+"."),
+			ptr("3", "www.example.com."),
+		},
+	}
+	corrections, err := api.GetDomainCorrections(dc)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, c := range corrections {
+		if err := c.F(); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	expected := []string{"3 PTR www.example.com."}
+	if strings.Join(created, "\n") != strings.Join(expected, "\n") {
+		t.Errorf("expected records\n%v\ngot\n%v", expected, created)
+	}
+}
+
+func TestRecordCache(t *testing.T) {
+	fetches := 0
+	api := newTestProvider(t, func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.Method == "GET" && r.URL.Path == "/zones":
+			w.Write([]byte(`{"zones":[{"id":"zone1","name":"example.com","ttl":3600}]}`))
+		case r.Method == "GET" && r.URL.Path == "/records":
+			fetches++
+			w.Write([]byte(`{"records":[{"id":"1","name":"www","type":"A","value":"1.2.3.4","ttl":3600,"zone_id":"zone1"}]}`))
+		case r.Method == "GET" && r.URL.Path == "/zones/zone1/dnssec":
+			w.Write([]byte(`{"dnssec":{"enabled":false}}`))
+		case r.Method == "POST" && r.URL.Path == "/records/bulk":
+			w.Write([]byte(`{}`))
+		default:
+			t.Errorf("unexpected request: %s %s", r.Method, r.URL)
+			w.WriteHeader(500)
+		}
+	})
+
+	if _, err := api.GetZoneRecords("example.com"); err != nil {
+		t.Fatal(err)
+	}
+	dc := &models.DomainConfig{
+		Name: "example.com",
+		Records: models.Records{
+			makeRC("www", "A", "1.2.3.4", 3600),
+			makeRC("mail", "A", "1.2.3.5", 3600),
+		},
+	}
+	corrections, err := api.GetDomainCorrections(dc)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if fetches != 1 {
+		t.Errorf("expected the records to be fetched once, got %d", fetches)
+	}
+
+	for _, c := range corrections {
+		if err := c.F(); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if _, err := api.GetZoneRecords("example.com"); err != nil {
+		t.Fatal(err)
+	}
+	if fetches != 2 {
+		t.Errorf("expected the records to be fetched again after a change, got %d fetches", fetches)
+	}
+}
+
+func TestSOA(t *testing.T) {
+	const exported = `$ORIGIN example.com.
+$TTL 3600
+@	86400	IN	SOA	hydrogen.ns.hetzner.com. dns.hetzner.com. 2021010101 86400 10800 3600000 3600
+www	3600	IN	A	1.2.3.4
+`
+	var imported, contentType string
+	api := newTestProvider(t, func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.Method == "GET" && r.URL.Path == "/zones":
+			w.Write([]byte(`{"zones":[{"id":"zone1","name":"example.com","ttl":3600}]}`))
+		case r.Method == "GET" && r.URL.Path == "/records":
+			w.Write([]byte(`{"records":[` +
+				`{"id":"1","name":"@","type":"SOA","value":"hydrogen.ns.hetzner.com. dns.hetzner.com. 2021010101 86400 10800 3600000 3600","ttl":86400,"zone_id":"zone1"},` +
+				`{"id":"2","name":"www","type":"A","value":"1.2.3.4","ttl":3600,"zone_id":"zone1"}]}`))
+		case r.Method == "GET" && r.URL.Path == "/zones/zone1/dnssec":
+			w.Write([]byte(`{"dnssec":{"enabled":false}}`))
+		case r.Method == "GET" && r.URL.Path == "/zones/zone1/export":
+			w.Write([]byte(exported))
+		case r.Method == "POST" && r.URL.Path == "/zones/zone1/import":
+			data, _ := ioutil.ReadAll(r.Body)
+			imported, contentType = string(data), r.Header.Get("Content-Type")
+			w.Write([]byte(`{"zone":{"id":"zone1","name":"example.com","ttl":3600}}`))
+		default:
+			t.Errorf("unexpected request: %s %s", r.Method, r.URL)
+			w.WriteHeader(500)
+		}
+	})
+
+	records, err := api.GetZoneRecords("example.com")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var soa *models.RecordConfig
+	for _, rc := range records {
+		if rc.Type == "SOA" {
+			soa = rc
+		}
+	}
+	if soa == nil || soa.SoaSerial != 2021010101 || soa.SoaRefresh != 86400 || soa.TTL != 86400 {
+		t.Fatalf("expected the SOA record of the zone, got %+v", soa)
+	}
+
+	makeSOA := func(refresh uint32) *models.RecordConfig {
+		rc := &models.RecordConfig{Type: "SOA", TTL: 86400, Metadata: map[string]string{}}
+		rc.SetLabel("@", "example.com")
+		rc.SetTargetSOA("hydrogen.ns.hetzner.com.", "dns.hetzner.com.", 0, refresh, 10800, 3600000, 3600)
+		return rc
+	}
+
+	// The serial is maintained by HETZNER, it must not cause a change.
+	dc := &models.DomainConfig{
+		Name:    "example.com",
+		Records: models.Records{makeSOA(86400), makeRC("www", "A", "1.2.3.4", 3600)},
+	}
+	corrections, err := api.GetDomainCorrections(dc)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(corrections) != 0 {
+		t.Fatalf("expected no corrections, got %d: %s", len(corrections), corrections[0].Msg)
+	}
+
+	dc.Records[0] = makeSOA(43200)
+	corrections, err = api.GetDomainCorrections(dc)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(corrections) != 1 || !strings.HasPrefix(corrections[0].Msg, "Update SOA of example.com") {
+		t.Fatalf("expected one SOA correction, got %d", len(corrections))
+	}
+	if err := corrections[0].F(); err != nil {
+		t.Fatal(err)
+	}
+	if contentType != "text/plain" {
+		t.Errorf("expected the zone to be imported as text/plain, got %q", contentType)
+	}
+	for _, want := range []string{
+		"hydrogen.ns.hetzner.com. dns.hetzner.com. 2021010101 43200 10800 3600000 3600",
+		"www.example.com.\t3600\tIN\tA\t1.2.3.4",
+	} {
+		if !strings.Contains(imported, want) {
+			t.Errorf("expected the imported zone to contain %q, got\n%s", want, imported)
+		}
+	}
+}
+
+func TestNewZoneDefaultNS(t *testing.T) {
+	created := false
+	api := newTestProvider(t, func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.Method == "GET" && r.URL.Path == "/zones":
+			if created {
+				w.Write([]byte(`{"zones":[{"id":"zone1","name":"example.com","ttl":86400,` +
+					`"ns":["hydrogen.ns.hetzner.com","oxygen.ns.hetzner.com","helium.ns.hetzner.de"]}]}`))
+			} else {
+				w.Write([]byte(`{"zones":[]}`))
+			}
+		case r.Method == "POST" && r.URL.Path == "/zones":
+			created = true
+			w.Write([]byte(`{"zone":{"id":"zone1","name":"example.com","ttl":86400}}`))
+		case r.Method == "GET" && r.URL.Path == "/records":
+			// The records HETZNER creates with a zone.
+			w.Write([]byte(`{"records":[` +
+				`{"id":"1","name":"@","type":"SOA","value":"hydrogen.ns.hetzner.com. dns.hetzner.com. 2021010101 86400 10800 3600000 3600","zone_id":"zone1"},` +
+				`{"id":"2","name":"@","type":"NS","value":"hydrogen.ns.hetzner.com.","zone_id":"zone1"},` +
+				`{"id":"3","name":"@","type":"NS","value":"oxygen.ns.hetzner.com.","zone_id":"zone1"},` +
+				`{"id":"4","name":"@","type":"NS","value":"helium.ns.hetzner.de.","zone_id":"zone1"}]}`))
+		case r.Method == "GET" && r.URL.Path == "/zones/zone1/dnssec":
+			w.Write([]byte(`{"dnssec":{"enabled":false}}`))
+		default:
+			t.Errorf("unexpected request: %s %s", r.Method, r.URL)
+			w.WriteHeader(500)
+		}
+	})
+	if err := api.EnsureDomainExists("example.com"); err != nil {
+		t.Fatal(err)
+	}
+
+	// The NS records added for the nameservers of the provider, with the default NAMESERVER_TTL.
+	dc := &models.DomainConfig{Name: "example.com", Records: models.Records{
+		makeRC("@", "NS", "hydrogen.ns.hetzner.com.", 300),
+		makeRC("@", "NS", "oxygen.ns.hetzner.com.", 300),
+		makeRC("@", "NS", "helium.ns.hetzner.de.", 300),
+	}}
+	corrections, err := api.GetDomainCorrections(dc)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(corrections) != 0 {
+		t.Errorf("expected no corrections, got %d: %s", len(corrections), corrections[0].Msg)
+	}
+
+	// No NS records in the configuration, e.g. DnsProvider(HETZNER, 0).
+	corrections, err = api.GetDomainCorrections(&models.DomainConfig{Name: "example.com"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(corrections) != 0 {
+		t.Errorf("expected the default NS records not to be deleted, got %d: %s", len(corrections), corrections[0].Msg)
+	}
+
+	// Other nameservers in the configuration replace the default ones.
+	dc.Records = models.Records{dc.Records[0], makeRC("@", "NS", "ns1.example.net.", 300)}
+	corrections, err = api.GetDomainCorrections(dc)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var msgs []string
+	for _, c := range corrections {
+		msgs = append(msgs, c.Msg)
+	}
+	all := strings.Join(msgs, "\n")
+	for _, want := range []string{
+		"oxygen.ns.hetzner.com.",
+		"helium.ns.hetzner.de.",
+		"ns1.example.net.",
+	} {
+		if !strings.Contains(all, want) {
+			t.Errorf("expected %q in the corrections, got\n%s", want, all)
+		}
+	}
+	if strings.Contains(all, "hydrogen") {
+		t.Errorf("expected the configured default NS record to be kept, got\n%s", all)
+	}
+}
+
+func TestListChanges(t *testing.T) {
+	api := newTestProvider(t, func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.Method == "GET" && r.URL.Path == "/zones":
+			w.Write([]byte(`{"zones":[{"id":"zone1","name":"example.com","ttl":86400,"ns":["hydrogen.ns.hetzner.com"]}]}`))
+		case r.Method == "GET" && r.URL.Path == "/records":
+			w.Write([]byte(`{"records":[` +
+				`{"id":"1","name":"@","type":"SOA","value":"hydrogen.ns.hetzner.com. dns.hetzner.com. 2021010101 86400 10800 3600000 3600","zone_id":"zone1"},` +
+				`{"id":"2","name":"@","type":"NS","value":"hydrogen.ns.hetzner.com.","zone_id":"zone1"},` +
+				`{"id":"3","name":"www","type":"A","value":"1.2.3.4","ttl":300,"zone_id":"zone1"}]}`))
+		default:
+			t.Errorf("unexpected request: %s %s", r.Method, r.URL)
+			w.WriteHeader(500)
+		}
+	})
+
+	// Neither the SOA record nor the default NS record are deleted.
+	dc := &models.DomainConfig{Name: "example.com", Records: models.Records{makeRC("www", "A", "1.2.3.5", 300)}}
+	create, del, modify, existing, err := api.ListChangesContext(context.Background(), dc)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(create) != 0 || len(del) != 0 || len(modify) != 1 || existing != 1 {
+		t.Errorf("expected 1 modification of 1 record, got %v %v %v of %d", create, del, modify, existing)
+	}
+}
+
+func TestLOCRoundTrip(t *testing.T) {
+	api := newTestProvider(t, func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.Method == "GET" && r.URL.Path == "/zones":
+			w.Write([]byte(`{"zones":[{"id":"zone1","name":"example.com","ttl":3600}]}`))
+		case r.Method == "GET" && r.URL.Path == "/records":
+			// The same location as below, with other decimals.
+			w.Write([]byte(`{"records":[{"id":"1","name":"@","type":"LOC","value":"52 22 23.0 N 4 53 32.00 E -2.0m 1m 10000.00m 10m","ttl":3600,"zone_id":"zone1"}]}`))
+		default:
+			t.Errorf("unexpected request: %s %s", r.Method, r.URL)
+			w.WriteHeader(500)
+		}
+	})
+
+	desired := makeRC("@", "LOC", "52 22 23 N 4 53 32 E -2m", 3600)
+	dc := &models.DomainConfig{
+		Name:    "example.com",
+		Records: models.Records{desired},
+	}
+	corrections, err := api.GetDomainCorrections(dc)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(corrections) != 0 {
+		t.Errorf("expected no corrections, got %d", len(corrections))
+	}
+
+	// What we write reads back as the same record.
+	r := fromRecordConfig(desired, &zone{ID: "zone1"})
+	ttl := 3600
+	r.TTL = &ttl
+	back := toRecordConfig("example.com", r)
+	if back.GetTargetCombined() != desired.GetTargetCombined() || back.LocLatitude != desired.LocLatitude {
+		t.Errorf("expected %q to read back unchanged, got %q", desired.GetTargetCombined(), back.GetTargetCombined())
+	}
+}
+
+func TestChildDS(t *testing.T) {
+	digest := strings.Repeat("ab", 32)
+	var created []record
+	api := newTestProvider(t, func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.Method == "GET" && r.URL.Path == "/zones":
+			w.Write([]byte(`{"zones":[{"id":"zone1","name":"example.com","ttl":3600}]}`))
+		case r.Method == "GET" && r.URL.Path == "/records":
+			w.Write([]byte(`{"records":[{"id":"1","name":"sub","type":"DS","value":"12345 13 2 ` + strings.ToUpper(digest) + `","ttl":3600,"zone_id":"zone1"}]}`))
+		case r.Method == "POST" && r.URL.Path == "/records/bulk":
+			request := &bulkCreateRecordsRequest{}
+			if err := json.NewDecoder(r.Body).Decode(request); err != nil {
+				t.Error(err)
+			}
+			created = append(created, request.Records...)
+			w.Write([]byte(`{}`))
+		default:
+			t.Errorf("unexpected request: %s %s", r.Method, r.URL)
+			w.WriteHeader(500)
+		}
+	})
+
+	dc := &models.DomainConfig{
+		Name: "example.com",
+		Records: models.Records{
+			makeRC("sub", "DS", "12345 13 2 "+digest, 3600),
+			makeRC("other.sub", "DS", "54321 8 2 "+digest, 3600),
+		},
+	}
+	if err := AuditRecords(dc.Records); err != nil {
+		t.Fatal(err)
+	}
+	corrections, err := api.GetDomainCorrections(dc)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, c := range corrections {
+		if err := c.F(); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if len(created) != 1 || created[0].Name != "other.sub" || created[0].Type != "DS" || created[0].Value != "54321 8 2 "+strings.ToUpper(digest) {
+		t.Errorf("expected only the DS record of other.sub to be created, got %+v", created)
+	}
+}
+
+func TestIDNNoDiff(t *testing.T) {
+	api := newTestProvider(t, func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.Method == "GET" && r.URL.Path == "/zones":
+			w.Write([]byte(`{"zones":[{"id":"zone1","name":"example.com","ttl":3600}]}`))
+		case r.Method == "GET" && r.URL.Path == "/records":
+			// The names as they were entered in the console, in Unicode.
+			w.Write([]byte(`{"records":[{"id":"1","name":"café","type":"CNAME","value":"www.bücher.example.","ttl":3600,"zone_id":"zone1"}]}`))
+		default:
+			t.Errorf("unexpected request: %s %s", r.Method, r.URL)
+			w.WriteHeader(500)
+		}
+	})
+
+	// The same records as in dnsconfig.js, converted by Punycode().
+	desired := &models.RecordConfig{Type: "CNAME", TTL: 3600, Metadata: map[string]string{}}
+	desired.SetLabelFromFQDN("café.example.com", "example.com")
+	desired.SetTarget("www.bücher.example.")
+	dc := &models.DomainConfig{
+		Name:    "example.com",
+		Records: models.Records{desired},
+	}
+	if err := dc.Punycode(); err != nil {
+		t.Fatal(err)
+	}
+	corrections, err := api.GetDomainCorrections(dc)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(corrections) != 0 {
+		t.Errorf("expected no corrections, got %d: %s", len(corrections), corrections[0].Msg)
+	}
+}
+
+func TestHINFORoundTrip(t *testing.T) {
+	api := newTestProvider(t, func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.Method == "GET" && r.URL.Path == "/zones":
+			w.Write([]byte(`{"zones":[{"id":"zone1","name":"example.com","ttl":3600}]}`))
+		case r.Method == "GET" && r.URL.Path == "/records":
+			w.Write([]byte(`{"records":[{"id":"1","name":"@","type":"HINFO","value":"\"INTEL 386\" \"Linux\"","ttl":3600,"zone_id":"zone1"}]}`))
+		default:
+			t.Errorf("unexpected request: %s %s", r.Method, r.URL)
+			w.WriteHeader(500)
+		}
+	})
+
+	for _, tst := range []struct {
+		target string
+		want   int
+	}{
+		{`"INTEL 386" "Linux"`, 0},
+		{`"INTEL  386" "Linux"`, 1}, // The spaces are part of the field.
+		{`"intel 386" "Linux"`, 1},  // The fields are case sensitive.
+	} {
+		dc := &models.DomainConfig{
+			Name:    "example.com",
+			Records: models.Records{makeRC("@", "HINFO", tst.target, 3600)},
+		}
+		corrections, err := api.GetDomainCorrections(dc)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(corrections) != tst.want {
+			t.Errorf("%s: expected %d corrections, got %d", tst.target, tst.want, len(corrections))
+		}
+	}
+}
+
+func TestNAPTRRoundTrip(t *testing.T) {
+	const enum = `100 10 "u" "E2U+sip" "!^(.*)$!sip:\\1@example.com!" .`
+	for _, value := range []string{
+		enum,
+		strings.TrimSuffix(enum, "."), // The empty replacement w/o trailing dot.
+		`100 10 "u" "E2U+sip" "!^(.*)$!sip:\\1@example.com!"`,
+	} {
+		api := newTestProvider(t, func(w http.ResponseWriter, r *http.Request) {
+			switch {
+			case r.Method == "GET" && r.URL.Path == "/zones":
+				w.Write([]byte(`{"zones":[{"id":"zone1","name":"example.com","ttl":3600}]}`))
+			case r.Method == "GET" && r.URL.Path == "/records":
+				json.NewEncoder(w).Encode(getAllRecordsResponse{Records: []record{
+					{ID: "1", Name: "4.3.2.1", Type: "NAPTR", Value: value, TTL: &[]int{3600}[0], ZoneID: "zone1"},
+				}})
+			default:
+				t.Errorf("unexpected request: %s %s", r.Method, r.URL)
+				w.WriteHeader(500)
+			}
+		})
+
+		desired := makeRC("4.3.2.1", "NAPTR", enum, 3600)
+		dc := &models.DomainConfig{
+			Name:    "example.com",
+			Records: models.Records{desired},
+		}
+		corrections, err := api.GetDomainCorrections(dc)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(corrections) != 0 {
+			t.Errorf("%s: expected no corrections, got %d: %s", value, len(corrections), corrections[0].Msg)
+		}
+	}
+
+	// What we write reads back as the same record, also with a replacement
+	// instead of the regexp. The regexp may contain spaces.
+	for _, target := range []string{enum, `10 100 "s" "SIP+D2U" "" _sip._udp.example.com.`} {
+		desired := makeRC("@", "NAPTR", target, 3600)
+		r := fromRecordConfig(desired, &zone{ID: "zone1", Name: "example.com"})
+		if r.Value != target {
+			t.Errorf("expected the value %q, got %q", target, r.Value)
+		}
+		ttl := 3600
+		r.TTL = &ttl
+		back := toRecordConfig("example.com", r)
+		if back.GetTargetCombined() != desired.GetTargetCombined() {
+			t.Errorf("expected %q to read back unchanged, got %q", desired.GetTargetCombined(), back.GetTargetCombined())
+		}
+	}
+	rc := &models.RecordConfig{Type: "NAPTR"}
+	if err := setTargetNAPTR(rc, `100 10 "u" "E2U+sip" "!^.* x$!sip:info@example.com!" sip`); err != nil {
+		t.Fatal(err)
+	}
+	if rc.NaptrRegexp != "!^.* x$!sip:info@example.com!" || rc.GetTargetField() != "sip" {
+		t.Errorf("expected the regexp with spaces and the replacement, got %q %q", rc.NaptrRegexp, rc.GetTargetField())
+	}
+}
+
+func TestTrailingDotNoDiff(t *testing.T) {
+	var stored []record
+	api := newTestProvider(t, func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.Method == "GET" && r.URL.Path == "/zones":
+			w.Write([]byte(`{"zones":[{"id":"zone1","name":"example.com","ttl":3600}]}`))
+		case r.Method == "GET" && r.URL.Path == "/records":
+			// HETZNER returns the targets w/o trailing dot.
+			for i := range stored {
+				stored[i].Value = strings.TrimSuffix(stored[i].Value, ".")
+			}
+			json.NewEncoder(w).Encode(getAllRecordsResponse{Records: stored})
+		case r.Method == "POST" && r.URL.Path == "/records/bulk":
+			request := &bulkCreateRecordsRequest{}
+			if err := json.NewDecoder(r.Body).Decode(request); err != nil {
+				t.Error(err)
+			}
+			for i, rec := range request.Records {
+				rec.ID = fmt.Sprint(len(stored) + i)
+				stored = append(stored, rec)
+			}
+			w.Write([]byte(`{}`))
+		default:
+			t.Errorf("unexpected request: %s %s", r.Method, r.URL)
+			w.WriteHeader(500)
+		}
+	})
+
+	dc := &models.DomainConfig{
+		Name: "example.com",
+		Records: models.Records{
+			makeRC("@", "MX", "10 mail.example.com.", 3600),
+			makeRC("www", "CNAME", "web.example.net.", 3600),
+			makeRC("_sip._tcp", "SRV", "10 20 5060 sip.example.com.", 3600),
+		},
+	}
+	corrections, err := api.GetDomainCorrections(dc)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, c := range corrections {
+		if err := c.F(); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if len(stored) != 3 {
+		t.Fatalf("expected 3 records to be written, got %v", stored)
+	}
+	for _, rec := range stored {
+		if !strings.HasSuffix(rec.Value, ".") {
+			t.Errorf("expected %s %s to be written with trailing dot", rec.Type, rec.Value)
+		}
+	}
+
+	corrections, err = api.GetDomainCorrections(dc)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(corrections) != 0 {
+		t.Errorf("expected no corrections, got %v", corrections)
+	}
+}
+
+func TestFQDNTarget(t *testing.T) {
+	for _, tst := range []struct{ in, want string }{
+		{"mail.example.com.", "mail.example.com."},
+		{"mail.example.com", "mail.example.com."},
+		{"mail", "mail.example.com."},
+		{".", "."},
+		{"", ""},
+	} {
+		if got := fqdnTarget(tst.in, "example.com"); got != tst.want {
+			t.Errorf("%q: expected %q, got %q", tst.in, tst.want, got)
+		}
+	}
+}
@@ -0,0 +1,92 @@
+package hetzner
+
+import (
+	"crypto/sha256"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/StackExchange/dnscontrol/v3/models"
+)
+
+// ownershipLabel is where the TXT manifest of the records owned by an
+// owner ID is kept. HETZNER has no record comments to tag records with.
+const ownershipLabel = "_dnscontrol-owner"
+
+// ownershipKey identifies a record in a manifest. The TTL is left out, so
+// that changing it does not lose the ownership.
+func ownershipKey(rc *models.RecordConfig) string {
+	target := rc.GetTargetCombined()
+	if rc.Type == "TXT" {
+		target = strings.Join(rc.TxtStrings, "")
+	}
+	sum := sha256.Sum256([]byte(rc.GetLabelFQDN() + " " + rc.Type + " " + target))
+	return fmt.Sprintf("%x", sum[:6])
+}
+
+// ownership is the set of records owned by ownerID, as read from the
+// manifest in the zone.
+type ownership struct {
+	ownerID  string
+	manifest *models.RecordConfig // the existing manifest, if any
+	owned    map[string]bool
+}
+
+func (o *ownership) prefix() string {
+	return "owner=" + o.ownerID
+}
+
+func (o *ownership) isManifest(rc *models.RecordConfig) bool {
+	if rc.Type != "TXT" || rc.GetLabel() != ownershipLabel {
+		return false
+	}
+	fields := strings.Fields(strings.Join(rc.TxtStrings, ""))
+	return len(fields) > 0 && fields[0] == o.prefix()
+}
+
+// readOwnership finds the manifest of ownerID in existing.
+func readOwnership(ownerID string, existing models.Records) *ownership {
+	o := &ownership{ownerID: ownerID, owned: map[string]bool{}}
+	for _, rc := range existing {
+		if !o.isManifest(rc) {
+			continue
+		}
+		o.manifest = rc
+		for _, key := range strings.Fields(strings.Join(rc.TxtStrings, ""))[1:] {
+			o.owned[key] = true
+		}
+	}
+	return o
+}
+
+// owns returns true if rc was created by this owner, or is its manifest.
+func (o *ownership) owns(rc *models.RecordConfig) bool {
+	return o.owned[ownershipKey(rc)] || o.isManifest(rc)
+}
+
+// manifestRecord returns the manifest listing the desired records.
+func (o *ownership) manifestRecord(domain string, desired models.Records) *models.RecordConfig {
+	var keys []string
+	for _, rc := range desired {
+		keys = append(keys, ownershipKey(rc))
+	}
+	sort.Strings(keys)
+	content := strings.Join(append([]string{o.prefix()}, keys...), " ")
+
+	// Split into strings of at most 255 bytes, at the spaces.
+	var chunks []string
+	for len(content) > 255 {
+		cut := strings.LastIndex(content[:256], " ")
+		chunks = append(chunks, content[:cut+1])
+		content = content[cut+1:]
+	}
+	chunks = append(chunks, content)
+
+	rc := &models.RecordConfig{Type: "TXT", TTL: 300, Metadata: map[string]string{}}
+	if o.manifest != nil {
+		rc.TTL = o.manifest.TTL
+	}
+	rc.SetLabel(ownershipLabel, domain)
+	rc.SetTargetTXTs(chunks)
+	return rc
+}
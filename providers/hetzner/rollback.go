@@ -0,0 +1,114 @@
+package hetzner
+
+import (
+	"context"
+	"fmt"
+	"strings"
+)
+
+// transaction collects the inverse operations of the corrections of a
+// single domain that were applied successfully. When a later correction
+// fails, the inverse operations are replayed in reverse order.
+//
+// This is a best-effort rollback: the HETZNER API is not transactional,
+// a failing bulk request may have been applied partially and the
+// rollback itself may fail.
+type transaction struct {
+	undo   []func() error
+	failed bool
+}
+
+// apply runs f, which returns the inverse of what it did. If f fails after
+// doing part of its work, e.g. some batches of a bulk request, it may
+// return the inverse of that part along with the error. A nil transaction
+// just runs f.
+func (t *transaction) apply(f func() (undo func() error, err error)) error {
+	if t == nil {
+		_, err := f()
+		return err
+	}
+	if t.failed {
+		return fmt.Errorf("skipped, an earlier correction failed and was rolled back")
+	}
+	undo, err := f()
+	if undo != nil {
+		t.undo = append(t.undo, undo)
+	}
+	if err != nil {
+		t.failed = true
+		return t.rollback(err)
+	}
+	return nil
+}
+
+func (t *transaction) rollback(cause error) error {
+	var errs []string
+	for i := len(t.undo) - 1; i >= 0; i-- {
+		if err := t.undo[i](); err != nil {
+			errs = append(errs, err.Error())
+		}
+	}
+	n := len(t.undo)
+	t.undo = nil
+	if len(errs) > 0 {
+		return fmt.Errorf("%w (rollback of %d correction(s) incomplete: %s)", cause, n, strings.Join(errs, "; "))
+	}
+	return fmt.Errorf("%w (rolled back %d correction(s))", cause, n)
+}
+
+// undoDelete re-creates a deleted record. The zone ID of deleted must be
+// current.
+func (api *hetznerProvider) undoDelete(ctx context.Context, deleted record) func() error {
+	return func() error {
+		restored := []record{withOwnTTL(deleted)}
+		restored[0].ID = ""
+		_, err := api.bulkCreateRecords(ctx, restored)
+		return err
+	}
+}
+
+// undoCreate deletes created records. It is nil if there are none.
+func (api *hetznerProvider) undoCreate(ctx context.Context, created []record) func() error {
+	if len(created) == 0 {
+		return nil
+	}
+	return func() error {
+		for _, r := range created {
+			if err := api.deleteRecord(ctx, r); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+}
+
+// undoModify restores the previous state of the updated records. It is
+// nil if none were updated. The IDs of previous must be current.
+func (api *hetznerProvider) undoModify(ctx context.Context, updated []record, previous []record) func() error {
+	ids := map[string]bool{}
+	for _, r := range updated {
+		ids[r.ID] = true
+	}
+	var restore []record
+	for _, r := range previous {
+		if ids[r.ID] {
+			restore = append(restore, withOwnTTL(r))
+		}
+	}
+	if len(restore) == 0 {
+		return nil
+	}
+	return func() error {
+		_, err := api.bulkUpdateRecords(ctx, restore)
+		return err
+	}
+}
+
+// withOwnTTL returns r as it has to be sent to restore it. A record
+// without a TTL of its own is restored without one, with "ttl": null.
+func withOwnTTL(r record) record {
+	if r.defaultTTL {
+		r.TTL = nil
+	}
+	return r
+}
@@ -0,0 +1,101 @@
+package hetzner
+
+import (
+	"crypto/sha256"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+)
+
+// apiCall is a request that a correction sends to the HETZNER API.
+type apiCall struct {
+	method   string
+	endpoint string
+	request  interface{}
+}
+
+// scriptHeader starts an exported script. Every step records that it is
+// done in $STATE_DIR, so an interrupted script can be run again safely.
+// Steps are named after a hash of their command, so a step of a different
+// plan is never mistaken for a step that is done.
+const scriptHeader = `#!/bin/sh
+# Generated by dnscontrol. Review, then run with HETZNER_API_KEY set.
+# Steps that succeeded are recorded in STATE_DIR and skipped when the
+# script is run again.
+set -eu
+: "${HETZNER_API_KEY:?HETZNER_API_KEY must be set}"
+STATE_DIR="${STATE_DIR:-./dnscontrol-hetzner-state}"
+mkdir -p "$STATE_DIR"
+
+step() {
+	name="$1"
+	shift
+	if [ -e "$STATE_DIR/$name" ]; then
+		echo "skipping $name (done)"
+		return
+	fi
+	"$@"
+	touch "$STATE_DIR/$name"
+}
+`
+
+// exportScript appends the calls for domain to the script at scriptPath,
+// as curl commands. The API key is never written, the script reads it
+// from $HETZNER_API_KEY.
+func (api *hetznerProvider) exportScript(domain string, calls []apiCall) error {
+	api.scriptMu.Lock()
+	defer api.scriptMu.Unlock()
+	var b strings.Builder
+	if !api.scriptStarted {
+		b.WriteString(scriptHeader)
+	}
+	fmt.Fprintf(&b, "\n# %s\n", domain)
+	for i, call := range calls {
+		cmd, err := api.curlCommand(call)
+		if err != nil {
+			return err
+		}
+		sum := sha256.Sum256([]byte(cmd))
+		name := fmt.Sprintf("%s-%d-%x", domain, i+1, sum[:6])
+		fmt.Fprintf(&b, "step %s %s\n", shellQuote(name), cmd)
+	}
+
+	flags := os.O_CREATE | os.O_WRONLY | os.O_APPEND
+	if !api.scriptStarted {
+		flags = os.O_CREATE | os.O_WRONLY | os.O_TRUNC
+	}
+	f, err := os.OpenFile(api.scriptPath, flags, 0700)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	if _, err := f.WriteString(b.String()); err != nil {
+		return err
+	}
+	api.scriptStarted = true
+	return nil
+}
+
+// curlCommand renders call as a curl command line.
+func (api *hetznerProvider) curlCommand(call apiCall) (string, error) {
+	args := []string{
+		"curl", "--fail", "--silent", "--show-error",
+		"-X", call.method,
+		"-H", `"Auth-API-Token: $HETZNER_API_KEY"`,
+	}
+	if call.request != nil {
+		body, err := json.Marshal(call.request)
+		if err != nil {
+			return "", err
+		}
+		args = append(args, "-H", shellQuote("Content-Type: application/json"), "--data", shellQuote(string(body)))
+	}
+	args = append(args, shellQuote(api.baseURL+call.endpoint))
+	return strings.Join(args, " "), nil
+}
+
+// shellQuote quotes s for a POSIX shell.
+func shellQuote(s string) string {
+	return "'" + strings.Replace(s, "'", `'\''`, -1) + "'"
+}
@@ -0,0 +1,106 @@
+package hetzner
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/StackExchange/dnscontrol/v3/models"
+	"github.com/miekg/dns"
+)
+
+// getSOA returns the SOA record of the zone, nil if HETZNER does not list one.
+func (api *hetznerProvider) getSOA(ctx context.Context, domain string) (*models.RecordConfig, error) {
+	records, err := api.getCachedRecords(ctx, domain)
+	if err != nil {
+		return nil, err
+	}
+	for i := range records {
+		if records[i].Type == "SOA" {
+			return toRecordConfig(domain, &records[i]), nil
+		}
+	}
+	return nil, nil
+}
+
+// setSOA updates the SOA record of the zone.
+// The records API does not allow changing the SOA record, hence the zone is
+// exported, the SOA record is replaced and the zone is imported again.
+func (api *hetznerProvider) setSOA(ctx context.Context, domain string, desired *models.RecordConfig) error {
+	zone, err := api.getCurrentZone(ctx, domain)
+	if err != nil {
+		return err
+	}
+	var exported zoneFile
+	if err := api.request(ctx, "/zones/"+zone.ID+"/export", "GET", nil, &exported); err != nil {
+		return fmt.Errorf("failed exporting zone %q: %w", domain, err)
+	}
+	updated, err := replaceSOA(exported, domain, desired)
+	if err != nil {
+		return fmt.Errorf("failed updating the SOA record of %q: %w", domain, err)
+	}
+	return api.auditedRequest(ctx, "/zones/"+zone.ID+"/import", "POST", updated, nil)
+}
+
+// replaceSOA returns file with the SOA record set to desired.
+// The serial is kept, it is maintained by HETZNER.
+func replaceSOA(file zoneFile, domain string, desired *models.RecordConfig) (zoneFile, error) {
+	zp := dns.NewZoneParser(strings.NewReader(string(file)), dns.Fqdn(domain), "")
+	var lines []string
+	found := false
+	for rr, ok := zp.Next(); ok; rr, ok = zp.Next() {
+		if soa, isSOA := rr.(*dns.SOA); isSOA {
+			soa.Hdr.Ttl = desired.TTL
+			soa.Ns = desired.GetTargetField()
+			soa.Mbox = desired.SoaMbox
+			soa.Refresh = desired.SoaRefresh
+			soa.Retry = desired.SoaRetry
+			soa.Expire = desired.SoaExpire
+			soa.Minttl = desired.SoaMinttl
+			found = true
+		}
+		lines = append(lines, rr.String())
+	}
+	if err := zp.Err(); err != nil {
+		return "", fmt.Errorf("failed parsing the exported zone: %w", err)
+	}
+	if !found {
+		return "", fmt.Errorf("the exported zone has no SOA record")
+	}
+	return zoneFile(strings.Join(lines, "\n") + "\n"), nil
+}
+
+// soaString describes the SOA fields that are compared. The serial is
+// left out, HETZNER increments it on every change.
+func soaString(rc *models.RecordConfig) string {
+	return fmt.Sprintf("%s %s %d %d %d %d ttl=%d",
+		rc.GetTargetField(), rc.SoaMbox, rc.SoaRefresh, rc.SoaRetry, rc.SoaExpire, rc.SoaMinttl, rc.TTL)
+}
+
+// getSOACorrections returns the correction that updates the SOA record of
+// the zone, if it differs from desired. desired may be nil, the SOA record
+// is not managed then.
+func (api *hetznerProvider) getSOACorrections(ctx context.Context, domain string, desired *models.RecordConfig) ([]*models.Correction, error) {
+	if desired == nil {
+		return nil, nil
+	}
+	existing, err := api.getSOA(ctx, domain)
+	if err != nil {
+		return nil, err
+	}
+	if existing != nil && soaString(existing) == soaString(desired) {
+		return nil, nil
+	}
+	msg := fmt.Sprintf("Update SOA of %s: %s", domain, soaString(desired))
+	if existing != nil {
+		msg = fmt.Sprintf("Update SOA of %s: (%s) -> (%s)", domain, soaString(existing), soaString(desired))
+	}
+	if api.scriptPath != "" {
+		// Like a zone replacement, the imported zone is computed when it runs.
+		msg += " (not exported, the zone file is computed when the correction runs)"
+	}
+	return []*models.Correction{{
+		Msg: msg,
+		F:   func() error { return api.setSOA(ctx, domain, desired) },
+	}}, nil
+}
@@ -1,21 +1,28 @@
 package hetzner
 
 import (
-	"github.com/StackExchange/dnscontrol/v3/models"
+	"fmt"
 	"strings"
+
+	"github.com/StackExchange/dnscontrol/v3/models"
+	"github.com/miekg/dns"
 )
 
 type bulkCreateRecordsRequest struct {
 	Records []record `json:"records"`
 }
 
+type bulkCreateRecordsResponse struct {
+	Records []record `json:"records"`
+}
+
 type bulkUpdateRecordsRequest struct {
 	Records []record `json:"records"`
 }
 
 type createRecordRequest struct {
 	Name   string `json:"name"`
-	TTL    int    `json:"ttl"`
+	TTL    *int   `json:"ttl"`
 	Type   string `json:"type"`
 	Value  string `json:"value"`
 	ZoneID string `json:"zone_id"`
@@ -25,6 +32,35 @@ type createZoneRequest struct {
 	Name string `json:"name"`
 }
 
+type createZoneResponse struct {
+	Zone zone `json:"zone"`
+}
+
+type dnssecResponse struct {
+	DNSSEC dnssecStatus `json:"dnssec"`
+}
+
+// dnssecStatus is the DNSSEC state of a zone. HETZNER generates the DS
+// records when DNSSEC is enabled, they have to be added at the registrar.
+type dnssecStatus struct {
+	Enabled   bool       `json:"enabled"`
+	DSRecords []dsRecord `json:"ds_records"`
+}
+
+type dsRecord struct {
+	KeyTag     uint16 `json:"key_tag"`
+	Algorithm  uint8  `json:"algorithm"`
+	DigestType uint8  `json:"digest_type"`
+	Digest     string `json:"digest"`
+}
+
+type errorResponse struct {
+	Error struct {
+		Message string `json:"message"`
+		Code    int    `json:"code"`
+	} `json:"error"`
+}
+
 type getAllRecordsResponse struct {
 	Records []record `json:"records"`
 	Meta    struct {
@@ -43,6 +79,10 @@ type getAllZonesResponse struct {
 	} `json:"meta"`
 }
 
+// zoneFile is a zone in BIND format, as exported and imported by HETZNER.
+// It is sent and received as plain text instead of JSON.
+type zoneFile string
+
 type record struct {
 	ID     string `json:"id"`
 	Name   string `json:"name"`
@@ -50,24 +90,80 @@ type record struct {
 	Type   string `json:"type"`
 	Value  string `json:"value"`
 	ZoneID string `json:"zone_id"`
+	// defaultTTL is set if the record has no TTL of its own, like the
+	// records HETZNER creates with a zone. TTL is the one of the zone then.
+	defaultTTL bool
 }
 
 type zone struct {
-	ID          string   `json:"id"`
-	Name        string   `json:"name"`
-	NameServers []string `json:"ns"`
-	TTL         int      `json:"ttl"`
+	ID              string           `json:"id"`
+	Name            string           `json:"name"`
+	NameServers     []string         `json:"ns"`
+	TTL             int              `json:"ttl"`
+	TxtVerification *txtVerification `json:"txt_verification,omitempty"`
+}
+
+// txtVerification is the TXT record that HETZNER may require to activate a zone.
+type txtVerification struct {
+	Name  string `json:"name"`
+	Token string `json:"token"`
+}
+
+// verification describes the TXT record required to verify the zone, if any.
+func (z *zone) verification() string {
+	if z == nil || z.TxtVerification == nil || z.TxtVerification.Token == "" {
+		return ""
+	}
+	name := z.TxtVerification.Name
+	if name == "" {
+		name = "@"
+	}
+	return fmt.Sprintf("%s TXT %q", name, z.TxtVerification.Token)
+}
+
+// hostnameTargets are the types whose target is a hostname.
+var hostnameTargets = map[string]bool{
+	"CNAME": true,
+	"MX":    true,
+	"NAPTR": true, // the replacement
+	"NS":    true,
+	"PTR":   true,
+	"SRV":   true,
+}
+
+// fqdnTarget returns a hostname target with trailing dot. HETZNER returns
+// targets with or without it, regardless of how they were written. A single
+// label w/o trailing dot is relative to the zone, as in a zone file, any
+// other name is taken as FQDN.
+func fqdnTarget(target, domain string) string {
+	if target == "" || strings.HasSuffix(target, ".") {
+		return target
+	}
+	if !strings.Contains(target, ".") {
+		return target + "." + domain + "."
+	}
+	return target + "."
 }
 
 func fromRecordConfig(in *models.RecordConfig, zone *zone) *record {
-	ttl := int(in.TTL)
+	if hostnameTargets[in.Type] {
+		if target := fqdnTarget(in.GetTargetField(), zone.Name); target != in.GetTargetField() {
+			fixed := *in
+			fixed.SetTarget(target)
+			in = &fixed
+		}
+	}
 	record := &record{
 		Name:   in.GetLabel(),
 		Type:   in.Type,
 		Value:  in.GetTargetCombined(),
-		TTL:    &ttl,
 		ZoneID: zone.ID,
 	}
+	if in.TTL != 0 {
+		ttl := int(in.TTL)
+		record.TTL = &ttl
+	}
+	// else: send "ttl": null, the record uses the default TTL of the zone.
 
 	if record.Type == "TXT" && len(in.TxtStrings) == 1 {
 		// HACK: HETZNER rejects values that fit into 255 bytes w/o quotes,
@@ -101,7 +197,37 @@ func toRecordConfig(domain string, record *record) *models.RecordConfig {
 		value = strings.TrimRight(value, " ")
 	}
 
-	_ = rc.PopulateFromString(record.Type, value, domain)
+	if record.Type == "NAPTR" {
+		_ = setTargetNAPTR(rc, value)
+	} else {
+		_ = rc.PopulateFromString(record.Type, value, domain)
+	}
+	if hostnameTargets[rc.Type] {
+		rc.SetTarget(fqdnTarget(rc.GetTargetField(), domain))
+	}
 
 	return rc
 }
+
+// setTargetNAPTR sets the NAPTR fields of rc from a value returned by
+// HETZNER, e.g. `100 10 "u" "E2U+sip" "!^.*$!sip:info@example.com!" .`.
+// The flags, service and regexp are quoted and the regexp may contain
+// spaces, the dns package deals with the quoting. The replacement is left
+// as is, to be made a FQDN like other targets. HETZNER may strip the
+// trailing dot of the empty replacement (`.`), which leaves none at all.
+func setTargetNAPTR(rc *models.RecordConfig, value string) error {
+	value = strings.TrimSpace(value)
+	fields, replacement := value, ""
+	if i := strings.LastIndexAny(value, " \t"); i >= 0 && !strings.HasSuffix(value, `"`) {
+		fields, replacement = strings.TrimSpace(value[:i]), value[i+1:]
+	}
+	if replacement == "" {
+		replacement = "."
+	}
+	rr, err := dns.NewRR(". NAPTR " + fields + " .")
+	if err != nil || rr == nil {
+		return fmt.Errorf("NAPTR value does not contain 6 fields: (%#v)", value)
+	}
+	n := rr.(*dns.NAPTR)
+	return rc.SetTargetNAPTR(n.Order, n.Preference, n.Flags, n.Service, n.Regexp, replacement)
+}
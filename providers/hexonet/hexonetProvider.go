@@ -72,4 +72,5 @@ func init() {
 	}
 	providers.RegisterRegistrarType("HEXONET", newReg)
 	providers.RegisterDomainServiceProviderType("HEXONET", fns, features)
+	providers.RegisterMaintainer("HEXONET", "@papakai")
 }
@@ -38,6 +38,7 @@ func init() {
 		RecordAuditor: AuditRecords,
 	}
 	providers.RegisterDomainServiceProviderType("HOSTINGDE", fns, features)
+	providers.RegisterMaintainer("HOSTINGDE", "@juliusrickert")
 }
 
 func newHostingde(m map[string]string) (*hostingdeProvider, error) {
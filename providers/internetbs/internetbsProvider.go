@@ -21,6 +21,7 @@ Info required in `creds.json`:
 
 func init() {
 	providers.RegisterRegistrarType("INTERNETBS", newInternetBs)
+	providers.RegisterMaintainer("INTERNETBS", "@pragmaton")
 }
 
 func newInternetBs(m map[string]string) (providers.Registrar, error) {
@@ -98,6 +98,7 @@ func init() {
 		RecordAuditor: AuditRecords,
 	}
 	providers.RegisterDomainServiceProviderType("LINODE", fns, features)
+	providers.RegisterMaintainer("LINODE", "@koesie10")
 }
 
 // GetNameservers returns the nameservers for a domain.
@@ -26,6 +26,7 @@ func init() {
 		RecordAuditor: AuditRecords,
 	}
 	providers.RegisterDomainServiceProviderType("NETCUP", fns, features)
+	providers.RegisterMaintainer("NETCUP", "@kordianbruck")
 }
 
 // New creates a new API handle.
@@ -29,6 +29,7 @@ func init() {
 		RecordAuditor: AuditRecords,
 	}
 	providers.RegisterDomainServiceProviderType("NS1", fns, providers.CanUseSRV, docNotes)
+	providers.RegisterMaintainer("NS1", "@captncraig")
 	providers.RegisterCustomRecordType("NS1_URLFWD", "NS1", "URLFWD")
 }
 
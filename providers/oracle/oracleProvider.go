@@ -40,6 +40,7 @@ func init() {
 		RecordAuditor: AuditRecords,
 	}
 	providers.RegisterDomainServiceProviderType("ORACLE", fns, features)
+	providers.RegisterMaintainer("ORACLE", "@kallsyms")
 }
 
 type oracleProvider struct {
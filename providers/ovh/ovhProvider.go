@@ -61,6 +61,7 @@ func init() {
 	}
 	providers.RegisterRegistrarType("OVH", newReg)
 	providers.RegisterDomainServiceProviderType("OVH", fns, features)
+	providers.RegisterMaintainer("OVH", "@masterzen")
 }
 
 func (c *ovhProvider) GetNameservers(domain string) ([]*models.Nameserver, error) {
@@ -37,6 +37,7 @@ func init() {
 		RecordAuditor: AuditRecords,
 	}
 	providers.RegisterDomainServiceProviderType("POWERDNS", fns, features)
+	providers.RegisterMaintainer("POWERDNS", "@jpbede")
 }
 
 // powerdnsProvider represents the powerdnsProvider DNSServiceProvider.
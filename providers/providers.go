@@ -1,11 +1,14 @@
 package providers
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"log"
+	"strings"
 
 	"github.com/StackExchange/dnscontrol/v3/models"
+	"github.com/StackExchange/dnscontrol/v3/pkg/diff"
 )
 
 // Registrar is an interface for a domain registrar. It can return a list of needed corrections to be applied in the future. Implement this only if the provider is a "registrar" (i.e. can update the NS records of the parent to a domain).
@@ -14,6 +17,15 @@ type Registrar interface {
 }
 
 // DNSServiceProvider is able to generate a set of corrections that need to be made to correct records for a domain. Implement this only if the provider is a DNS Service Provider (can update records in a DNS zone).
+//
+// The contract, for built-in and out-of-tree providers alike:
+// GetDomainCorrections receives the desired records after they were
+// normalized, checked against the capabilities of the provider and
+// audited by its RecordAuditor. It must not change the zone itself, only
+// the functions of the corrections it returns do. GetZoneRecords returns
+// the existing records, it is used by get-zones and to report changes.
+// A provider may implement the optional interfaces below, e.g.
+// DomainCreator or ZoneLister, to support more commands.
 type DNSServiceProvider interface {
 	models.DNSProvider
 }
@@ -31,6 +43,110 @@ type ZoneLister interface {
 	ListZones() ([]string, error)
 }
 
+// ChangeLister should be implemented by providers whose corrections are
+// not a plain diff of the records of GetZoneRecords, e.g. because they
+// leave out records that the API manages itself. ListChangesContext
+// returns the changes of records that GetDomainCorrections would make, and
+// the number of existing records they were diffed with. They are used for
+// reports and the deletion limits of push; the records of other providers
+// are diffed with pkg/diff instead.
+type ChangeLister interface {
+	ListChangesContext(ctx context.Context, dc *models.DomainConfig) (create, del, modify diff.Changeset, existingCount int, err error)
+}
+
+// DNSServiceProviderContext should be implemented by providers whose
+// requests can be cancelled. The methods are like those of
+// DNSServiceProvider, but abort when ctx is done. Use the functions
+// GetZoneRecordsContext and GetDomainCorrectionsContext, they fall back to
+// the methods without a context for other providers.
+type DNSServiceProviderContext interface {
+	GetZoneRecordsContext(ctx context.Context, domain string) (models.Records, error)
+	GetDomainCorrectionsContext(ctx context.Context, dc *models.DomainConfig) ([]*models.Correction, error)
+}
+
+// ZoneListerContext is like ZoneLister, for providers whose requests can
+// be cancelled. Use the function ListZonesContext.
+type ZoneListerContext interface {
+	ListZonesContext(ctx context.Context) ([]string, error)
+}
+
+// GetZoneRecordsContext returns the records of a zone at p. Unless p
+// implements DNSServiceProviderContext, ctx is only checked before the
+// records are fetched.
+func GetZoneRecordsContext(ctx context.Context, p models.DNSProvider, domain string) (models.Records, error) {
+	if pc, ok := p.(DNSServiceProviderContext); ok {
+		return pc.GetZoneRecordsContext(ctx, domain)
+	}
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	return p.GetZoneRecords(domain)
+}
+
+// GetDomainCorrectionsContext returns the corrections of dc at p. Unless p
+// implements DNSServiceProviderContext, ctx is only checked before the
+// corrections are computed.
+func GetDomainCorrectionsContext(ctx context.Context, p models.DNSProvider, dc *models.DomainConfig) ([]*models.Correction, error) {
+	if pc, ok := p.(DNSServiceProviderContext); ok {
+		return pc.GetDomainCorrectionsContext(ctx, dc)
+	}
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	return p.GetDomainCorrections(dc)
+}
+
+type planKey struct{}
+
+// WithPlan returns a context for computing the corrections that preview
+// or push plan for a zone. Providers that export the planned changes,
+// like the export_script of HETZNER, only do so for such a context, not
+// e.g. for the challenge records of get-certs.
+func WithPlan(ctx context.Context) context.Context {
+	return context.WithValue(ctx, planKey{}, true)
+}
+
+// IsPlan returns true if ctx was created by WithPlan.
+func IsPlan(ctx context.Context) bool {
+	plan, _ := ctx.Value(planKey{}).(bool)
+	return plan
+}
+
+// ListZonesContext lists the zones of l. Unless l implements
+// ZoneListerContext, ctx is only checked before the zones are listed.
+func ListZonesContext(ctx context.Context, l ZoneLister) ([]string, error) {
+	if lc, ok := l.(ZoneListerContext); ok {
+		return lc.ListZonesContext(ctx)
+	}
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	return l.ListZones()
+}
+
+// ZoneReplacer should be implemented by providers that can replace all
+// records of a zone with a few requests. Providers that implement it
+// declare the CanReplaceZone capability.
+type ZoneReplacer interface {
+	ReplaceZoneRecords(domain string, records models.Records) error
+}
+
+// ReplaceZoneThreshold is the number of changes to a zone from which
+// providers with the CanReplaceZone capability replace the whole zone
+// instead of applying incremental corrections. Replacing is opt-in, the
+// default of 0 disables it (see --replace-zone-threshold).
+var ReplaceZoneThreshold = 0
+
+// ShouldReplaceZone returns true if changes to dc should be applied by
+// replacing the zone. A replacement removes all records that are not in
+// dc, hence it is never used if dc has to keep unknown or ignored records.
+func ShouldReplaceZone(dc *models.DomainConfig, changes int) bool {
+	if ReplaceZoneThreshold <= 0 || changes < ReplaceZoneThreshold {
+		return false
+	}
+	return !dc.KeepUnknown && len(dc.IgnoredNames) == 0 && len(dc.IgnoredTargets) == 0
+}
+
 // RegistrarInitializer is a function to create a registrar. Function will be passed the unprocessed json payload from the configuration file for the given provider.
 type RegistrarInitializer func(map[string]string) (Registrar, error)
 
@@ -47,7 +163,11 @@ type RecordAuditor func([]*models.RecordConfig) error
 
 // DspFuncs lists functions registered with a provider.
 type DspFuncs struct {
-	Initializer   DspInitializer
+	// Initializer creates an instance of the provider for each entry of
+	// its type in creds.json. RegisterDNSProvider requires it.
+	Initializer DspInitializer
+	// RecordAuditor rejects the records the provider can not handle,
+	// beyond what its capabilities declare. RegisterDNSProvider requires it.
 	RecordAuditor RecordAuditor
 }
 
@@ -72,6 +192,83 @@ func RegisterDomainServiceProviderType(name string, fns DspFuncs, pm ...Provider
 	unwrapProviderCapabilities(name, pm)
 }
 
+// RegisterRegistrar adds a registrar type to the registry, like
+// RegisterDNSProvider does for DNS providers.
+func RegisterRegistrar(name string, init RegistrarInitializer, pm ...ProviderMetadata) error {
+	if err := checkRegistration(name, pm); err != nil {
+		return err
+	}
+	if _, ok := RegistrarTypes[name]; ok {
+		return fmt.Errorf("cannot register registrar type %s multiple times", name)
+	}
+	if init == nil {
+		return fmt.Errorf("registrar type %s has no initializer", name)
+	}
+	RegisterRegistrarType(name, init, pm...)
+	return nil
+}
+
+// RegisterDNSProvider adds a DNS provider type to the registry. It is the
+// entry point for providers that are not part of dnscontrol: a program
+// that embeds dnscontrol registers them before it calls commands.Run, e.g.
+//
+//	func main() {
+//		err := providers.RegisterDNSProvider("APPLIANCE", providers.DspFuncs{
+//			Initializer:   newAppliance,
+//			RecordAuditor: auditAppliance,
+//		}, providers.DocumentationNotes{
+//			providers.CanUseSRV: providers.Can(),
+//		})
+//		if err != nil {
+//			log.Fatal(err)
+//		}
+//		os.Exit(commands.Run("dnscontrol with APPLIANCE"))
+//	}
+//
+// name is the TYPE in creds.json. The capabilities in pm are checked and
+// the records audited like those of the built-in providers. Registering
+// is not safe for concurrent use and must be done before any command runs.
+func RegisterDNSProvider(name string, fns DspFuncs, pm ...ProviderMetadata) error {
+	if err := checkRegistration(name, pm); err != nil {
+		return err
+	}
+	if _, ok := DNSProviderTypes[name]; ok {
+		return fmt.Errorf("cannot register DNS provider type %s multiple times", name)
+	}
+	if fns.Initializer == nil {
+		return fmt.Errorf("DNS provider type %s has no Initializer", name)
+	}
+	if fns.RecordAuditor == nil {
+		return fmt.Errorf("DNS provider type %s has no RecordAuditor", name)
+	}
+	RegisterDomainServiceProviderType(name, fns, pm...)
+	return nil
+}
+
+// checkRegistration checks the name and metadata of a provider before it
+// is registered, so that a failed registration leaves no trace.
+func checkRegistration(name string, pm []ProviderMetadata) error {
+	if name == "" || strings.ContainsAny(name, " \t\n") {
+		return fmt.Errorf("invalid provider type %q", name)
+	}
+	for _, m := range pm {
+		switch m.(type) {
+		case Capability, DocumentationNotes:
+		default:
+			return fmt.Errorf("provider type %s: unrecognized ProviderMetadata type: %T", name, m)
+		}
+	}
+	return nil
+}
+
+// ProviderMaintainers stores the GitHub handle of the maintainer of each provider, keyed by provider type.
+var ProviderMaintainers = map[string]string{}
+
+// RegisterMaintainer records the GitHub handle of the person maintaining a provider.
+func RegisterMaintainer(name string, maintainer string) {
+	ProviderMaintainers[name] = maintainer
+}
+
 // CreateRegistrar initializes a registrar instance from given credentials.
 func CreateRegistrar(rType string, config map[string]string) (Registrar, error) {
 	initer, ok := RegistrarTypes[rType]
@@ -102,6 +299,40 @@ func AuditRecords(dType string, rcs models.Records) error {
 	return p.RecordAuditor(rcs)
 }
 
+// AuditConfig runs the RecordAuditor of each DNS provider used by
+// the domain and returns all the problems found, rather than stopping
+// at the first one.
+func AuditConfig(dc *models.DomainConfig) []error {
+	var errs []error
+	seen := map[string]bool{}
+	for _, p := range dc.DNSProviderInstances {
+		dType := p.ProviderType
+		if seen[dType] {
+			continue
+		}
+		seen[dType] = true
+
+		err := AuditRecords(dType, dc.Records)
+		if err == nil {
+			continue
+		}
+		// Auditors report only the first problem. Audit each record on
+		// its own to find all of them.
+		var recErrs []error
+		for _, rc := range dc.Records {
+			if err := AuditRecords(dType, models.Records{rc}); err != nil {
+				recErrs = append(recErrs, err)
+			}
+		}
+		if len(recErrs) == 0 {
+			// The problem spans multiple records.
+			recErrs = append(recErrs, err)
+		}
+		errs = append(errs, recErrs...)
+	}
+	return errs
+}
+
 // None is a basic provider type that does absolutely nothing. Can be useful as a placeholder for third parties or unimplemented providers.
 type None struct{}
 
@@ -131,7 +362,7 @@ func (n None) GetDomainCorrections(dc *models.DomainConfig) ([]*models.Correctio
 func init() {
 	RegisterRegistrarType("NONE", func(map[string]string) (Registrar, error) {
 		return None{}, nil
-	})
+	}, CanRunConcurrently)
 }
 
 // CustomRType stores an rtype that is only valid for this DSP.
@@ -0,0 +1,188 @@
+package providers
+
+import (
+	"encoding/json"
+	"fmt"
+	"testing"
+
+	"github.com/StackExchange/dnscontrol/v3/models"
+)
+
+func TestRegisterDNSProvider(t *testing.T) {
+	const name = "TEST_OUT_OF_TREE"
+	defer func() {
+		delete(DNSProviderTypes, name)
+		delete(providerCapabilities, name)
+		delete(Notes, name)
+	}()
+
+	initializer := func(map[string]string, json.RawMessage) (DNSServiceProvider, error) {
+		return nil, nil
+	}
+	audited := 0
+	auditor := func(rcs []*models.RecordConfig) error {
+		audited++
+		for _, rc := range rcs {
+			if rc.Type == "TXT" {
+				return fmt.Errorf("TXT is not supported")
+			}
+		}
+		return nil
+	}
+
+	for _, tst := range []struct {
+		desc string
+		name string
+		fns  DspFuncs
+		pm   []ProviderMetadata
+	}{
+		{"no name", "", DspFuncs{Initializer: initializer, RecordAuditor: auditor}, nil},
+		{"space in name", "TEST OUT", DspFuncs{Initializer: initializer, RecordAuditor: auditor}, nil},
+		{"no initializer", name, DspFuncs{RecordAuditor: auditor}, nil},
+		{"no auditor", name, DspFuncs{Initializer: initializer}, nil},
+		{"bad metadata", name, DspFuncs{Initializer: initializer, RecordAuditor: auditor}, []ProviderMetadata{"CanUseSRV"}},
+	} {
+		if err := RegisterDNSProvider(tst.name, tst.fns, tst.pm...); err == nil {
+			t.Errorf("%s: expected an error", tst.desc)
+		}
+		if _, ok := DNSProviderTypes[name]; ok {
+			t.Fatalf("%s: expected %s not to be registered", tst.desc, name)
+		}
+	}
+
+	err := RegisterDNSProvider(name, DspFuncs{Initializer: initializer, RecordAuditor: auditor}, CanUsePTR, DocumentationNotes{
+		CanUseSRV: Can(),
+		CanUseCAA: Cannot(),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := RegisterDNSProvider(name, DspFuncs{Initializer: initializer, RecordAuditor: auditor}); err == nil {
+		t.Errorf("expected an error when registering twice")
+	}
+
+	for cap, want := range map[Capability]bool{CanUsePTR: true, CanUseSRV: true, CanUseCAA: false, CanUseLOC: false} {
+		if got := ProviderHasCapability(name, cap); got != want {
+			t.Errorf("%s: expected %v, got %v", cap, want, got)
+		}
+	}
+	if _, err := CreateDNSProvider(name, nil, nil); err != nil {
+		t.Error(err)
+	}
+
+	txt := &models.RecordConfig{Type: "TXT"}
+	txt.SetLabel("@", "example.com")
+	dc := &models.DomainConfig{
+		Name:                 "example.com",
+		Records:              models.Records{txt},
+		DNSProviderInstances: []*models.DNSProviderInstance{{ProviderBase: models.ProviderBase{ProviderType: name}}},
+	}
+	if errs := AuditConfig(dc); len(errs) != 1 || audited == 0 {
+		t.Errorf("expected the auditor to reject the TXT record, got %v", errs)
+	}
+}
+
+func TestRegisterRegistrar(t *testing.T) {
+	const name = "TEST_OUT_OF_TREE_REGISTRAR"
+	defer func() {
+		delete(RegistrarTypes, name)
+		delete(providerCapabilities, name)
+		delete(Notes, name)
+	}()
+
+	initializer := func(map[string]string) (Registrar, error) {
+		return None{}, nil
+	}
+	for _, tst := range []struct {
+		desc string
+		name string
+		init RegistrarInitializer
+		pm   []ProviderMetadata
+	}{
+		{"no name", "", initializer, nil},
+		{"space in name", "TEST OUT", initializer, nil},
+		{"no initializer", name, nil, nil},
+		{"bad metadata", name, initializer, []ProviderMetadata{"DocCreateDomains"}},
+	} {
+		if err := RegisterRegistrar(tst.name, tst.init, tst.pm...); err == nil {
+			t.Errorf("%s: expected an error", tst.desc)
+		}
+		if _, ok := RegistrarTypes[name]; ok {
+			t.Fatalf("%s: expected %s not to be registered", tst.desc, name)
+		}
+	}
+
+	if err := RegisterRegistrar(name, initializer, DocumentationNotes{DocCreateDomains: Can()}); err != nil {
+		t.Fatal(err)
+	}
+	if err := RegisterRegistrar(name, initializer); err == nil {
+		t.Errorf("expected an error when registering twice")
+	}
+	if !ProviderHasCapability(name, DocCreateDomains) {
+		t.Errorf("expected the capabilities to be registered")
+	}
+	if r, err := CreateRegistrar(name, nil); err != nil || r == nil {
+		t.Errorf("expected the registrar to be created, got %v, %v", r, err)
+	}
+}
+
+func TestAuditConfig(t *testing.T) {
+	const name = "TEST_AUDIT"
+	defer func() {
+		delete(DNSProviderTypes, name)
+		delete(providerCapabilities, name)
+		delete(Notes, name)
+	}()
+	auditor := func(rcs []*models.RecordConfig) error {
+		for _, rc := range rcs {
+			if rc.Type == "TXT" {
+				return fmt.Errorf("TXT at %s is not supported", rc.GetLabel())
+			}
+		}
+		if len(rcs) > 2 {
+			return fmt.Errorf("at most 2 records are supported")
+		}
+		return nil
+	}
+	initializer := func(map[string]string, json.RawMessage) (DNSServiceProvider, error) {
+		return nil, nil
+	}
+	if err := RegisterDNSProvider(name, DspFuncs{Initializer: initializer, RecordAuditor: auditor}); err != nil {
+		t.Fatal(err)
+	}
+
+	rc := func(label, rtype string) *models.RecordConfig {
+		r := &models.RecordConfig{Type: rtype}
+		r.SetLabel(label, "example.com")
+		return r
+	}
+	instance := func(pType string) *models.DNSProviderInstance {
+		return &models.DNSProviderInstance{ProviderBase: models.ProviderBase{ProviderType: pType}}
+	}
+
+	for _, tst := range []struct {
+		desc      string
+		records   models.Records
+		providers []*models.DNSProviderInstance
+		expected  []string
+	}{
+		{"valid", models.Records{rc("@", "A")}, []*models.DNSProviderInstance{instance(name)}, nil},
+		{"one problem", models.Records{rc("a", "TXT"), rc("@", "A")}, []*models.DNSProviderInstance{instance(name)},
+			[]string{"TXT at a is not supported"}},
+		{"all problems", models.Records{rc("a", "TXT"), rc("@", "A"), rc("b", "TXT")}, []*models.DNSProviderInstance{instance(name)},
+			[]string{"TXT at a is not supported", "TXT at b is not supported"}},
+		{"problem of several records", models.Records{rc("a", "A"), rc("b", "A"), rc("c", "A")}, []*models.DNSProviderInstance{instance(name)},
+			[]string{"at most 2 records are supported"}},
+		{"provider type audited once", models.Records{rc("a", "TXT")}, []*models.DNSProviderInstance{instance(name), instance(name)},
+			[]string{"TXT at a is not supported"}},
+	} {
+		dc := &models.DomainConfig{Name: "example.com", Records: tst.records, DNSProviderInstances: tst.providers}
+		var got []string
+		for _, err := range AuditConfig(dc) {
+			got = append(got, err.Error())
+		}
+		if fmt.Sprint(got) != fmt.Sprint(tst.expected) {
+			t.Errorf("%s: expected %q, got %q", tst.desc, tst.expected, got)
+		}
+	}
+}
@@ -42,6 +42,7 @@ func init() {
 		RecordAuditor: AuditRecords,
 	}
 	providers.RegisterDomainServiceProviderType("VULTR", fns, features)
+	providers.RegisterMaintainer("VULTR", "@pgaskin")
 }
 
 // vultrProvider represents the Vultr DNSServiceProvider.